@@ -11,6 +11,7 @@ require (
 	k8s.io/api v0.34.2
 	k8s.io/apimachinery v0.34.2
 	k8s.io/client-go v0.34.2
+	k8s.io/metrics v0.34.2
 )
 
 // Can use this to develop a bit faster when changing the library:
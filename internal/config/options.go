@@ -18,8 +18,20 @@ type Options struct {
 
 	// MaskSecrets determines if secrets should be masked in the output
 	MaskSecrets bool
+
+	// ProtectionLabel is a label key or key=value pair marking objects,
+	// which cannot be deleted by tools unless deletion is forced
+	ProtectionLabel string
+
+	// SampleMetrics determines if usage of pods is sampled in background
+	// for namespaces asked about by recommend-k8s-resources tool
+	SampleMetrics bool
 }
 
+// DefaultProtectionLabel is the label which protects objects
+// from deletion when --protection-label is not specified
+const DefaultProtectionLabel = "mcp-k8s-go/protected"
+
 // GlobalOptions contains the parsed command line options
 var GlobalOptions = &Options{}
 
@@ -29,6 +41,8 @@ func ParseFlags() bool {
 	flag.StringVar(&allowedContextsStr, "allowed-contexts", "", "Comma-separated list of allowed k8s contexts. If empty, all contexts are allowed")
 	flag.BoolVar(&GlobalOptions.Readonly, "readonly", false, "Disables any tool which can write changes to the cluster. If not specified, all tools are allowed")
 	flag.BoolVar(&GlobalOptions.MaskSecrets, "mask-secrets", true, "Mask secrets in the output. Defaults to true; use --mask-secrets=false to disable")
+	flag.StringVar(&GlobalOptions.ProtectionLabel, "protection-label", DefaultProtectionLabel, "Label key or key=value marking objects which cannot be deleted unless forced")
+	flag.BoolVar(&GlobalOptions.SampleMetrics, "sample-metrics", true, "Sample usage of pods in background for resource recommendations. Defaults to true; use --sample-metrics=false to disable")
 
 	// Add other flags here
 
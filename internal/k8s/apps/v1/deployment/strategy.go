@@ -0,0 +1,68 @@
+package deployment
+
+import (
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// StrategyLimits is rolling update strategy resolved to numbers of pods,
+// MaxPods is how many pods may exist during rollout and MinAvailable
+// is how many of them must stay available
+type StrategyLimits struct {
+	Type           string `json:"type"`
+	Replicas       int32  `json:"replicas"`
+	MaxSurge       int32  `json:"maxSurge"`
+	MaxUnavailable int32  `json:"maxUnavailable"`
+	MaxPods        int32  `json:"maxPods"`
+	MinAvailable   int32  `json:"minAvailable"`
+}
+
+// NewStrategyLimits resolves maxSurge and maxUnavailable the same way
+// as deployment controller does, rounding surge up and unavailable down
+func NewStrategyLimits(dep *appsv1.Deployment) (*StrategyLimits, error) {
+	replicas := int32(1)
+	if dep.Spec.Replicas != nil {
+		replicas = *dep.Spec.Replicas
+	}
+	limits := &StrategyLimits{
+		Type:     string(dep.Spec.Strategy.Type),
+		Replicas: replicas,
+	}
+
+	if dep.Spec.Strategy.Type == appsv1.RecreateDeploymentStrategyType {
+		limits.MaxUnavailable = replicas
+	} else {
+		limits.Type = string(appsv1.RollingUpdateDeploymentStrategyType)
+		maxSurge := intstr.FromString("25%")
+		maxUnavailable := intstr.FromString("25%")
+		if rollingUpdate := dep.Spec.Strategy.RollingUpdate; rollingUpdate != nil {
+			if rollingUpdate.MaxSurge != nil {
+				maxSurge = *rollingUpdate.MaxSurge
+			}
+			if rollingUpdate.MaxUnavailable != nil {
+				maxUnavailable = *rollingUpdate.MaxUnavailable
+			}
+		}
+
+		surge, err := intstr.GetScaledValueFromIntOrPercent(&maxSurge, int(replicas), true)
+		if err != nil {
+			return nil, fmt.Errorf("invalid maxSurge of deployment %s: %w", dep.Name, err)
+		}
+		unavailable, err := intstr.GetScaledValueFromIntOrPercent(&maxUnavailable, int(replicas), false)
+		if err != nil {
+			return nil, fmt.Errorf("invalid maxUnavailable of deployment %s: %w", dep.Name, err)
+		}
+		// controller does not allow both to be zero, as rollout could not progress
+		if surge == 0 && unavailable == 0 {
+			unavailable = 1
+		}
+		limits.MaxSurge = int32(surge)
+		limits.MaxUnavailable = int32(min(unavailable, int(replicas)))
+	}
+
+	limits.MaxPods = replicas + limits.MaxSurge
+	limits.MinAvailable = max(replicas-limits.MaxUnavailable, 0)
+	return limits, nil
+}
@@ -0,0 +1,210 @@
+package rollout
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/strowk/mcp-k8s-go/internal/utils"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+)
+
+const restartedAtAnnotation = "kubectl.kubernetes.io/restartedAt"
+
+// Result describes outcome of rollout action
+type Result struct {
+	Kind      Kind           `json:"kind"`
+	Name      string         `json:"name"`
+	Namespace string         `json:"namespace"`
+	Message   string         `json:"message"`
+	DryRun    bool           `json:"dryRun,omitempty"`
+	Changes   []utils.Change `json:"changes,omitempty"`
+}
+
+// Restart triggers new rollout by setting restartedAt annotation on pod template,
+// the same way as kubectl rollout restart does
+func Restart(ctx context.Context, clientset kubernetes.Interface, kind Kind, namespace string, name string, dryRun bool) (*Result, error) {
+	w, err := getWorkload(ctx, clientset, kind, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	if w.paused {
+		return nil, fmt.Errorf("can't restart paused %s %q, resume it first", kind.displayName(), name)
+	}
+
+	patch, err := json.Marshal(map[string]any{
+		"spec": map[string]any{
+			"template": map[string]any{
+				"metadata": map[string]any{
+					"annotations": map[string]string{
+						restartedAtAnnotation: time.Now().Format(time.RFC3339),
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	patched, err := patchWorkload(ctx, clientset, kind, namespace, name, types.StrategicMergePatchType, patch, dryRun)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Result{
+		Kind:      kind,
+		Name:      name,
+		Namespace: namespace,
+		Message:   fmt.Sprintf("%s %q restarted", kind.displayName(), name),
+		DryRun:    dryRun,
+		Changes:   utils.Diff(templateContent(&w.template), templateContent(&patched.template)),
+	}, nil
+}
+
+// Undo rolls back pod template of the workload to the given revision,
+// or to the previous one if revision is 0
+func Undo(
+	ctx context.Context,
+	clientset kubernetes.Interface,
+	kind Kind,
+	namespace string,
+	name string,
+	toRevision int64,
+	dryRun bool,
+) (*Result, error) {
+	w, err := getWorkload(ctx, clientset, kind, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	if w.paused {
+		return nil, fmt.Errorf("can't roll back paused %s %q, resume it first", kind.displayName(), name)
+	}
+
+	revisions, err := listRevisions(ctx, clientset, kind, w)
+	if err != nil {
+		return nil, err
+	}
+
+	var target *revision
+	if toRevision == 0 {
+		if len(revisions) < 2 {
+			return nil, fmt.Errorf("no previous revision found in rollout history of %s %q", kind.displayName(), name)
+		}
+		target = &revisions[len(revisions)-2]
+	} else {
+		for i := range revisions {
+			if revisions[i].number == toRevision {
+				target = &revisions[i]
+				break
+			}
+		}
+		if target == nil {
+			return nil, fmt.Errorf("unable to find revision %d in rollout history of %s %q", toRevision, kind.displayName(), name)
+		}
+	}
+
+	var patchType types.PatchType
+	var patch []byte
+	if kind == KindDeployment {
+		template := target.template.DeepCopy()
+		// label is added by deployment controller to pods of each ReplicaSet
+		// and is not part of template of the deployment itself
+		delete(template.Labels, appsv1.DefaultDeploymentUniqueLabelKey)
+		patchType = types.JSONPatchType
+		patch, err = json.Marshal([]map[string]any{
+			{"op": "replace", "path": "/spec/template", "value": template},
+		})
+	} else {
+		// ControllerRevision stores exactly the patch restoring the revision
+		patchType = types.StrategicMergePatchType
+		patch = target.data
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	patched, err := patchWorkload(ctx, clientset, kind, namespace, name, patchType, patch, dryRun)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{
+		Kind:      kind,
+		Name:      name,
+		Namespace: namespace,
+		DryRun:    dryRun,
+		Changes:   utils.Diff(templateContent(&w.template), templateContent(&patched.template)),
+	}
+	if len(result.Changes) == 0 {
+		result.Message = fmt.Sprintf("skipped rollback of %s %q, current template already matches revision %d", kind.displayName(), name, target.number)
+	} else {
+		result.Message = fmt.Sprintf("%s %q rolled back to revision %d", kind.displayName(), name, target.number)
+	}
+	return result, nil
+}
+
+// Pause marks Deployment as paused, so changes of its template do not trigger rollouts
+func Pause(ctx context.Context, clientset kubernetes.Interface, kind Kind, namespace string, name string, dryRun bool) (*Result, error) {
+	return setPaused(ctx, clientset, kind, namespace, name, true, dryRun)
+}
+
+// Resume resumes paused Deployment
+func Resume(ctx context.Context, clientset kubernetes.Interface, kind Kind, namespace string, name string, dryRun bool) (*Result, error) {
+	return setPaused(ctx, clientset, kind, namespace, name, false, dryRun)
+}
+
+func setPaused(
+	ctx context.Context,
+	clientset kubernetes.Interface,
+	kind Kind,
+	namespace string,
+	name string,
+	paused bool,
+	dryRun bool,
+) (*Result, error) {
+	action := "paused"
+	if !paused {
+		action = "resumed"
+	}
+
+	if kind != KindDeployment {
+		return nil, fmt.Errorf("%s %q can not be %s, only deployments support pausing", kind.displayName(), name, action)
+	}
+
+	w, err := getWorkload(ctx, clientset, kind, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{
+		Kind:      kind,
+		Name:      name,
+		Namespace: namespace,
+		DryRun:    dryRun,
+	}
+	if w.paused == paused {
+		if paused {
+			result.Message = fmt.Sprintf("%s %q is already paused", kind.displayName(), name)
+		} else {
+			result.Message = fmt.Sprintf("%s %q is not paused", kind.displayName(), name)
+		}
+		return result, nil
+	}
+
+	patch, err := json.Marshal(map[string]any{
+		"spec": map[string]any{"paused": paused},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := patchWorkload(ctx, clientset, kind, namespace, name, types.MergePatchType, patch, dryRun); err != nil {
+		return nil, err
+	}
+
+	result.Message = fmt.Sprintf("%s %q %s", kind.displayName(), name, action)
+	return result, nil
+}
@@ -0,0 +1,418 @@
+package rollout
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/strowk/mcp-k8s-go/internal/k8s/apps/v1/deployment"
+	"github.com/strowk/mcp-k8s-go/internal/k8s/core/v1/pod"
+	"github.com/strowk/mcp-k8s-go/internal/k8s/core/v1/quota"
+	"github.com/strowk/mcp-k8s-go/internal/utils"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// maxDiagnosedPods limits number of unready new pods diagnosed in detail
+const maxDiagnosedPods = 5
+
+// DeploymentDiagnosis explains why rollout of Deployment does not progress
+// by comparing its new and old ReplicaSets, diagnosing unready new pods and
+// checking whether pods allowed by rolling update strategy fit into quotas
+type DeploymentDiagnosis struct {
+	Deployment      *deployment.DeploymentInList `json:"deployment"`
+	Status          *Status                      `json:"status"`
+	Findings        []pod.Finding                `json:"findings"`
+	Conditions      []string                     `json:"conditions,omitempty"`
+	Strategy        *deployment.StrategyLimits   `json:"strategy"`
+	NewReplicaSet   *ReplicaSetInRollout         `json:"newReplicaSet,omitempty"`
+	OldReplicaSets  []ReplicaSetInRollout        `json:"oldReplicaSets,omitempty"`
+	TemplateChanges []utils.Change               `json:"templateChanges,omitempty"`
+	UnreadyPods     []UnreadyPod                 `json:"unreadyPods,omitempty"`
+	Quotas          []QuotaCheck                 `json:"quotas,omitempty"`
+}
+
+type ReplicaSetInRollout struct {
+	Name              string            `json:"name"`
+	Revision          int64             `json:"revision"`
+	Replicas          int32             `json:"replicas"`
+	ReadyReplicas     int32             `json:"readyReplicas"`
+	AvailableReplicas int32             `json:"availableReplicas"`
+	Images            map[string]string `json:"images"`
+	Conditions        []string          `json:"conditions,omitempty"`
+}
+
+type UnreadyPod struct {
+	Name    string `json:"name"`
+	Phase   string `json:"phase"`
+	Verdict string `json:"verdict,omitempty"`
+}
+
+// QuotaCheck compares what is left in ResourceQuota with what pods,
+// which rollout is still allowed to create, would need
+type QuotaCheck struct {
+	Quota    string `json:"quota"`
+	Resource string `json:"resource"`
+	Hard     string `json:"hard"`
+	Used     string `json:"used"`
+	Needed   string `json:"needed"`
+	Fits     bool   `json:"fits"`
+}
+
+// DiagnoseDeployment inspects rollout of the Deployment to explain why it is stuck
+func DiagnoseDeployment(ctx context.Context, clientset kubernetes.Interface, namespace string, name string) (*DeploymentDiagnosis, error) {
+	d, err := clientset.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	status := &Status{Kind: KindDeployment, Name: name, Namespace: namespace, Paused: d.Spec.Paused}
+	setDeploymentStatus(status, d)
+
+	limits, err := deployment.NewStrategyLimits(d)
+	if err != nil {
+		return nil, err
+	}
+
+	diagnosis := &DeploymentDiagnosis{
+		Deployment: deployment.NewDeploymentInList(d),
+		Status:     status,
+		Findings:   []pod.Finding{},
+		Strategy:   limits,
+	}
+	diagnosis.checkDeploymentConditions(d)
+
+	newReplicaSet, oldReplicaSets, err := deploymentReplicaSets(ctx, clientset, d)
+	if err != nil {
+		return nil, err
+	}
+	if newReplicaSet != nil {
+		summary := newReplicaSetInRollout(newReplicaSet)
+		diagnosis.NewReplicaSet = &summary
+		diagnosis.checkReplicaSetConditions(newReplicaSet)
+		if err := diagnosis.checkNewPods(ctx, clientset, newReplicaSet); err != nil {
+			return nil, err
+		}
+	} else if !status.Done && d.Status.ObservedGeneration >= d.Generation {
+		diagnosis.add(pod.Finding{
+			Severity: pod.SeverityWarning,
+			Reason:   "NewReplicaSetMissing",
+			Message:  "ReplicaSet for current pod template was not created yet",
+		})
+	}
+	for _, replicaSet := range oldReplicaSets {
+		diagnosis.OldReplicaSets = append(diagnosis.OldReplicaSets, newReplicaSetInRollout(replicaSet))
+	}
+
+	if newReplicaSet != nil && len(oldReplicaSets) > 0 {
+		diagnosis.TemplateChanges = utils.Diff(
+			templateContent(templateWithoutHash(&oldReplicaSets[0].Spec.Template)),
+			templateContent(templateWithoutHash(&newReplicaSet.Spec.Template)),
+		)
+	}
+
+	if !status.Done {
+		diagnosis.checkStrategy(d, oldReplicaSets)
+		if err := diagnosis.checkQuotas(ctx, clientset, d); err != nil {
+			return nil, err
+		}
+	}
+	return diagnosis, nil
+}
+
+func (d *DeploymentDiagnosis) add(finding pod.Finding) {
+	d.Findings = append(d.Findings, finding)
+}
+
+func (d *DeploymentDiagnosis) checkDeploymentConditions(dep *appsv1.Deployment) {
+	if dep.Spec.Paused {
+		d.add(pod.Finding{
+			Severity: pod.SeverityWarning,
+			Reason:   "Paused",
+			Message:  "rollout is paused, new pod template is not rolled out until it is resumed",
+		})
+	}
+
+	for _, condition := range dep.Status.Conditions {
+		d.Conditions = append(d.Conditions, formatCondition(string(condition.Type), condition.Status, condition.Reason, condition.Message))
+		switch {
+		case condition.Type == appsv1.DeploymentProgressing && condition.Reason == "ProgressDeadlineExceeded":
+			evidence := []string{}
+			if dep.Spec.ProgressDeadlineSeconds != nil {
+				evidence = append(evidence, fmt.Sprintf("progressDeadlineSeconds: %d", *dep.Spec.ProgressDeadlineSeconds))
+			}
+			if !condition.LastUpdateTime.IsZero() {
+				evidence = append(evidence, "last progress: "+condition.LastUpdateTime.UTC().Format(time.RFC3339))
+			}
+			d.add(pod.Finding{
+				Severity: pod.SeverityError,
+				Reason:   "ProgressDeadlineExceeded",
+				Message:  condition.Message,
+				Evidence: evidence,
+			})
+		case condition.Type == appsv1.DeploymentReplicaFailure && condition.Status == corev1.ConditionTrue:
+			d.add(pod.Finding{
+				Severity: pod.SeverityError,
+				Reason:   condition.Reason,
+				Message:  condition.Message,
+			})
+		}
+	}
+}
+
+func (d *DeploymentDiagnosis) checkReplicaSetConditions(replicaSet *appsv1.ReplicaSet) {
+	for _, condition := range replicaSet.Status.Conditions {
+		if condition.Type == appsv1.ReplicaSetReplicaFailure && condition.Status == corev1.ConditionTrue {
+			d.add(pod.Finding{
+				Severity: pod.SeverityError,
+				Reason:   condition.Reason,
+				Message:  fmt.Sprintf("new ReplicaSet %s cannot create pods: %s", replicaSet.Name, condition.Message),
+			})
+		}
+	}
+}
+
+// checkNewPods finds pods of new ReplicaSet, which are not ready,
+// and diagnoses some of them to explain why
+func (d *DeploymentDiagnosis) checkNewPods(ctx context.Context, clientset kubernetes.Interface, replicaSet *appsv1.ReplicaSet) error {
+	selector, err := metav1.LabelSelectorAsSelector(replicaSet.Spec.Selector)
+	if err != nil {
+		return fmt.Errorf("invalid selector of ReplicaSet %s: %w", replicaSet.Name, err)
+	}
+	pods, err := clientset.CoreV1().Pods(replicaSet.Namespace).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return err
+	}
+
+	total := 0
+	evidence := []string{}
+	for _, p := range pods.Items {
+		if !metav1.IsControlledBy(&p, replicaSet) || p.DeletionTimestamp != nil {
+			continue
+		}
+		total++
+		if pod.IsPodReady(&p) {
+			continue
+		}
+		unready := UnreadyPod{Name: p.Name, Phase: string(p.Status.Phase)}
+		if len(d.UnreadyPods) < maxDiagnosedPods {
+			diagnosis, err := pod.Diagnose(ctx, clientset, p.Namespace, p.Name, pod.DiagnoseOptions{})
+			if err != nil {
+				unready.Verdict = fmt.Sprintf("failed to diagnose: %v", err)
+			} else {
+				unready.Verdict = diagnosis.Verdict
+			}
+			evidence = append(evidence, fmt.Sprintf("%s: %s", p.Name, unready.Verdict))
+		}
+		d.UnreadyPods = append(d.UnreadyPods, unready)
+	}
+
+	if len(d.UnreadyPods) > 0 {
+		d.add(pod.Finding{
+			Severity: pod.SeverityError,
+			Reason:   "NewPodsNotReady",
+			Message:  fmt.Sprintf("%d of %d pods of new ReplicaSet %s are not ready", len(d.UnreadyPods), total, replicaSet.Name),
+			Evidence: evidence,
+		})
+	}
+	return nil
+}
+
+// checkStrategy explains when rolling update cannot remove more old pods,
+// because new pods do not become available and too few would remain
+func (d *DeploymentDiagnosis) checkStrategy(dep *appsv1.Deployment, oldReplicaSets []*appsv1.ReplicaSet) {
+	if d.Strategy.Type != string(appsv1.RollingUpdateDeploymentStrategyType) {
+		return
+	}
+	oldReplicas := int32(0)
+	for _, replicaSet := range oldReplicaSets {
+		oldReplicas += replicaSet.Status.Replicas
+	}
+	if oldReplicas == 0 || len(d.UnreadyPods) == 0 {
+		return
+	}
+
+	available := dep.Status.AvailableReplicas
+	if available <= d.Strategy.MinAvailable {
+		d.add(pod.Finding{
+			Severity: pod.SeverityWarning,
+			Reason:   "ScaleDownBlocked",
+			Message: fmt.Sprintf(
+				"%d old pods cannot be removed until new pods become available, because %d pods are available and at least %d must stay available",
+				oldReplicas, available, d.Strategy.MinAvailable,
+			),
+			Evidence: []string{fmt.Sprintf(
+				"replicas %d - maxUnavailable %d = %d",
+				d.Strategy.Replicas, d.Strategy.MaxUnavailable, d.Strategy.MinAvailable,
+			)},
+		})
+	}
+	if dep.Status.Replicas >= d.Strategy.MaxPods {
+		d.add(pod.Finding{
+			Severity: pod.SeverityWarning,
+			Reason:   "SurgeExhausted",
+			Message: fmt.Sprintf(
+				"no more new pods can be created, because %d pods already exist and at most %d are allowed during rollout",
+				dep.Status.Replicas, d.Strategy.MaxPods,
+			),
+			Evidence: []string{fmt.Sprintf(
+				"replicas %d + maxSurge %d = %d",
+				d.Strategy.Replicas, d.Strategy.MaxSurge, d.Strategy.MaxPods,
+			)},
+		})
+	}
+}
+
+// checkQuotas compares ResourceQuotas of the namespace with resources,
+// which pods that rollout is still allowed to create would request
+func (d *DeploymentDiagnosis) checkQuotas(ctx context.Context, clientset kubernetes.Interface, dep *appsv1.Deployment) error {
+	quotas, err := clientset.CoreV1().ResourceQuotas(dep.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	if len(quotas.Items) == 0 {
+		return nil
+	}
+
+	newPods := max(d.Strategy.MaxPods-dep.Status.Replicas, 0)
+	template := &corev1.Pod{Spec: dep.Spec.Template.Spec}
+	requests := pod.Requests(template)
+	limits := pod.Limits(template)
+
+	sort.Slice(quotas.Items, func(i, j int) bool {
+		return quotas.Items[i].Name < quotas.Items[j].Name
+	})
+	for _, resourceQuota := range quotas.Items {
+		names := make([]string, 0, len(resourceQuota.Status.Hard))
+		for name := range resourceQuota.Status.Hard {
+			names = append(names, string(name))
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			perPod, ok := quota.UsagePerPod(corev1.ResourceName(name), requests, limits)
+			if !ok {
+				continue
+			}
+			hard := resourceQuota.Status.Hard[corev1.ResourceName(name)]
+			used := resourceQuota.Status.Used[corev1.ResourceName(name)]
+			needed := perPod.DeepCopy()
+			needed.Mul(int64(newPods))
+
+			total := used.DeepCopy()
+			total.Add(needed)
+			check := QuotaCheck{
+				Quota:    resourceQuota.Name,
+				Resource: name,
+				Hard:     hard.String(),
+				Used:     used.String(),
+				Needed:   needed.String(),
+				Fits:     total.Cmp(hard) <= 0,
+			}
+			d.Quotas = append(d.Quotas, check)
+
+			if !check.Fits {
+				free := hard.DeepCopy()
+				free.Sub(used)
+				d.add(pod.Finding{
+					Severity: pod.SeverityError,
+					Reason:   "QuotaExceeded",
+					Message: fmt.Sprintf(
+						"ResourceQuota %s does not allow %d more pods of new template: %s needs %s, but only %s of %s is left",
+						resourceQuota.Name, newPods, name, needed.String(), free.String(), hard.String(),
+					),
+					Evidence: []string{fmt.Sprintf(
+						"max pods %d - existing pods %d = %d new pods, each needs %s %s",
+						d.Strategy.MaxPods, dep.Status.Replicas, newPods, perPod.String(), name,
+					)},
+				})
+			}
+		}
+	}
+	return nil
+}
+
+// deploymentReplicaSets returns ReplicaSet matching current pod template
+// of the Deployment and other ReplicaSets owned by it, newest first
+func deploymentReplicaSets(
+	ctx context.Context,
+	clientset kubernetes.Interface,
+	dep *appsv1.Deployment,
+) (*appsv1.ReplicaSet, []*appsv1.ReplicaSet, error) {
+	selector, err := metav1.LabelSelectorAsSelector(dep.Spec.Selector)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid selector of deployment %s: %w", dep.Name, err)
+	}
+	replicaSets, err := clientset.AppsV1().ReplicaSets(dep.Namespace).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var owned []*appsv1.ReplicaSet
+	for i := range replicaSets.Items {
+		if metav1.IsControlledBy(&replicaSets.Items[i], dep) {
+			owned = append(owned, &replicaSets.Items[i])
+		}
+	}
+	sort.Slice(owned, func(i, j int) bool {
+		return replicaSetRevision(owned[i]) > replicaSetRevision(owned[j])
+	})
+
+	template := templateWithoutHash(&dep.Spec.Template)
+	var newReplicaSet *appsv1.ReplicaSet
+	var oldReplicaSets []*appsv1.ReplicaSet
+	for _, replicaSet := range owned {
+		if newReplicaSet == nil && equality.Semantic.DeepEqual(templateWithoutHash(&replicaSet.Spec.Template), template) {
+			newReplicaSet = replicaSet
+			continue
+		}
+		oldReplicaSets = append(oldReplicaSets, replicaSet)
+	}
+	return newReplicaSet, oldReplicaSets, nil
+}
+
+func templateWithoutHash(template *corev1.PodTemplateSpec) *corev1.PodTemplateSpec {
+	result := template.DeepCopy()
+	delete(result.Labels, appsv1.DefaultDeploymentUniqueLabelKey)
+	return result
+}
+
+func replicaSetRevision(replicaSet *appsv1.ReplicaSet) int64 {
+	number, err := strconv.ParseInt(replicaSet.Annotations[revisionAnnotation], 10, 64)
+	if err != nil {
+		return 0
+	}
+	return number
+}
+
+func newReplicaSetInRollout(replicaSet *appsv1.ReplicaSet) ReplicaSetInRollout {
+	summary := ReplicaSetInRollout{
+		Name:              replicaSet.Name,
+		Revision:          replicaSetRevision(replicaSet),
+		Replicas:          replicaSet.Status.Replicas,
+		ReadyReplicas:     replicaSet.Status.ReadyReplicas,
+		AvailableReplicas: replicaSet.Status.AvailableReplicas,
+		Images:            templateImages(&replicaSet.Spec.Template),
+	}
+	for _, condition := range replicaSet.Status.Conditions {
+		summary.Conditions = append(summary.Conditions, formatCondition(string(condition.Type), condition.Status, condition.Reason, condition.Message))
+	}
+	return summary
+}
+
+func formatCondition(conditionType string, status corev1.ConditionStatus, reason string, message string) string {
+	formatted := conditionType + "=" + string(status)
+	if reason != "" {
+		formatted += " " + reason
+	}
+	if message != "" {
+		formatted += ": " + strings.TrimSpace(message)
+	}
+	return formatted
+}
@@ -0,0 +1,188 @@
+package rollout
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	revisionAnnotation    = "deployment.kubernetes.io/revision"
+	changeCauseAnnotation = "kubernetes.io/change-cause"
+)
+
+// Revision describes one revision of the workload from its rollout history
+type Revision struct {
+	Revision     int64             `json:"revision"`
+	Name         string            `json:"name"`
+	CreatedAt    string            `json:"createdAt"`
+	ChangeCause  string            `json:"changeCause,omitempty"`
+	Current      bool              `json:"current,omitempty"`
+	Images       map[string]string `json:"images"`
+	ImageChanges []ImageChange     `json:"imageChanges,omitempty"`
+}
+
+// ImageChange describes change of container image comparing to previous revision,
+// where Before is empty for added containers and After is empty for removed ones
+type ImageChange struct {
+	Container string `json:"container"`
+	Before    string `json:"before,omitempty"`
+	After     string `json:"after,omitempty"`
+}
+
+// revision is a revision of the workload as found in the cluster,
+// Deployment revisions are stored in ReplicaSets, while StatefulSet and
+// DaemonSet revisions are stored in ControllerRevisions
+type revision struct {
+	number      int64
+	name        string
+	createdAt   metav1.Time
+	changeCause string
+	template    corev1.PodTemplateSpec
+	// data is the patch stored in ControllerRevision, which restores the revision
+	data []byte
+}
+
+// GetHistory returns rollout history of the workload ordered by revision
+func GetHistory(ctx context.Context, clientset kubernetes.Interface, kind Kind, namespace string, name string) ([]Revision, error) {
+	w, err := getWorkload(ctx, clientset, kind, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+
+	revisions, err := listRevisions(ctx, clientset, kind, w)
+	if err != nil {
+		return nil, err
+	}
+
+	history := make([]Revision, 0, len(revisions))
+	var previousImages map[string]string
+	for i, r := range revisions {
+		images := templateImages(&r.template)
+		entry := Revision{
+			Revision:    r.number,
+			Name:        r.name,
+			CreatedAt:   r.createdAt.Format(time.RFC3339),
+			ChangeCause: r.changeCause,
+			Current:     i == len(revisions)-1,
+			Images:      images,
+		}
+		if previousImages != nil {
+			entry.ImageChanges = diffImages(previousImages, images)
+		}
+		previousImages = images
+		history = append(history, entry)
+	}
+	return history, nil
+}
+
+// listRevisions returns revisions of the workload sorted by revision number,
+// so the last one is the revision currently being rolled out
+func listRevisions(ctx context.Context, clientset kubernetes.Interface, kind Kind, w *workload) ([]revision, error) {
+	selector, err := metav1.LabelSelectorAsSelector(w.selector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid selector of %s %s: %w", kind.displayName(), w.object.GetName(), err)
+	}
+	listOptions := metav1.ListOptions{LabelSelector: selector.String()}
+	namespace := w.object.GetNamespace()
+
+	var revisions []revision
+	if kind == KindDeployment {
+		replicaSets, err := clientset.AppsV1().ReplicaSets(namespace).List(ctx, listOptions)
+		if err != nil {
+			return nil, err
+		}
+		for _, replicaSet := range replicaSets.Items {
+			if !metav1.IsControlledBy(&replicaSet, w.object) {
+				continue
+			}
+			number, err := strconv.ParseInt(replicaSet.Annotations[revisionAnnotation], 10, 64)
+			if err != nil {
+				continue
+			}
+			revisions = append(revisions, revision{
+				number:      number,
+				name:        replicaSet.Name,
+				createdAt:   replicaSet.CreationTimestamp,
+				changeCause: replicaSet.Annotations[changeCauseAnnotation],
+				template:    replicaSet.Spec.Template,
+			})
+		}
+	} else {
+		controllerRevisions, err := clientset.AppsV1().ControllerRevisions(namespace).List(ctx, listOptions)
+		if err != nil {
+			return nil, err
+		}
+		for _, controllerRevision := range controllerRevisions.Items {
+			if !metav1.IsControlledBy(&controllerRevision, w.object) {
+				continue
+			}
+			template, err := controllerRevisionTemplate(&controllerRevision)
+			if err != nil {
+				return nil, err
+			}
+			revisions = append(revisions, revision{
+				number:      controllerRevision.Revision,
+				name:        controllerRevision.Name,
+				createdAt:   controllerRevision.CreationTimestamp,
+				changeCause: controllerRevision.Annotations[changeCauseAnnotation],
+				template:    *template,
+				data:        controllerRevision.Data.Raw,
+			})
+		}
+	}
+
+	sort.Slice(revisions, func(i, j int) bool {
+		return revisions[i].number < revisions[j].number
+	})
+	return revisions, nil
+}
+
+func controllerRevisionTemplate(controllerRevision *appsv1.ControllerRevision) (*corev1.PodTemplateSpec, error) {
+	var data struct {
+		Spec struct {
+			Template corev1.PodTemplateSpec `json:"template"`
+		} `json:"spec"`
+	}
+	if err := json.Unmarshal(controllerRevision.Data.Raw, &data); err != nil {
+		return nil, fmt.Errorf("failed to decode controller revision %s: %w", controllerRevision.Name, err)
+	}
+	return &data.Spec.Template, nil
+}
+
+func templateImages(template *corev1.PodTemplateSpec) map[string]string {
+	images := map[string]string{}
+	for _, container := range template.Spec.InitContainers {
+		images[container.Name] = container.Image
+	}
+	for _, container := range template.Spec.Containers {
+		images[container.Name] = container.Image
+	}
+	return images
+}
+
+func diffImages(before map[string]string, after map[string]string) []ImageChange {
+	var changes []ImageChange
+	for container, image := range before {
+		if after[container] != image {
+			changes = append(changes, ImageChange{Container: container, Before: image, After: after[container]})
+		}
+	}
+	for container, image := range after {
+		if _, ok := before[container]; !ok {
+			changes = append(changes, ImageChange{Container: container, After: image})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Container < changes[j].Container
+	})
+	return changes
+}
@@ -0,0 +1,378 @@
+package rollout
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/strowk/mcp-k8s-go/internal/k8s/apps/v1/deployment"
+	"github.com/strowk/mcp-k8s-go/internal/utils"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func newTemplate(image string) corev1.PodTemplateSpec {
+	return corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "web"}},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "web", Image: image}},
+		},
+	}
+}
+
+func newDeployment(image string) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "web",
+			Namespace:   "default",
+			UID:         types.UID("deployment-uid"),
+			Annotations: map[string]string{revisionAnnotation: "2"},
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: utils.Ptr(int32(2)),
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+			Template: newTemplate(image),
+		},
+	}
+}
+
+func newReplicaSet(deployment *appsv1.Deployment, name string, revision string, image string) *appsv1.ReplicaSet {
+	template := newTemplate(image)
+	template.Labels[appsv1.DefaultDeploymentUniqueLabelKey] = name
+	return &appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+			Labels:    map[string]string{"app": "web"},
+			Annotations: map[string]string{
+				revisionAnnotation: revision,
+			},
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(deployment, appsv1.SchemeGroupVersion.WithKind("Deployment")),
+			},
+		},
+		Spec: appsv1.ReplicaSetSpec{Template: template},
+	}
+}
+
+func TestParseKind(t *testing.T) {
+	for _, name := range []string{"Deployment", "deployments", "deploy"} {
+		kind, err := ParseKind(name)
+		require.NoError(t, err)
+		assert.Equal(t, KindDeployment, kind)
+	}
+
+	_, err := ParseKind("ReplicaSet")
+	assert.EqualError(t, err, "rollout is not supported for kind ReplicaSet, expected one of Deployment, StatefulSet or DaemonSet")
+}
+
+func TestDeploymentStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   appsv1.DeploymentStatus
+		done     bool
+		failed   bool
+		expected string
+	}{
+		{
+			name:     "spec update is not observed",
+			status:   appsv1.DeploymentStatus{ObservedGeneration: 1},
+			expected: "Waiting for deployment spec update to be observed...",
+		},
+		{
+			name:     "new replicas are being updated",
+			status:   appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 2, UpdatedReplicas: 1},
+			expected: `Waiting for deployment "web" rollout to finish: 1 out of 2 new replicas have been updated...`,
+		},
+		{
+			name:     "old replicas are pending termination",
+			status:   appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 3, UpdatedReplicas: 2},
+			expected: `Waiting for deployment "web" rollout to finish: 1 old replicas are pending termination...`,
+		},
+		{
+			name:     "updated replicas are not available",
+			status:   appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 2, UpdatedReplicas: 2, AvailableReplicas: 1},
+			expected: `Waiting for deployment "web" rollout to finish: 1 of 2 updated replicas are available...`,
+		},
+		{
+			name: "progress deadline exceeded",
+			status: appsv1.DeploymentStatus{
+				ObservedGeneration: 2,
+				Conditions: []appsv1.DeploymentCondition{
+					{Type: appsv1.DeploymentProgressing, Status: corev1.ConditionFalse, Reason: "ProgressDeadlineExceeded"},
+				},
+			},
+			failed:   true,
+			expected: `deployment "web" exceeded its progress deadline`,
+		},
+		{
+			name:     "rolled out",
+			status:   appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 2, UpdatedReplicas: 2, AvailableReplicas: 2},
+			done:     true,
+			expected: `deployment "web" successfully rolled out`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deployment := newDeployment("nginx:1.27")
+			deployment.Generation = 2
+			deployment.Status = tt.status
+
+			status, err := GetStatus(context.Background(), fake.NewClientset(deployment), KindDeployment, "default", "web")
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, status.Message)
+			assert.Equal(t, tt.done, status.Done)
+			assert.Equal(t, tt.failed, status.Failed)
+		})
+	}
+}
+
+func TestDeploymentHistoryAndUndo(t *testing.T) {
+	deployment := newDeployment("nginx:1.28")
+	clientset := fake.NewClientset(
+		deployment,
+		newReplicaSet(deployment, "web-1", "1", "nginx:1.27"),
+		newReplicaSet(deployment, "web-2", "2", "nginx:1.28"),
+		// not controlled by the deployment, so must be ignored
+		&appsv1.ReplicaSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "web-orphan",
+				Namespace:   "default",
+				Labels:      map[string]string{"app": "web"},
+				Annotations: map[string]string{revisionAnnotation: "3"},
+			},
+		},
+	)
+
+	history, err := GetHistory(context.Background(), clientset, KindDeployment, "default", "web")
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	assert.Equal(t, int64(1), history[0].Revision)
+	assert.False(t, history[0].Current)
+	assert.Empty(t, history[0].ImageChanges)
+	assert.Equal(t, int64(2), history[1].Revision)
+	assert.True(t, history[1].Current)
+	assert.Equal(t, map[string]string{"web": "nginx:1.28"}, history[1].Images)
+	assert.Equal(t, []ImageChange{{Container: "web", Before: "nginx:1.27", After: "nginx:1.28"}}, history[1].ImageChanges)
+
+	result, err := Undo(context.Background(), clientset, KindDeployment, "default", "web", 0, false)
+	require.NoError(t, err)
+	assert.Equal(t, `deployment "web" rolled back to revision 1`, result.Message)
+	assert.Equal(t, []utils.Change{
+		{Path: "spec.containers[0].image", Before: "nginx:1.28", After: "nginx:1.27"},
+	}, result.Changes)
+
+	updated, err := clientset.AppsV1().Deployments("default").Get(context.Background(), "web", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "nginx:1.27", updated.Spec.Template.Spec.Containers[0].Image)
+	assert.NotContains(t, updated.Spec.Template.Labels, appsv1.DefaultDeploymentUniqueLabelKey)
+
+	_, err = Undo(context.Background(), clientset, KindDeployment, "default", "web", 5, false)
+	assert.EqualError(t, err, `unable to find revision 5 in rollout history of deployment "web"`)
+}
+
+func TestDaemonSetHistory(t *testing.T) {
+	daemonSet := &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "agent", Namespace: "default", UID: types.UID("daemonset-uid")},
+		Spec: appsv1.DaemonSetSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+			Template: newTemplate("agent:2"),
+		},
+	}
+	newControllerRevision := func(name string, revision int64, image string) *appsv1.ControllerRevision {
+		data, err := json.Marshal(map[string]any{
+			"spec": map[string]any{"template": newTemplate(image)},
+		})
+		require.NoError(t, err)
+		return &appsv1.ControllerRevision{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+				Labels:    map[string]string{"app": "web"},
+				OwnerReferences: []metav1.OwnerReference{
+					*metav1.NewControllerRef(daemonSet, appsv1.SchemeGroupVersion.WithKind("DaemonSet")),
+				},
+			},
+			Revision: revision,
+			Data:     runtime.RawExtension{Raw: data},
+		}
+	}
+	clientset := fake.NewClientset(
+		daemonSet,
+		newControllerRevision("agent-2", 2, "agent:2"),
+		newControllerRevision("agent-1", 1, "agent:1"),
+	)
+
+	history, err := GetHistory(context.Background(), clientset, KindDaemonSet, "default", "agent")
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	assert.Equal(t, "agent-1", history[0].Name)
+	assert.Equal(t, "agent-2", history[1].Name)
+	assert.Equal(t, []ImageChange{{Container: "web", Before: "agent:1", After: "agent:2"}}, history[1].ImageChanges)
+}
+
+func TestPause(t *testing.T) {
+	clientset := fake.NewClientset(newDeployment("nginx:1.28"))
+
+	result, err := Pause(context.Background(), clientset, KindDeployment, "default", "web", false)
+	require.NoError(t, err)
+	assert.Equal(t, `deployment "web" paused`, result.Message)
+
+	result, err = Pause(context.Background(), clientset, KindDeployment, "default", "web", false)
+	require.NoError(t, err)
+	assert.Equal(t, `deployment "web" is already paused`, result.Message)
+
+	_, err = Restart(context.Background(), clientset, KindDeployment, "default", "web", false)
+	assert.EqualError(t, err, `can't restart paused deployment "web", resume it first`)
+
+	_, err = Pause(context.Background(), clientset, KindStatefulSet, "default", "web", false)
+	assert.EqualError(t, err, `statefulset "web" can not be paused, only deployments support pausing`)
+}
+
+func TestDiagnoseDeployment(t *testing.T) {
+	withRequests := func(template *corev1.PodTemplateSpec) {
+		template.Spec.Containers[0].Resources.Requests = corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("500m")}
+	}
+
+	dep := newDeployment("nginx:1.28")
+	dep.Generation = 2
+	dep.Spec.Replicas = utils.Ptr(int32(4))
+	dep.Spec.ProgressDeadlineSeconds = utils.Ptr(int32(600))
+	dep.Spec.Strategy = appsv1.DeploymentStrategy{
+		Type: appsv1.RollingUpdateDeploymentStrategyType,
+		RollingUpdate: &appsv1.RollingUpdateDeployment{
+			MaxSurge:       utils.Ptr(intstr.FromInt32(2)),
+			MaxUnavailable: utils.Ptr(intstr.FromString("10%")),
+		},
+	}
+	withRequests(&dep.Spec.Template)
+	dep.Status = appsv1.DeploymentStatus{
+		ObservedGeneration: 2, Replicas: 5, UpdatedReplicas: 1, AvailableReplicas: 4,
+		Conditions: []appsv1.DeploymentCondition{{
+			Type:    appsv1.DeploymentProgressing,
+			Status:  corev1.ConditionFalse,
+			Reason:  "ProgressDeadlineExceeded",
+			Message: `ReplicaSet "web-2" has timed out progressing.`,
+		}},
+	}
+
+	oldReplicaSet := newReplicaSet(dep, "web-1", "1", "nginx:1.27")
+	withRequests(&oldReplicaSet.Spec.Template)
+	oldReplicaSet.Status = appsv1.ReplicaSetStatus{Replicas: 4, ReadyReplicas: 4, AvailableReplicas: 4}
+
+	newReplicaSet := newReplicaSet(dep, "web-2", "2", "nginx:1.28")
+	withRequests(&newReplicaSet.Spec.Template)
+	newReplicaSet.Spec.Selector = &metav1.LabelSelector{MatchLabels: map[string]string{appsv1.DefaultDeploymentUniqueLabelKey: "web-2"}}
+	newReplicaSet.Status = appsv1.ReplicaSetStatus{
+		Replicas: 1,
+		Conditions: []appsv1.ReplicaSetCondition{{
+			Type:    appsv1.ReplicaSetReplicaFailure,
+			Status:  corev1.ConditionTrue,
+			Reason:  "FailedCreate",
+			Message: `pods "web-2-b" is forbidden: exceeded quota: compute`,
+		}},
+	}
+
+	clientset := fake.NewClientset(
+		dep,
+		oldReplicaSet,
+		newReplicaSet,
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "web-2-a",
+				Namespace: "default",
+				Labels:    map[string]string{appsv1.DefaultDeploymentUniqueLabelKey: "web-2"},
+				OwnerReferences: []metav1.OwnerReference{
+					*metav1.NewControllerRef(newReplicaSet, appsv1.SchemeGroupVersion.WithKind("ReplicaSet")),
+				},
+			},
+			Spec: newReplicaSet.Spec.Template.Spec,
+			Status: corev1.PodStatus{
+				Phase: corev1.PodRunning,
+				ContainerStatuses: []corev1.ContainerStatus{{
+					Name:         "web",
+					RestartCount: 3,
+					State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{
+						Reason: "CrashLoopBackOff",
+					}},
+				}},
+			},
+		},
+		&corev1.ResourceQuota{
+			ObjectMeta: metav1.ObjectMeta{Name: "compute", Namespace: "default"},
+			Status: corev1.ResourceQuotaStatus{
+				Hard: corev1.ResourceList{
+					corev1.ResourcePods:        resource.MustParse("5"),
+					corev1.ResourceRequestsCPU: resource.MustParse("4"),
+					corev1.ResourceConfigMaps:  resource.MustParse("10"),
+				},
+				Used: corev1.ResourceList{
+					corev1.ResourcePods:        resource.MustParse("5"),
+					corev1.ResourceRequestsCPU: resource.MustParse("2500m"),
+					corev1.ResourceConfigMaps:  resource.MustParse("1"),
+				},
+			},
+		},
+	)
+
+	diagnosis, err := DiagnoseDeployment(context.Background(), clientset, "default", "web")
+	require.NoError(t, err)
+
+	assert.Equal(t, "web", diagnosis.Deployment.Name)
+	assert.True(t, diagnosis.Status.Failed)
+	assert.Equal(t, &deployment.StrategyLimits{
+		Type: "RollingUpdate", Replicas: 4, MaxSurge: 2, MaxUnavailable: 0, MaxPods: 6, MinAvailable: 4,
+	}, diagnosis.Strategy)
+
+	reasons := []string{}
+	for _, finding := range diagnosis.Findings {
+		reasons = append(reasons, finding.Severity+" "+finding.Reason)
+	}
+	assert.Equal(t, []string{
+		"error ProgressDeadlineExceeded",
+		"error FailedCreate",
+		"error NewPodsNotReady",
+		"warning ScaleDownBlocked",
+		"error QuotaExceeded",
+	}, reasons)
+	assert.Equal(t, []string{"progressDeadlineSeconds: 600"}, diagnosis.Findings[0].Evidence)
+	assert.Equal(t, "1 of 1 pods of new ReplicaSet web-2 are not ready", diagnosis.Findings[2].Message)
+	assert.Equal(t, []string{
+		"web-2-a: CrashLoopBackOff: container web: container keeps crashing after start, see its previous logs and last termination",
+	}, diagnosis.Findings[2].Evidence)
+	assert.Equal(t,
+		"4 old pods cannot be removed until new pods become available, because 4 pods are available and at least 4 must stay available",
+		diagnosis.Findings[3].Message,
+	)
+	assert.Equal(t,
+		"ResourceQuota compute does not allow 1 more pods of new template: pods needs 1, but only 0 of 5 is left",
+		diagnosis.Findings[4].Message,
+	)
+
+	require.NotNil(t, diagnosis.NewReplicaSet)
+	assert.Equal(t, "web-2", diagnosis.NewReplicaSet.Name)
+	assert.Equal(t, map[string]string{"web": "nginx:1.28"}, diagnosis.NewReplicaSet.Images)
+	require.Len(t, diagnosis.OldReplicaSets, 1)
+	assert.Equal(t, int64(1), diagnosis.OldReplicaSets[0].Revision)
+	assert.Equal(t, []utils.Change{
+		{Path: "spec.containers[0].image", Before: "nginx:1.27", After: "nginx:1.28"},
+	}, diagnosis.TemplateChanges)
+	assert.Equal(t, []UnreadyPod{{
+		Name:    "web-2-a",
+		Phase:   "Running",
+		Verdict: "CrashLoopBackOff: container web: container keeps crashing after start, see its previous logs and last termination",
+	}}, diagnosis.UnreadyPods)
+	assert.Equal(t, []QuotaCheck{
+		{Quota: "compute", Resource: "pods", Hard: "5", Used: "5", Needed: "1", Fits: false},
+		{Quota: "compute", Resource: "requests.cpu", Hard: "4", Used: "2500m", Needed: "500m", Fits: true},
+	}, diagnosis.Quotas)
+}
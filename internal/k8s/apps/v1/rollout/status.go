@@ -0,0 +1,208 @@
+package rollout
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+)
+
+// pollInterval is how often status is checked while waiting for rollout
+var pollInterval = 2 * time.Second
+
+// Status describes progress of rollout in the same way as kubectl rollout status does
+type Status struct {
+	Kind      Kind   `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Done      bool   `json:"done"`
+	Failed    bool   `json:"failed,omitempty"`
+	Paused    bool   `json:"paused,omitempty"`
+	Message   string `json:"message"`
+}
+
+// GetStatus returns current status of rollout of the workload
+func GetStatus(ctx context.Context, clientset kubernetes.Interface, kind Kind, namespace string, name string) (*Status, error) {
+	status := &Status{
+		Kind:      kind,
+		Name:      name,
+		Namespace: namespace,
+	}
+
+	apps := clientset.AppsV1()
+	switch kind {
+	case KindDeployment:
+		deployment, err := apps.Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		status.Paused = deployment.Spec.Paused
+		setDeploymentStatus(status, deployment)
+	case KindStatefulSet:
+		statefulSet, err := apps.StatefulSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		if err := setStatefulSetStatus(status, statefulSet); err != nil {
+			return nil, err
+		}
+	case KindDaemonSet:
+		daemonSet, err := apps.DaemonSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		if err := setDaemonSetStatus(status, daemonSet); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported kind %s", kind)
+	}
+	return status, nil
+}
+
+// WaitForStatus waits until rollout is finished or failed, or timeout passes,
+// and returns the last observed status of rollout
+func WaitForStatus(
+	ctx context.Context,
+	clientset kubernetes.Interface,
+	kind Kind,
+	namespace string,
+	name string,
+	timeout time.Duration,
+) (*Status, error) {
+	var status *Status
+	err := wait.PollUntilContextTimeout(ctx, pollInterval, timeout, true, func(ctx context.Context) (bool, error) {
+		var err error
+		status, err = GetStatus(ctx, clientset, kind, namespace, name)
+		if err != nil {
+			return false, err
+		}
+		return status.Done || status.Failed, nil
+	})
+	if err != nil && (!wait.Interrupted(err) || status == nil) {
+		return nil, err
+	}
+	return status, nil
+}
+
+func setDeploymentStatus(status *Status, deployment *appsv1.Deployment) {
+	if deployment.Generation > deployment.Status.ObservedGeneration {
+		status.Message = "Waiting for deployment spec update to be observed..."
+		return
+	}
+
+	for _, condition := range deployment.Status.Conditions {
+		if condition.Type == appsv1.DeploymentProgressing && condition.Reason == "ProgressDeadlineExceeded" {
+			status.Failed = true
+			status.Message = fmt.Sprintf("deployment %q exceeded its progress deadline", deployment.Name)
+			return
+		}
+	}
+
+	if deployment.Spec.Replicas != nil && deployment.Status.UpdatedReplicas < *deployment.Spec.Replicas {
+		status.Message = fmt.Sprintf(
+			"Waiting for deployment %q rollout to finish: %d out of %d new replicas have been updated...",
+			deployment.Name, deployment.Status.UpdatedReplicas, *deployment.Spec.Replicas,
+		)
+		return
+	}
+	if deployment.Status.Replicas > deployment.Status.UpdatedReplicas {
+		status.Message = fmt.Sprintf(
+			"Waiting for deployment %q rollout to finish: %d old replicas are pending termination...",
+			deployment.Name, deployment.Status.Replicas-deployment.Status.UpdatedReplicas,
+		)
+		return
+	}
+	if deployment.Status.AvailableReplicas < deployment.Status.UpdatedReplicas {
+		status.Message = fmt.Sprintf(
+			"Waiting for deployment %q rollout to finish: %d of %d updated replicas are available...",
+			deployment.Name, deployment.Status.AvailableReplicas, deployment.Status.UpdatedReplicas,
+		)
+		return
+	}
+
+	status.Done = true
+	status.Message = fmt.Sprintf("deployment %q successfully rolled out", deployment.Name)
+}
+
+func setStatefulSetStatus(status *Status, statefulSet *appsv1.StatefulSet) error {
+	if statefulSet.Spec.UpdateStrategy.Type != appsv1.RollingUpdateStatefulSetStrategyType {
+		return fmt.Errorf("rollout status is only available for %s strategy type", appsv1.RollingUpdateStatefulSetStrategyType)
+	}
+
+	if statefulSet.Status.ObservedGeneration == 0 || statefulSet.Generation > statefulSet.Status.ObservedGeneration {
+		status.Message = "Waiting for statefulset spec update to be observed..."
+		return nil
+	}
+
+	if statefulSet.Spec.Replicas != nil && statefulSet.Status.ReadyReplicas < *statefulSet.Spec.Replicas {
+		status.Message = fmt.Sprintf("Waiting for %d pods to be ready...", *statefulSet.Spec.Replicas-statefulSet.Status.ReadyReplicas)
+		return nil
+	}
+
+	rollingUpdate := statefulSet.Spec.UpdateStrategy.RollingUpdate
+	if rollingUpdate != nil && rollingUpdate.Partition != nil {
+		if statefulSet.Spec.Replicas != nil {
+			expected := *statefulSet.Spec.Replicas - *rollingUpdate.Partition
+			if statefulSet.Status.UpdatedReplicas < expected {
+				status.Message = fmt.Sprintf(
+					"Waiting for partitioned roll out to finish: %d out of %d new pods have been updated...",
+					statefulSet.Status.UpdatedReplicas, expected,
+				)
+				return nil
+			}
+		}
+		status.Done = true
+		status.Message = fmt.Sprintf("partitioned roll out complete: %d new pods have been updated...", statefulSet.Status.UpdatedReplicas)
+		return nil
+	}
+
+	if statefulSet.Status.UpdateRevision != statefulSet.Status.CurrentRevision {
+		status.Message = fmt.Sprintf(
+			"waiting for statefulset rolling update to complete %d pods at revision %s...",
+			statefulSet.Status.UpdatedReplicas, statefulSet.Status.UpdateRevision,
+		)
+		return nil
+	}
+
+	status.Done = true
+	status.Message = fmt.Sprintf(
+		"statefulset rolling update complete %d pods at revision %s...",
+		statefulSet.Status.CurrentReplicas, statefulSet.Status.CurrentRevision,
+	)
+	return nil
+}
+
+func setDaemonSetStatus(status *Status, daemonSet *appsv1.DaemonSet) error {
+	if daemonSet.Spec.UpdateStrategy.Type != appsv1.RollingUpdateDaemonSetStrategyType {
+		return fmt.Errorf("rollout status is only available for %s strategy type", appsv1.RollingUpdateDaemonSetStrategyType)
+	}
+
+	if daemonSet.Generation > daemonSet.Status.ObservedGeneration {
+		status.Message = "Waiting for daemon set spec update to be observed..."
+		return nil
+	}
+
+	if daemonSet.Status.UpdatedNumberScheduled < daemonSet.Status.DesiredNumberScheduled {
+		status.Message = fmt.Sprintf(
+			"Waiting for daemon set %q rollout to finish: %d out of %d new pods have been updated...",
+			daemonSet.Name, daemonSet.Status.UpdatedNumberScheduled, daemonSet.Status.DesiredNumberScheduled,
+		)
+		return nil
+	}
+	if daemonSet.Status.NumberAvailable < daemonSet.Status.DesiredNumberScheduled {
+		status.Message = fmt.Sprintf(
+			"Waiting for daemon set %q rollout to finish: %d of %d updated pods are available...",
+			daemonSet.Name, daemonSet.Status.NumberAvailable, daemonSet.Status.DesiredNumberScheduled,
+		)
+		return nil
+	}
+
+	status.Done = true
+	status.Message = fmt.Sprintf("daemon set %q successfully rolled out", daemonSet.Name)
+	return nil
+}
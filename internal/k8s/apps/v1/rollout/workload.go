@@ -0,0 +1,150 @@
+package rollout
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Kind is a kind of workload, which supports rollouts
+type Kind string
+
+const (
+	KindDeployment  Kind = "Deployment"
+	KindStatefulSet Kind = "StatefulSet"
+	KindDaemonSet   Kind = "DaemonSet"
+)
+
+// ParseKind parses kind of workload, accepting also plural and short names
+func ParseKind(kind string) (Kind, error) {
+	switch strings.ToLower(kind) {
+	case "deployment", "deployments", "deploy":
+		return KindDeployment, nil
+	case "statefulset", "statefulsets", "sts":
+		return KindStatefulSet, nil
+	case "daemonset", "daemonsets", "ds":
+		return KindDaemonSet, nil
+	}
+	return "", fmt.Errorf("rollout is not supported for kind %s, expected one of Deployment, StatefulSet or DaemonSet", kind)
+}
+
+func (k Kind) displayName() string {
+	return strings.ToLower(string(k))
+}
+
+// workload holds parts of Deployment, StatefulSet or DaemonSet,
+// which are common for all of them and needed to manage rollouts
+type workload struct {
+	object   metav1.Object
+	selector *metav1.LabelSelector
+	template corev1.PodTemplateSpec
+	paused   bool
+	// updateRevision is the name of ControllerRevision of StatefulSet being rolled out
+	updateRevision string
+}
+
+func getWorkload(ctx context.Context, clientset kubernetes.Interface, kind Kind, namespace string, name string) (*workload, error) {
+	apps := clientset.AppsV1()
+	switch kind {
+	case KindDeployment:
+		deployment, err := apps.Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return &workload{
+			object:   deployment,
+			selector: deployment.Spec.Selector,
+			template: deployment.Spec.Template,
+			paused:   deployment.Spec.Paused,
+		}, nil
+	case KindStatefulSet:
+		statefulSet, err := apps.StatefulSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return &workload{
+			object:         statefulSet,
+			selector:       statefulSet.Spec.Selector,
+			template:       statefulSet.Spec.Template,
+			updateRevision: statefulSet.Status.UpdateRevision,
+		}, nil
+	case KindDaemonSet:
+		daemonSet, err := apps.DaemonSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return &workload{
+			object:   daemonSet,
+			selector: daemonSet.Spec.Selector,
+			template: daemonSet.Spec.Template,
+		}, nil
+	}
+	return nil, fmt.Errorf("unsupported kind %s", kind)
+}
+
+func patchWorkload(
+	ctx context.Context,
+	clientset kubernetes.Interface,
+	kind Kind,
+	namespace string,
+	name string,
+	patchType types.PatchType,
+	data []byte,
+	dryRun bool,
+) (*workload, error) {
+	options := metav1.PatchOptions{}
+	if dryRun {
+		options.DryRun = []string{metav1.DryRunAll}
+	}
+
+	apps := clientset.AppsV1()
+	switch kind {
+	case KindDeployment:
+		deployment, err := apps.Deployments(namespace).Patch(ctx, name, patchType, data, options)
+		if err != nil {
+			return nil, err
+		}
+		return &workload{
+			object:   deployment,
+			selector: deployment.Spec.Selector,
+			template: deployment.Spec.Template,
+			paused:   deployment.Spec.Paused,
+		}, nil
+	case KindStatefulSet:
+		statefulSet, err := apps.StatefulSets(namespace).Patch(ctx, name, patchType, data, options)
+		if err != nil {
+			return nil, err
+		}
+		return &workload{
+			object:         statefulSet,
+			selector:       statefulSet.Spec.Selector,
+			template:       statefulSet.Spec.Template,
+			updateRevision: statefulSet.Status.UpdateRevision,
+		}, nil
+	case KindDaemonSet:
+		daemonSet, err := apps.DaemonSets(namespace).Patch(ctx, name, patchType, data, options)
+		if err != nil {
+			return nil, err
+		}
+		return &workload{
+			object:   daemonSet,
+			selector: daemonSet.Spec.Selector,
+			template: daemonSet.Spec.Template,
+		}, nil
+	}
+	return nil, fmt.Errorf("unsupported kind %s", kind)
+}
+
+func templateContent(template *corev1.PodTemplateSpec) map[string]any {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(template)
+	if err != nil {
+		return map[string]any{}
+	}
+	return content
+}
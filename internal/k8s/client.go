@@ -2,6 +2,7 @@ package k8s
 
 import (
 	"github.com/strowk/mcp-k8s-go/internal/config"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/clientcmd"
 )
@@ -36,6 +37,22 @@ func GetCurrentContext() (string, error) {
 	return config.CurrentContext, nil
 }
 
+// GetContextNamespace returns namespace configured for the context in kubeconfig,
+// or "default" if the context does not specify one
+func GetContextNamespace(k8sContext string) (string, error) {
+	config, err := GetKubeConfig().RawConfig()
+	if err != nil {
+		return "", err
+	}
+	if k8sContext == "" {
+		k8sContext = config.CurrentContext
+	}
+	if context, ok := config.Contexts[k8sContext]; ok && context.Namespace != "" {
+		return context.Namespace, nil
+	}
+	return metav1.NamespaceDefault, nil
+}
+
 func GetKubeClientset() (*kubernetes.Clientset, error) {
 	kubeConfig := GetKubeConfig()
 
@@ -0,0 +1,339 @@
+package namespace
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/strowk/mcp-k8s-go/internal/k8s/core/v1/pod"
+	"github.com/strowk/mcp-k8s-go/internal/utils"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/metadata"
+)
+
+// QuotaWarningThreshold is fraction of hard limit of resource quota,
+// above which namespace inventory warns about it
+const QuotaWarningThreshold = 0.9
+
+// omittedAnnotations are too big and not useful in inventory
+var omittedAnnotations = []string{
+	corev1.LastAppliedConfigAnnotation,
+}
+
+type InventoryOptions struct {
+	// Namespace limits inventory to single namespace, all namespaces if empty
+	Namespace     string
+	LabelSelector string
+}
+
+// Inventory summarizes namespace with counts of its objects, resource
+// quotas usage against hard limits and limit ranges
+type Inventory struct {
+	Name                   string            `json:"name"`
+	Phase                  string            `json:"phase"`
+	Age                    string            `json:"age"`
+	CreatedAt              time.Time         `json:"createdAt"`
+	Labels                 map[string]string `json:"labels,omitempty"`
+	Annotations            map[string]string `json:"annotations,omitempty"`
+	Pods                   PodCounts         `json:"pods"`
+	Deployments            int               `json:"deployments"`
+	Services               int               `json:"services"`
+	PersistentVolumeClaims int               `json:"persistentVolumeClaims"`
+	Secrets                int               `json:"secrets"`
+	ResourceQuotas         []QuotaUsage      `json:"resourceQuotas,omitempty"`
+	LimitRanges            []LimitRange      `json:"limitRanges,omitempty"`
+	// Warnings list quota resources near or at their hard limits
+	// and kinds of objects, which could not be listed
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// objectCounter counts objects of a resource, optionally
+// only those matching field selector, into inventory
+type objectCounter struct {
+	name          string
+	resource      schema.GroupVersionResource
+	fieldSelector string
+	set           func(inventory *Inventory, count int)
+}
+
+var (
+	podsResource = corev1.SchemeGroupVersion.WithResource("pods")
+
+	objectCounters = []objectCounter{
+		{name: "pods", resource: podsResource, set: func(i *Inventory, count int) { i.Pods.Total = count }},
+		podPhaseCounter(corev1.PodRunning, func(i *Inventory, count int) { i.Pods.Running = count }),
+		podPhaseCounter(corev1.PodPending, func(i *Inventory, count int) { i.Pods.Pending = count }),
+		podPhaseCounter(corev1.PodSucceeded, func(i *Inventory, count int) { i.Pods.Succeeded = count }),
+		podPhaseCounter(corev1.PodFailed, func(i *Inventory, count int) { i.Pods.Failed = count }),
+		{name: "deployments", resource: appsv1.SchemeGroupVersion.WithResource("deployments"), set: func(i *Inventory, count int) { i.Deployments = count }},
+		{name: "services", resource: corev1.SchemeGroupVersion.WithResource("services"), set: func(i *Inventory, count int) { i.Services = count }},
+		{name: "persistent volume claims", resource: corev1.SchemeGroupVersion.WithResource("persistentvolumeclaims"), set: func(i *Inventory, count int) { i.PersistentVolumeClaims = count }},
+		{name: "secrets", resource: corev1.SchemeGroupVersion.WithResource("secrets"), set: func(i *Inventory, count int) { i.Secrets = count }},
+	}
+)
+
+func podPhaseCounter(phase corev1.PodPhase, set func(inventory *Inventory, count int)) objectCounter {
+	return objectCounter{
+		name:          fmt.Sprintf("%s pods", phase),
+		resource:      podsResource,
+		fieldSelector: fields.OneTermEqualSelector("status.phase", string(phase)).String(),
+		set:           set,
+	}
+}
+
+type PodCounts struct {
+	Total     int `json:"total"`
+	Running   int `json:"running,omitempty"`
+	Pending   int `json:"pending,omitempty"`
+	Succeeded int `json:"succeeded,omitempty"`
+	Failed    int `json:"failed,omitempty"`
+	Unknown   int `json:"unknown,omitempty"`
+}
+
+type QuotaUsage struct {
+	Name      string          `json:"name"`
+	Scopes    []string        `json:"scopes,omitempty"`
+	Resources []QuotaResource `json:"resources"`
+}
+
+// QuotaResource compares used amount of the resource with hard limit of the quota
+type QuotaResource struct {
+	Resource string `json:"resource"`
+	Hard     string `json:"hard"`
+	Used     string `json:"used"`
+	Percent  int    `json:"percent"`
+}
+
+type LimitRange struct {
+	Name   string           `json:"name"`
+	Limits []LimitRangeItem `json:"limits"`
+}
+
+// LimitRangeItem holds constraints and defaults of limit range
+// for one type of object and one resource
+type LimitRangeItem struct {
+	Type                 string `json:"type"`
+	Resource             string `json:"resource"`
+	Min                  string `json:"min,omitempty"`
+	Max                  string `json:"max,omitempty"`
+	Default              string `json:"default,omitempty"`
+	DefaultRequest       string `json:"defaultRequest,omitempty"`
+	MaxLimitRequestRatio string `json:"maxLimitRequestRatio,omitempty"`
+}
+
+// GetInventory builds inventory of namespaces matching options, objects
+// of every kind are listed once for all namespaces and grouped locally,
+// counted objects are only listed with their metadata, kinds, which
+// cannot be listed due to missing permissions, are reported in warnings
+func GetInventory(ctx context.Context, clientset kubernetes.Interface, metadataClient metadata.Interface, options InventoryOptions) ([]Inventory, error) {
+	var namespaces []corev1.Namespace
+	if options.Namespace != "" {
+		namespace, err := clientset.CoreV1().Namespaces().Get(ctx, options.Namespace, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		namespaces = append(namespaces, *namespace)
+	} else {
+		list, err := clientset.CoreV1().Namespaces().List(ctx, metav1.ListOptions{LabelSelector: options.LabelSelector})
+		if err != nil {
+			return nil, err
+		}
+		namespaces = list.Items
+	}
+	sort.Slice(namespaces, func(i, j int) bool {
+		return namespaces[i].Name < namespaces[j].Name
+	})
+
+	inventories := make([]Inventory, 0, len(namespaces))
+	byName := map[string]*Inventory{}
+	for _, namespace := range namespaces {
+		inventories = append(inventories, Inventory{
+			Name:        namespace.Name,
+			Phase:       string(namespace.Status.Phase),
+			Age:         utils.FormatAge(time.Since(namespace.CreationTimestamp.Time)),
+			CreatedAt:   namespace.CreationTimestamp.Time,
+			Labels:      namespace.Labels,
+			Annotations: withoutOmittedAnnotations(namespace.Annotations),
+		})
+	}
+	for i := range inventories {
+		byName[inventories[i].Name] = &inventories[i]
+	}
+
+	forbidden := map[schema.GroupVersionResource]bool{}
+	for _, counter := range objectCounters {
+		if forbidden[counter.resource] {
+			continue
+		}
+		counts, err := countObjects(ctx, metadataClient, options.Namespace, counter)
+		if apierrors.IsForbidden(err) {
+			forbidden[counter.resource] = true
+			warnAll(inventories, fmt.Sprintf("unable to count %s: %v", counter.name, err))
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		for name, count := range counts {
+			if inventory, ok := byName[name]; ok {
+				counter.set(inventory, count)
+			}
+		}
+	}
+	for i := range inventories {
+		pods := &inventories[i].Pods
+		// pods counted by phase and in total are listed separately
+		// and can differ, when pods come and go in between
+		pods.Unknown = max(0, pods.Total-pods.Running-pods.Pending-pods.Succeeded-pods.Failed)
+	}
+
+	core := clientset.CoreV1()
+	listOptions := metav1.ListOptions{}
+	quotas, err := core.ResourceQuotas(options.Namespace).List(ctx, listOptions)
+	if apierrors.IsForbidden(err) {
+		warnAll(inventories, fmt.Sprintf("unable to list resource quotas: %v", err))
+		quotas = &corev1.ResourceQuotaList{}
+	} else if err != nil {
+		return nil, err
+	}
+	sort.Slice(quotas.Items, func(i, j int) bool {
+		return quotas.Items[i].Name < quotas.Items[j].Name
+	})
+	for i := range quotas.Items {
+		quota := &quotas.Items[i]
+		if inventory, ok := byName[quota.Namespace]; ok {
+			usage := NewQuotaUsage(quota)
+			inventory.ResourceQuotas = append(inventory.ResourceQuotas, usage)
+			for _, resource := range usage.Resources {
+				if resource.Percent >= int(QuotaWarningThreshold*100) {
+					inventory.Warnings = append(inventory.Warnings, fmt.Sprintf(
+						"resource quota %s uses %s of %s %s (%d%%)", quota.Name, resource.Used, resource.Hard, resource.Resource, resource.Percent,
+					))
+				}
+			}
+		}
+	}
+
+	limitRanges, err := core.LimitRanges(options.Namespace).List(ctx, listOptions)
+	if apierrors.IsForbidden(err) {
+		warnAll(inventories, fmt.Sprintf("unable to list limit ranges: %v", err))
+		limitRanges = &corev1.LimitRangeList{}
+	} else if err != nil {
+		return nil, err
+	}
+	sort.Slice(limitRanges.Items, func(i, j int) bool {
+		return limitRanges.Items[i].Name < limitRanges.Items[j].Name
+	})
+	for i := range limitRanges.Items {
+		limitRange := &limitRanges.Items[i]
+		if inventory, ok := byName[limitRange.Namespace]; ok {
+			inventory.LimitRanges = append(inventory.LimitRanges, NewLimitRange(limitRange))
+		}
+	}
+
+	return inventories, nil
+}
+
+// NewQuotaUsage compares used resources of the quota with its hard limits, sorted by resource name
+func NewQuotaUsage(quota *corev1.ResourceQuota) QuotaUsage {
+	usage := QuotaUsage{Name: quota.Name, Resources: []QuotaResource{}}
+	for _, scope := range quota.Spec.Scopes {
+		usage.Scopes = append(usage.Scopes, string(scope))
+	}
+	for name, hard := range quota.Status.Hard {
+		used := quota.Status.Used[name]
+		usage.Resources = append(usage.Resources, QuotaResource{
+			Resource: string(name),
+			Hard:     hard.String(),
+			Used:     used.String(),
+			Percent:  percent(used, hard),
+		})
+	}
+	sort.Slice(usage.Resources, func(i, j int) bool {
+		return usage.Resources[i].Resource < usage.Resources[j].Resource
+	})
+	return usage
+}
+
+// NewLimitRange flattens limits of limit range into item per type and resource
+func NewLimitRange(limitRange *corev1.LimitRange) LimitRange {
+	result := LimitRange{Name: limitRange.Name, Limits: []LimitRangeItem{}}
+	for _, limit := range limitRange.Spec.Limits {
+		names := map[corev1.ResourceName]bool{}
+		for _, list := range []corev1.ResourceList{limit.Min, limit.Max, limit.Default, limit.DefaultRequest, limit.MaxLimitRequestRatio} {
+			for name := range list {
+				names[name] = true
+			}
+		}
+		sorted := make([]corev1.ResourceName, 0, len(names))
+		for name := range names {
+			sorted = append(sorted, name)
+		}
+		sort.Slice(sorted, func(i, j int) bool {
+			return sorted[i] < sorted[j]
+		})
+		for _, name := range sorted {
+			result.Limits = append(result.Limits, LimitRangeItem{
+				Type:                 string(limit.Type),
+				Resource:             string(name),
+				Min:                  pod.QuantityString(limit.Min, name),
+				Max:                  pod.QuantityString(limit.Max, name),
+				Default:              pod.QuantityString(limit.Default, name),
+				DefaultRequest:       pod.QuantityString(limit.DefaultRequest, name),
+				MaxLimitRequestRatio: pod.QuantityString(limit.MaxLimitRequestRatio, name),
+			})
+		}
+	}
+	return result
+}
+
+func withoutOmittedAnnotations(annotations map[string]string) map[string]string {
+	result := map[string]string{}
+	for key, value := range annotations {
+		result[key] = value
+	}
+	for _, key := range omittedAnnotations {
+		delete(result, key)
+	}
+	if len(result) == 0 {
+		return nil
+	}
+	return result
+}
+
+func percent(value, total resource.Quantity) int {
+	if total.IsZero() && !value.IsZero() {
+		return 100
+	}
+	return int(math.Round(pod.Fraction(value, total) * 100))
+}
+
+// countObjects counts objects of the counter per namespace by listing their metadata only
+func countObjects(ctx context.Context, metadataClient metadata.Interface, namespace string, counter objectCounter) (map[string]int, error) {
+	list, err := metadataClient.Resource(counter.resource).Namespace(namespace).List(ctx, metav1.ListOptions{
+		FieldSelector: counter.fieldSelector,
+	})
+	if err != nil {
+		return nil, err
+	}
+	counts := map[string]int{}
+	for _, item := range list.Items {
+		counts[item.Namespace]++
+	}
+	return counts, nil
+}
+
+func warnAll(inventories []Inventory, warning string) {
+	for i := range inventories {
+		inventories[i].Warnings = append(inventories[i].Warnings, warning)
+	}
+}
@@ -0,0 +1,195 @@
+package namespace
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	metadatafake "k8s.io/client-go/metadata/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestGetInventory(t *testing.T) {
+	created := time.Date(2024, 12, 1, 19, 0, 8, 0, time.UTC)
+	clientset := fake.NewClientset(
+		&corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "team-a",
+				CreationTimestamp: metav1.NewTime(created),
+				Labels:            map[string]string{"team": "a"},
+				Annotations: map[string]string{
+					"owner":                            "alice",
+					corev1.LastAppliedConfigAnnotation: "{}",
+				},
+			},
+			Status: corev1.NamespaceStatus{Phase: corev1.NamespaceActive},
+		},
+		&corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{Name: "old", CreationTimestamp: metav1.NewTime(created)},
+			Status:     corev1.NamespaceStatus{Phase: corev1.NamespaceTerminating},
+		},
+		&corev1.ResourceQuota{
+			ObjectMeta: metav1.ObjectMeta{Name: "compute", Namespace: "team-a"},
+			Spec:       corev1.ResourceQuotaSpec{Scopes: []corev1.ResourceQuotaScope{corev1.ResourceQuotaScopeNotTerminating}},
+			Status: corev1.ResourceQuotaStatus{
+				Hard: corev1.ResourceList{
+					corev1.ResourceRequestsCPU:    resource.MustParse("2"),
+					corev1.ResourceRequestsMemory: resource.MustParse("4Gi"),
+					corev1.ResourcePods:           resource.MustParse("4"),
+				},
+				Used: corev1.ResourceList{
+					corev1.ResourceRequestsCPU:    resource.MustParse("500m"),
+					corev1.ResourceRequestsMemory: resource.MustParse("1Gi"),
+					corev1.ResourcePods:           resource.MustParse("4"),
+				},
+			},
+		},
+		&corev1.LimitRange{
+			ObjectMeta: metav1.ObjectMeta{Name: "defaults", Namespace: "team-a"},
+			Spec: corev1.LimitRangeSpec{Limits: []corev1.LimitRangeItem{{
+				Type:           corev1.LimitTypeContainer,
+				Max:            corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2")},
+				Default:        corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("500m"), corev1.ResourceMemory: resource.MustParse("256Mi")},
+				DefaultRequest: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m"), corev1.ResourceMemory: resource.MustParse("128Mi")},
+			}}},
+		},
+	)
+
+	metadataClient := newMetadataClient(t,
+		podMetadata("web-1", "team-a", corev1.PodRunning),
+		podMetadata("web-2", "team-a", corev1.PodRunning),
+		podMetadata("migrate", "team-a", corev1.PodSucceeded),
+		podMetadata("broken", "team-a", corev1.PodPending),
+		podMetadata("leftover", "old", corev1.PodFailed),
+		objectMetadata("apps/v1", "Deployment", "web", "team-a"),
+		objectMetadata("v1", "Service", "web", "team-a"),
+		objectMetadata("v1", "PersistentVolumeClaim", "data", "team-a"),
+		objectMetadata("v1", "Secret", "token", "team-a"),
+		objectMetadata("v1", "Secret", "token", "old"),
+	)
+
+	t.Run("all namespaces", func(t *testing.T) {
+		inventories, err := GetInventory(context.Background(), clientset, metadataClient, InventoryOptions{})
+		require.NoError(t, err)
+		require.Len(t, inventories, 2)
+
+		old := inventories[0]
+		assert.Equal(t, "old", old.Name)
+		assert.Equal(t, "Terminating", old.Phase)
+		assert.Equal(t, PodCounts{Total: 1, Failed: 1}, old.Pods)
+		assert.Equal(t, 1, old.Secrets)
+		assert.Nil(t, old.Annotations)
+		assert.Empty(t, old.ResourceQuotas)
+
+		teamA := inventories[1]
+		assert.NotEmpty(t, teamA.Age)
+		teamA.Age = ""
+		assert.Equal(t, Inventory{
+			Name:                   "team-a",
+			Phase:                  "Active",
+			CreatedAt:              created,
+			Labels:                 map[string]string{"team": "a"},
+			Annotations:            map[string]string{"owner": "alice"},
+			Pods:                   PodCounts{Total: 4, Running: 2, Pending: 1, Succeeded: 1},
+			Deployments:            1,
+			Services:               1,
+			PersistentVolumeClaims: 1,
+			Secrets:                1,
+			ResourceQuotas: []QuotaUsage{{
+				Name:   "compute",
+				Scopes: []string{"NotTerminating"},
+				Resources: []QuotaResource{
+					{Resource: "pods", Hard: "4", Used: "4", Percent: 100},
+					{Resource: "requests.cpu", Hard: "2", Used: "500m", Percent: 25},
+					{Resource: "requests.memory", Hard: "4Gi", Used: "1Gi", Percent: 25},
+				},
+			}},
+			LimitRanges: []LimitRange{{
+				Name: "defaults",
+				Limits: []LimitRangeItem{
+					{Type: "Container", Resource: "cpu", Max: "2", Default: "500m", DefaultRequest: "100m"},
+					{Type: "Container", Resource: "memory", Default: "256Mi", DefaultRequest: "128Mi"},
+				},
+			}},
+			Warnings: []string{"resource quota compute uses 4 of 4 pods (100%)"},
+		}, teamA)
+	})
+
+	t.Run("single namespace", func(t *testing.T) {
+		inventories, err := GetInventory(context.Background(), clientset, metadataClient, InventoryOptions{Namespace: "old"})
+		require.NoError(t, err)
+		require.Len(t, inventories, 1)
+		assert.Equal(t, "old", inventories[0].Name)
+		assert.Equal(t, 0, inventories[0].Deployments)
+	})
+
+	t.Run("label selector", func(t *testing.T) {
+		inventories, err := GetInventory(context.Background(), clientset, metadataClient, InventoryOptions{LabelSelector: "team=a"})
+		require.NoError(t, err)
+		require.Len(t, inventories, 1)
+		assert.Equal(t, "team-a", inventories[0].Name)
+	})
+	t.Run("forbidden kinds are reported as warnings", func(t *testing.T) {
+		restricted := newMetadataClient(t)
+		restricted.PrependReactor("list", "secrets", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			return true, nil, apierrors.NewForbidden(schema.GroupResource{Resource: "secrets"}, "", errors.New("not allowed"))
+		})
+		inventories, err := GetInventory(context.Background(), clientset, restricted, InventoryOptions{Namespace: "old"})
+		require.NoError(t, err)
+		require.Len(t, inventories, 1)
+		assert.Equal(t, []string{`unable to count secrets: secrets is forbidden: not allowed`}, inventories[0].Warnings)
+	})
+}
+
+func objectMetadata(apiVersion, kind, name, namespace string) *metav1.PartialObjectMetadata {
+	return &metav1.PartialObjectMetadata{
+		TypeMeta:   metav1.TypeMeta{APIVersion: apiVersion, Kind: kind},
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+	}
+}
+
+// podMetadata keeps phase of the pod in annotation, since metadata
+// does not have it, to let fake client filter pods by status.phase
+func podMetadata(name, namespace string, phase corev1.PodPhase) *metav1.PartialObjectMetadata {
+	pod := objectMetadata("v1", "Pod", name, namespace)
+	pod.Annotations = map[string]string{"phase": string(phase)}
+	return pod
+}
+
+// newMetadataClient creates fake metadata client, which supports
+// status.phase field selector for pods unlike the default one
+func newMetadataClient(t *testing.T, objects ...runtime.Object) *metadatafake.FakeMetadataClient {
+	scheme := runtime.NewScheme()
+	require.NoError(t, metav1.AddMetaToScheme(scheme))
+	client := metadatafake.NewSimpleMetadataClient(scheme, objects...)
+	client.PrependReactor("list", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		restrictions := action.(k8stesting.ListAction).GetListRestrictions()
+		phase, ok := restrictions.Fields.RequiresExactMatch("status.phase")
+		if !ok {
+			return false, nil, nil
+		}
+		list, err := client.Tracker().List(action.GetResource(), schema.GroupVersionKind{Group: "fake-metadata-client-group", Version: "v1"}, action.GetNamespace())
+		if err != nil {
+			return true, nil, err
+		}
+		items := list.(*metav1.List)
+		filtered := &metav1.List{}
+		for _, item := range items.Items {
+			if item.Object.(*metav1.PartialObjectMetadata).Annotations["phase"] == phase {
+				filtered.Items = append(filtered.Items, item)
+			}
+		}
+		return true, filtered, nil
+	})
+	return client
+}
@@ -0,0 +1,320 @@
+package node
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/strowk/mcp-k8s-go/internal/k8s/core/v1/pod"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/kubernetes"
+)
+
+// noPool is the pool of nodes, which do not have pool label
+const noPool = "none"
+
+// DefaultPoolLabels are labels of managed Kubernetes offerings and
+// autoscalers, which are used to group nodes into pools, first found wins
+var DefaultPoolLabels = []string{
+	"cloud.google.com/gke-nodepool",
+	"eks.amazonaws.com/nodegroup",
+	"alpha.eksctl.io/nodegroup-name",
+	"karpenter.sh/nodepool",
+	"kubernetes.azure.com/agentpool",
+	"agentpool",
+	"doks.digitalocean.com/node-pool",
+	"node.kubernetes.io/pool",
+}
+
+// allocatedResources are always reported, other extended
+// resources are only reported when they look like GPUs
+var allocatedResources = []corev1.ResourceName{
+	corev1.ResourceCPU,
+	corev1.ResourceMemory,
+	corev1.ResourceEphemeralStorage,
+}
+
+type AllocationOptions struct {
+	// NodeName limits report to single node
+	NodeName      string
+	LabelSelector string
+	// PoolLabel is label grouping nodes into pools, DefaultPoolLabels are tried if empty
+	PoolLabel string
+	// TopConsumers is number of pods with the biggest requests reported per node
+	TopConsumers int
+}
+
+// AllocationReport sums requests and limits of non-terminated pods
+// per node like "Allocated resources" of kubectl describe node does,
+// and rolls them up by node pools and the whole cluster
+type AllocationReport struct {
+	Nodes   []NodeAllocation `json:"nodes"`
+	Pools   []PoolAllocation `json:"pools"`
+	Cluster PoolAllocation   `json:"cluster"`
+}
+
+type NodeAllocation struct {
+	Node         string               `json:"node"`
+	Pool         string               `json:"pool"`
+	Pods         int                  `json:"pods"`
+	Resources    []ResourceAllocation `json:"resources"`
+	TopConsumers []PodConsumption     `json:"topConsumers,omitempty"`
+}
+
+type PoolAllocation struct {
+	Pool      string               `json:"pool,omitempty"`
+	Nodes     int                  `json:"nodes"`
+	Pods      int                  `json:"pods"`
+	Resources []ResourceAllocation `json:"resources"`
+}
+
+// ResourceAllocation compares requests and limits of the resource with allocatable,
+// OvercommitRatio is limits divided by allocatable and is above 1 when overcommitted
+type ResourceAllocation struct {
+	Resource        string  `json:"resource"`
+	Allocatable     string  `json:"allocatable"`
+	Requests        string  `json:"requests"`
+	Limits          string  `json:"limits"`
+	RequestsPercent int     `json:"requestsPercent"`
+	LimitsPercent   int     `json:"limitsPercent"`
+	OvercommitRatio float64 `json:"overcommitRatio"`
+
+	allocatable resource.Quantity
+	requests    resource.Quantity
+	limits      resource.Quantity
+}
+
+type PodConsumption struct {
+	Namespace string            `json:"namespace"`
+	Name      string            `json:"name"`
+	Requests  map[string]string `json:"requests,omitempty"`
+	Limits    map[string]string `json:"limits,omitempty"`
+
+	// share is the biggest fraction of allocatable requested by the pod
+	share float64
+}
+
+// GetAllocation builds allocation report for nodes matching options
+func GetAllocation(ctx context.Context, clientset kubernetes.Interface, options AllocationOptions) (*AllocationReport, error) {
+	var nodes []corev1.Node
+	if options.NodeName != "" {
+		node, err := clientset.CoreV1().Nodes().Get(ctx, options.NodeName, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, *node)
+	} else {
+		nodeList, err := clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{LabelSelector: options.LabelSelector})
+		if err != nil {
+			return nil, err
+		}
+		nodes = nodeList.Items
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].Name < nodes[j].Name
+	})
+
+	podsByNode, err := nonTerminatedPods(ctx, clientset, options.NodeName)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &AllocationReport{Nodes: []NodeAllocation{}, Pools: []PoolAllocation{}}
+	pools := map[string]*PoolAllocation{}
+	for i := range nodes {
+		node := &nodes[i]
+		allocation := newNodeAllocation(node, podsByNode[node.Name], poolOf(node, options.PoolLabel), options.TopConsumers)
+		report.Nodes = append(report.Nodes, allocation)
+
+		if pools[allocation.Pool] == nil {
+			pools[allocation.Pool] = &PoolAllocation{Pool: allocation.Pool}
+		}
+		pools[allocation.Pool].add(allocation)
+		report.Cluster.add(allocation)
+	}
+
+	for _, pool := range pools {
+		report.Pools = append(report.Pools, *pool)
+	}
+	sort.Slice(report.Pools, func(i, j int) bool {
+		return report.Pools[i].Pool < report.Pools[j].Pool
+	})
+	return report, nil
+}
+
+// nonTerminatedPods returns pods, which are assigned to nodes and still
+// hold resources there, grouped by node name
+func nonTerminatedPods(ctx context.Context, clientset kubernetes.Interface, nodeName string) (map[string][]corev1.Pod, error) {
+	selector := fields.AndSelectors(
+		fields.OneTermNotEqualSelector("status.phase", string(corev1.PodSucceeded)),
+		fields.OneTermNotEqualSelector("status.phase", string(corev1.PodFailed)),
+	)
+	if nodeName != "" {
+		selector = fields.AndSelectors(selector, fields.OneTermEqualSelector("spec.nodeName", nodeName))
+	}
+	pods, err := clientset.CoreV1().Pods(metav1.NamespaceAll).List(ctx, metav1.ListOptions{FieldSelector: selector.String()})
+	if err != nil {
+		return nil, err
+	}
+
+	podsByNode := map[string][]corev1.Pod{}
+	for _, p := range pods.Items {
+		if p.Spec.NodeName == "" || p.Status.Phase == corev1.PodSucceeded || p.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		podsByNode[p.Spec.NodeName] = append(podsByNode[p.Spec.NodeName], p)
+	}
+	return podsByNode, nil
+}
+
+func poolOf(node *corev1.Node, poolLabel string) string {
+	if poolLabel != "" {
+		if pool, ok := node.Labels[poolLabel]; ok && pool != "" {
+			return pool
+		}
+		return noPool
+	}
+	for _, label := range DefaultPoolLabels {
+		if pool, ok := node.Labels[label]; ok && pool != "" {
+			return pool
+		}
+	}
+	return noPool
+}
+
+func newNodeAllocation(node *corev1.Node, pods []corev1.Pod, pool string, topConsumers int) NodeAllocation {
+	allocation := NodeAllocation{
+		Node: node.Name,
+		Pool: pool,
+		Pods: len(pods),
+	}
+
+	names := reportedResources(node.Status.Allocatable)
+	requests := corev1.ResourceList{}
+	limits := corev1.ResourceList{}
+	consumers := make([]PodConsumption, 0, len(pods))
+	for i := range pods {
+		podRequests := pod.Requests(&pods[i])
+		podLimits := pod.Limits(&pods[i])
+		pod.AddResources(requests, podRequests)
+		pod.AddResources(limits, podLimits)
+		for name := range podRequests {
+			if isGPU(name) && !containsResource(names, name) {
+				names = append(names, name)
+			}
+		}
+
+		consumer := PodConsumption{
+			Namespace: pods[i].Namespace,
+			Name:      pods[i].Name,
+			Requests:  formatResources(podRequests, names),
+			Limits:    formatResources(podLimits, names),
+		}
+		for _, name := range names {
+			consumer.share = math.Max(consumer.share, pod.Fraction(podRequests[name], node.Status.Allocatable[name]))
+		}
+		consumers = append(consumers, consumer)
+	}
+
+	for _, name := range names {
+		allocation.Resources = append(allocation.Resources, newResourceAllocation(
+			name, node.Status.Allocatable[name], requests[name], limits[name],
+		))
+	}
+
+	sort.SliceStable(consumers, func(i, j int) bool {
+		return consumers[i].share > consumers[j].share
+	})
+	if len(consumers) > topConsumers {
+		consumers = consumers[:topConsumers]
+	}
+	if len(consumers) > 0 {
+		allocation.TopConsumers = consumers
+	}
+	return allocation
+}
+
+func (p *PoolAllocation) add(node NodeAllocation) {
+	p.Nodes++
+	p.Pods += node.Pods
+	for _, added := range node.Resources {
+		found := false
+		for i := range p.Resources {
+			if p.Resources[i].Resource == added.Resource {
+				existing := &p.Resources[i]
+				existing.allocatable.Add(added.allocatable)
+				existing.requests.Add(added.requests)
+				existing.limits.Add(added.limits)
+				*existing = newResourceAllocation(corev1.ResourceName(existing.Resource), existing.allocatable, existing.requests, existing.limits)
+				found = true
+				break
+			}
+		}
+		if !found {
+			p.Resources = append(p.Resources, newResourceAllocation(
+				corev1.ResourceName(added.Resource), added.allocatable.DeepCopy(), added.requests.DeepCopy(), added.limits.DeepCopy(),
+			))
+		}
+	}
+}
+
+func newResourceAllocation(name corev1.ResourceName, allocatable, requests, limits resource.Quantity) ResourceAllocation {
+	return ResourceAllocation{
+		Resource:        string(name),
+		Allocatable:     allocatable.String(),
+		Requests:        requests.String(),
+		Limits:          limits.String(),
+		RequestsPercent: int(math.Round(pod.Fraction(requests, allocatable) * 100)),
+		LimitsPercent:   int(math.Round(pod.Fraction(limits, allocatable) * 100)),
+		OvercommitRatio: math.Round(pod.Fraction(limits, allocatable)*100) / 100,
+		allocatable:     allocatable,
+		requests:        requests,
+		limits:          limits,
+	}
+}
+
+// reportedResources returns names of resources reported for the node,
+// which are cpu, memory, ephemeral storage and GPUs found in allocatable
+func reportedResources(allocatable corev1.ResourceList) []corev1.ResourceName {
+	names := append([]corev1.ResourceName{}, allocatedResources...)
+	var gpus []corev1.ResourceName
+	for name := range allocatable {
+		if isGPU(name) {
+			gpus = append(gpus, name)
+		}
+	}
+	sort.Slice(gpus, func(i, j int) bool {
+		return gpus[i] < gpus[j]
+	})
+	return append(names, gpus...)
+}
+
+func isGPU(name corev1.ResourceName) bool {
+	return strings.Contains(strings.ToLower(string(name)), "gpu")
+}
+
+func containsResource(names []corev1.ResourceName, name corev1.ResourceName) bool {
+	for _, existing := range names {
+		if existing == name {
+			return true
+		}
+	}
+	return false
+}
+
+func formatResources(resources corev1.ResourceList, names []corev1.ResourceName) map[string]string {
+	formatted := map[string]string{}
+	for _, name := range names {
+		if quantity, ok := resources[name]; ok && !quantity.IsZero() {
+			formatted[string(name)] = quantity.String()
+		}
+	}
+	if len(formatted) == 0 {
+		return nil
+	}
+	return formatted
+}
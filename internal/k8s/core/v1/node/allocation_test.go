@@ -0,0 +1,134 @@
+package node
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestGetAllocation(t *testing.T) {
+	newNode := func(name string, pool string, allocatable corev1.ResourceList) *corev1.Node {
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"cloud.google.com/gke-nodepool": pool}},
+			Status:     corev1.NodeStatus{Allocatable: allocatable},
+		}
+	}
+	newPod := func(name string, node string, phase corev1.PodPhase, requests, limits corev1.ResourceList) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec: corev1.PodSpec{
+				NodeName: node,
+				Containers: []corev1.Container{{Name: "app", Resources: corev1.ResourceRequirements{
+					Requests: requests, Limits: limits,
+				}}},
+			},
+			Status: corev1.PodStatus{Phase: phase},
+		}
+	}
+	resources := func(cpu, memory string) corev1.ResourceList {
+		return corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(cpu), corev1.ResourceMemory: resource.MustParse(memory)}
+	}
+
+	gpuAllocatable := resources("8", "32Gi")
+	gpuAllocatable["nvidia.com/gpu"] = resource.MustParse("2")
+	gpuAllocatable[corev1.ResourceEphemeralStorage] = resource.MustParse("100Gi")
+	gpuRequests := resources("2", "8Gi")
+	gpuRequests["nvidia.com/gpu"] = resource.MustParse("1")
+
+	clientset := fake.NewClientset(
+		newNode("general-1", "general", resources("4", "16Gi")),
+		newNode("general-2", "general", resources("4", "16Gi")),
+		newNode("gpu-1", "gpu", gpuAllocatable),
+		newPod("web", "general-1", corev1.PodRunning, resources("1", "2Gi"), resources("2", "4Gi")),
+		newPod("api", "general-1", corev1.PodRunning, resources("2", "1Gi"), resources("6", "8Gi")),
+		newPod("done", "general-1", corev1.PodSucceeded, resources("4", "16Gi"), nil),
+		newPod("pending", "", corev1.PodPending, resources("4", "16Gi"), nil),
+		newPod("train", "gpu-1", corev1.PodRunning, gpuRequests, gpuRequests),
+	)
+
+	report, err := GetAllocation(context.Background(), clientset, AllocationOptions{TopConsumers: 1})
+	require.NoError(t, err)
+	require.Len(t, report.Nodes, 3)
+
+	general := report.Nodes[0]
+	assert.Equal(t, "general-1", general.Node)
+	assert.Equal(t, "general", general.Pool)
+	assert.Equal(t, 2, general.Pods)
+	assert.Equal(t, []ResourceAllocation{
+		{Resource: "cpu", Allocatable: "4", Requests: "3", Limits: "8", RequestsPercent: 75, LimitsPercent: 200, OvercommitRatio: 2},
+		{Resource: "memory", Allocatable: "16Gi", Requests: "3Gi", Limits: "12Gi", RequestsPercent: 19, LimitsPercent: 75, OvercommitRatio: 0.75},
+		{Resource: "ephemeral-storage", Allocatable: "0", Requests: "0", Limits: "0"},
+	}, withoutQuantities(general.Resources))
+	assert.Equal(t, []PodConsumption{{
+		Namespace: "default",
+		Name:      "api",
+		Requests:  map[string]string{"cpu": "2", "memory": "1Gi"},
+		Limits:    map[string]string{"cpu": "6", "memory": "8Gi"},
+	}}, withoutShare(general.TopConsumers))
+
+	assert.Equal(t, 0, report.Nodes[1].Pods)
+	assert.Empty(t, report.Nodes[1].TopConsumers)
+
+	gpu := report.Nodes[2]
+	assert.Equal(t, ResourceAllocation{
+		Resource: "nvidia.com/gpu", Allocatable: "2", Requests: "1", Limits: "1", RequestsPercent: 50, LimitsPercent: 50, OvercommitRatio: 0.5,
+	}, withoutQuantities(gpu.Resources)[3])
+
+	require.Len(t, report.Pools, 2)
+	assert.Equal(t, "general", report.Pools[0].Pool)
+	assert.Equal(t, 2, report.Pools[0].Nodes)
+	assert.Equal(t, ResourceAllocation{
+		Resource: "cpu", Allocatable: "8", Requests: "3", Limits: "8", RequestsPercent: 38, LimitsPercent: 100, OvercommitRatio: 1,
+	}, withoutQuantities(report.Pools[0].Resources)[0])
+
+	assert.Equal(t, 3, report.Cluster.Nodes)
+	assert.Equal(t, 3, report.Cluster.Pods)
+	assert.Equal(t, ResourceAllocation{
+		Resource: "cpu", Allocatable: "16", Requests: "5", Limits: "10", RequestsPercent: 31, LimitsPercent: 63, OvercommitRatio: 0.63,
+	}, withoutQuantities(report.Cluster.Resources)[0])
+	assert.Len(t, report.Cluster.Resources, 4)
+}
+
+func TestGetAllocationOfSingleNodeWithPoolLabel(t *testing.T) {
+	clientset := fake.NewClientset(
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "a", Labels: map[string]string{"team": "payments"}}},
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "b"}},
+	)
+
+	report, err := GetAllocation(context.Background(), clientset, AllocationOptions{NodeName: "a", PoolLabel: "team"})
+	require.NoError(t, err)
+	require.Len(t, report.Nodes, 1)
+	assert.Equal(t, "payments", report.Nodes[0].Pool)
+
+	report, err = GetAllocation(context.Background(), clientset, AllocationOptions{NodeName: "b", PoolLabel: "team"})
+	require.NoError(t, err)
+	assert.Equal(t, "none", report.Nodes[0].Pool)
+
+	_, err = GetAllocation(context.Background(), clientset, AllocationOptions{NodeName: "missing"})
+	assert.EqualError(t, err, `nodes "missing" not found`)
+}
+
+func withoutQuantities(resources []ResourceAllocation) []ResourceAllocation {
+	result := make([]ResourceAllocation, len(resources))
+	for i, r := range resources {
+		result[i] = ResourceAllocation{
+			Resource: r.Resource, Allocatable: r.Allocatable, Requests: r.Requests, Limits: r.Limits,
+			RequestsPercent: r.RequestsPercent, LimitsPercent: r.LimitsPercent, OvercommitRatio: r.OvercommitRatio,
+		}
+	}
+	return result
+}
+
+func withoutShare(consumers []PodConsumption) []PodConsumption {
+	result := make([]PodConsumption, len(consumers))
+	for i, c := range consumers {
+		result[i] = PodConsumption{Namespace: c.Namespace, Name: c.Name, Requests: c.Requests, Limits: c.Limits}
+	}
+	return result
+}
@@ -0,0 +1,284 @@
+package node
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+)
+
+const mirrorPodAnnotation = "kubernetes.io/config.mirror"
+
+// SetUnschedulable cordons or uncordons the node and returns
+// whether it was changed, or was already in requested state
+func SetUnschedulable(ctx context.Context, clientset kubernetes.Interface, name string, unschedulable bool, dryRun bool) (bool, error) {
+	node, err := clientset.CoreV1().Nodes().Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return false, err
+	}
+	if node.Spec.Unschedulable == unschedulable {
+		return false, nil
+	}
+
+	patch, err := json.Marshal(map[string]any{
+		"spec": map[string]any{"unschedulable": unschedulable},
+	})
+	if err != nil {
+		return false, err
+	}
+	options := metav1.PatchOptions{}
+	if dryRun {
+		options.DryRun = []string{metav1.DryRunAll}
+	}
+	if _, err := clientset.CoreV1().Nodes().Patch(ctx, name, types.MergePatchType, patch, options); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// DrainOptions configures which pods can be evicted from the node
+type DrainOptions struct {
+	DryRun bool
+	// Force allows evicting pods, which are not managed by any controller
+	Force bool
+	// DeleteEmptyDirData allows evicting pods using emptyDir volumes
+	DeleteEmptyDirData bool
+	GracePeriodSeconds *int64
+}
+
+// DrainReport describes what happened to pods on the drained node,
+// in dry run Evicted lists pods, which would be evicted, Cordoned
+// tells only whether node was cordoned before and WouldCordon tells
+// whether it would be cordoned by the drain
+type DrainReport struct {
+	Node        string       `json:"node"`
+	DryRun      bool         `json:"dryRun,omitempty"`
+	Cordoned    bool         `json:"cordoned"`
+	WouldCordon bool         `json:"wouldCordon,omitempty"`
+	Evicted     []PodRef     `json:"evicted"`
+	Skipped     []SkippedPod `json:"skipped,omitempty"`
+	Blocked     []BlockedPod `json:"blocked,omitempty"`
+}
+
+type PodRef struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+}
+
+type SkippedPod struct {
+	PodRef
+	Reason string `json:"reason"`
+}
+
+// BlockedPod is a pod, which could not be evicted, with names of
+// PodDisruptionBudgets blocking eviction if that is the reason
+type BlockedPod struct {
+	PodRef
+	Reason            string   `json:"reason"`
+	DisruptionBudgets []string `json:"disruptionBudgets,omitempty"`
+}
+
+// Drain cordons the node and evicts its pods using Eviction API, skipping
+// DaemonSet, mirror and terminating pods and reporting pods, which eviction
+// is blocked
+func Drain(ctx context.Context, clientset kubernetes.Interface, name string, options DrainOptions) (*DrainReport, error) {
+	report := &DrainReport{
+		Node:    name,
+		DryRun:  options.DryRun,
+		Evicted: []PodRef{},
+	}
+
+	changed, err := SetUnschedulable(ctx, clientset, name, true, options.DryRun)
+	if err != nil {
+		return nil, fmt.Errorf("failed to cordon node %s: %w", name, err)
+	}
+	if options.DryRun {
+		report.Cordoned = !changed
+		report.WouldCordon = changed
+	} else {
+		report.Cordoned = true
+	}
+
+	pods, err := clientset.CoreV1().Pods(metav1.NamespaceAll).List(ctx, metav1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("spec.nodeName", name).String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(pods.Items, func(i, j int) bool {
+		if pods.Items[i].Namespace != pods.Items[j].Namespace {
+			return pods.Items[i].Namespace < pods.Items[j].Namespace
+		}
+		return pods.Items[i].Name < pods.Items[j].Name
+	})
+
+	budgets := &disruptionBudgets{
+		clientset:   clientset,
+		byNamespace: map[string][]*policyv1.PodDisruptionBudget{},
+	}
+
+	for _, pod := range pods.Items {
+		ref := PodRef{Namespace: pod.Namespace, Name: pod.Name}
+
+		if reason := skipReason(&pod); reason != "" {
+			report.Skipped = append(report.Skipped, SkippedPod{PodRef: ref, Reason: reason})
+			continue
+		}
+
+		if reason := blockReason(&pod, options); reason != "" {
+			report.Blocked = append(report.Blocked, BlockedPod{PodRef: ref, Reason: reason})
+			continue
+		}
+
+		var matching []*policyv1.PodDisruptionBudget
+		// Eviction API does not check disruption budgets for pending
+		// pods, while finished pods do not count towards them
+		if !isFinished(&pod) && pod.Status.Phase != corev1.PodPending {
+			matching, err = budgets.matching(ctx, &pod)
+			if err != nil {
+				return nil, err
+			}
+			if blocking := blockingBudgets(matching); len(blocking) > 0 {
+				report.Blocked = append(report.Blocked, BlockedPod{
+					PodRef:            ref,
+					Reason:            "eviction would violate pod disruption budget",
+					DisruptionBudgets: blocking,
+				})
+				continue
+			}
+		}
+
+		if !options.DryRun {
+			err := clientset.PolicyV1().Evictions(pod.Namespace).Evict(ctx, &policyv1.Eviction{
+				ObjectMeta: metav1.ObjectMeta{Name: pod.Name, Namespace: pod.Namespace},
+				DeleteOptions: &metav1.DeleteOptions{
+					GracePeriodSeconds: options.GracePeriodSeconds,
+				},
+			})
+			if apierrors.IsNotFound(err) {
+				// pod is already gone
+				continue
+			}
+			if err != nil {
+				blocked := BlockedPod{PodRef: ref, Reason: err.Error()}
+				if apierrors.IsTooManyRequests(err) {
+					blocked.DisruptionBudgets = budgetNames(matching)
+				}
+				report.Blocked = append(report.Blocked, blocked)
+				continue
+			}
+		}
+
+		// account for this disruption, so that following pods
+		// covered by the same budget are reported as blocked
+		for _, budget := range matching {
+			budget.Status.DisruptionsAllowed--
+		}
+		report.Evicted = append(report.Evicted, ref)
+	}
+
+	return report, nil
+}
+
+func skipReason(pod *corev1.Pod) string {
+	if _, ok := pod.Annotations[mirrorPodAnnotation]; ok {
+		return "mirror pod"
+	}
+	if controller := metav1.GetControllerOf(pod); controller != nil && controller.Kind == "DaemonSet" {
+		return fmt.Sprintf("managed by DaemonSet %s", controller.Name)
+	}
+	if pod.DeletionTimestamp != nil {
+		return "already terminating"
+	}
+	return ""
+}
+
+// isFinished tells whether pod has completed, so that it is not running
+// anymore and evicting it would not disrupt anything or lose any data
+func isFinished(pod *corev1.Pod) bool {
+	return pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed
+}
+
+func blockReason(pod *corev1.Pod, options DrainOptions) string {
+	if isFinished(pod) {
+		return ""
+	}
+	if !options.Force && metav1.GetControllerOf(pod) == nil {
+		return "not managed by any controller and would not be recreated, use force to evict"
+	}
+	if !options.DeleteEmptyDirData {
+		for _, volume := range pod.Spec.Volumes {
+			if volume.EmptyDir != nil {
+				return fmt.Sprintf("uses emptyDir volume %s, which data would be lost, use deleteEmptyDirData to evict", volume.Name)
+			}
+		}
+	}
+	return ""
+}
+
+func blockingBudgets(budgets []*policyv1.PodDisruptionBudget) []string {
+	var blocking []*policyv1.PodDisruptionBudget
+	for _, budget := range budgets {
+		if budget.Status.DisruptionsAllowed < 1 {
+			blocking = append(blocking, budget)
+		}
+	}
+	return budgetNames(blocking)
+}
+
+func budgetNames(budgets []*policyv1.PodDisruptionBudget) []string {
+	var names []string
+	for _, budget := range budgets {
+		names = append(names, budget.Name)
+	}
+	return names
+}
+
+// disruptionBudgets lazily loads PodDisruptionBudgets per namespace,
+// keeping track of disruptions made during the drain
+type disruptionBudgets struct {
+	clientset   kubernetes.Interface
+	byNamespace map[string][]*policyv1.PodDisruptionBudget
+}
+
+func (d *disruptionBudgets) matching(ctx context.Context, pod *corev1.Pod) ([]*policyv1.PodDisruptionBudget, error) {
+	budgets, ok := d.byNamespace[pod.Namespace]
+	if !ok {
+		list, err := d.clientset.PolicyV1().PodDisruptionBudgets(pod.Namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list pod disruption budgets in namespace %s: %w", pod.Namespace, err)
+		}
+		for i := range list.Items {
+			budgets = append(budgets, &list.Items[i])
+		}
+		sort.Slice(budgets, func(i, j int) bool {
+			return budgets[i].Name < budgets[j].Name
+		})
+		d.byNamespace[pod.Namespace] = budgets
+	}
+
+	var matching []*policyv1.PodDisruptionBudget
+	for _, budget := range budgets {
+		if budget.Spec.Selector == nil {
+			// budget without selector does not match any pods
+			continue
+		}
+		selector, err := metav1.LabelSelectorAsSelector(budget.Spec.Selector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid selector of pod disruption budget %s: %w", budget.Name, err)
+		}
+		if selector.Matches(labels.Set(pod.Labels)) {
+			matching = append(matching, budget)
+		}
+	}
+	return matching, nil
+}
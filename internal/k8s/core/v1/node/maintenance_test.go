@@ -0,0 +1,155 @@
+package node
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/strowk/mcp-k8s-go/internal/utils"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func newPod(name string, controllerKind string, labels map[string]string) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+			Labels:    labels,
+		},
+		Spec:   corev1.PodSpec{NodeName: "worker"},
+		Status: corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+	if controllerKind != "" {
+		pod.OwnerReferences = []metav1.OwnerReference{
+			{Kind: controllerKind, Name: "owner", Controller: utils.Ptr(true)},
+		}
+	}
+	return pod
+}
+
+func TestDrain(t *testing.T) {
+	mirror := newPod("mirror", "", nil)
+	mirror.Annotations = map[string]string{mirrorPodAnnotation: "hash"}
+	withEmptyDir := newPod("with-empty-dir", "ReplicaSet", nil)
+	withEmptyDir.Spec.Volumes = []corev1.Volume{
+		{Name: "cache", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}},
+	}
+	terminating := newPod("terminating", "ReplicaSet", nil)
+	terminating.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+	completed := newPod("completed", "", nil)
+	completed.Status.Phase = corev1.PodSucceeded
+	completed.Spec.Volumes = withEmptyDir.Spec.Volumes
+	pending := newPod("web-pending", "ReplicaSet", map[string]string{"app": "web"})
+	pending.Status.Phase = corev1.PodPending
+
+	setup := func() *fake.Clientset {
+		return fake.NewClientset(
+			&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "worker"}},
+			newPod("agent", "DaemonSet", nil),
+			mirror,
+			terminating,
+			completed,
+			newPod("unmanaged", "", nil),
+			withEmptyDir,
+			newPod("web-1", "ReplicaSet", map[string]string{"app": "web"}),
+			newPod("web-2", "ReplicaSet", map[string]string{"app": "web"}),
+			pending,
+			&policyv1.PodDisruptionBudget{
+				ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+				Spec: policyv1.PodDisruptionBudgetSpec{
+					Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+				},
+				Status: policyv1.PodDisruptionBudgetStatus{DisruptionsAllowed: 1},
+			},
+		)
+	}
+
+	ref := func(name string) PodRef {
+		return PodRef{Namespace: "default", Name: name}
+	}
+
+	t.Run("dry run lists pods which would be evicted", func(t *testing.T) {
+		clientset := setup()
+
+		report, err := Drain(context.Background(), clientset, "worker", DrainOptions{DryRun: true})
+		require.NoError(t, err)
+		assert.Equal(t, &DrainReport{
+			Node:        "worker",
+			DryRun:      true,
+			Cordoned:    false,
+			WouldCordon: true,
+			Evicted:     []PodRef{ref("completed"), ref("web-1"), ref("web-pending")},
+			Skipped: []SkippedPod{
+				{PodRef: ref("agent"), Reason: "managed by DaemonSet owner"},
+				{PodRef: ref("mirror"), Reason: "mirror pod"},
+				{PodRef: ref("terminating"), Reason: "already terminating"},
+			},
+			Blocked: []BlockedPod{
+				{PodRef: ref("unmanaged"), Reason: "not managed by any controller and would not be recreated, use force to evict"},
+				{PodRef: ref("web-2"), Reason: "eviction would violate pod disruption budget", DisruptionBudgets: []string{"web"}},
+				{PodRef: ref("with-empty-dir"), Reason: "uses emptyDir volume cache, which data would be lost, use deleteEmptyDirData to evict"},
+			},
+		}, report)
+
+		for _, action := range clientset.Actions() {
+			assert.False(t, action.Matches("create", "pods") && action.GetSubresource() == "eviction", "no pods must be evicted in dry run")
+		}
+	})
+
+	t.Run("dry run reports already cordoned node", func(t *testing.T) {
+		clientset := setup()
+		_, err := SetUnschedulable(context.Background(), clientset, "worker", true, false)
+		require.NoError(t, err)
+
+		report, err := Drain(context.Background(), clientset, "worker", DrainOptions{DryRun: true})
+		require.NoError(t, err)
+		assert.True(t, report.Cordoned)
+		assert.False(t, report.WouldCordon)
+	})
+
+	t.Run("evict pods", func(t *testing.T) {
+		clientset := setup()
+
+		report, err := Drain(context.Background(), clientset, "worker", DrainOptions{Force: true, DeleteEmptyDirData: true})
+		require.NoError(t, err)
+		assert.True(t, report.Cordoned)
+		assert.Equal(t, []PodRef{ref("completed"), ref("unmanaged"), ref("web-1"), ref("web-pending"), ref("with-empty-dir")}, report.Evicted)
+		assert.Equal(t, []BlockedPod{
+			{PodRef: ref("web-2"), Reason: "eviction would violate pod disruption budget", DisruptionBudgets: []string{"web"}},
+		}, report.Blocked)
+
+		var evicted []string
+		for _, action := range clientset.Actions() {
+			if action.Matches("create", "pods") && action.GetSubresource() == "eviction" {
+				evicted = append(evicted, action.(k8stesting.CreateAction).GetObject().(*policyv1.Eviction).Name)
+			}
+		}
+		assert.Equal(t, []string{"completed", "unmanaged", "web-1", "web-pending", "with-empty-dir"}, evicted)
+
+		node, err := clientset.CoreV1().Nodes().Get(context.Background(), "worker", metav1.GetOptions{})
+		require.NoError(t, err)
+		assert.True(t, node.Spec.Unschedulable)
+	})
+}
+
+func TestSetUnschedulable(t *testing.T) {
+	clientset := fake.NewClientset(&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "worker"}})
+
+	changed, err := SetUnschedulable(context.Background(), clientset, "worker", true, false)
+	require.NoError(t, err)
+	assert.True(t, changed)
+
+	changed, err = SetUnschedulable(context.Background(), clientset, "worker", true, false)
+	require.NoError(t, err)
+	assert.False(t, changed)
+
+	changed, err = SetUnschedulable(context.Background(), clientset, "worker", false, false)
+	require.NoError(t, err)
+	assert.True(t, changed)
+}
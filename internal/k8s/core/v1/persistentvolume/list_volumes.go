@@ -0,0 +1,75 @@
+package persistentvolume
+
+import (
+	"github.com/strowk/mcp-k8s-go/internal/k8s/list_mapping"
+	"github.com/strowk/mcp-k8s-go/internal/k8s/storage"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+type VolumeContent struct {
+	Name          string   `json:"name"`
+	Capacity      string   `json:"capacity"`
+	AccessModes   []string `json:"accessModes"`
+	ReclaimPolicy string   `json:"reclaimPolicy"`
+	Status        string   `json:"status"`
+	Claim         string   `json:"claim"`
+	StorageClass  string   `json:"storageClass"`
+	Reason        string   `json:"reason"`
+	Age           string   `json:"age"`
+}
+
+// NewVolumeContent formats volume similarly to kubectl get pv
+func NewVolumeContent(volume *corev1.PersistentVolume) *VolumeContent {
+	info := storage.NewVolumeInfo(volume)
+	content := &VolumeContent{
+		Name:          info.Name,
+		Capacity:      info.Capacity,
+		AccessModes:   info.AccessModes,
+		ReclaimPolicy: info.ReclaimPolicy,
+		Status:        info.Phase,
+		Claim:         info.Claim,
+		StorageClass:  info.StorageClass,
+		Reason:        info.Reason,
+		Age:           info.Age,
+	}
+	if volume.DeletionTimestamp != nil {
+		content.Status = "Terminating"
+	}
+	return content
+}
+
+func (v *VolumeContent) GetName() string {
+	return v.Name
+}
+
+func (v *VolumeContent) GetNamespace() string {
+	return ""
+}
+
+func getVolumeListMapping() list_mapping.ListMapping {
+	return func(u runtime.Unstructured) (list_mapping.ListContentItem, error) {
+		volume := corev1.PersistentVolume{}
+		err := runtime.DefaultUnstructuredConverter.FromUnstructuredWithValidation(u.UnstructuredContent(), &volume, false)
+		if err != nil {
+			return nil, err
+		}
+		return NewVolumeContent(&volume), nil
+	}
+}
+
+type listMappingResolver struct {
+	list_mapping.ListMappingResolver
+}
+
+func (r *listMappingResolver) GetListMapping(gvk *schema.GroupVersionKind) list_mapping.ListMapping {
+	if (gvk.Group == "core" || gvk.Group == "") && gvk.Version == "v1" && gvk.Kind == "PersistentVolume" {
+		return getVolumeListMapping()
+	}
+	return nil
+}
+
+func NewListMappingResolver() list_mapping.ListMappingResolver {
+	return &listMappingResolver{}
+}
@@ -0,0 +1,79 @@
+package persistentvolumeclaim
+
+import (
+	"time"
+
+	"github.com/strowk/mcp-k8s-go/internal/k8s/list_mapping"
+	"github.com/strowk/mcp-k8s-go/internal/k8s/storage"
+	"github.com/strowk/mcp-k8s-go/internal/utils"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+type ClaimContent struct {
+	Name         string   `json:"name"`
+	Namespace    string   `json:"namespace"`
+	Status       string   `json:"status"`
+	Volume       string   `json:"volume"`
+	Capacity     string   `json:"capacity"`
+	AccessModes  []string `json:"accessModes"`
+	StorageClass string   `json:"storageClass"`
+	Age          string   `json:"age"`
+}
+
+// NewClaimContent formats claim similarly to kubectl get pvc
+func NewClaimContent(claim *corev1.PersistentVolumeClaim) *ClaimContent {
+	content := &ClaimContent{
+		Name:        claim.Name,
+		Namespace:   claim.Namespace,
+		Status:      string(claim.Status.Phase),
+		Volume:      claim.Spec.VolumeName,
+		AccessModes: storage.FormatAccessModes(claim.Status.AccessModes),
+		Age:         utils.FormatAge(time.Since(claim.CreationTimestamp.Time)),
+	}
+	if capacity, ok := claim.Status.Capacity[corev1.ResourceStorage]; ok {
+		content.Capacity = capacity.String()
+	}
+	if claim.Spec.StorageClassName != nil {
+		content.StorageClass = *claim.Spec.StorageClassName
+	}
+	if claim.DeletionTimestamp != nil {
+		content.Status = "Terminating"
+	}
+	return content
+}
+
+func (c *ClaimContent) GetName() string {
+	return c.Name
+}
+
+func (c *ClaimContent) GetNamespace() string {
+	return c.Namespace
+}
+
+func getClaimListMapping() list_mapping.ListMapping {
+	return func(u runtime.Unstructured) (list_mapping.ListContentItem, error) {
+		claim := corev1.PersistentVolumeClaim{}
+		err := runtime.DefaultUnstructuredConverter.FromUnstructuredWithValidation(u.UnstructuredContent(), &claim, false)
+		if err != nil {
+			return nil, err
+		}
+		return NewClaimContent(&claim), nil
+	}
+}
+
+type listMappingResolver struct {
+	list_mapping.ListMappingResolver
+}
+
+func (r *listMappingResolver) GetListMapping(gvk *schema.GroupVersionKind) list_mapping.ListMapping {
+	if (gvk.Group == "core" || gvk.Group == "") && gvk.Version == "v1" && gvk.Kind == "PersistentVolumeClaim" {
+		return getClaimListMapping()
+	}
+	return nil
+}
+
+func NewListMappingResolver() list_mapping.ListMappingResolver {
+	return &listMappingResolver{}
+}
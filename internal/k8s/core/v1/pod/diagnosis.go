@@ -0,0 +1,745 @@
+package pod
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/strowk/mcp-k8s-go/internal/utils"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Severities of findings, errors explain why the pod does not work
+// and warnings point to problems, which might cause that later
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// maxDiagnosisEvents limits number of most recent events in diagnosis
+const maxDiagnosisEvents = 10
+
+// DiagnoseOptions configures how much evidence is collected
+type DiagnoseOptions struct {
+	// LogTailLines is number of lines of logs of previous container
+	// to return for restarted containers, logs are not fetched if zero
+	LogTailLines int64
+}
+
+// Diagnosis explains why the pod does not work, Verdict summarizes
+// the most severe finding and Findings list everything found with evidence
+type Diagnosis struct {
+	Pod        string               `json:"pod"`
+	Namespace  string               `json:"namespace"`
+	Phase      string               `json:"phase"`
+	Healthy    bool                 `json:"healthy"`
+	Verdict    string               `json:"verdict"`
+	Findings   []Finding            `json:"findings"`
+	Containers []ContainerDiagnosis `json:"containers"`
+	Node       *NodeDiagnosis       `json:"node,omitempty"`
+	Events     []string             `json:"events,omitempty"`
+}
+
+type Finding struct {
+	Severity  string   `json:"severity"`
+	Reason    string   `json:"reason"`
+	Container string   `json:"container,omitempty"`
+	Message   string   `json:"message"`
+	Evidence  []string `json:"evidence,omitempty"`
+}
+
+type ContainerDiagnosis struct {
+	Name            string   `json:"name"`
+	Init            bool     `json:"init,omitempty"`
+	Image           string   `json:"image"`
+	State           string   `json:"state"`
+	Ready           bool     `json:"ready"`
+	RestartCount    int32    `json:"restartCount"`
+	LastTermination string   `json:"lastTermination,omitempty"`
+	Probes          []string `json:"probes,omitempty"`
+	PreviousLogs    string   `json:"previousLogs,omitempty"`
+}
+
+type NodeDiagnosis struct {
+	Name          string   `json:"name"`
+	Ready         bool     `json:"ready"`
+	Unschedulable bool     `json:"unschedulable,omitempty"`
+	Conditions    []string `json:"conditions,omitempty"`
+}
+
+// Diagnose inspects the pod, its containers, events, referenced
+// configuration and volumes and its node to explain why it is broken
+func Diagnose(ctx context.Context, clientset kubernetes.Interface, namespace string, name string, options DiagnoseOptions) (*Diagnosis, error) {
+	pod, err := clientset.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	d := &Diagnosis{
+		Pod:        pod.Name,
+		Namespace:  pod.Namespace,
+		Phase:      string(pod.Status.Phase),
+		Findings:   []Finding{},
+		Containers: []ContainerDiagnosis{},
+	}
+
+	events, err := podEvents(ctx, clientset, pod)
+	if err != nil {
+		return nil, err
+	}
+	for _, event := range events {
+		d.Events = append(d.Events, FormatEvent(event))
+	}
+
+	d.checkPodStatus(pod, events)
+	d.checkContainers(ctx, clientset, pod, options)
+	d.checkProbeEvents(events)
+	if err := d.checkReferences(ctx, clientset, pod); err != nil {
+		return nil, err
+	}
+	if err := d.checkNode(ctx, clientset, pod); err != nil {
+		return nil, err
+	}
+
+	d.Healthy = true
+	for _, finding := range d.Findings {
+		if finding.Severity == SeverityError {
+			d.Healthy = false
+			break
+		}
+	}
+	// errors go first, as they are the most likely explanation
+	sort.SliceStable(d.Findings, func(i, j int) bool {
+		return d.Findings[i].Severity == SeverityError && d.Findings[j].Severity != SeverityError
+	})
+
+	switch {
+	case len(d.Findings) == 0:
+		d.Verdict = "no problems found"
+	case d.Findings[0].Container != "":
+		d.Verdict = fmt.Sprintf("%s: container %s: %s", d.Findings[0].Reason, d.Findings[0].Container, d.Findings[0].Message)
+	default:
+		d.Verdict = fmt.Sprintf("%s: %s", d.Findings[0].Reason, d.Findings[0].Message)
+	}
+
+	return d, nil
+}
+
+func (d *Diagnosis) add(finding Finding) {
+	d.Findings = append(d.Findings, finding)
+}
+
+func (d *Diagnosis) checkPodStatus(pod *corev1.Pod, events []corev1.Event) {
+	switch pod.Status.Phase {
+	case corev1.PodFailed:
+		message := pod.Status.Message
+		if message == "" {
+			message = "all containers terminated and at least one of them failed"
+		}
+		reason := pod.Status.Reason
+		if reason == "" {
+			reason = "Failed"
+		}
+		d.add(Finding{Severity: SeverityError, Reason: reason, Message: message})
+	case corev1.PodPending:
+		for _, condition := range pod.Status.Conditions {
+			if condition.Type == corev1.PodScheduled && condition.Status == corev1.ConditionFalse {
+				d.add(Finding{
+					Severity: SeverityError,
+					Reason:   utils.FirstNonEmpty(condition.Reason, "Unschedulable"),
+					Message:  "pod cannot be scheduled to any node",
+					Evidence: append(nonEmpty(condition.Message), EventMessages(events, "FailedScheduling")...),
+				})
+			}
+		}
+	}
+
+	if pod.DeletionTimestamp != nil {
+		d.add(Finding{
+			Severity: SeverityWarning,
+			Reason:   "Terminating",
+			Message:  fmt.Sprintf("pod is being deleted since %s", pod.DeletionTimestamp.UTC().Format(time.RFC3339)),
+		})
+	}
+}
+
+func (d *Diagnosis) checkContainers(ctx context.Context, clientset kubernetes.Interface, pod *corev1.Pod, options DiagnoseOptions) {
+	statuses := map[string]corev1.ContainerStatus{}
+	for _, status := range pod.Status.InitContainerStatuses {
+		statuses["init/"+status.Name] = status
+	}
+	for _, status := range pod.Status.ContainerStatuses {
+		statuses[status.Name] = status
+	}
+
+	check := func(container corev1.Container, init bool) {
+		key := container.Name
+		if init {
+			key = "init/" + key
+		}
+		status, hasStatus := statuses[key]
+
+		diagnosis := ContainerDiagnosis{
+			Name:         container.Name,
+			Init:         init,
+			Image:        container.Image,
+			State:        formatContainerState(status.State),
+			Ready:        status.Ready,
+			RestartCount: status.RestartCount,
+			Probes:       formatProbes(container),
+		}
+		if !hasStatus {
+			diagnosis.State = "unknown"
+		}
+		if terminated := status.LastTerminationState.Terminated; terminated != nil {
+			diagnosis.LastTermination = formatTermination(terminated)
+		}
+
+		d.checkContainerStatus(container, status, hasStatus, pod)
+
+		if options.LogTailLines > 0 && status.RestartCount > 0 {
+			logs, err := previousLogs(ctx, clientset, pod, container.Name, options.LogTailLines)
+			if err != nil {
+				diagnosis.PreviousLogs = fmt.Sprintf("failed to get logs: %s", err)
+			} else {
+				diagnosis.PreviousLogs = logs
+			}
+		}
+
+		d.Containers = append(d.Containers, diagnosis)
+	}
+
+	for _, container := range pod.Spec.InitContainers {
+		check(container, true)
+	}
+	for _, container := range pod.Spec.Containers {
+		check(container, false)
+	}
+}
+
+func (d *Diagnosis) checkContainerStatus(container corev1.Container, status corev1.ContainerStatus, hasStatus bool, pod *corev1.Pod) {
+	if !hasStatus {
+		return
+	}
+
+	var evidence []string
+	if terminated := status.LastTerminationState.Terminated; terminated != nil {
+		evidence = append(evidence, "last termination: "+formatTermination(terminated))
+	}
+	if status.RestartCount > 0 {
+		evidence = append(evidence, fmt.Sprintf("restarted %d times", status.RestartCount))
+	}
+
+	if waiting := status.State.Waiting; waiting != nil {
+		switch waiting.Reason {
+		case "CrashLoopBackOff":
+			d.add(Finding{
+				Severity:  SeverityError,
+				Reason:    waiting.Reason,
+				Container: container.Name,
+				Message:   "container keeps crashing after start, see its previous logs and last termination",
+				Evidence:  append([]string{waiting.Message}, evidence...),
+			})
+		case "ImagePullBackOff", "ErrImagePull", "InvalidImageName", "ErrImageNeverPull":
+			d.add(Finding{
+				Severity:  SeverityError,
+				Reason:    waiting.Reason,
+				Container: container.Name,
+				Message:   fmt.Sprintf("image %s cannot be pulled, check image name, tag and registry credentials", container.Image),
+				Evidence:  nonEmpty(waiting.Message),
+			})
+		case "CreateContainerConfigError", "CreateContainerError", "RunContainerError":
+			d.add(Finding{
+				Severity:  SeverityError,
+				Reason:    waiting.Reason,
+				Container: container.Name,
+				Message:   "container cannot be created from its configuration",
+				Evidence:  nonEmpty(waiting.Message),
+			})
+		}
+	}
+
+	if terminated := status.State.Terminated; terminated != nil && terminated.ExitCode != 0 &&
+		pod.Spec.RestartPolicy == corev1.RestartPolicyNever {
+		d.add(Finding{
+			Severity:  SeverityError,
+			Reason:    utils.FirstNonEmpty(terminated.Reason, "Error"),
+			Container: container.Name,
+			Message:   "container failed and is not restarted",
+			Evidence:  []string{formatTermination(terminated)},
+		})
+	}
+
+	if isOOMKilled(status) {
+		memoryLimit := "not set"
+		if limit, ok := container.Resources.Limits[corev1.ResourceMemory]; ok {
+			memoryLimit = limit.String()
+		}
+		d.add(Finding{
+			Severity:  SeverityError,
+			Reason:    "OOMKilled",
+			Container: container.Name,
+			Message:   "container was killed for exceeding its memory limit or because the node ran out of memory",
+			Evidence:  append([]string{"memory limit: " + memoryLimit}, evidence...),
+		})
+	}
+
+	if status.State.Running != nil && !status.Ready && container.ReadinessProbe != nil {
+		d.add(Finding{
+			Severity:  SeverityWarning,
+			Reason:    "NotReady",
+			Container: container.Name,
+			Message:   "container is running, but its readiness probe does not pass",
+			Evidence:  []string{"readiness: " + formatProbe(container.ReadinessProbe)},
+		})
+	}
+}
+
+func isOOMKilled(status corev1.ContainerStatus) bool {
+	if terminated := status.State.Terminated; terminated != nil && terminated.Reason == "OOMKilled" {
+		return true
+	}
+	if terminated := status.LastTerminationState.Terminated; terminated != nil && terminated.Reason == "OOMKilled" {
+		return true
+	}
+	return false
+}
+
+// checkProbeEvents reports failing liveness and startup probes, which
+// cause restarts, readiness ones are reported by container status
+func (d *Diagnosis) checkProbeEvents(events []corev1.Event) {
+	messages := EventMessages(events, "Unhealthy")
+	if len(messages) == 0 {
+		return
+	}
+	d.add(Finding{
+		Severity: SeverityWarning,
+		Reason:   "ProbeFailed",
+		Message:  "probes of containers are failing",
+		Evidence: messages,
+	})
+}
+
+// checkReferences finds ConfigMaps, Secrets and PersistentVolumeClaims,
+// which are referenced by the pod, but do not exist or are not usable
+func (d *Diagnosis) checkReferences(ctx context.Context, clientset kubernetes.Interface, pod *corev1.Pod) error {
+	// results are cached, as the same object is often referenced multiple times
+	configMaps := map[string]*corev1.ConfigMap{}
+	secrets := map[string]*corev1.Secret{}
+	forbidden := map[string]error{}
+	reported := map[string]bool{}
+
+	getConfigMap := func(name string) (*corev1.ConfigMap, error) {
+		if configMap, ok := configMaps[name]; ok {
+			return configMap, nil
+		}
+		if err, ok := forbidden["ConfigMap "+name]; ok {
+			return nil, err
+		}
+		configMap, err := clientset.CoreV1().ConfigMaps(pod.Namespace).Get(ctx, name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			configMap, err = nil, nil
+		}
+		if apierrors.IsForbidden(err) {
+			forbidden["ConfigMap "+name] = err
+		}
+		if err != nil {
+			return nil, err
+		}
+		configMaps[name] = configMap
+		return configMap, nil
+	}
+	// whole Secret is read, as names of its keys are only available in its data,
+	// values are kept in memory only while the pod is diagnosed and never returned
+	getSecret := func(name string) (*corev1.Secret, error) {
+		if secret, ok := secrets[name]; ok {
+			return secret, nil
+		}
+		if err, ok := forbidden["Secret "+name]; ok {
+			return nil, err
+		}
+		secret, err := clientset.CoreV1().Secrets(pod.Namespace).Get(ctx, name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			secret, err = nil, nil
+		}
+		if apierrors.IsForbidden(err) {
+			forbidden["Secret "+name] = err
+		}
+		if err != nil {
+			return nil, err
+		}
+		secrets[name] = secret
+		return secret, nil
+	}
+	// unchecked reports objects, which cannot be read due to permissions, as
+	// warnings instead of failing, so that the rest of diagnosis is still returned
+	unchecked := func(kind, name, usage string, err error) error {
+		if !apierrors.IsForbidden(err) {
+			return err
+		}
+		message := fmt.Sprintf("unable to check %s %s: %v", kind, name, err)
+		if !reported[message] {
+			reported[message] = true
+			d.add(Finding{Severity: SeverityWarning, Reason: "Forbidden", Message: message, Evidence: []string{usage}})
+		}
+		return nil
+	}
+	report := func(kind, name, key, usage string, optional *bool) {
+		if optional != nil && *optional {
+			return
+		}
+		reason, message := "Missing"+kind, fmt.Sprintf("%s %s does not exist", kind, name)
+		if key != "" {
+			reason, message = "Missing"+kind+"Key", fmt.Sprintf("%s %s does not have key %s", kind, name, key)
+		}
+		if reported[message] {
+			return
+		}
+		reported[message] = true
+		d.add(Finding{Severity: SeverityError, Reason: reason, Message: message, Evidence: []string{usage}})
+	}
+
+	checkConfigMap := func(name, key, usage string, optional *bool) error {
+		configMap, err := getConfigMap(name)
+		if err != nil {
+			return unchecked("ConfigMap", name, usage, err)
+		}
+		if configMap == nil {
+			report("ConfigMap", name, "", usage, optional)
+		} else if _, ok := configMap.Data[key]; key != "" && !ok {
+			if _, ok := configMap.BinaryData[key]; !ok {
+				report("ConfigMap", name, key, usage, optional)
+			}
+		}
+		return nil
+	}
+	checkSecret := func(name, key, usage string, optional *bool) error {
+		secret, err := getSecret(name)
+		if err != nil {
+			return unchecked("Secret", name, usage, err)
+		}
+		if secret == nil {
+			report("Secret", name, "", usage, optional)
+		} else if _, ok := secret.Data[key]; key != "" && !ok {
+			report("Secret", name, key, usage, optional)
+		}
+		return nil
+	}
+
+	for _, volume := range pod.Spec.Volumes {
+		usage := fmt.Sprintf("volume %s", volume.Name)
+		var err error
+		switch {
+		case volume.ConfigMap != nil:
+			err = checkConfigMap(volume.ConfigMap.Name, "", usage, volume.ConfigMap.Optional)
+		case volume.Secret != nil:
+			err = checkSecret(volume.Secret.SecretName, "", usage, volume.Secret.Optional)
+		case volume.Projected != nil:
+			for _, source := range volume.Projected.Sources {
+				if source.ConfigMap != nil {
+					err = checkConfigMap(source.ConfigMap.Name, "", usage, source.ConfigMap.Optional)
+				} else if source.Secret != nil {
+					err = checkSecret(source.Secret.Name, "", usage, source.Secret.Optional)
+				}
+				if err != nil {
+					break
+				}
+			}
+		case volume.PersistentVolumeClaim != nil:
+			err = d.checkClaim(ctx, clientset, pod.Namespace, volume.PersistentVolumeClaim.ClaimName, usage)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	containers := append(append([]corev1.Container{}, pod.Spec.InitContainers...), pod.Spec.Containers...)
+	for _, container := range containers {
+		for _, envFrom := range container.EnvFrom {
+			usage := fmt.Sprintf("envFrom of container %s", container.Name)
+			var err error
+			if envFrom.ConfigMapRef != nil {
+				err = checkConfigMap(envFrom.ConfigMapRef.Name, "", usage, envFrom.ConfigMapRef.Optional)
+			} else if envFrom.SecretRef != nil {
+				err = checkSecret(envFrom.SecretRef.Name, "", usage, envFrom.SecretRef.Optional)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		for _, env := range container.Env {
+			if env.ValueFrom == nil {
+				continue
+			}
+			usage := fmt.Sprintf("env %s of container %s", env.Name, container.Name)
+			var err error
+			if ref := env.ValueFrom.ConfigMapKeyRef; ref != nil {
+				err = checkConfigMap(ref.Name, ref.Key, usage, ref.Optional)
+			} else if ref := env.ValueFrom.SecretKeyRef; ref != nil {
+				err = checkSecret(ref.Name, ref.Key, usage, ref.Optional)
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, pullSecret := range pod.Spec.ImagePullSecrets {
+		secret, err := getSecret(pullSecret.Name)
+		if err != nil {
+			if err := unchecked("Secret", pullSecret.Name, "imagePullSecrets", err); err != nil {
+				return err
+			}
+			continue
+		}
+		if secret == nil {
+			d.add(Finding{
+				Severity: SeverityWarning,
+				Reason:   "MissingImagePullSecret",
+				Message:  fmt.Sprintf("Secret %s does not exist", pullSecret.Name),
+				Evidence: []string{"imagePullSecrets"},
+			})
+		}
+	}
+	return nil
+}
+
+func (d *Diagnosis) checkClaim(ctx context.Context, clientset kubernetes.Interface, namespace, name, usage string) error {
+	claim, err := clientset.CoreV1().PersistentVolumeClaims(namespace).Get(ctx, name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		d.add(Finding{
+			Severity: SeverityError,
+			Reason:   "MissingPersistentVolumeClaim",
+			Message:  fmt.Sprintf("PersistentVolumeClaim %s does not exist", name),
+			Evidence: []string{usage},
+		})
+		return nil
+	}
+	if apierrors.IsForbidden(err) {
+		d.add(Finding{
+			Severity: SeverityWarning,
+			Reason:   "Forbidden",
+			Message:  fmt.Sprintf("unable to check PersistentVolumeClaim %s: %v", name, err),
+			Evidence: []string{usage},
+		})
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if claim.Status.Phase != corev1.ClaimBound {
+		d.add(Finding{
+			Severity: SeverityError,
+			Reason:   "PersistentVolumeClaimNotBound",
+			Message:  fmt.Sprintf("PersistentVolumeClaim %s is %s", name, claim.Status.Phase),
+			Evidence: []string{usage},
+		})
+	}
+	return nil
+}
+
+func (d *Diagnosis) checkNode(ctx context.Context, clientset kubernetes.Interface, pod *corev1.Pod) error {
+	if pod.Spec.NodeName == "" {
+		return nil
+	}
+	node, err := clientset.CoreV1().Nodes().Get(ctx, pod.Spec.NodeName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		d.add(Finding{
+			Severity: SeverityError,
+			Reason:   "NodeNotFound",
+			Message:  fmt.Sprintf("node %s of the pod does not exist anymore", pod.Spec.NodeName),
+		})
+		return nil
+	}
+	if apierrors.IsForbidden(err) {
+		d.add(Finding{
+			Severity: SeverityWarning,
+			Reason:   "Forbidden",
+			Message:  fmt.Sprintf("unable to check node %s of the pod: %v", pod.Spec.NodeName, err),
+		})
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	d.Node = &NodeDiagnosis{Name: node.Name, Unschedulable: node.Spec.Unschedulable}
+	for _, condition := range node.Status.Conditions {
+		normal := condition.Status == corev1.ConditionFalse
+		if condition.Type == corev1.NodeReady {
+			normal = condition.Status == corev1.ConditionTrue
+			d.Node.Ready = normal
+		}
+		if normal {
+			continue
+		}
+		formatted := fmt.Sprintf("%s=%s", condition.Type, condition.Status)
+		if condition.Message != "" {
+			formatted += ": " + condition.Message
+		}
+		d.Node.Conditions = append(d.Node.Conditions, formatted)
+
+		severity := SeverityWarning
+		if condition.Type == corev1.NodeReady {
+			severity = SeverityError
+		}
+		d.add(Finding{
+			Severity: severity,
+			Reason:   "Node" + string(condition.Type),
+			Message:  fmt.Sprintf("node %s has condition %s", node.Name, formatted),
+		})
+	}
+	return nil
+}
+
+func podEvents(ctx context.Context, clientset kubernetes.Interface, pod *corev1.Pod) ([]corev1.Event, error) {
+	list, err := clientset.CoreV1().Events(pod.Namespace).List(ctx, metav1.ListOptions{
+		FieldSelector: fields.AndSelectors(
+			fields.OneTermEqualSelector("involvedObject.kind", "Pod"),
+			fields.OneTermEqualSelector("involvedObject.name", pod.Name),
+		).String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var events []corev1.Event
+	for _, event := range list.Items {
+		// events of previous pod with the same name are not relevant
+		if event.InvolvedObject.Kind != "Pod" || event.InvolvedObject.Name != pod.Name || (event.InvolvedObject.UID != "" && event.InvolvedObject.UID != pod.UID) {
+			continue
+		}
+		events = append(events, event)
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return EventTime(events[i]).Before(EventTime(events[j]))
+	})
+	if len(events) > maxDiagnosisEvents {
+		events = events[len(events)-maxDiagnosisEvents:]
+	}
+	return events, nil
+}
+
+// EventTime returns when the event was last observed, events.k8s.io API
+// sets series or event time, while core one sets last and first timestamps
+func EventTime(event corev1.Event) time.Time {
+	switch {
+	case event.Series != nil:
+		return event.Series.LastObservedTime.Time
+	case !event.LastTimestamp.IsZero():
+		return event.LastTimestamp.Time
+	case !event.EventTime.IsZero():
+		return event.EventTime.Time
+	case !event.FirstTimestamp.IsZero():
+		return event.FirstTimestamp.Time
+	}
+	return event.CreationTimestamp.Time
+}
+
+// FormatEvent formats event as a line with time, type, reason, message and count
+func FormatEvent(event corev1.Event) string {
+	formatted := fmt.Sprintf("%s %s %s: %s", EventTime(event).UTC().Format(time.RFC3339), event.Type, event.Reason, event.Message)
+	if event.Count > 1 {
+		formatted += fmt.Sprintf(" (x%d)", event.Count)
+	}
+	return formatted
+}
+
+// EventMessages returns messages of events with the reason
+func EventMessages(events []corev1.Event, reason string) []string {
+	var messages []string
+	for _, event := range events {
+		if event.Reason == reason {
+			messages = append(messages, event.Message)
+		}
+	}
+	return messages
+}
+
+func previousLogs(ctx context.Context, clientset kubernetes.Interface, pod *corev1.Pod, container string, tailLines int64) (string, error) {
+	data, err := clientset.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &corev1.PodLogOptions{
+		Container: container,
+		Previous:  true,
+		TailLines: &tailLines,
+	}).Do(ctx).Raw()
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func formatContainerState(state corev1.ContainerState) string {
+	switch {
+	case state.Running != nil:
+		return "running since " + state.Running.StartedAt.UTC().Format(time.RFC3339)
+	case state.Waiting != nil:
+		return "waiting: " + state.Waiting.Reason
+	case state.Terminated != nil:
+		return "terminated: " + formatTermination(state.Terminated)
+	}
+	return "unknown"
+}
+
+func formatTermination(terminated *corev1.ContainerStateTerminated) string {
+	formatted := fmt.Sprintf("%s, exit code %d", utils.FirstNonEmpty(terminated.Reason, "Terminated"), terminated.ExitCode)
+	if !terminated.FinishedAt.IsZero() {
+		formatted += " at " + terminated.FinishedAt.UTC().Format(time.RFC3339)
+	}
+	if terminated.Message != "" {
+		formatted += ": " + terminated.Message
+	}
+	return formatted
+}
+
+func formatProbes(container corev1.Container) []string {
+	var probes []string
+	if container.StartupProbe != nil {
+		probes = append(probes, "startup: "+formatProbe(container.StartupProbe))
+	}
+	if container.LivenessProbe != nil {
+		probes = append(probes, "liveness: "+formatProbe(container.LivenessProbe))
+	}
+	if container.ReadinessProbe != nil {
+		probes = append(probes, "readiness: "+formatProbe(container.ReadinessProbe))
+	}
+	return probes
+}
+
+// formatProbe describes probe similarly to kubectl describe
+func formatProbe(probe *corev1.Probe) string {
+	var handler string
+	switch {
+	case probe.HTTPGet != nil:
+		scheme := strings.ToLower(string(probe.HTTPGet.Scheme))
+		if scheme == "" {
+			scheme = "http"
+		}
+		handler = fmt.Sprintf("http-get %s://%s:%s%s", scheme, probe.HTTPGet.Host, probe.HTTPGet.Port.String(), probe.HTTPGet.Path)
+	case probe.TCPSocket != nil:
+		handler = fmt.Sprintf("tcp-socket %s:%s", probe.TCPSocket.Host, probe.TCPSocket.Port.String())
+	case probe.Exec != nil:
+		handler = fmt.Sprintf("exec %v", probe.Exec.Command)
+	case probe.GRPC != nil:
+		handler = fmt.Sprintf("grpc <pod>:%d", probe.GRPC.Port)
+	default:
+		handler = "unknown"
+	}
+	return fmt.Sprintf("%s delay=%ds timeout=%ds period=%ds #success=%d #failure=%d",
+		handler, probe.InitialDelaySeconds, probe.TimeoutSeconds, probe.PeriodSeconds, probe.SuccessThreshold, probe.FailureThreshold)
+}
+
+func nonEmpty(values ...string) []string {
+	var result []string
+	for _, value := range values {
+		if value != "" {
+			result = append(result, value)
+		}
+	}
+	return result
+}
@@ -0,0 +1,267 @@
+package pod
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/strowk/mcp-k8s-go/internal/utils"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestDiagnose(t *testing.T) {
+	finishedAt := metav1.NewTime(time.Date(2024, 12, 1, 19, 0, 8, 0, time.UTC))
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default", UID: "pod-uid"},
+		Spec: corev1.PodSpec{
+			NodeName:      "worker",
+			RestartPolicy: corev1.RestartPolicyAlways,
+			Volumes: []corev1.Volume{
+				{Name: "config", VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "missing-config"},
+				}}},
+				{Name: "optional", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{
+					SecretName: "missing-optional", Optional: utils.Ptr(true),
+				}}},
+				{Name: "data", VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+					ClaimName: "data",
+				}}},
+			},
+			Containers: []corev1.Container{
+				{
+					Name:  "app",
+					Image: "app:1",
+					Resources: corev1.ResourceRequirements{
+						Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("128Mi")},
+					},
+					LivenessProbe: &corev1.Probe{
+						ProbeHandler: corev1.ProbeHandler{HTTPGet: &corev1.HTTPGetAction{
+							Path: "/healthz", Port: intstr.FromInt32(8080),
+						}},
+						TimeoutSeconds: 1, PeriodSeconds: 10, SuccessThreshold: 1, FailureThreshold: 3,
+					},
+					Env: []corev1.EnvVar{{Name: "PASSWORD", ValueFrom: &corev1.EnvVarSource{
+						SecretKeyRef: &corev1.SecretKeySelector{
+							LocalObjectReference: corev1.LocalObjectReference{Name: "credentials"},
+							Key:                  "password",
+						},
+					}}},
+				},
+				{Name: "sidecar", Image: "registry.example.com/sidecar:1"},
+			},
+		},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodRunning,
+			ContainerStatuses: []corev1.ContainerStatus{
+				{
+					Name:         "app",
+					RestartCount: 4,
+					State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{
+						Reason:  "CrashLoopBackOff",
+						Message: "back-off 1m20s restarting failed container=app",
+					}},
+					LastTerminationState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+						Reason: "OOMKilled", ExitCode: 137, FinishedAt: finishedAt,
+					}},
+				},
+				{
+					Name: "sidecar",
+					State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{
+						Reason:  "ImagePullBackOff",
+						Message: "Back-off pulling image",
+					}},
+				},
+			},
+		},
+	}
+
+	clientset := fake.NewClientset(
+		pod,
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "credentials", Namespace: "default"},
+			Data:       map[string][]byte{"username": []byte("admin")},
+		},
+		&corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: "data", Namespace: "default"},
+			Status:     corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimPending},
+		},
+		&corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "worker"},
+			Status: corev1.NodeStatus{Conditions: []corev1.NodeCondition{
+				{Type: corev1.NodeReady, Status: corev1.ConditionTrue},
+				{Type: corev1.NodeMemoryPressure, Status: corev1.ConditionTrue, Message: "kubelet has insufficient memory available"},
+				{Type: corev1.NodeDiskPressure, Status: corev1.ConditionFalse},
+			}},
+		},
+		&corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: "unhealthy", Namespace: "default"},
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "web", UID: "pod-uid"},
+			Type:           "Warning",
+			Reason:         "Unhealthy",
+			Message:        "Liveness probe failed: connection refused",
+			Count:          3,
+			LastTimestamp:  finishedAt,
+		},
+		&corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: "previous-pod", Namespace: "default"},
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "web", UID: "previous-uid"},
+			Reason:         "Started",
+		},
+	)
+
+	diagnosis, err := Diagnose(context.Background(), clientset, "default", "web", DiagnoseOptions{LogTailLines: 10})
+	require.NoError(t, err)
+
+	assert.False(t, diagnosis.Healthy)
+	assert.Equal(t, "CrashLoopBackOff: container app: container keeps crashing after start, see its previous logs and last termination", diagnosis.Verdict)
+
+	reasons := []string{}
+	for _, finding := range diagnosis.Findings {
+		reasons = append(reasons, finding.Severity+" "+finding.Reason)
+	}
+	assert.Equal(t, []string{
+		"error CrashLoopBackOff",
+		"error OOMKilled",
+		"error ImagePullBackOff",
+		"error MissingConfigMap",
+		"error PersistentVolumeClaimNotBound",
+		"error MissingSecretKey",
+		"warning ProbeFailed",
+		"warning NodeMemoryPressure",
+	}, reasons)
+
+	assert.Equal(t, []string{
+		"memory limit: 128Mi",
+		"last termination: OOMKilled, exit code 137 at 2024-12-01T19:00:08Z",
+		"restarted 4 times",
+	}, diagnosis.Findings[1].Evidence)
+	assert.Equal(t, "Secret credentials does not have key password", diagnosis.Findings[5].Message)
+	assert.Equal(t, []string{"env PASSWORD of container app"}, diagnosis.Findings[5].Evidence)
+
+	assert.Equal(t, []ContainerDiagnosis{
+		{
+			Name:            "app",
+			Image:           "app:1",
+			State:           "waiting: CrashLoopBackOff",
+			RestartCount:    4,
+			LastTermination: "OOMKilled, exit code 137 at 2024-12-01T19:00:08Z",
+			Probes:          []string{"liveness: http-get http://:8080/healthz delay=0s timeout=1s period=10s #success=1 #failure=3"},
+			PreviousLogs:    "fake logs",
+		},
+		{
+			Name:  "sidecar",
+			Image: "registry.example.com/sidecar:1",
+			State: "waiting: ImagePullBackOff",
+		},
+	}, diagnosis.Containers)
+
+	assert.Equal(t, &NodeDiagnosis{
+		Name:       "worker",
+		Ready:      true,
+		Conditions: []string{"MemoryPressure=True: kubelet has insufficient memory available"},
+	}, diagnosis.Node)
+	assert.Equal(t, []string{"2024-12-01T19:00:08Z Warning Unhealthy: Liveness probe failed: connection refused (x3)"}, diagnosis.Events)
+}
+
+func TestDiagnoseHealthyPod(t *testing.T) {
+	clientset := fake.NewClientset(
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "app", Image: "app:1"}}},
+			Status: corev1.PodStatus{
+				Phase: corev1.PodRunning,
+				ContainerStatuses: []corev1.ContainerStatus{{
+					Name:  "app",
+					Ready: true,
+					State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{
+						StartedAt: metav1.NewTime(time.Date(2024, 12, 1, 19, 0, 8, 0, time.UTC)),
+					}},
+				}},
+			},
+		},
+	)
+
+	diagnosis, err := Diagnose(context.Background(), clientset, "default", "web", DiagnoseOptions{})
+	require.NoError(t, err)
+	assert.True(t, diagnosis.Healthy)
+	assert.Equal(t, "no problems found", diagnosis.Verdict)
+	assert.Empty(t, diagnosis.Findings)
+	assert.Equal(t, "running since 2024-12-01T19:00:08Z", diagnosis.Containers[0].State)
+}
+
+func TestDiagnoseForbiddenReferences(t *testing.T) {
+	clientset := fake.NewClientset(
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Spec: corev1.PodSpec{
+				NodeName:         "worker",
+				ImagePullSecrets: []corev1.LocalObjectReference{{Name: "registry"}},
+				Containers: []corev1.Container{{
+					Name:  "app",
+					Image: "app:1",
+					EnvFrom: []corev1.EnvFromSource{{
+						ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "settings"}},
+					}},
+					Env: []corev1.EnvVar{{
+						Name: "TOKEN",
+						ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{
+							LocalObjectReference: corev1.LocalObjectReference{Name: "registry"}, Key: "token",
+						}},
+					}},
+				}},
+			},
+			Status: corev1.PodStatus{
+				Phase: corev1.PodRunning,
+				ContainerStatuses: []corev1.ContainerStatus{{
+					Name:  "app",
+					Ready: true,
+					State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{
+						StartedAt: metav1.NewTime(time.Date(2024, 12, 1, 19, 0, 8, 0, time.UTC)),
+					}},
+				}},
+			},
+		},
+	)
+	for _, resource := range []string{"secrets", "configmaps", "nodes"} {
+		clientset.PrependReactor("get", resource, func(action k8stesting.Action) (bool, runtime.Object, error) {
+			name := action.(k8stesting.GetAction).GetName()
+			return true, nil, apierrors.NewForbidden(schema.GroupResource{Resource: resource}, name, errors.New("no access"))
+		})
+	}
+
+	diagnosis, err := Diagnose(context.Background(), clientset, "default", "web", DiagnoseOptions{})
+	require.NoError(t, err)
+	assert.True(t, diagnosis.Healthy)
+	assert.Nil(t, diagnosis.Node)
+	assert.Equal(t, []Finding{
+		{
+			Severity: SeverityWarning,
+			Reason:   "Forbidden",
+			Message:  `unable to check ConfigMap settings: configmaps "settings" is forbidden: no access`,
+			Evidence: []string{"envFrom of container app"},
+		},
+		{
+			Severity: SeverityWarning,
+			Reason:   "Forbidden",
+			Message:  `unable to check Secret registry: secrets "registry" is forbidden: no access`,
+			Evidence: []string{"env TOKEN of container app"},
+		},
+		{
+			Severity: SeverityWarning,
+			Reason:   "Forbidden",
+			Message:  `unable to check node worker of the pod: nodes "worker" is forbidden: no access`,
+		},
+	}, diagnosis.Findings)
+}
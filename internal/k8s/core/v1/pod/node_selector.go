@@ -0,0 +1,84 @@
+package pod
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+)
+
+// NodeSelectorMatches returns true if any of terms of the selector matches
+// the node, the same way as scheduler does, so empty term matches nothing
+// and only metadata.name field can be matched
+func NodeSelectorMatches(selector *corev1.NodeSelector, node *corev1.Node) bool {
+	for _, term := range selector.NodeSelectorTerms {
+		if nodeSelectorTermMatches(term, node) {
+			return true
+		}
+	}
+	return false
+}
+
+func nodeSelectorTermMatches(term corev1.NodeSelectorTerm, node *corev1.Node) bool {
+	if len(term.MatchExpressions) == 0 && len(term.MatchFields) == 0 {
+		// empty term matches nothing
+		return false
+	}
+	for _, expression := range term.MatchExpressions {
+		if !nodeSelectorRequirementMatches(expression, labels.Set(node.Labels)) {
+			return false
+		}
+	}
+	for _, field := range term.MatchFields {
+		if field.Key != "metadata.name" || !nodeSelectorRequirementMatches(field, labels.Set{"metadata.name": node.Name}) {
+			return false
+		}
+	}
+	return true
+}
+
+var nodeSelectorOperators = map[corev1.NodeSelectorOperator]selection.Operator{
+	corev1.NodeSelectorOpIn:           selection.In,
+	corev1.NodeSelectorOpNotIn:        selection.NotIn,
+	corev1.NodeSelectorOpExists:       selection.Exists,
+	corev1.NodeSelectorOpDoesNotExist: selection.DoesNotExist,
+	corev1.NodeSelectorOpGt:           selection.GreaterThan,
+	corev1.NodeSelectorOpLt:           selection.LessThan,
+}
+
+func nodeSelectorRequirementMatches(requirement corev1.NodeSelectorRequirement, set labels.Set) bool {
+	operator, ok := nodeSelectorOperators[requirement.Operator]
+	if !ok {
+		return false
+	}
+	parsed, err := labels.NewRequirement(requirement.Key, operator, requirement.Values)
+	if err != nil {
+		return false
+	}
+	return parsed.Matches(set)
+}
+
+// FormatNodeSelectorTerms formats every term of node selector,
+// terms are ORed and expressions within them are ANDed
+func FormatNodeSelectorTerms(selector *corev1.NodeSelector) []string {
+	var terms []string
+	for _, term := range selector.NodeSelectorTerms {
+		var requirements []string
+		for _, expression := range append(append([]corev1.NodeSelectorRequirement{}, term.MatchExpressions...), term.MatchFields...) {
+			requirements = append(requirements, strings.TrimSpace(fmt.Sprintf("%s %s %s", expression.Key, expression.Operator, strings.Join(expression.Values, ","))))
+		}
+		terms = append(terms, strings.Join(requirements, " and "))
+	}
+	return terms
+}
+
+// FormatNodeSelector formats node selector as one expression
+func FormatNodeSelector(selector *corev1.NodeSelector) string {
+	var terms []string
+	for _, term := range FormatNodeSelectorTerms(selector) {
+		terms = append(terms, "("+term+")")
+	}
+	return strings.Join(terms, " or ")
+}
@@ -0,0 +1,57 @@
+package pod
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestNodeSelectorMatches(t *testing.T) {
+	node := newNode("node-a", "zone-a", map[string]string{"cores": "8"})
+	requirement := func(key string, operator corev1.NodeSelectorOperator, values ...string) corev1.NodeSelectorRequirement {
+		return corev1.NodeSelectorRequirement{Key: key, Operator: operator, Values: values}
+	}
+
+	cases := []struct {
+		name    string
+		terms   []corev1.NodeSelectorTerm
+		matches bool
+	}{
+		{name: "no terms", matches: false},
+		{name: "empty term", terms: []corev1.NodeSelectorTerm{{}}, matches: false},
+		{name: "any term matching", matches: true, terms: []corev1.NodeSelectorTerm{
+			{MatchExpressions: []corev1.NodeSelectorRequirement{requirement("topology.kubernetes.io/zone", corev1.NodeSelectorOpIn, "zone-b")}},
+			{MatchExpressions: []corev1.NodeSelectorRequirement{requirement("cores", corev1.NodeSelectorOpGt, "4")}},
+		}},
+		{name: "node name field", matches: true, terms: []corev1.NodeSelectorTerm{
+			{MatchFields: []corev1.NodeSelectorRequirement{requirement("metadata.name", corev1.NodeSelectorOpIn, "node-a")}},
+		}},
+		{name: "unsupported field", matches: false, terms: []corev1.NodeSelectorTerm{
+			{MatchFields: []corev1.NodeSelectorRequirement{requirement("metadata.uid", corev1.NodeSelectorOpDoesNotExist)}},
+		}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.matches, NodeSelectorMatches(&corev1.NodeSelector{NodeSelectorTerms: c.terms}, node))
+		})
+	}
+}
+
+func TestFormatNodeSelector(t *testing.T) {
+	selector := &corev1.NodeSelector{NodeSelectorTerms: []corev1.NodeSelectorTerm{
+		{
+			MatchExpressions: []corev1.NodeSelectorRequirement{
+				{Key: "topology.kubernetes.io/zone", Operator: corev1.NodeSelectorOpIn, Values: []string{"zone-a", "zone-b"}},
+				{Key: "gpu", Operator: corev1.NodeSelectorOpExists},
+			},
+		},
+		{
+			MatchFields: []corev1.NodeSelectorRequirement{
+				{Key: "metadata.name", Operator: corev1.NodeSelectorOpIn, Values: []string{"node-a"}},
+			},
+		},
+	}}
+	assert.Equal(t, []string{"topology.kubernetes.io/zone In zone-a,zone-b and gpu Exists", "metadata.name In node-a"}, FormatNodeSelectorTerms(selector))
+	assert.Equal(t, "(topology.kubernetes.io/zone In zone-a,zone-b and gpu Exists) or (metadata.name In node-a)", FormatNodeSelector(selector))
+}
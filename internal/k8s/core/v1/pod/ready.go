@@ -0,0 +1,14 @@
+package pod
+
+import corev1 "k8s.io/api/core/v1"
+
+// IsPodReady tells whether the pod has Ready condition, which means
+// that it passes readiness probes and can serve traffic
+func IsPodReady(p *corev1.Pod) bool {
+	for _, condition := range p.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
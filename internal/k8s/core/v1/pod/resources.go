@@ -0,0 +1,85 @@
+package pod
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// Requests returns effective resource requests of the pod used by
+// the scheduler, which is the bigger one of sum of containers together
+// with sidecars and each of init containers, plus pod overhead
+func Requests(pod *corev1.Pod) corev1.ResourceList {
+	return effectiveResources(pod, func(requirements corev1.ResourceRequirements) corev1.ResourceList {
+		return requirements.Requests
+	})
+}
+
+// Limits returns effective resource limits of the pod computed
+// the same way as requests, resources without limit are omitted
+func Limits(pod *corev1.Pod) corev1.ResourceList {
+	return effectiveResources(pod, func(requirements corev1.ResourceRequirements) corev1.ResourceList {
+		return requirements.Limits
+	})
+}
+
+func effectiveResources(pod *corev1.Pod, get func(corev1.ResourceRequirements) corev1.ResourceList) corev1.ResourceList {
+	result := corev1.ResourceList{}
+	for _, container := range pod.Spec.Containers {
+		AddResources(result, get(container.Resources))
+	}
+
+	// sidecars are init containers, which keep running together
+	// with regular containers, so they are added to them, while
+	// other init containers only need to fit with sidecars started before
+	sidecars := corev1.ResourceList{}
+	for _, container := range pod.Spec.InitContainers {
+		if container.RestartPolicy != nil && *container.RestartPolicy == corev1.ContainerRestartPolicyAlways {
+			AddResources(result, get(container.Resources))
+			AddResources(sidecars, get(container.Resources))
+			continue
+		}
+		initResources := sidecars.DeepCopy()
+		AddResources(initResources, get(container.Resources))
+		maxResources(result, initResources)
+	}
+
+	AddResources(result, pod.Spec.Overhead)
+	return result
+}
+
+// AddResources adds every quantity of resources to the same resource of target
+func AddResources(target corev1.ResourceList, resources corev1.ResourceList) {
+	for name, quantity := range resources {
+		if current, ok := target[name]; ok {
+			current.Add(quantity)
+			target[name] = current
+		} else {
+			target[name] = quantity.DeepCopy()
+		}
+	}
+}
+
+func maxResources(target corev1.ResourceList, resources corev1.ResourceList) {
+	for name, quantity := range resources {
+		if current, ok := target[name]; !ok || quantity.Cmp(current) > 0 {
+			target[name] = quantity.DeepCopy()
+		}
+	}
+}
+
+// QuantityString formats quantity of the resource, or returns
+// empty string if the resource is not in the list
+func QuantityString(resources corev1.ResourceList, name corev1.ResourceName) string {
+	if quantity, ok := resources[name]; ok {
+		return quantity.String()
+	}
+	return ""
+}
+
+// Fraction returns value as fraction of total, or zero if total is zero
+func Fraction(value, total resource.Quantity) float64 {
+	if total.IsZero() {
+		return 0
+	}
+	return value.AsApproximateFloat64() / total.AsApproximateFloat64()
+}
@@ -0,0 +1,625 @@
+package pod
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/kubernetes"
+)
+
+// SchedulingExplanation tells for every node whether the pod fits
+// there and if not, which scheduling constraints reject the node
+type SchedulingExplanation struct {
+	Pod       string `json:"pod"`
+	Namespace string `json:"namespace"`
+	Phase     string `json:"phase"`
+	// NodeName is set when the pod is already scheduled
+	NodeName string `json:"nodeName,omitempty"`
+	// SchedulerMessage is the latest message of the scheduler from pod conditions
+	SchedulerMessage string            `json:"schedulerMessage,omitempty"`
+	Summary          string            `json:"summary"`
+	Requests         map[string]string `json:"requests,omitempty"`
+	Nodes            []NodeFit         `json:"nodes"`
+}
+
+// NodeFit lists reasons why the pod cannot be scheduled to the node
+type NodeFit struct {
+	Node    string   `json:"node"`
+	Fits    bool     `json:"fits"`
+	Reasons []string `json:"reasons,omitempty"`
+}
+
+// rejection is a reason to reject the node, where category is
+// used to summarize reasons similarly to the scheduler
+type rejection struct {
+	category string
+	detail   string
+}
+
+type schedulingState struct {
+	pod         *corev1.Pod
+	nodes       []corev1.Node
+	nodesByName map[string]*corev1.Node
+	// pods are all pods assigned to nodes, which are not terminated
+	pods       []corev1.Pod
+	namespaces []corev1.Namespace
+	requests   corev1.ResourceList
+	// claims are persistent volume claims of the pod with their volumes
+	claims []claimState
+}
+
+type claimState struct {
+	name         string
+	claim        *corev1.PersistentVolumeClaim
+	volume       *corev1.PersistentVolume
+	storageClass *storagev1.StorageClass
+}
+
+// ExplainScheduling evaluates every node against scheduling
+// constraints of the pod and explains why nodes are rejected
+func ExplainScheduling(ctx context.Context, clientset kubernetes.Interface, namespace string, name string) (*SchedulingExplanation, error) {
+	pod, err := clientset.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := loadSchedulingState(ctx, clientset, pod)
+	if err != nil {
+		return nil, err
+	}
+
+	explanation := &SchedulingExplanation{
+		Pod:       pod.Name,
+		Namespace: pod.Namespace,
+		Phase:     string(pod.Status.Phase),
+		NodeName:  pod.Spec.NodeName,
+		Requests:  map[string]string{},
+		Nodes:     []NodeFit{},
+	}
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodScheduled && condition.Status != corev1.ConditionTrue {
+			explanation.SchedulerMessage = condition.Message
+		}
+	}
+	for resourceName, quantity := range state.requests {
+		explanation.Requests[string(resourceName)] = quantity.String()
+	}
+
+	categories := map[string]int{}
+	available := 0
+	for i := range state.nodes {
+		node := &state.nodes[i]
+		rejections := state.evaluate(node)
+
+		fit := NodeFit{Node: node.Name, Fits: len(rejections) == 0}
+		nodeCategories := sets.New[string]()
+		for _, rejection := range rejections {
+			fit.Reasons = append(fit.Reasons, rejection.detail)
+			nodeCategories.Insert(rejection.category)
+		}
+		for category := range nodeCategories {
+			categories[category]++
+		}
+		if fit.Fits {
+			available++
+		}
+		explanation.Nodes = append(explanation.Nodes, fit)
+	}
+
+	explanation.Summary = fmt.Sprintf("%d/%d nodes are available", available, len(state.nodes))
+	if len(categories) > 0 {
+		var parts []string
+		for category, count := range categories {
+			parts = append(parts, fmt.Sprintf("%d %s", count, category))
+		}
+		sort.Strings(parts)
+		explanation.Summary += ": " + strings.Join(parts, ", ")
+	}
+	explanation.Summary += "."
+
+	return explanation, nil
+}
+
+func loadSchedulingState(ctx context.Context, clientset kubernetes.Interface, pod *corev1.Pod) (*schedulingState, error) {
+	nodes, err := clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	pods, err := clientset.CoreV1().Pods(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	state := &schedulingState{
+		pod:         pod,
+		nodes:       nodes.Items,
+		nodesByName: map[string]*corev1.Node{},
+		requests:    Requests(pod),
+	}
+	sort.Slice(state.nodes, func(i, j int) bool {
+		return state.nodes[i].Name < state.nodes[j].Name
+	})
+	for i := range state.nodes {
+		state.nodesByName[state.nodes[i].Name] = &state.nodes[i]
+	}
+	for _, other := range pods.Items {
+		if other.Spec.NodeName == "" || other.UID == pod.UID ||
+			other.Status.Phase == corev1.PodSucceeded || other.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		state.pods = append(state.pods, other)
+	}
+
+	if usesNamespaceSelector(pod) {
+		namespaces, err := clientset.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		state.namespaces = namespaces.Items
+	}
+
+	for _, volume := range pod.Spec.Volumes {
+		if volume.PersistentVolumeClaim == nil {
+			continue
+		}
+		claimName := volume.PersistentVolumeClaim.ClaimName
+		claim := claimState{name: claimName}
+		pvc, err := clientset.CoreV1().PersistentVolumeClaims(pod.Namespace).Get(ctx, claimName, metav1.GetOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return nil, err
+		}
+		if err == nil {
+			claim.claim = pvc
+			if pvc.Spec.VolumeName != "" {
+				pv, err := clientset.CoreV1().PersistentVolumes().Get(ctx, pvc.Spec.VolumeName, metav1.GetOptions{})
+				if err != nil && !apierrors.IsNotFound(err) {
+					return nil, err
+				}
+				if err == nil {
+					claim.volume = pv
+				}
+			} else if pvc.Spec.StorageClassName != nil && *pvc.Spec.StorageClassName != "" {
+				storageClass, err := clientset.StorageV1().StorageClasses().Get(ctx, *pvc.Spec.StorageClassName, metav1.GetOptions{})
+				if err != nil && !apierrors.IsNotFound(err) {
+					return nil, err
+				}
+				if err == nil {
+					claim.storageClass = storageClass
+				}
+			}
+		}
+		state.claims = append(state.claims, claim)
+	}
+
+	return state, nil
+}
+
+func usesNamespaceSelector(pod *corev1.Pod) bool {
+	affinity := pod.Spec.Affinity
+	if affinity == nil {
+		return false
+	}
+	var terms []corev1.PodAffinityTerm
+	if affinity.PodAffinity != nil {
+		terms = append(terms, affinity.PodAffinity.RequiredDuringSchedulingIgnoredDuringExecution...)
+	}
+	if affinity.PodAntiAffinity != nil {
+		terms = append(terms, affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution...)
+	}
+	for _, term := range terms {
+		if term.NamespaceSelector != nil {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *schedulingState) evaluate(node *corev1.Node) []rejection {
+	var rejections []rejection
+	rejections = append(rejections, s.checkUnschedulable(node)...)
+	rejections = append(rejections, s.checkNodeAffinity(node)...)
+	rejections = append(rejections, s.checkTaints(node)...)
+	rejections = append(rejections, s.checkPodAffinity(node)...)
+	rejections = append(rejections, s.checkTopologySpread(node)...)
+	rejections = append(rejections, s.checkResources(node)...)
+	rejections = append(rejections, s.checkVolumes(node)...)
+	return rejections
+}
+
+func (s *schedulingState) checkUnschedulable(node *corev1.Node) []rejection {
+	if !node.Spec.Unschedulable {
+		return nil
+	}
+	taint := &corev1.Taint{Key: corev1.TaintNodeUnschedulable, Effect: corev1.TaintEffectNoSchedule}
+	if tolerates(s.pod.Spec.Tolerations, taint) {
+		return nil
+	}
+	return []rejection{{category: "node(s) were unschedulable", detail: "node is cordoned"}}
+}
+
+func (s *schedulingState) checkNodeAffinity(node *corev1.Node) []rejection {
+	const category = "node(s) didn't match Pod's node affinity/selector"
+	var rejections []rejection
+
+	var keys []string
+	for key := range s.pod.Spec.NodeSelector {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		expected := s.pod.Spec.NodeSelector[key]
+		if actual, ok := node.Labels[key]; !ok || actual != expected {
+			detail := fmt.Sprintf("nodeSelector %s=%s does not match, node has no such label", key, expected)
+			if ok {
+				detail = fmt.Sprintf("nodeSelector %s=%s does not match, node has %s=%s", key, expected, key, actual)
+			}
+			rejections = append(rejections, rejection{category: category, detail: detail})
+		}
+	}
+
+	if selector := requiredNodeAffinity(s.pod); selector != nil && !NodeSelectorMatches(selector, node) {
+		rejections = append(rejections, rejection{
+			category: category,
+			detail:   fmt.Sprintf("required node affinity does not match: %s", FormatNodeSelector(selector)),
+		})
+	}
+	return rejections
+}
+
+func requiredNodeAffinity(pod *corev1.Pod) *corev1.NodeSelector {
+	if pod.Spec.Affinity == nil || pod.Spec.Affinity.NodeAffinity == nil {
+		return nil
+	}
+	return pod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution
+}
+
+func (s *schedulingState) checkTaints(node *corev1.Node) []rejection {
+	var rejections []rejection
+	for i := range node.Spec.Taints {
+		taint := &node.Spec.Taints[i]
+		if taint.Effect == corev1.TaintEffectPreferNoSchedule || tolerates(s.pod.Spec.Tolerations, taint) {
+			continue
+		}
+		rejections = append(rejections, rejection{
+			category: fmt.Sprintf("node(s) had untolerated taint {%s: %s}", taint.Key, taint.Value),
+			detail:   fmt.Sprintf("taint %s is not tolerated", taint.ToString()),
+		})
+	}
+	return rejections
+}
+
+func tolerates(tolerations []corev1.Toleration, taint *corev1.Taint) bool {
+	for i := range tolerations {
+		if tolerations[i].ToleratesTaint(taint) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *schedulingState) checkPodAffinity(node *corev1.Node) []rejection {
+	var rejections []rejection
+	affinity := s.pod.Spec.Affinity
+
+	if affinity != nil && affinity.PodAffinity != nil {
+		for _, term := range affinity.PodAffinity.RequiredDuringSchedulingIgnoredDuringExecution {
+			if s.termSatisfiedOn(term, node) {
+				continue
+			}
+			// the first pod of a group is allowed to be scheduled
+			// anywhere, if it matches its own affinity term
+			if !s.anyPodMatches(term, s.pod.Namespace) && s.podMatchesTerm(s.pod, term, s.pod.Namespace) {
+				continue
+			}
+			rejections = append(rejections, rejection{
+				category: "node(s) didn't match pod affinity rules",
+				detail:   fmt.Sprintf("no pod matching %s in the same %s domain", formatLabelSelector(term.LabelSelector), term.TopologyKey),
+			})
+		}
+	}
+
+	if affinity != nil && affinity.PodAntiAffinity != nil {
+		for _, term := range affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution {
+			if matching := s.podsInDomain(term, s.pod.Namespace, node); len(matching) > 0 {
+				rejections = append(rejections, rejection{
+					category: "node(s) didn't match pod anti-affinity rules",
+					detail: fmt.Sprintf("pod %s matches anti-affinity %s in the same %s domain",
+						matching[0], formatLabelSelector(term.LabelSelector), term.TopologyKey),
+				})
+			}
+		}
+	}
+
+	// anti-affinity of existing pods applies to the incoming pod as well
+	for _, other := range s.pods {
+		if other.Spec.Affinity == nil || other.Spec.Affinity.PodAntiAffinity == nil {
+			continue
+		}
+		otherNode := s.nodesByName[other.Spec.NodeName]
+		if otherNode == nil {
+			continue
+		}
+		for _, term := range other.Spec.Affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution {
+			value, hasTopology := node.Labels[term.TopologyKey]
+			if !hasTopology || otherNode.Labels[term.TopologyKey] != value {
+				continue
+			}
+			if s.podMatchesTerm(s.pod, term, other.Namespace) {
+				rejections = append(rejections, rejection{
+					category: "node(s) didn't satisfy existing pods anti-affinity rules",
+					detail:   fmt.Sprintf("anti-affinity of existing pod %s/%s rejects this pod in the same %s domain", other.Namespace, other.Name, term.TopologyKey),
+				})
+			}
+		}
+	}
+
+	return rejections
+}
+
+func (s *schedulingState) termSatisfiedOn(term corev1.PodAffinityTerm, node *corev1.Node) bool {
+	return len(s.podsInDomain(term, s.pod.Namespace, node)) > 0
+}
+
+// podsInDomain returns pods matching the term located in the same
+// topology domain as the node, which are names in namespace/name format
+func (s *schedulingState) podsInDomain(term corev1.PodAffinityTerm, ownerNamespace string, node *corev1.Node) []string {
+	value, ok := node.Labels[term.TopologyKey]
+	if !ok {
+		return nil
+	}
+	var matching []string
+	for i := range s.pods {
+		other := &s.pods[i]
+		otherNode := s.nodesByName[other.Spec.NodeName]
+		if otherNode == nil || otherNode.Labels[term.TopologyKey] != value {
+			continue
+		}
+		if s.podMatchesTerm(other, term, ownerNamespace) {
+			matching = append(matching, other.Namespace+"/"+other.Name)
+		}
+	}
+	return matching
+}
+
+func (s *schedulingState) anyPodMatches(term corev1.PodAffinityTerm, ownerNamespace string) bool {
+	for i := range s.pods {
+		if s.podMatchesTerm(&s.pods[i], term, ownerNamespace) {
+			return true
+		}
+	}
+	return false
+}
+
+// podMatchesTerm checks whether the pod is selected by affinity term
+// defined by the pod in ownerNamespace
+func (s *schedulingState) podMatchesTerm(pod *corev1.Pod, term corev1.PodAffinityTerm, ownerNamespace string) bool {
+	if !s.termNamespaces(term, ownerNamespace).Has(pod.Namespace) {
+		return false
+	}
+	selector, err := metav1.LabelSelectorAsSelector(term.LabelSelector)
+	if err != nil || term.LabelSelector == nil {
+		return false
+	}
+	return selector.Matches(labels.Set(pod.Labels))
+}
+
+func (s *schedulingState) termNamespaces(term corev1.PodAffinityTerm, ownerNamespace string) sets.Set[string] {
+	namespaces := sets.New(term.Namespaces...)
+	if term.NamespaceSelector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(term.NamespaceSelector)
+		if err == nil {
+			for _, namespace := range s.namespaces {
+				if selector.Matches(labels.Set(namespace.Labels)) {
+					namespaces.Insert(namespace.Name)
+				}
+			}
+		}
+	} else if len(term.Namespaces) == 0 {
+		namespaces.Insert(ownerNamespace)
+	}
+	return namespaces
+}
+
+func formatLabelSelector(selector *metav1.LabelSelector) string {
+	if selector == nil {
+		return "<none>"
+	}
+	return metav1.FormatLabelSelector(selector)
+}
+
+func (s *schedulingState) checkTopologySpread(node *corev1.Node) []rejection {
+	var rejections []rejection
+	for _, constraint := range s.pod.Spec.TopologySpreadConstraints {
+		if constraint.WhenUnsatisfiable != corev1.DoNotSchedule {
+			continue
+		}
+		value, ok := node.Labels[constraint.TopologyKey]
+		if !ok {
+			rejections = append(rejections, rejection{
+				category: "node(s) didn't match pod topology spread constraints (missing required label)",
+				detail:   fmt.Sprintf("node does not have label %s of topology spread constraint", constraint.TopologyKey),
+			})
+			continue
+		}
+
+		selector, err := metav1.LabelSelectorAsSelector(constraint.LabelSelector)
+		if err != nil || constraint.LabelSelector == nil {
+			continue
+		}
+
+		// domains are counted only among nodes, where the pod could be
+		// placed by its node affinity, as the scheduler does by default
+		counts := map[string]int{}
+		for i := range s.nodes {
+			candidate := &s.nodes[i]
+			domain, ok := candidate.Labels[constraint.TopologyKey]
+			if !ok || len(s.checkNodeAffinity(candidate)) > 0 {
+				continue
+			}
+			if _, ok := counts[domain]; !ok {
+				counts[domain] = 0
+			}
+		}
+		for _, other := range s.pods {
+			otherNode := s.nodesByName[other.Spec.NodeName]
+			if otherNode == nil || other.Namespace != s.pod.Namespace || !selector.Matches(labels.Set(other.Labels)) {
+				continue
+			}
+			if domain, ok := otherNode.Labels[constraint.TopologyKey]; ok {
+				if _, counted := counts[domain]; counted {
+					counts[domain]++
+				}
+			}
+		}
+
+		minCount := -1
+		for _, count := range counts {
+			if minCount == -1 || count < minCount {
+				minCount = count
+			}
+		}
+		if minCount == -1 {
+			minCount = 0
+		}
+		selfMatch := 0
+		if selector.Matches(labels.Set(s.pod.Labels)) {
+			selfMatch = 1
+		}
+		skew := counts[value] + selfMatch - minCount
+		if skew > int(constraint.MaxSkew) {
+			rejections = append(rejections, rejection{
+				category: "node(s) didn't match pod topology spread constraints",
+				detail: fmt.Sprintf("placing pod into %s=%s would make skew %d, which is more than maxSkew %d (%d matching pods there, minimum is %d)",
+					constraint.TopologyKey, value, skew, constraint.MaxSkew, counts[value], minCount),
+			})
+		}
+	}
+	return rejections
+}
+
+func (s *schedulingState) checkResources(node *corev1.Node) []rejection {
+	requested := corev1.ResourceList{}
+	podCount := 0
+	for i := range s.pods {
+		if s.pods[i].Spec.NodeName != node.Name {
+			continue
+		}
+		podCount++
+		AddResources(requested, Requests(&s.pods[i]))
+	}
+
+	var rejections []rejection
+	if allocatablePods, ok := node.Status.Allocatable[corev1.ResourcePods]; ok && int64(podCount+1) > allocatablePods.Value() {
+		rejections = append(rejections, rejection{
+			category: "Too many pods",
+			detail:   fmt.Sprintf("node already runs %d pods of %d allowed", podCount, allocatablePods.Value()),
+		})
+	}
+
+	var names []string
+	for name := range s.requests {
+		names = append(names, string(name))
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		resourceName := corev1.ResourceName(name)
+		request := s.requests[resourceName]
+		if request.IsZero() {
+			continue
+		}
+		allocatable := node.Status.Allocatable[resourceName]
+		free := allocatable.DeepCopy()
+		used := requested[resourceName]
+		free.Sub(used)
+		if request.Cmp(free) > 0 {
+			if free.Sign() < 0 {
+				free = resource.Quantity{}
+			}
+			rejections = append(rejections, rejection{
+				category: fmt.Sprintf("Insufficient %s", name),
+				detail: fmt.Sprintf("insufficient %s: requested %s, free %s (allocatable %s, already requested %s)",
+					name, request.String(), free.String(), allocatable.String(), used.String()),
+			})
+		}
+	}
+	return rejections
+}
+
+func (s *schedulingState) checkVolumes(node *corev1.Node) []rejection {
+	var rejections []rejection
+	for _, claim := range s.claims {
+		switch {
+		case claim.claim == nil:
+			rejections = append(rejections, rejection{
+				category: fmt.Sprintf("persistentvolumeclaim %q not found", claim.name),
+				detail:   fmt.Sprintf("PersistentVolumeClaim %s does not exist", claim.name),
+			})
+		case claim.volume != nil:
+			affinity := claim.volume.Spec.NodeAffinity
+			if affinity != nil && affinity.Required != nil && !NodeSelectorMatches(affinity.Required, node) {
+				rejections = append(rejections, rejection{
+					category: "node(s) had volume node affinity conflict",
+					detail: fmt.Sprintf("PersistentVolume %s of claim %s requires nodes matching %s",
+						claim.volume.Name, claim.name, FormatNodeSelector(affinity.Required)),
+				})
+			}
+		case claim.claim.Spec.VolumeName != "":
+			rejections = append(rejections, rejection{
+				category: "node(s) had volume node affinity conflict",
+				detail:   fmt.Sprintf("PersistentVolume %s of claim %s does not exist", claim.claim.Spec.VolumeName, claim.name),
+			})
+		case claim.storageClass == nil && claim.claim.Spec.StorageClassName != nil && *claim.claim.Spec.StorageClassName != "":
+			rejections = append(rejections, rejection{
+				category: "pod has unbound PersistentVolumeClaims",
+				detail:   fmt.Sprintf("StorageClass %s of claim %s does not exist", *claim.claim.Spec.StorageClassName, claim.name),
+			})
+		case claim.storageClass == nil ||
+			claim.storageClass.VolumeBindingMode == nil ||
+			*claim.storageClass.VolumeBindingMode == storagev1.VolumeBindingImmediate:
+			rejections = append(rejections, rejection{
+				category: "pod has unbound immediate PersistentVolumeClaims",
+				detail:   fmt.Sprintf("PersistentVolumeClaim %s is not bound yet and is not bound on scheduling", claim.name),
+			})
+		default:
+			if !topologiesAllow(claim.storageClass.AllowedTopologies, node) {
+				rejections = append(rejections, rejection{
+					category: "node(s) didn't find available persistent volumes to bind",
+					detail: fmt.Sprintf("StorageClass %s of claim %s does not allow provisioning volumes in topology of the node",
+						claim.storageClass.Name, claim.name),
+				})
+			}
+		}
+	}
+	return rejections
+}
+
+// topologiesAllow checks whether the node is in one of allowed topologies,
+// no topologies allow any node
+func topologiesAllow(topologies []corev1.TopologySelectorTerm, node *corev1.Node) bool {
+	if len(topologies) == 0 {
+		return true
+	}
+	for _, topology := range topologies {
+		matches := true
+		for _, expression := range topology.MatchLabelExpressions {
+			value, ok := node.Labels[expression.Key]
+			if !ok || !sets.New(expression.Values...).Has(value) {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,197 @@
+package pod
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/strowk/mcp-k8s-go/internal/utils"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func newNode(name string, zone string, labels map[string]string) *corev1.Node {
+	nodeLabels := map[string]string{"topology.kubernetes.io/zone": zone}
+	for key, value := range labels {
+		nodeLabels[key] = value
+	}
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: nodeLabels},
+		Status: corev1.NodeStatus{Allocatable: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("2"),
+			corev1.ResourceMemory: resource.MustParse("4Gi"),
+			corev1.ResourcePods:   resource.MustParse("110"),
+		}},
+	}
+}
+
+func newRunningPod(name string, node string, labels map[string]string, cpu string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", UID: types.UID("uid-" + name), Labels: labels},
+		Spec: corev1.PodSpec{
+			NodeName: node,
+			Containers: []corev1.Container{{Name: "app", Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(cpu)},
+			}}},
+		},
+		Status: corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+}
+
+func TestExplainScheduling(t *testing.T) {
+	cordoned := newNode("a-cordoned", "zone-a", map[string]string{"disk": "ssd"})
+	cordoned.Spec.Unschedulable = true
+	tainted := newNode("b-tainted", "zone-a", map[string]string{"disk": "ssd"})
+	tainted.Spec.Taints = []corev1.Taint{{Key: "dedicated", Value: "gpu", Effect: corev1.TaintEffectNoSchedule}}
+	full := newNode("c-full", "zone-b", map[string]string{"disk": "ssd"})
+	hdd := newNode("d-hdd", "zone-b", map[string]string{"disk": "hdd"})
+	fits := newNode("e-fits", "zone-c", map[string]string{"disk": "ssd"})
+	wrongZone := newNode("f-other-zone", "zone-d", map[string]string{"disk": "ssd"})
+
+	pending := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default", UID: "web-uid", Labels: map[string]string{"app": "web"}},
+		Spec: corev1.PodSpec{
+			NodeSelector: map[string]string{"disk": "ssd"},
+			Affinity: &corev1.Affinity{
+				NodeAffinity: &corev1.NodeAffinity{RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+					NodeSelectorTerms: []corev1.NodeSelectorTerm{{MatchExpressions: []corev1.NodeSelectorRequirement{{
+						Key: "topology.kubernetes.io/zone", Operator: corev1.NodeSelectorOpIn, Values: []string{"zone-a", "zone-b", "zone-c"},
+					}}}},
+				}},
+				PodAntiAffinity: &corev1.PodAntiAffinity{RequiredDuringSchedulingIgnoredDuringExecution: []corev1.PodAffinityTerm{{
+					LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}},
+					TopologyKey:   "kubernetes.io/hostname",
+				}}},
+			},
+			Containers: []corev1.Container{{Name: "app", Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("500m")},
+			}}},
+			InitContainers: []corev1.Container{{Name: "init", Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m")},
+			}}},
+		},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodPending,
+			Conditions: []corev1.PodCondition{{
+				Type: corev1.PodScheduled, Status: corev1.ConditionFalse, Reason: "Unschedulable",
+				Message: "0/6 nodes are available",
+			}},
+		},
+	}
+	for _, node := range []*corev1.Node{cordoned, tainted, full, hdd, fits, wrongZone} {
+		node.Labels["kubernetes.io/hostname"] = node.Name
+	}
+
+	db := newRunningPod("db", "d-hdd", map[string]string{"app": "db"}, "100m")
+	clientset := fake.NewClientset(
+		cordoned, tainted, full, hdd, fits, wrongZone,
+		pending,
+		newRunningPod("heavy", "c-full", nil, "1800m"),
+		db,
+	)
+
+	explanation, err := ExplainScheduling(context.Background(), clientset, "default", "web")
+	require.NoError(t, err)
+
+	assert.Equal(t, "0/6 nodes are available", explanation.SchedulerMessage)
+	assert.Equal(t, map[string]string{"cpu": "500m"}, explanation.Requests)
+	assert.Equal(t, "1/6 nodes are available: "+
+		"1 Insufficient cpu, "+
+		"1 node(s) didn't match pod anti-affinity rules, "+
+		"1 node(s) had untolerated taint {dedicated: gpu}, "+
+		"1 node(s) were unschedulable, "+
+		"2 node(s) didn't match Pod's node affinity/selector.", explanation.Summary)
+
+	assert.Equal(t, []NodeFit{
+		{Node: "a-cordoned", Reasons: []string{"node is cordoned"}},
+		{Node: "b-tainted", Reasons: []string{"taint dedicated=gpu:NoSchedule is not tolerated"}},
+		{Node: "c-full", Reasons: []string{"insufficient cpu: requested 500m, free 200m (allocatable 2, already requested 1800m)"}},
+		{Node: "d-hdd", Reasons: []string{
+			"nodeSelector disk=ssd does not match, node has disk=hdd",
+			"pod default/db matches anti-affinity app=db in the same kubernetes.io/hostname domain",
+		}},
+		{Node: "e-fits", Fits: true},
+		{Node: "f-other-zone", Reasons: []string{
+			"required node affinity does not match: (topology.kubernetes.io/zone In zone-a,zone-b,zone-c)",
+		}},
+	}, explanation.Nodes)
+}
+
+func TestExplainSchedulingSpreadAndVolumes(t *testing.T) {
+	zoneA := newNode("a", "zone-a", nil)
+	zoneB := newNode("b", "zone-b", nil)
+	noZone := newNode("c", "", nil)
+	delete(noZone.Labels, "topology.kubernetes.io/zone")
+
+	pending := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default", UID: "web-uid", Labels: map[string]string{"app": "web"}},
+		Spec: corev1.PodSpec{
+			TopologySpreadConstraints: []corev1.TopologySpreadConstraint{{
+				MaxSkew:           1,
+				TopologyKey:       "topology.kubernetes.io/zone",
+				WhenUnsatisfiable: corev1.DoNotSchedule,
+				LabelSelector:     &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+			}},
+			Volumes: []corev1.Volume{
+				{Name: "bound", VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "bound"}}},
+				{Name: "delayed", VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "delayed"}}},
+			},
+		},
+		Status: corev1.PodStatus{Phase: corev1.PodPending},
+	}
+
+	clientset := fake.NewClientset(
+		zoneA, zoneB, noZone, pending,
+		newRunningPod("web-1", "a", map[string]string{"app": "web"}, "0"),
+		&corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: "bound", Namespace: "default"},
+			Spec:       corev1.PersistentVolumeClaimSpec{VolumeName: "pv-a"},
+		},
+		&corev1.PersistentVolume{
+			ObjectMeta: metav1.ObjectMeta{Name: "pv-a"},
+			Spec: corev1.PersistentVolumeSpec{NodeAffinity: &corev1.VolumeNodeAffinity{Required: &corev1.NodeSelector{
+				NodeSelectorTerms: []corev1.NodeSelectorTerm{{MatchExpressions: []corev1.NodeSelectorRequirement{{
+					Key: "topology.kubernetes.io/zone", Operator: corev1.NodeSelectorOpIn, Values: []string{"zone-a"},
+				}}}},
+			}}},
+		},
+		&corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: "delayed", Namespace: "default"},
+			Spec:       corev1.PersistentVolumeClaimSpec{StorageClassName: utils.Ptr("zonal")},
+		},
+		&storagev1.StorageClass{
+			ObjectMeta:        metav1.ObjectMeta{Name: "zonal"},
+			VolumeBindingMode: utils.Ptr(storagev1.VolumeBindingWaitForFirstConsumer),
+			AllowedTopologies: []corev1.TopologySelectorTerm{{MatchLabelExpressions: []corev1.TopologySelectorLabelRequirement{{
+				Key: "topology.kubernetes.io/zone", Values: []string{"zone-a", "zone-b"},
+			}}}},
+		},
+	)
+
+	explanation, err := ExplainScheduling(context.Background(), clientset, "default", "web")
+	require.NoError(t, err)
+
+	assert.Equal(t, []NodeFit{
+		{Node: "a", Reasons: []string{
+			"placing pod into topology.kubernetes.io/zone=zone-a would make skew 2, which is more than maxSkew 1 (1 matching pods there, minimum is 0)",
+		}},
+		{Node: "b", Reasons: []string{
+			"PersistentVolume pv-a of claim bound requires nodes matching (topology.kubernetes.io/zone In zone-a)",
+		}},
+		{Node: "c", Reasons: []string{
+			"node does not have label topology.kubernetes.io/zone of topology spread constraint",
+			"PersistentVolume pv-a of claim bound requires nodes matching (topology.kubernetes.io/zone In zone-a)",
+			"StorageClass zonal of claim delayed does not allow provisioning volumes in topology of the node",
+		}},
+	}, explanation.Nodes)
+	assert.Equal(t, "0/3 nodes are available: "+
+		"1 node(s) didn't find available persistent volumes to bind, "+
+		"1 node(s) didn't match pod topology spread constraints, "+
+		"1 node(s) didn't match pod topology spread constraints (missing required label), "+
+		"2 node(s) had volume node affinity conflict.", explanation.Summary)
+}
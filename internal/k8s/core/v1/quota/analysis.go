@@ -0,0 +1,549 @@
+package quota
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/strowk/mcp-k8s-go/internal/k8s/apps/v1/deployment"
+	"github.com/strowk/mcp-k8s-go/internal/k8s/core/v1/namespace"
+	"github.com/strowk/mcp-k8s-go/internal/k8s/core/v1/pod"
+	"github.com/strowk/mcp-k8s-go/internal/utils"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/kubernetes"
+)
+
+type AnalysisOptions struct {
+	Namespace string
+	// Manifest of Pod or workload in YAML or JSON to project into quotas
+	Manifest string
+	// Kind and Name of existing workload to project scaling it to Replicas
+	Kind     string
+	Name     string
+	Replicas int32
+}
+
+// Analysis shows usage of resource quotas and limit ranges of the namespace
+// and optionally projects whether pods of a manifest or scale-up would fit
+type Analysis struct {
+	Namespace   string                 `json:"namespace"`
+	Quotas      []namespace.QuotaUsage `json:"quotas"`
+	LimitRanges []namespace.LimitRange `json:"limitRanges"`
+	Projection  *Projection            `json:"projection,omitempty"`
+}
+
+// Projection describes pods, which would be created, with requests
+// and limits after LimitRange defaults and checks them against quotas,
+// when pod template of existing workload changes, SurgePods are created
+// above replicas during rollout and ReplacedPods are recreated with
+// the new template, which adds difference of their usage
+type Projection struct {
+	Object               string            `json:"object"`
+	NewPods              int32             `json:"newPods"`
+	SurgePods            int32             `json:"surgePods,omitempty"`
+	ReplacedPods         int32             `json:"replacedPods,omitempty"`
+	PodRequests          map[string]string `json:"podRequests,omitempty"`
+	PodLimits            map[string]string `json:"podLimits,omitempty"`
+	InjectedDefaults     []InjectedDefault `json:"injectedDefaults,omitempty"`
+	LimitRangeViolations []string          `json:"limitRangeViolations,omitempty"`
+	Checks               []Check           `json:"checks,omitempty"`
+	Fits                 bool              `json:"fits"`
+	// Problems explain why pods would be rejected
+	Problems []string `json:"problems,omitempty"`
+}
+
+// Check compares what is left in resource quota with what new pods need,
+// Remaining is what would be left after creating them and is negative when they do not fit
+type Check struct {
+	Quota     string `json:"quota"`
+	Resource  string `json:"resource"`
+	Hard      string `json:"hard"`
+	Used      string `json:"used"`
+	Needed    string `json:"needed"`
+	Remaining string `json:"remaining"`
+	Fits      bool   `json:"fits"`
+}
+
+// projected is a pod template, which would be created number of times,
+// live is template of existing workload, which pods are replaced
+// by the new template and surge is how many pods above replicas
+// could be created while replacing them
+type projected struct {
+	object   string
+	spec     corev1.PodSpec
+	newPods  int32
+	live     *corev1.PodSpec
+	replaced int32
+	surge    int32
+}
+
+// Analyze reads resource quotas and limit ranges of the namespace and
+// projects manifest or scale-up from options into them when it is set
+func Analyze(ctx context.Context, clientset kubernetes.Interface, options AnalysisOptions) (*Analysis, error) {
+	if options.Manifest != "" && options.Kind != "" {
+		return nil, fmt.Errorf("only one of manifest or kind and name to scale can be set")
+	}
+
+	var target *projected
+	var err error
+	if options.Manifest != "" {
+		target, err = fromManifest(ctx, clientset, options.Namespace, options.Manifest)
+	} else if options.Kind != "" {
+		target, err = fromScale(ctx, clientset, options.Namespace, options.Kind, options.Name, options.Replicas)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	quotas, err := clientset.CoreV1().ResourceQuotas(options.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(quotas.Items, func(i, j int) bool {
+		return quotas.Items[i].Name < quotas.Items[j].Name
+	})
+	limitRanges, err := clientset.CoreV1().LimitRanges(options.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(limitRanges.Items, func(i, j int) bool {
+		return limitRanges.Items[i].Name < limitRanges.Items[j].Name
+	})
+
+	analysis := &Analysis{
+		Namespace:   options.Namespace,
+		Quotas:      []namespace.QuotaUsage{},
+		LimitRanges: []namespace.LimitRange{},
+	}
+	for i := range quotas.Items {
+		analysis.Quotas = append(analysis.Quotas, namespace.NewQuotaUsage(&quotas.Items[i]))
+	}
+	for i := range limitRanges.Items {
+		analysis.LimitRanges = append(analysis.LimitRanges, namespace.NewLimitRange(&limitRanges.Items[i]))
+	}
+
+	if target != nil {
+		analysis.Projection = project(target, quotas.Items, limitRanges.Items)
+	}
+	return analysis, nil
+}
+
+func project(target *projected, quotas []corev1.ResourceQuota, limitRanges []corev1.LimitRange) *Projection {
+	spec := target.spec.DeepCopy()
+	injected, violations := ApplyLimitRanges(spec, limitRanges)
+	template := &corev1.Pod{Spec: *spec}
+	requests := pod.Requests(template)
+	limits := pod.Limits(template)
+
+	var liveRequests, liveLimits corev1.ResourceList
+	var surge, replaced int32
+	if target.live != nil {
+		live := target.live.DeepCopy()
+		ApplyLimitRanges(live, limitRanges)
+		liveTemplate := &corev1.Pod{Spec: *live}
+		liveRequests = pod.Requests(liveTemplate)
+		liveLimits = pod.Limits(liveTemplate)
+		if templateChanged(live, spec, liveRequests, requests, liveLimits, limits) {
+			surge, replaced = target.surge, target.replaced
+		}
+	}
+
+	projection := &Projection{
+		Object:               target.object,
+		NewPods:              target.newPods,
+		SurgePods:            surge,
+		ReplacedPods:         replaced,
+		PodRequests:          formatResources(requests),
+		PodLimits:            formatResources(limits),
+		InjectedDefaults:     injected,
+		LimitRangeViolations: violations,
+	}
+	for _, violation := range violations {
+		projection.Problems = append(projection.Problems, "LimitRange rejects pod: "+violation)
+	}
+
+	for _, quota := range quotas {
+		if !matchesScopes(&quota, spec, requests, limits) {
+			continue
+		}
+		for _, name := range sortedNames(quota.Status.Hard) {
+			perPod, ok := UsagePerPod(name, requests, limits)
+			if !ok {
+				continue
+			}
+			if missing := containersMissing(spec, name); len(missing) > 0 {
+				projection.Problems = append(projection.Problems, fmt.Sprintf(
+					"ResourceQuota %s rejects pod: must specify %s for: %s", quota.Name, name, strings.Join(missing, ","),
+				))
+			}
+
+			hard := quota.Status.Hard[name]
+			used := quota.Status.Used[name]
+			needed := perPod.DeepCopy()
+			needed.Mul(int64(target.newPods + surge))
+			if replaced > 0 {
+				// replaced pods only add what the new template needs above
+				// the live one, lowered usage is not counted, as old pods
+				// are still there while surge pods are created
+				livePerPod, _ := UsagePerPod(name, liveRequests, liveLimits)
+				delta := perPod.DeepCopy()
+				delta.Sub(livePerPod)
+				if delta.Sign() > 0 {
+					delta.Mul(int64(replaced))
+					needed.Add(delta)
+				}
+			}
+			remaining := hard.DeepCopy()
+			remaining.Sub(used)
+			remaining.Sub(needed)
+
+			check := Check{
+				Quota:     quota.Name,
+				Resource:  string(name),
+				Hard:      hard.String(),
+				Used:      used.String(),
+				Needed:    needed.String(),
+				Remaining: remaining.String(),
+				Fits:      remaining.Sign() >= 0,
+			}
+			projection.Checks = append(projection.Checks, check)
+			if !check.Fits {
+				free := hard.DeepCopy()
+				free.Sub(used)
+				projection.Problems = append(projection.Problems, fmt.Sprintf(
+					"ResourceQuota %s is exceeded: %s need %s %s, but only %s of %s is left",
+					quota.Name, describePods(projection), needed.String(), name, free.String(), hard.String(),
+				))
+			}
+		}
+	}
+	projection.Fits = len(projection.Problems) == 0
+	return projection
+}
+
+func describePods(projection *Projection) string {
+	if projection.SurgePods == 0 && projection.ReplacedPods == 0 {
+		return fmt.Sprintf("%d new pods", projection.NewPods)
+	}
+	return fmt.Sprintf("%d new pods, %d surge pods and %d replaced pods",
+		projection.NewPods, projection.SurgePods, projection.ReplacedPods)
+}
+
+// templateChanged tells whether applying the new pod template would roll
+// pods of the workload out, only images and resources are compared, as
+// manifest lacks fields defaulted by the API server in the live template
+func templateChanged(live, spec *corev1.PodSpec, liveRequests, requests, liveLimits, limits corev1.ResourceList) bool {
+	if !apiequality.Semantic.DeepEqual(liveRequests, requests) || !apiequality.Semantic.DeepEqual(liveLimits, limits) {
+		return true
+	}
+	images := func(spec *corev1.PodSpec) []string {
+		var images []string
+		for _, containers := range [][]corev1.Container{spec.InitContainers, spec.Containers} {
+			for _, container := range containers {
+				images = append(images, container.Name+"="+container.Image)
+			}
+		}
+		return images
+	}
+	return !slices.Equal(images(live), images(spec))
+}
+
+// UsagePerPod returns how much of quota resource one pod consumes
+func UsagePerPod(name corev1.ResourceName, requests corev1.ResourceList, limits corev1.ResourceList) (resource.Quantity, bool) {
+	switch name {
+	case corev1.ResourcePods, "count/pods":
+		return resource.MustParse("1"), true
+	case corev1.ResourceCPU, corev1.ResourceRequestsCPU:
+		return requests[corev1.ResourceCPU], true
+	case corev1.ResourceMemory, corev1.ResourceRequestsMemory:
+		return requests[corev1.ResourceMemory], true
+	case corev1.ResourceEphemeralStorage, corev1.ResourceRequestsEphemeralStorage:
+		return requests[corev1.ResourceEphemeralStorage], true
+	case corev1.ResourceLimitsCPU:
+		return limits[corev1.ResourceCPU], true
+	case corev1.ResourceLimitsMemory:
+		return limits[corev1.ResourceMemory], true
+	case corev1.ResourceLimitsEphemeralStorage:
+		return limits[corev1.ResourceEphemeralStorage], true
+	}
+	return resource.Quantity{}, false
+}
+
+// containersMissing returns names of containers, which do not specify request
+// or limit required by quota tracking cpu or memory, as quota admission rejects such pods
+func containersMissing(spec *corev1.PodSpec, name corev1.ResourceName) []string {
+	var resourceName corev1.ResourceName
+	requests := true
+	switch name {
+	case corev1.ResourceCPU, corev1.ResourceRequestsCPU:
+		resourceName = corev1.ResourceCPU
+	case corev1.ResourceMemory, corev1.ResourceRequestsMemory:
+		resourceName = corev1.ResourceMemory
+	case corev1.ResourceLimitsCPU:
+		resourceName, requests = corev1.ResourceCPU, false
+	case corev1.ResourceLimitsMemory:
+		resourceName, requests = corev1.ResourceMemory, false
+	default:
+		return nil
+	}
+
+	var missing []string
+	for _, containers := range [][]corev1.Container{spec.InitContainers, spec.Containers} {
+		for _, container := range containers {
+			resources := container.Resources.Limits
+			if requests {
+				resources = container.Resources.Requests
+			}
+			if _, ok := resources[resourceName]; !ok {
+				missing = append(missing, container.Name)
+			}
+		}
+	}
+	return missing
+}
+
+// matchesScopes tells whether quota tracks the pod, scopes not related
+// to pods, like cross namespace affinity, are considered not matching
+func matchesScopes(quota *corev1.ResourceQuota, spec *corev1.PodSpec, requests, limits corev1.ResourceList) bool {
+	for _, scope := range quota.Spec.Scopes {
+		if !matchesScope(scope, corev1.ScopeSelectorOpExists, nil, spec, requests, limits) {
+			return false
+		}
+	}
+	if quota.Spec.ScopeSelector != nil {
+		for _, expression := range quota.Spec.ScopeSelector.MatchExpressions {
+			if !matchesScope(expression.ScopeName, expression.Operator, expression.Values, spec, requests, limits) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func matchesScope(
+	scope corev1.ResourceQuotaScope,
+	operator corev1.ScopeSelectorOperator,
+	values []string,
+	spec *corev1.PodSpec,
+	requests, limits corev1.ResourceList,
+) bool {
+	bestEffort := isBestEffort(requests, limits)
+	var matches bool
+	switch scope {
+	case corev1.ResourceQuotaScopeTerminating:
+		matches = spec.ActiveDeadlineSeconds != nil
+	case corev1.ResourceQuotaScopeNotTerminating:
+		matches = spec.ActiveDeadlineSeconds == nil
+	case corev1.ResourceQuotaScopeBestEffort:
+		matches = bestEffort
+	case corev1.ResourceQuotaScopeNotBestEffort:
+		matches = !bestEffort
+	case corev1.ResourceQuotaScopePriorityClass:
+		switch operator {
+		case corev1.ScopeSelectorOpIn:
+			return slices.Contains(values, spec.PriorityClassName)
+		case corev1.ScopeSelectorOpNotIn:
+			return !slices.Contains(values, spec.PriorityClassName)
+		case corev1.ScopeSelectorOpDoesNotExist:
+			return spec.PriorityClassName == ""
+		}
+		return spec.PriorityClassName != ""
+	default:
+		return false
+	}
+	if operator == corev1.ScopeSelectorOpDoesNotExist {
+		return !matches
+	}
+	return matches
+}
+
+func isBestEffort(requests, limits corev1.ResourceList) bool {
+	for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+		if _, ok := requests[name]; ok {
+			return false
+		}
+		if _, ok := limits[name]; ok {
+			return false
+		}
+	}
+	return true
+}
+
+// fromManifest decodes pod template and number of pods from manifest, pods
+// of workloads, which already exist, are only counted above existing replicas,
+// while their existing pods are projected to be replaced by the new template
+func fromManifest(ctx context.Context, clientset kubernetes.Interface, namespace string, manifest string) (*projected, error) {
+	obj := &unstructured.Unstructured{}
+	decoder := yaml.NewYAMLOrJSONDecoder(bytes.NewReader([]byte(manifest)), 4096)
+	if err := decoder.Decode(obj); err != nil {
+		if err == io.EOF {
+			return nil, fmt.Errorf("manifest is empty")
+		}
+		return nil, fmt.Errorf("failed to unmarshal manifest: %w", err)
+	}
+	if ns := obj.GetNamespace(); ns != "" && ns != namespace {
+		return nil, fmt.Errorf("manifest is in namespace %s, but namespace %s is analyzed", ns, namespace)
+	}
+
+	object := fmt.Sprintf("%s/%s", obj.GetKind(), obj.GetName())
+	content := obj.UnstructuredContent()
+	converter := runtime.DefaultUnstructuredConverter
+	switch obj.GetKind() {
+	case "Pod":
+		var p corev1.Pod
+		if err := converter.FromUnstructured(content, &p); err != nil {
+			return nil, err
+		}
+		return &projected{object: object, spec: p.Spec, newPods: 1}, nil
+	case "Deployment", "ReplicaSet", "StatefulSet":
+		replicas, _, err := unstructured.NestedInt64(content, "spec", "replicas")
+		if err != nil {
+			return nil, err
+		}
+		if _, found, _ := unstructured.NestedFieldNoCopy(content, "spec", "replicas"); !found {
+			replicas = 1
+		}
+		var template corev1.PodTemplateSpec
+		templateContent, _, err := unstructured.NestedMap(content, "spec", "template")
+		if err != nil {
+			return nil, err
+		}
+		if err := converter.FromUnstructured(templateContent, &template); err != nil {
+			return nil, err
+		}
+		current, live, err := getScalable(ctx, clientset, obj.GetKind(), namespace, obj.GetName())
+		if apierrors.IsNotFound(err) {
+			return &projected{object: object, spec: template.Spec, newPods: int32(replicas)}, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		target := &projected{object: object, spec: template.Spec, newPods: max(int32(replicas)-current, 0)}
+		switch obj.GetKind() {
+		case "Deployment":
+			var dep appsv1.Deployment
+			if err := converter.FromUnstructured(content, &dep); err != nil {
+				return nil, err
+			}
+			dep.Spec.Replicas = utils.Ptr(int32(replicas))
+			limits, err := deployment.NewStrategyLimits(&dep)
+			if err != nil {
+				return nil, err
+			}
+			target.live, target.replaced, target.surge = &live.Spec, min(int32(replicas), current), limits.MaxSurge
+		case "StatefulSet":
+			// pods are replaced one by one without surge
+			target.live, target.replaced = &live.Spec, min(int32(replicas), current)
+		}
+		// ReplicaSet does not replace existing pods when template changes
+		return target, nil
+	case "Job":
+		var job batchv1.Job
+		if err := converter.FromUnstructured(content, &job); err != nil {
+			return nil, err
+		}
+		return &projected{object: object, spec: job.Spec.Template.Spec, newPods: jobPods(job.Spec)}, nil
+	case "CronJob":
+		var cronJob batchv1.CronJob
+		if err := converter.FromUnstructured(content, &cronJob); err != nil {
+			return nil, err
+		}
+		return &projected{object: object, spec: cronJob.Spec.JobTemplate.Spec.Template.Spec, newPods: jobPods(cronJob.Spec.JobTemplate.Spec)}, nil
+	}
+	return nil, fmt.Errorf("projection is not supported for kind %s, expected one of Pod, Deployment, ReplicaSet, StatefulSet, Job or CronJob", obj.GetKind())
+}
+
+// fromScale projects scaling existing workload to replicas
+func fromScale(ctx context.Context, clientset kubernetes.Interface, namespace, kind, name string, replicas int32) (*projected, error) {
+	if name == "" {
+		return nil, fmt.Errorf("name must be set together with kind")
+	}
+	canonical, ok := scalableKinds[strings.ToLower(kind)]
+	if !ok {
+		return nil, fmt.Errorf("scaling is not supported for kind %s, expected one of Deployment, ReplicaSet or StatefulSet", kind)
+	}
+	current, template, err := getScalable(ctx, clientset, canonical, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	return &projected{
+		object:  fmt.Sprintf("%s/%s scaled from %d to %d replicas", canonical, name, current, replicas),
+		spec:    template.Spec,
+		newPods: max(replicas-current, 0),
+	}, nil
+}
+
+var scalableKinds = map[string]string{
+	"deployment": "Deployment", "deployments": "Deployment", "deploy": "Deployment",
+	"replicaset": "ReplicaSet", "replicasets": "ReplicaSet", "rs": "ReplicaSet",
+	"statefulset": "StatefulSet", "statefulsets": "StatefulSet", "sts": "StatefulSet",
+}
+
+// getScalable returns current number of replicas and pod template of the workload
+func getScalable(ctx context.Context, clientset kubernetes.Interface, kind, namespace, name string) (int32, *corev1.PodTemplateSpec, error) {
+	apps := clientset.AppsV1()
+	var replicas *int32
+	var template *corev1.PodTemplateSpec
+	switch kind {
+	case "Deployment":
+		deployment, err := apps.Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return 0, nil, err
+		}
+		replicas, template = deployment.Spec.Replicas, &deployment.Spec.Template
+	case "ReplicaSet":
+		replicaSet, err := apps.ReplicaSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return 0, nil, err
+		}
+		replicas, template = replicaSet.Spec.Replicas, &replicaSet.Spec.Template
+	case "StatefulSet":
+		statefulSet, err := apps.StatefulSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return 0, nil, err
+		}
+		replicas, template = statefulSet.Spec.Replicas, &statefulSet.Spec.Template
+	default:
+		return 0, nil, fmt.Errorf("unsupported kind %s", kind)
+	}
+	if replicas == nil {
+		return 1, template, nil
+	}
+	return *replicas, template, nil
+}
+
+// jobPods returns number of pods job runs at the same time
+func jobPods(spec batchv1.JobSpec) int32 {
+	pods := int32(1)
+	if spec.Parallelism != nil {
+		pods = *spec.Parallelism
+	}
+	if spec.Completions != nil && *spec.Completions < pods {
+		pods = *spec.Completions
+	}
+	return pods
+}
+
+func formatResources(resources corev1.ResourceList) map[string]string {
+	if len(resources) == 0 {
+		return nil
+	}
+	formatted := map[string]string{}
+	for name, quantity := range resources {
+		formatted[string(name)] = quantity.String()
+	}
+	return formatted
+}
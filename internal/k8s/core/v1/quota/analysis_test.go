@@ -0,0 +1,205 @@
+package quota
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/strowk/mcp-k8s-go/internal/utils"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func resources(pairs ...string) corev1.ResourceList {
+	list := corev1.ResourceList{}
+	for i := 0; i < len(pairs); i += 2 {
+		list[corev1.ResourceName(pairs[i])] = resource.MustParse(pairs[i+1])
+	}
+	return list
+}
+
+func TestAnalyze(t *testing.T) {
+	clientset := fake.NewClientset(
+		&corev1.ResourceQuota{
+			ObjectMeta: metav1.ObjectMeta{Name: "compute", Namespace: "team"},
+			Status: corev1.ResourceQuotaStatus{
+				Hard: resources("requests.cpu", "2", "limits.memory", "2Gi", "pods", "10"),
+				Used: resources("requests.cpu", "1500m", "limits.memory", "1Gi", "pods", "3"),
+			},
+		},
+		&corev1.ResourceQuota{
+			ObjectMeta: metav1.ObjectMeta{Name: "best-effort", Namespace: "team"},
+			Spec:       corev1.ResourceQuotaSpec{Scopes: []corev1.ResourceQuotaScope{corev1.ResourceQuotaScopeBestEffort}},
+			Status: corev1.ResourceQuotaStatus{
+				Hard: resources("pods", "0"),
+				Used: resources("pods", "0"),
+			},
+		},
+		&corev1.LimitRange{
+			ObjectMeta: metav1.ObjectMeta{Name: "defaults", Namespace: "team"},
+			Spec: corev1.LimitRangeSpec{Limits: []corev1.LimitRangeItem{{
+				Type:           corev1.LimitTypeContainer,
+				Max:            resources("memory", "1Gi"),
+				Default:        resources("cpu", "500m", "memory", "512Mi"),
+				DefaultRequest: resources("cpu", "250m", "memory", "256Mi"),
+			}}},
+		},
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "team"},
+			Spec: appsv1.DeploymentSpec{
+				Replicas: utils.Ptr(int32(2)),
+				Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "app"}}}},
+			},
+		},
+		&corev1.ResourceQuota{
+			ObjectMeta: metav1.ObjectMeta{Name: "limits", Namespace: "bare"},
+			Status: corev1.ResourceQuotaStatus{
+				Hard: resources("limits.cpu", "4"),
+				Used: resources("limits.cpu", "1"),
+			},
+		},
+	)
+
+	t.Run("usage only", func(t *testing.T) {
+		analysis, err := Analyze(context.Background(), clientset, AnalysisOptions{Namespace: "team"})
+		require.NoError(t, err)
+		require.Len(t, analysis.Quotas, 2)
+		assert.Equal(t, "best-effort", analysis.Quotas[0].Name)
+		assert.Equal(t, "compute", analysis.Quotas[1].Name)
+		require.Len(t, analysis.LimitRanges, 1)
+		assert.Nil(t, analysis.Projection)
+	})
+
+	t.Run("scale-up fitting into quota", func(t *testing.T) {
+		analysis, err := Analyze(context.Background(), clientset, AnalysisOptions{
+			Namespace: "team", Kind: "deploy", Name: "web", Replicas: 4,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, &Projection{
+			Object:      "Deployment/web scaled from 2 to 4 replicas",
+			NewPods:     2,
+			PodRequests: map[string]string{"cpu": "250m", "memory": "256Mi"},
+			PodLimits:   map[string]string{"cpu": "500m", "memory": "512Mi"},
+			InjectedDefaults: []InjectedDefault{
+				{Container: "app", Resource: "cpu", Field: FieldLimit, Value: "500m", LimitRange: "defaults"},
+				{Container: "app", Resource: "memory", Field: FieldLimit, Value: "512Mi", LimitRange: "defaults"},
+				{Container: "app", Resource: "cpu", Field: FieldRequest, Value: "250m", LimitRange: "defaults"},
+				{Container: "app", Resource: "memory", Field: FieldRequest, Value: "256Mi", LimitRange: "defaults"},
+			},
+			Checks: []Check{
+				{Quota: "compute", Resource: "limits.memory", Hard: "2Gi", Used: "1Gi", Needed: "1Gi", Remaining: "0", Fits: true},
+				{Quota: "compute", Resource: "pods", Hard: "10", Used: "3", Needed: "2", Remaining: "5", Fits: true},
+				{Quota: "compute", Resource: "requests.cpu", Hard: "2", Used: "1500m", Needed: "500m", Remaining: "0", Fits: true},
+			},
+			Fits: true,
+		}, analysis.Projection)
+	})
+
+	t.Run("scale-up exceeding quota", func(t *testing.T) {
+		analysis, err := Analyze(context.Background(), clientset, AnalysisOptions{
+			Namespace: "team", Kind: "Deployment", Name: "web", Replicas: 5,
+		})
+		require.NoError(t, err)
+		assert.False(t, analysis.Projection.Fits)
+		assert.Equal(t, []string{
+			"ResourceQuota compute is exceeded: 3 new pods need 1536Mi limits.memory, but only 1Gi of 2Gi is left",
+			"ResourceQuota compute is exceeded: 3 new pods need 750m requests.cpu, but only 500m of 2 is left",
+		}, analysis.Projection.Problems)
+	})
+
+	t.Run("manifest violating limit range", func(t *testing.T) {
+		analysis, err := Analyze(context.Background(), clientset, AnalysisOptions{
+			Namespace: "team",
+			Manifest: `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: big
+spec:
+  containers:
+    - name: app
+      resources:
+        limits:
+          cpu: "2"
+          memory: 2Gi
+`,
+		})
+		require.NoError(t, err)
+		projection := analysis.Projection
+		assert.Equal(t, "Pod/big", projection.Object)
+		assert.Equal(t, int32(1), projection.NewPods)
+		assert.Empty(t, projection.InjectedDefaults)
+		assert.Equal(t, map[string]string{"cpu": "2", "memory": "2Gi"}, projection.PodRequests)
+		assert.Equal(t, []string{
+			"LimitRange rejects pod: container app limits 2Gi memory, which is above maximum 1Gi of LimitRange defaults",
+			"ResourceQuota compute is exceeded: 1 new pods need 2Gi limits.memory, but only 1Gi of 2Gi is left",
+			"ResourceQuota compute is exceeded: 1 new pods need 2 requests.cpu, but only 500m of 2 is left",
+		}, projection.Problems)
+	})
+
+	t.Run("manifest of existing deployment only counts added replicas", func(t *testing.T) {
+		analysis, err := Analyze(context.Background(), clientset, AnalysisOptions{
+			Namespace: "team",
+			Manifest:  `{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"name":"web"},"spec":{"replicas":3,"template":{"spec":{"containers":[{"name":"app"}]}}}}`,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, int32(1), analysis.Projection.NewPods)
+		assert.True(t, analysis.Projection.Fits)
+	})
+
+	t.Run("manifest changing template of existing deployment counts surge and replaced pods", func(t *testing.T) {
+		analysis, err := Analyze(context.Background(), clientset, AnalysisOptions{
+			Namespace: "team",
+			Manifest:  `{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"name":"web"},"spec":{"replicas":2,"template":{"spec":{"containers":[{"name":"app","resources":{"requests":{"cpu":"500m"},"limits":{"memory":"768Mi"}}}]}}}}`,
+		})
+		require.NoError(t, err)
+		projection := analysis.Projection
+		assert.Equal(t, int32(0), projection.NewPods)
+		assert.Equal(t, int32(1), projection.SurgePods)
+		assert.Equal(t, int32(2), projection.ReplacedPods)
+		assert.Equal(t, []Check{
+			{Quota: "compute", Resource: "limits.memory", Hard: "2Gi", Used: "1Gi", Needed: "1280Mi", Remaining: "-256Mi", Fits: false},
+			{Quota: "compute", Resource: "pods", Hard: "10", Used: "3", Needed: "1", Remaining: "6", Fits: true},
+			{Quota: "compute", Resource: "requests.cpu", Hard: "2", Used: "1500m", Needed: "1", Remaining: "-500m", Fits: false},
+		}, projection.Checks)
+		assert.Equal(t, []string{
+			"ResourceQuota compute is exceeded: 0 new pods, 1 surge pods and 2 replaced pods need 1280Mi limits.memory, but only 1Gi of 2Gi is left",
+			"ResourceQuota compute is exceeded: 0 new pods, 1 surge pods and 2 replaced pods need 1 requests.cpu, but only 500m of 2 is left",
+		}, projection.Problems)
+		assert.False(t, projection.Fits)
+	})
+
+	t.Run("manifest keeping template of existing deployment needs nothing", func(t *testing.T) {
+		analysis, err := Analyze(context.Background(), clientset, AnalysisOptions{
+			Namespace: "team",
+			Manifest:  `{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"name":"web"},"spec":{"replicas":2,"template":{"spec":{"containers":[{"name":"app"}]}}}}`,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, int32(0), analysis.Projection.NewPods)
+		assert.Equal(t, int32(0), analysis.Projection.SurgePods)
+		assert.Equal(t, int32(0), analysis.Projection.ReplacedPods)
+		assert.True(t, analysis.Projection.Fits)
+	})
+
+	t.Run("quota requires limits without defaults", func(t *testing.T) {
+		analysis, err := Analyze(context.Background(), clientset, AnalysisOptions{
+			Namespace: "bare",
+			Manifest:  `{"apiVersion":"v1","kind":"Pod","metadata":{"name":"p"},"spec":{"containers":[{"name":"app"},{"name":"sidecar"}]}}`,
+		})
+		require.NoError(t, err)
+		assert.False(t, analysis.Projection.Fits)
+		assert.Equal(t, []string{"ResourceQuota limits rejects pod: must specify limits.cpu for: app,sidecar"}, analysis.Projection.Problems)
+	})
+
+	t.Run("unsupported manifest kind", func(t *testing.T) {
+		_, err := Analyze(context.Background(), clientset, AnalysisOptions{
+			Namespace: "team",
+			Manifest:  `{"apiVersion":"v1","kind":"Service","metadata":{"name":"web"}}`,
+		})
+		assert.EqualError(t, err, "projection is not supported for kind Service, expected one of Pod, Deployment, ReplicaSet, StatefulSet, Job or CronJob")
+	})
+}
@@ -0,0 +1,180 @@
+package quota
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/strowk/mcp-k8s-go/internal/k8s/core/v1/pod"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// InjectedDefault is request or limit, which LimitRange admission
+// sets for container not specifying it
+type InjectedDefault struct {
+	Container  string `json:"container"`
+	Resource   string `json:"resource"`
+	Field      string `json:"field"`
+	Value      string `json:"value"`
+	LimitRange string `json:"limitRange,omitempty"`
+}
+
+// Fields of container resources, which get defaults
+const (
+	FieldRequest = "request"
+	FieldLimit   = "limit"
+)
+
+// ApplyLimitRanges sets requests and limits of containers of the pod spec
+// the same way as API server would: requests are first defaulted to explicit
+// limits, then Container limit ranges inject defaults for what is still missing,
+// spec is modified in place and violations of min, max and ratio constraints
+// of Container and Pod limit ranges are returned together with injected defaults
+func ApplyLimitRanges(spec *corev1.PodSpec, limitRanges []corev1.LimitRange) ([]InjectedDefault, []string) {
+	sorted := append([]corev1.LimitRange{}, limitRanges...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Name < sorted[j].Name
+	})
+
+	var injected []InjectedDefault
+	for _, containers := range [][]corev1.Container{spec.InitContainers, spec.Containers} {
+		for i := range containers {
+			container := &containers[i]
+			defaultRequestsToLimits(container)
+			for _, limitRange := range sorted {
+				for _, item := range limitRange.Spec.Limits {
+					if item.Type != corev1.LimitTypeContainer {
+						continue
+					}
+					injected = append(injected, injectDefaults(container, limitRange.Name, FieldLimit, item.Default)...)
+					injected = append(injected, injectDefaults(container, limitRange.Name, FieldRequest, item.DefaultRequest)...)
+				}
+			}
+		}
+	}
+
+	var violations []string
+	for _, limitRange := range sorted {
+		for _, item := range limitRange.Spec.Limits {
+			switch item.Type {
+			case corev1.LimitTypeContainer:
+				for _, containers := range [][]corev1.Container{spec.InitContainers, spec.Containers} {
+					for _, container := range containers {
+						violations = append(violations, checkLimits(
+							fmt.Sprintf("container %s", container.Name), limitRange.Name, item,
+							container.Resources.Requests, container.Resources.Limits,
+						)...)
+					}
+				}
+			case corev1.LimitTypePod:
+				requests, limits := podTotals(spec)
+				violations = append(violations, checkLimits("pod", limitRange.Name, item, requests, limits)...)
+			}
+		}
+	}
+	return injected, violations
+}
+
+// defaultRequestsToLimits mirrors defaulting of API server,
+// which sets missing requests to explicitly set limits
+func defaultRequestsToLimits(container *corev1.Container) {
+	for name, limit := range container.Resources.Limits {
+		if _, ok := container.Resources.Requests[name]; ok {
+			continue
+		}
+		if container.Resources.Requests == nil {
+			container.Resources.Requests = corev1.ResourceList{}
+		}
+		container.Resources.Requests[name] = limit.DeepCopy()
+	}
+}
+
+func injectDefaults(container *corev1.Container, limitRange string, field string, defaults corev1.ResourceList) []InjectedDefault {
+	target := &container.Resources.Limits
+	if field == FieldRequest {
+		target = &container.Resources.Requests
+	}
+
+	var injected []InjectedDefault
+	for _, name := range sortedNames(defaults) {
+		if _, ok := (*target)[name]; ok {
+			continue
+		}
+		if *target == nil {
+			*target = corev1.ResourceList{}
+		}
+		value := defaults[name]
+		(*target)[name] = value.DeepCopy()
+		injected = append(injected, InjectedDefault{
+			Container:  container.Name,
+			Resource:   string(name),
+			Field:      field,
+			Value:      value.String(),
+			LimitRange: limitRange,
+		})
+	}
+	return injected
+}
+
+func checkLimits(subject string, limitRange string, item corev1.LimitRangeItem, requests, limits corev1.ResourceList) []string {
+	var violations []string
+	for _, name := range sortedNames(item.Min) {
+		minimum := item.Min[name]
+		if request, ok := requests[name]; !ok {
+			violations = append(violations, fmt.Sprintf("%s has no %s request, but LimitRange %s requires minimum %s", subject, name, limitRange, minimum.String()))
+		} else if request.Cmp(minimum) < 0 {
+			violations = append(violations, fmt.Sprintf("%s requests %s %s, which is below minimum %s of LimitRange %s", subject, request.String(), name, minimum.String(), limitRange))
+		}
+	}
+	for _, name := range sortedNames(item.Max) {
+		maximum := item.Max[name]
+		if limit, ok := limits[name]; !ok {
+			violations = append(violations, fmt.Sprintf("%s has no %s limit, but LimitRange %s requires maximum %s", subject, name, limitRange, maximum.String()))
+		} else if limit.Cmp(maximum) > 0 {
+			violations = append(violations, fmt.Sprintf("%s limits %s %s, which is above maximum %s of LimitRange %s", subject, limit.String(), name, maximum.String(), limitRange))
+		}
+	}
+	for _, name := range sortedNames(item.MaxLimitRequestRatio) {
+		ratio := item.MaxLimitRequestRatio[name]
+		request, hasRequest := requests[name]
+		limit, hasLimit := limits[name]
+		if !hasRequest || !hasLimit || request.IsZero() {
+			continue
+		}
+		actual := pod.Fraction(limit, request)
+		if actual > ratio.AsApproximateFloat64() {
+			violations = append(violations, fmt.Sprintf("%s has %s limit to request ratio %.2f, which is above maximum %s of LimitRange %s", subject, name, actual, ratio.String(), limitRange))
+		}
+	}
+	return violations
+}
+
+// podTotals sums requests and limits of regular containers as LimitRange
+// admission does for Pod type, limits are only summed when all containers have them
+func podTotals(spec *corev1.PodSpec) (corev1.ResourceList, corev1.ResourceList) {
+	requests, limits := corev1.ResourceList{}, corev1.ResourceList{}
+	limited := map[corev1.ResourceName]int{}
+	for _, container := range spec.Containers {
+		pod.AddResources(requests, container.Resources.Requests)
+		pod.AddResources(limits, container.Resources.Limits)
+		for name := range container.Resources.Limits {
+			limited[name]++
+		}
+	}
+	for name, count := range limited {
+		if count != len(spec.Containers) {
+			delete(limits, name)
+		}
+	}
+	return requests, limits
+}
+
+func sortedNames(resources corev1.ResourceList) []corev1.ResourceName {
+	names := make([]corev1.ResourceName, 0, len(resources))
+	for name := range resources {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return names[i] < names[j]
+	})
+	return names
+}
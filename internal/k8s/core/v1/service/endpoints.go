@@ -0,0 +1,521 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/strowk/mcp-k8s-go/internal/k8s/core/v1/pod"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes"
+)
+
+// EndpointHealth tells whether Service has backends, resolving its
+// selector to pods and comparing them with EndpointSlices of the service
+type EndpointHealth struct {
+	Service   *ServiceContent   `json:"service"`
+	Selector  map[string]string `json:"selector,omitempty"`
+	Ready     int               `json:"ready"`
+	NotReady  int               `json:"notReady"`
+	Endpoints []EndpointInfo    `json:"endpoints"`
+	Pods      []SelectedPod     `json:"pods"`
+	Healthy   bool              `json:"healthy"`
+	Findings  []pod.Finding     `json:"findings"`
+}
+
+type EndpointInfo struct {
+	Address     string   `json:"address"`
+	Ports       []string `json:"ports,omitempty"`
+	Ready       bool     `json:"ready"`
+	Serving     bool     `json:"serving"`
+	Terminating bool     `json:"terminating,omitempty"`
+	Pod         string   `json:"pod,omitempty"`
+	Node        string   `json:"node,omitempty"`
+	Zone        string   `json:"zone,omitempty"`
+	Slice       string   `json:"slice"`
+	// Reason explains why endpoint is not ready
+	Reason string `json:"reason,omitempty"`
+}
+
+// SelectedPod is pod matching selector of the service
+type SelectedPod struct {
+	Name  string `json:"name"`
+	Phase string `json:"phase"`
+	IP    string `json:"ip,omitempty"`
+	Node  string `json:"node,omitempty"`
+	Ready bool   `json:"ready"`
+	// InEndpoints tells whether any EndpointSlice of the service points to the pod
+	InEndpoints bool   `json:"inEndpoints"`
+	Reason      string `json:"reason,omitempty"`
+}
+
+// CheckEndpoints resolves selector of the service to pods and EndpointSlices and
+// reports services without ready endpoints, pods not matching the selector
+// and target ports, which selected pods do not expose
+func CheckEndpoints(ctx context.Context, clientset kubernetes.Interface, namespace string, name string) (*EndpointHealth, error) {
+	svc, err := clientset.CoreV1().Services(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	h := &EndpointHealth{
+		Service:   NewServiceContent(svc),
+		Selector:  svc.Spec.Selector,
+		Endpoints: []EndpointInfo{},
+		Pods:      []SelectedPod{},
+		Findings:  []pod.Finding{},
+	}
+
+	if svc.Spec.Type == corev1.ServiceTypeExternalName {
+		h.add(pod.Finding{
+			Severity: pod.SeverityWarning,
+			Reason:   "ExternalName",
+			Message:  fmt.Sprintf("service is an alias of %s resolved by DNS and has no endpoints", svc.Spec.ExternalName),
+		})
+		h.Healthy = true
+		return h, nil
+	}
+
+	var pods []corev1.Pod
+	if len(svc.Spec.Selector) > 0 {
+		list, err := clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{
+			LabelSelector: labels.SelectorFromSet(svc.Spec.Selector).String(),
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range list.Items {
+			if p.Status.Phase != corev1.PodSucceeded && p.Status.Phase != corev1.PodFailed {
+				pods = append(pods, p)
+			}
+		}
+		sort.Slice(pods, func(i, j int) bool {
+			return pods[i].Name < pods[j].Name
+		})
+	}
+
+	slices, err := clientset.DiscoveryV1().EndpointSlices(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(labels.Set{discoveryv1.LabelServiceName: name}).String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(slices.Items, func(i, j int) bool {
+		return slices.Items[i].Name < slices.Items[j].Name
+	})
+
+	podsByName := map[string]*corev1.Pod{}
+	for i := range pods {
+		podsByName[pods[i].Name] = &pods[i]
+	}
+	endpointPods := h.collectEndpoints(slices.Items, podsByName)
+
+	for _, p := range pods {
+		selected := SelectedPod{
+			Name:        p.Name,
+			Phase:       string(p.Status.Phase),
+			IP:          p.Status.PodIP,
+			Node:        p.Spec.NodeName,
+			Ready:       pod.IsPodReady(&p),
+			InEndpoints: endpointPods[p.Name],
+		}
+		if !selected.Ready {
+			selected.Reason = notReadyReason(&p)
+		}
+		h.Pods = append(h.Pods, selected)
+	}
+
+	if len(svc.Spec.Selector) > 0 && len(pods) == 0 {
+		if err := h.checkSelector(ctx, clientset, svc); err != nil {
+			return nil, err
+		}
+	}
+	h.checkTargetPorts(svc, pods)
+	h.checkMissingPods(svc)
+	h.checkReadiness(svc)
+
+	h.Healthy = true
+	for _, finding := range h.Findings {
+		if finding.Severity == pod.SeverityError {
+			h.Healthy = false
+		}
+	}
+	return h, nil
+}
+
+func (h *EndpointHealth) add(finding pod.Finding) {
+	h.Findings = append(h.Findings, finding)
+}
+
+// collectEndpoints flattens addresses of EndpointSlices and returns
+// names of pods, which endpoints point to
+func (h *EndpointHealth) collectEndpoints(slices []discoveryv1.EndpointSlice, pods map[string]*corev1.Pod) map[string]bool {
+	endpointPods := map[string]bool{}
+	for _, slice := range slices {
+		var ports []string
+		for _, port := range slice.Ports {
+			ports = append(ports, formatEndpointPort(port))
+		}
+		for _, endpoint := range slice.Endpoints {
+			info := EndpointInfo{
+				Ports: ports,
+				// nil conditions mean that state is unknown and consumers treat endpoint as ready
+				Ready:       endpoint.Conditions.Ready == nil || *endpoint.Conditions.Ready,
+				Terminating: endpoint.Conditions.Terminating != nil && *endpoint.Conditions.Terminating,
+				Slice:       slice.Name,
+			}
+			info.Serving = info.Ready
+			if endpoint.Conditions.Serving != nil {
+				info.Serving = *endpoint.Conditions.Serving
+			}
+			if endpoint.NodeName != nil {
+				info.Node = *endpoint.NodeName
+			}
+			if endpoint.Zone != nil {
+				info.Zone = *endpoint.Zone
+			}
+			if ref := endpoint.TargetRef; ref != nil && ref.Kind == "Pod" {
+				info.Pod = ref.Name
+				endpointPods[ref.Name] = true
+			}
+			if !info.Ready {
+				info.Reason = endpointReason(info, pods[info.Pod])
+			}
+
+			for _, address := range endpoint.Addresses {
+				info.Address = address
+				h.Endpoints = append(h.Endpoints, info)
+				if info.Ready {
+					h.Ready++
+				} else {
+					h.NotReady++
+				}
+			}
+		}
+	}
+	return endpointPods
+}
+
+// checkSelector explains why selector of the service matches no pods
+// by finding pods in the namespace, which match only some of its labels
+func (h *EndpointHealth) checkSelector(ctx context.Context, clientset kubernetes.Interface, svc *corev1.Service) error {
+	list, err := clientset.CoreV1().Pods(svc.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	sort.Slice(list.Items, func(i, j int) bool {
+		return list.Items[i].Name < list.Items[j].Name
+	})
+
+	keys := make([]string, 0, len(svc.Spec.Selector))
+	for key := range svc.Spec.Selector {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var evidence []string
+	for _, p := range list.Items {
+		var matched int
+		var differences []string
+		for _, key := range keys {
+			value, ok := p.Labels[key]
+			switch {
+			case !ok:
+				differences = append(differences, fmt.Sprintf("has no label %s", key))
+			case value != svc.Spec.Selector[key]:
+				differences = append(differences, fmt.Sprintf("has %s=%s instead of %s", key, value, svc.Spec.Selector[key]))
+			default:
+				matched++
+			}
+		}
+		// pods are similar when they match some labels of the selector,
+		// or have the only label of the selector with other value
+		if matched == 0 && (len(keys) > 1 || !hasKey(p.Labels, keys[0])) {
+			continue
+		}
+		if len(differences) == 0 {
+			differences = append(differences, fmt.Sprintf("matches, but is %s", p.Status.Phase))
+		}
+		evidence = append(evidence, fmt.Sprintf("pod %s %s", p.Name, strings.Join(differences, ", ")))
+	}
+
+	message := fmt.Sprintf("selector %s of the service matches no pods in namespace %s", labels.SelectorFromSet(svc.Spec.Selector).String(), svc.Namespace)
+	if len(evidence) > 0 {
+		message += ", but some pods have similar labels"
+	}
+	h.add(pod.Finding{
+		Severity: pod.SeverityError,
+		Reason:   "SelectorMismatch",
+		Message:  message,
+		Evidence: evidence,
+	})
+	return nil
+}
+
+// checkTargetPorts compares target ports of the service with ports declared by selected pods,
+// named target ports must be declared, while numbered ones work even when not declared
+func (h *EndpointHealth) checkTargetPorts(svc *corev1.Service, pods []corev1.Pod) {
+	for _, port := range svc.Spec.Ports {
+		targetPort := port.TargetPort
+		if targetPort.Type == intstr.Int && targetPort.IntVal == 0 {
+			targetPort = intstr.FromInt32(port.Port)
+		}
+
+		var missing []string
+		var declared []string
+		for _, p := range pods {
+			ports := containerPorts(&p)
+			if hasContainerPort(ports, targetPort, port.Protocol) {
+				continue
+			}
+			if targetPort.Type == intstr.Int && len(ports) == 0 {
+				// pod does not declare any ports, so nothing can be told
+				continue
+			}
+			missing = append(missing, p.Name)
+			declared = append(declared, fmt.Sprintf("pod %s declares %s", p.Name, formatContainerPorts(ports)))
+		}
+		if len(missing) == 0 {
+			continue
+		}
+
+		if targetPort.Type == intstr.String {
+			h.add(pod.Finding{
+				Severity: pod.SeverityError,
+				Reason:   "TargetPortMismatch",
+				Message: fmt.Sprintf(
+					"port %s of the service targets named port %s, which is not declared by %d of %d selected pods, so they get no endpoints for it",
+					formatServicePort(port), targetPort.StrVal, len(missing), len(pods),
+				),
+				Evidence: declared,
+			})
+			continue
+		}
+		h.add(pod.Finding{
+			Severity: pod.SeverityWarning,
+			Reason:   "TargetPortMismatch",
+			Message: fmt.Sprintf(
+				"port %s of the service targets port %d, which is not among ports declared by %d of %d selected pods, traffic goes there only if container listens on it anyway",
+				formatServicePort(port), targetPort.IntVal, len(missing), len(pods),
+			),
+			Evidence: declared,
+		})
+	}
+}
+
+// checkMissingPods reports pods with IP, which EndpointSlices do not point to yet
+func (h *EndpointHealth) checkMissingPods(svc *corev1.Service) {
+	var missing []string
+	for _, p := range h.Pods {
+		if p.IP != "" && !p.InEndpoints {
+			missing = append(missing, p.Name)
+		}
+	}
+	if len(missing) == 0 || len(svc.Spec.Ports) == 0 {
+		return
+	}
+	h.add(pod.Finding{
+		Severity: pod.SeverityWarning,
+		Reason:   "PodsWithoutEndpoints",
+		Message:  "pods match selector of the service, but no EndpointSlice points to them, endpoints might not be updated yet or pods lack named target port",
+		Evidence: missing,
+	})
+}
+
+// checkReadiness reports services without ready endpoints and not ready endpoints
+func (h *EndpointHealth) checkReadiness(svc *corev1.Service) {
+	var notReady []string
+	for _, endpoint := range h.Endpoints {
+		if !endpoint.Ready {
+			notReady = append(notReady, fmt.Sprintf("%s: %s", endpointName(endpoint), endpoint.Reason))
+		}
+	}
+
+	if h.Ready > 0 {
+		if len(notReady) > 0 {
+			h.add(pod.Finding{
+				Severity: pod.SeverityWarning,
+				Reason:   "NotReadyEndpoints",
+				Message:  fmt.Sprintf("%d of %d endpoints are not ready and receive no traffic", h.NotReady, h.Ready+h.NotReady),
+				Evidence: notReady,
+			})
+		}
+		return
+	}
+
+	finding := pod.Finding{
+		Severity: pod.SeverityError,
+		Reason:   "NoEndpoints",
+		Evidence: notReady,
+	}
+	switch {
+	case len(notReady) > 0:
+		finding.Reason = "NoReadyEndpoints"
+		finding.Message = "service has endpoints, but none of them is ready, so connections to the service fail"
+	case len(svc.Spec.Selector) == 0:
+		finding.Message = fmt.Sprintf(
+			"service has no selector and no endpoints, EndpointSlices labeled %s=%s have to be created for it manually",
+			discoveryv1.LabelServiceName, svc.Name,
+		)
+	case len(h.Pods) == 0:
+		finding.Message = "service has no endpoints, because no pods match its selector"
+	default:
+		finding.Message = "service has no endpoints, although pods match its selector"
+		for _, p := range h.Pods {
+			if !p.Ready {
+				finding.Evidence = append(finding.Evidence, fmt.Sprintf("pod %s: %s", p.Name, p.Reason))
+			}
+		}
+	}
+	h.add(finding)
+}
+
+// notReadyReason explains why the pod is not ready to serve traffic
+func notReadyReason(p *corev1.Pod) string {
+	if p.DeletionTimestamp != nil {
+		return "pod is terminating"
+	}
+	if p.Spec.NodeName == "" {
+		for _, condition := range p.Status.Conditions {
+			if condition.Type == corev1.PodScheduled && condition.Status == corev1.ConditionFalse {
+				return "pod is not scheduled: " + condition.Message
+			}
+		}
+		return "pod is not scheduled"
+	}
+
+	var containers []string
+	probes := map[string]bool{}
+	for _, container := range p.Spec.Containers {
+		probes[container.Name] = container.ReadinessProbe != nil
+	}
+	for _, status := range p.Status.ContainerStatuses {
+		if status.Ready {
+			continue
+		}
+		switch {
+		case status.State.Waiting != nil:
+			containers = append(containers, fmt.Sprintf("container %s is waiting: %s", status.Name, status.State.Waiting.Reason))
+		case status.State.Terminated != nil:
+			containers = append(containers, fmt.Sprintf("container %s terminated: %s", status.Name, status.State.Terminated.Reason))
+		case probes[status.Name]:
+			containers = append(containers, fmt.Sprintf("container %s is running, but its readiness probe fails", status.Name))
+		default:
+			containers = append(containers, fmt.Sprintf("container %s is not ready", status.Name))
+		}
+	}
+	if len(containers) > 0 {
+		return strings.Join(containers, ", ")
+	}
+
+	for _, condition := range p.Status.Conditions {
+		if condition.Type == corev1.PodReady && condition.Status != corev1.ConditionTrue {
+			if condition.Message != "" {
+				return "pod is not ready: " + condition.Message
+			}
+			break
+		}
+	}
+	return fmt.Sprintf("pod is %s and not ready", p.Status.Phase)
+}
+
+func endpointReason(endpoint EndpointInfo, p *corev1.Pod) string {
+	if endpoint.Terminating {
+		return "endpoint is terminating"
+	}
+	if p != nil {
+		return notReadyReason(p)
+	}
+	if endpoint.Pod != "" {
+		return fmt.Sprintf("pod %s is not ready", endpoint.Pod)
+	}
+	return "endpoint is not ready"
+}
+
+func endpointName(endpoint EndpointInfo) string {
+	if endpoint.Pod != "" {
+		return fmt.Sprintf("%s (pod %s)", endpoint.Address, endpoint.Pod)
+	}
+	return endpoint.Address
+}
+
+func hasKey(values map[string]string, key string) bool {
+	_, ok := values[key]
+	return ok
+}
+
+func containerPorts(p *corev1.Pod) []corev1.ContainerPort {
+	var ports []corev1.ContainerPort
+	for _, container := range p.Spec.Containers {
+		ports = append(ports, container.Ports...)
+	}
+	return ports
+}
+
+func hasContainerPort(ports []corev1.ContainerPort, target intstr.IntOrString, protocol corev1.Protocol) bool {
+	if protocol == "" {
+		protocol = corev1.ProtocolTCP
+	}
+	for _, port := range ports {
+		portProtocol := port.Protocol
+		if portProtocol == "" {
+			portProtocol = corev1.ProtocolTCP
+		}
+		if portProtocol != protocol {
+			continue
+		}
+		if target.Type == intstr.String && port.Name == target.StrVal {
+			return true
+		}
+		if target.Type == intstr.Int && port.ContainerPort == target.IntVal {
+			return true
+		}
+	}
+	return false
+}
+
+func formatContainerPorts(ports []corev1.ContainerPort) string {
+	if len(ports) == 0 {
+		return "no ports"
+	}
+	var formatted []string
+	for _, port := range ports {
+		protocol := port.Protocol
+		if protocol == "" {
+			protocol = corev1.ProtocolTCP
+		}
+		value := fmt.Sprintf("%d/%s", port.ContainerPort, protocol)
+		if port.Name != "" {
+			value = port.Name + ":" + value
+		}
+		formatted = append(formatted, value)
+	}
+	return strings.Join(formatted, ", ")
+}
+
+func formatServicePort(port corev1.ServicePort) string {
+	value := fmt.Sprintf("%d/%s", port.Port, port.Protocol)
+	if port.Name != "" {
+		value = port.Name + ":" + value
+	}
+	return value
+}
+
+func formatEndpointPort(port discoveryv1.EndpointPort) string {
+	var value string
+	if port.Port != nil {
+		value = fmt.Sprintf("%d", *port.Port)
+	}
+	if port.Protocol != nil {
+		value += "/" + string(*port.Protocol)
+	}
+	if port.Name != nil && *port.Name != "" {
+		value = *port.Name + ":" + value
+	}
+	return value
+}
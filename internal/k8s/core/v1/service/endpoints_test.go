@@ -0,0 +1,172 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/strowk/mcp-k8s-go/internal/k8s/core/v1/pod"
+	"github.com/strowk/mcp-k8s-go/internal/utils"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func webService(name string, selector map[string]string, targetPort intstr.IntOrString) *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "app"},
+		Spec: corev1.ServiceSpec{
+			Type:      corev1.ServiceTypeClusterIP,
+			ClusterIP: "10.0.0.10",
+			Selector:  selector,
+			Ports: []corev1.ServicePort{{
+				Name: "http", Port: 80, Protocol: corev1.ProtocolTCP, TargetPort: targetPort,
+			}},
+		},
+	}
+}
+
+func webPod(name string, podLabels map[string]string, ready bool) *corev1.Pod {
+	readyStatus := corev1.ConditionTrue
+	if !ready {
+		readyStatus = corev1.ConditionFalse
+	}
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "app", Labels: podLabels},
+		Spec: corev1.PodSpec{
+			NodeName: "node-a",
+			Containers: []corev1.Container{{
+				Name:           "web",
+				Ports:          []corev1.ContainerPort{{Name: "http", ContainerPort: 8080}},
+				ReadinessProbe: &corev1.Probe{},
+			}},
+		},
+		Status: corev1.PodStatus{
+			Phase:             corev1.PodRunning,
+			PodIP:             "10.1.0." + name[len(name)-1:],
+			Conditions:        []corev1.PodCondition{{Type: corev1.PodReady, Status: readyStatus}},
+			ContainerStatuses: []corev1.ContainerStatus{{Name: "web", Ready: ready, State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}}},
+		},
+	}
+}
+
+func webSlice(service string, endpoints ...discoveryv1.Endpoint) *discoveryv1.EndpointSlice {
+	return &discoveryv1.EndpointSlice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      service + "-abc",
+			Namespace: "app",
+			Labels:    map[string]string{discoveryv1.LabelServiceName: service},
+		},
+		AddressType: discoveryv1.AddressTypeIPv4,
+		Ports:       []discoveryv1.EndpointPort{{Name: utils.Ptr("http"), Port: utils.Ptr(int32(8080)), Protocol: utils.Ptr(corev1.ProtocolTCP)}},
+		Endpoints:   endpoints,
+	}
+}
+
+func podEndpoint(p *corev1.Pod, ready bool) discoveryv1.Endpoint {
+	return discoveryv1.Endpoint{
+		Addresses:  []string{p.Status.PodIP},
+		Conditions: discoveryv1.EndpointConditions{Ready: utils.Ptr(ready), Serving: utils.Ptr(ready)},
+		NodeName:   utils.Ptr(p.Spec.NodeName),
+		TargetRef:  &corev1.ObjectReference{Kind: "Pod", Name: p.Name, Namespace: "app"},
+	}
+}
+
+func findingReasons(findings []pod.Finding) []string {
+	var reasons []string
+	for _, finding := range findings {
+		reasons = append(reasons, finding.Reason)
+	}
+	return reasons
+}
+
+func TestCheckEndpoints(t *testing.T) {
+	web1 := webPod("web-1", map[string]string{"app": "web"}, true)
+	web2 := webPod("web-2", map[string]string{"app": "web"}, false)
+	api1 := webPod("api-1", map[string]string{"app": "api", "tier": "backend-v2"}, false)
+
+	clientset := fake.NewClientset(
+		web1, web2, api1,
+		webService("web", map[string]string{"app": "web"}, intstr.FromString("http")),
+		webSlice("web", podEndpoint(web1, true), podEndpoint(web2, false)),
+		webService("web-numbered", map[string]string{"app": "web"}, intstr.FromInt32(9090)),
+		webSlice("web-numbered", podEndpoint(web1, true), podEndpoint(web2, false)),
+		webService("api", map[string]string{"app": "api", "tier": "backend"}, intstr.FromString("http")),
+		webService("api-not-ready", map[string]string{"app": "api"}, intstr.FromString("metrics")),
+		webSlice("api-not-ready", podEndpoint(api1, false)),
+		webService("external", nil, intstr.FromInt32(80)),
+	)
+
+	t.Run("ready and not ready endpoints", func(t *testing.T) {
+		health, err := CheckEndpoints(context.Background(), clientset, "app", "web")
+		require.NoError(t, err)
+		assert.True(t, health.Healthy)
+		assert.Equal(t, 1, health.Ready)
+		assert.Equal(t, 1, health.NotReady)
+		assert.Equal(t, []EndpointInfo{
+			{Address: "10.1.0.1", Ports: []string{"http:8080/TCP"}, Ready: true, Serving: true, Pod: "web-1", Node: "node-a", Slice: "web-abc"},
+			{
+				Address: "10.1.0.2", Ports: []string{"http:8080/TCP"}, Pod: "web-2", Node: "node-a", Slice: "web-abc",
+				Reason: "container web is running, but its readiness probe fails",
+			},
+		}, health.Endpoints)
+		assert.Equal(t, []string{"NotReadyEndpoints"}, findingReasons(health.Findings))
+		assert.Equal(t, []string{"10.1.0.2 (pod web-2): container web is running, but its readiness probe fails"}, health.Findings[0].Evidence)
+		require.Len(t, health.Pods, 2)
+		assert.True(t, health.Pods[0].InEndpoints)
+	})
+
+	t.Run("numbered target port not declared by pods", func(t *testing.T) {
+		health, err := CheckEndpoints(context.Background(), clientset, "app", "web-numbered")
+		require.NoError(t, err)
+		assert.True(t, health.Healthy)
+		assert.Equal(t, []string{"TargetPortMismatch", "NotReadyEndpoints"}, findingReasons(health.Findings))
+		assert.Equal(t, pod.SeverityWarning, health.Findings[0].Severity)
+		assert.Equal(t, []string{"pod web-1 declares http:8080/TCP", "pod web-2 declares http:8080/TCP"}, health.Findings[0].Evidence)
+	})
+
+	t.Run("selector matching no pods", func(t *testing.T) {
+		health, err := CheckEndpoints(context.Background(), clientset, "app", "api")
+		require.NoError(t, err)
+		assert.False(t, health.Healthy)
+		assert.Empty(t, health.Pods)
+		assert.Equal(t, []pod.Finding{
+			{
+				Severity: pod.SeverityError,
+				Reason:   "SelectorMismatch",
+				Message:  "selector app=api,tier=backend of the service matches no pods in namespace app, but some pods have similar labels",
+				Evidence: []string{"pod api-1 has tier=backend-v2 instead of backend"},
+			},
+			{
+				Severity: pod.SeverityError,
+				Reason:   "NoEndpoints",
+				Message:  "service has no endpoints, because no pods match its selector",
+			},
+		}, health.Findings)
+	})
+
+	t.Run("named target port missing and no ready endpoints", func(t *testing.T) {
+		health, err := CheckEndpoints(context.Background(), clientset, "app", "api-not-ready")
+		require.NoError(t, err)
+		assert.False(t, health.Healthy)
+		assert.Equal(t, []string{"TargetPortMismatch", "NoReadyEndpoints"}, findingReasons(health.Findings))
+		assert.Equal(t,
+			"port http:80/TCP of the service targets named port metrics, which is not declared by 1 of 1 selected pods, so they get no endpoints for it",
+			health.Findings[0].Message,
+		)
+	})
+
+	t.Run("service without selector and endpoints", func(t *testing.T) {
+		health, err := CheckEndpoints(context.Background(), clientset, "app", "external")
+		require.NoError(t, err)
+		assert.False(t, health.Healthy)
+		require.Len(t, health.Findings, 1)
+		assert.Equal(t,
+			"service has no selector and no endpoints, EndpointSlices labeled kubernetes.io/service-name=external have to be created for it manually",
+			health.Findings[0].Message,
+		)
+	})
+}
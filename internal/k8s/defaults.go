@@ -0,0 +1,95 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/strowk/foxy-contexts/pkg/session"
+)
+
+// SessionDefaults holds values that tools should use within
+// one MCP session when they are not given explicitly in the call.
+type SessionDefaults struct {
+	Context   string `json:"context,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+}
+
+func (d *SessionDefaults) String() string {
+	data, err := json.Marshal(d)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// Defaults resolves values which are not explicitly given by the client,
+// such as context and namespace, taking into account overrides stored in
+// the MCP session and configuration of the Kubernetes context from kubeconfig.
+type Defaults interface {
+	// ResolveContext returns k8sContext if it is not empty, otherwise
+	// the context set for the current session, which can also be empty,
+	// meaning that the current context from kubeconfig should be used.
+	ResolveContext(ctx context.Context, k8sContext string) string
+
+	// ResolveNamespace returns namespace if it is not empty, otherwise
+	// the namespace set for the current session if it was set for the same
+	// context, and if that is not set either, then the namespace configured
+	// for the context in kubeconfig, which falls back to "default".
+	ResolveNamespace(ctx context.Context, k8sContext string, namespace string) (string, error)
+
+	// GetSessionDefaults returns defaults stored in the current session.
+	GetSessionDefaults(ctx context.Context) SessionDefaults
+
+	// SetSessionDefaults stores defaults in the current session.
+	SetSessionDefaults(ctx context.Context, defaults SessionDefaults)
+}
+
+type defaults struct {
+	sessionManager *session.SessionManager
+}
+
+func NewDefaults(sessionManager *session.SessionManager) Defaults {
+	return &defaults{
+		sessionManager: sessionManager,
+	}
+}
+
+func (d *defaults) ResolveContext(ctx context.Context, k8sContext string) string {
+	if k8sContext != "" {
+		return k8sContext
+	}
+	return d.GetSessionDefaults(ctx).Context
+}
+
+func (d *defaults) ResolveNamespace(ctx context.Context, k8sContext string, namespace string) (string, error) {
+	if namespace != "" {
+		return namespace, nil
+	}
+
+	sessionDefaults := d.GetSessionDefaults(ctx)
+	if sessionDefaults.Namespace != "" &&
+		// namespace chosen for the session context should not leak
+		// into calls that explicitly target some other context
+		(sessionDefaults.Context == "" || sessionDefaults.Context == k8sContext) {
+		return sessionDefaults.Namespace, nil
+	}
+
+	return GetContextNamespace(k8sContext)
+}
+
+func (d *defaults) GetSessionDefaults(ctx context.Context) SessionDefaults {
+	if d.sessionManager == nil {
+		return SessionDefaults{}
+	}
+	if sessionDefaults, ok := d.sessionManager.GetSessionData(ctx).(*SessionDefaults); ok && sessionDefaults != nil {
+		return *sessionDefaults
+	}
+	return SessionDefaults{}
+}
+
+func (d *defaults) SetSessionDefaults(ctx context.Context, defaults SessionDefaults) {
+	if d.sessionManager == nil {
+		return
+	}
+	d.sessionManager.SetSessionData(ctx, &defaults)
+}
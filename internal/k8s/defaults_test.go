@@ -0,0 +1,123 @@
+package k8s
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/strowk/foxy-contexts/pkg/session"
+)
+
+const testKubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+- cluster:
+    server: https://127.0.0.1:6443
+  name: test-cluster
+users:
+- name: test-user
+contexts:
+- context:
+    cluster: test-cluster
+    user: test-user
+    namespace: team-a
+  name: with-namespace
+- context:
+    cluster: test-cluster
+    user: test-user
+  name: without-namespace
+current-context: with-namespace
+`
+
+func TestResolveNamespace(t *testing.T) {
+	kubeconfigPath := filepath.Join(t.TempDir(), "kubeconfig")
+	require.NoError(t, os.WriteFile(kubeconfigPath, []byte(testKubeconfig), 0o600))
+	t.Setenv("KUBECONFIG", kubeconfigPath)
+
+	sessionManager := session.NewSessionManager()
+	defaults := NewDefaults(sessionManager)
+	ctx, _, err := sessionManager.CreateNewSession(context.Background(), nil)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name             string
+		sessionContext   string
+		sessionNamespace string
+		k8sContext       string
+		namespace        string
+		expected         string
+	}{
+		{
+			name:      "explicit namespace wins",
+			namespace: "explicit",
+			expected:  "explicit",
+		},
+		{
+			name:     "namespace of current context",
+			expected: "team-a",
+		},
+		{
+			name:       "namespace of explicit context",
+			k8sContext: "with-namespace",
+			expected:   "team-a",
+		},
+		{
+			name:       "context without namespace falls back to default",
+			k8sContext: "without-namespace",
+			expected:   "default",
+		},
+		{
+			name:             "session override wins over context",
+			sessionNamespace: "from-session",
+			k8sContext:       "with-namespace",
+			expected:         "from-session",
+		},
+		{
+			name:             "session override for session context",
+			sessionContext:   "without-namespace",
+			sessionNamespace: "from-session",
+			k8sContext:       "without-namespace",
+			expected:         "from-session",
+		},
+		{
+			name:             "session override is ignored for other context",
+			sessionContext:   "without-namespace",
+			sessionNamespace: "from-session",
+			k8sContext:       "with-namespace",
+			expected:         "team-a",
+		},
+		{
+			name:             "explicit namespace wins over session override",
+			sessionNamespace: "from-session",
+			namespace:        "explicit",
+			expected:         "explicit",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sessionManager.SetSessionData(ctx, &SessionDefaults{Context: tt.sessionContext, Namespace: tt.sessionNamespace})
+
+			namespace, err := defaults.ResolveNamespace(ctx, tt.k8sContext, tt.namespace)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, namespace)
+		})
+	}
+}
+
+func TestResolveContext(t *testing.T) {
+	sessionManager := session.NewSessionManager()
+	defaults := NewDefaults(sessionManager)
+	ctx, _, err := sessionManager.CreateNewSession(context.Background(), nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, "", defaults.ResolveContext(ctx, ""))
+	assert.Equal(t, "explicit", defaults.ResolveContext(ctx, "explicit"))
+
+	defaults.SetSessionDefaults(ctx, SessionDefaults{Context: "from-session"})
+	assert.Equal(t, "from-session", defaults.ResolveContext(ctx, ""))
+	assert.Equal(t, "explicit", defaults.ResolveContext(ctx, "explicit"))
+}
@@ -0,0 +1,446 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/strowk/mcp-k8s-go/internal/k8s"
+	"github.com/strowk/mcp-k8s-go/internal/k8s/core/v1/pod"
+	"github.com/strowk/mcp-k8s-go/internal/utils"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/discovery"
+)
+
+// maxWarningEvents limits number of most recent warning events in report
+const maxWarningEvents = 20
+
+type Options struct {
+	// WarningEventsSince is how far back warning events are reported
+	WarningEventsSince time.Duration
+}
+
+// Report is a compact overview of cluster health, listing only
+// things that need attention together with overall counts
+type Report struct {
+	Healthy                bool                    `json:"healthy"`
+	Summary                string                  `json:"summary"`
+	APIServer              APIServer               `json:"apiServer"`
+	Nodes                  NodeHealth              `json:"nodes"`
+	Pods                   PodHealth               `json:"pods"`
+	FailedJobs             []FailedJob             `json:"failedJobs,omitempty"`
+	UnavailableDeployments []UnavailableDeployment `json:"unavailableDeployments,omitempty"`
+	WarningEvents          WarningEvents           `json:"warningEvents"`
+}
+
+type APIServer struct {
+	Version  string `json:"version,omitempty"`
+	Platform string `json:"platform,omitempty"`
+	Ready    bool   `json:"ready"`
+	Message  string `json:"message,omitempty"`
+}
+
+type NodeHealth struct {
+	Total    int           `json:"total"`
+	Ready    int           `json:"ready"`
+	Problems []NodeProblem `json:"problems,omitempty"`
+}
+
+type NodeProblem struct {
+	Name     string   `json:"name"`
+	Problems []string `json:"problems"`
+}
+
+type PodHealth struct {
+	Total        int             `json:"total"`
+	CrashLooping int             `json:"crashLooping"`
+	Pending      int             `json:"pending"`
+	Evicted      int             `json:"evicted"`
+	Namespaces   []NamespacePods `json:"namespaces,omitempty"`
+}
+
+// NamespacePods groups problematic pods of one namespace
+type NamespacePods struct {
+	Namespace    string       `json:"namespace"`
+	CrashLooping []PodProblem `json:"crashLooping,omitempty"`
+	Pending      []PodProblem `json:"pending,omitempty"`
+	Evicted      []PodProblem `json:"evicted,omitempty"`
+}
+
+type PodProblem struct {
+	Name    string `json:"name"`
+	Message string `json:"message,omitempty"`
+}
+
+type FailedJob struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Reason    string `json:"reason,omitempty"`
+	Message   string `json:"message,omitempty"`
+}
+
+type UnavailableDeployment struct {
+	Namespace   string `json:"namespace"`
+	Name        string `json:"name"`
+	Desired     int32  `json:"desired"`
+	Available   int32  `json:"available"`
+	Unavailable int32  `json:"unavailable"`
+}
+
+// WarningEvents has total number of warning events seen since given time
+// and the most recent of them
+type WarningEvents struct {
+	Since  string         `json:"since"`
+	Total  int            `json:"total"`
+	Recent []WarningEvent `json:"recent,omitempty"`
+}
+
+type WarningEvent struct {
+	Namespace string `json:"namespace,omitempty"`
+	Object    string `json:"object"`
+	Reason    string `json:"reason"`
+	Message   string `json:"message"`
+	Count     int32  `json:"count,omitempty"`
+	LastSeen  string `json:"lastSeen"`
+
+	lastSeen time.Time
+}
+
+// Check builds health report of the cluster from informers of the pool,
+// so repeated checks are served from cache instead of listing everything
+func Check(ctx context.Context, pool k8s.ClientPool, k8sCtx string, options Options) (*Report, error) {
+	report := &Report{}
+
+	clientset, err := pool.GetClientset(k8sCtx)
+	if err != nil {
+		return nil, err
+	}
+	report.checkAPIServer(ctx, clientset.Discovery())
+
+	nodes, err := listCached[corev1.Node](pool, k8sCtx, "Node", "", "v1")
+	if err != nil {
+		return nil, err
+	}
+	report.checkNodes(nodes)
+
+	pods, err := listCached[corev1.Pod](pool, k8sCtx, "Pod", "", "v1")
+	if err != nil {
+		return nil, err
+	}
+	report.checkPods(pods)
+
+	jobs, err := listCached[batchv1.Job](pool, k8sCtx, "Job", "batch", "v1")
+	if err != nil {
+		return nil, err
+	}
+	report.checkJobs(jobs)
+
+	deployments, err := listCached[appsv1.Deployment](pool, k8sCtx, "Deployment", "apps", "v1")
+	if err != nil {
+		return nil, err
+	}
+	report.checkDeployments(deployments)
+
+	events, err := listCached[corev1.Event](pool, k8sCtx, "Event", "", "v1")
+	if err != nil {
+		return nil, err
+	}
+	report.checkEvents(events, time.Now().Add(-options.WarningEventsSince))
+
+	report.summarize()
+	return report, nil
+}
+
+// listCached lists all objects of the kind from informer and converts them to typed objects
+func listCached[T any](pool k8s.ClientPool, k8sCtx string, kind string, group string, version string) ([]*T, error) {
+	informer, err := pool.GetInformer(k8sCtx, kind, group, version)
+	if err != nil {
+		return nil, err
+	}
+	objects, err := informer.Lister().List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*T, 0, len(objects))
+	for _, object := range objects {
+		u, ok := object.(runtime.Unstructured)
+		if !ok {
+			return nil, fmt.Errorf("unexpected object %T in informer of %s", object, kind)
+		}
+		typed := new(T)
+		err := runtime.DefaultUnstructuredConverter.FromUnstructuredWithValidation(u.UnstructuredContent(), typed, false)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, typed)
+	}
+	return result, nil
+}
+
+func (r *Report) checkAPIServer(ctx context.Context, d discovery.DiscoveryInterface) {
+	version, err := d.ServerVersion()
+	if err != nil {
+		r.APIServer.Message = fmt.Sprintf("failed to get version: %v", err)
+		return
+	}
+	r.APIServer.Version = version.GitVersion
+	r.APIServer.Platform = version.Platform
+	r.APIServer.Ready = true
+
+	restClient := d.RESTClient()
+	if restClient == nil {
+		return
+	}
+	body, err := restClient.Get().AbsPath("/readyz").DoRaw(ctx)
+	if err != nil {
+		r.APIServer.Ready = false
+		r.APIServer.Message = strings.TrimSpace(utils.FirstNonEmpty(string(body), err.Error()))
+	}
+}
+
+func (r *Report) checkNodes(nodes []*corev1.Node) {
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].Name < nodes[j].Name
+	})
+
+	r.Nodes.Total = len(nodes)
+	for _, node := range nodes {
+		var problems []string
+		ready, hasReady := false, false
+		for _, condition := range node.Status.Conditions {
+			switch condition.Type {
+			case corev1.NodeReady:
+				hasReady = true
+				ready = condition.Status == corev1.ConditionTrue
+				if !ready {
+					problems = append(problems, formatCondition("NotReady", condition))
+				}
+			case corev1.NodeMemoryPressure, corev1.NodeDiskPressure, corev1.NodePIDPressure, corev1.NodeNetworkUnavailable:
+				if condition.Status == corev1.ConditionTrue {
+					problems = append(problems, formatCondition(string(condition.Type), condition))
+				}
+			}
+		}
+		if ready {
+			r.Nodes.Ready++
+		} else if !hasReady {
+			problems = append(problems, "NotReady: node has no Ready condition")
+		}
+		if node.Spec.Unschedulable {
+			problems = append(problems, "Unschedulable: node is cordoned")
+		}
+		if len(problems) > 0 {
+			r.Nodes.Problems = append(r.Nodes.Problems, NodeProblem{Name: node.Name, Problems: problems})
+		}
+	}
+}
+
+func (r *Report) checkPods(pods []*corev1.Pod) {
+	r.Pods.Total = len(pods)
+	byNamespace := map[string]*NamespacePods{}
+	group := func(namespace string) *NamespacePods {
+		if byNamespace[namespace] == nil {
+			byNamespace[namespace] = &NamespacePods{Namespace: namespace}
+		}
+		return byNamespace[namespace]
+	}
+
+	for _, pod := range pods {
+		switch {
+		case pod.Status.Phase == corev1.PodFailed && pod.Status.Reason == "Evicted":
+			r.Pods.Evicted++
+			namespacePods := group(pod.Namespace)
+			namespacePods.Evicted = append(namespacePods.Evicted, PodProblem{Name: pod.Name, Message: pod.Status.Message})
+		case crashLoopMessage(pod) != "":
+			r.Pods.CrashLooping++
+			namespacePods := group(pod.Namespace)
+			namespacePods.CrashLooping = append(namespacePods.CrashLooping, PodProblem{Name: pod.Name, Message: crashLoopMessage(pod)})
+		case pod.Status.Phase == corev1.PodPending:
+			r.Pods.Pending++
+			namespacePods := group(pod.Namespace)
+			namespacePods.Pending = append(namespacePods.Pending, PodProblem{Name: pod.Name, Message: pendingMessage(pod)})
+		}
+	}
+
+	for _, namespacePods := range byNamespace {
+		for _, problems := range [][]PodProblem{namespacePods.CrashLooping, namespacePods.Pending, namespacePods.Evicted} {
+			sort.Slice(problems, func(i, j int) bool {
+				return problems[i].Name < problems[j].Name
+			})
+		}
+		r.Pods.Namespaces = append(r.Pods.Namespaces, *namespacePods)
+	}
+	sort.Slice(r.Pods.Namespaces, func(i, j int) bool {
+		return r.Pods.Namespaces[i].Namespace < r.Pods.Namespaces[j].Namespace
+	})
+}
+
+// crashLoopMessage describes container of the pod, which is in CrashLoopBackOff,
+// or returns empty string if there is no such container
+func crashLoopMessage(pod *corev1.Pod) string {
+	statuses := append(append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+	for _, status := range statuses {
+		if status.State.Waiting == nil || status.State.Waiting.Reason != "CrashLoopBackOff" {
+			continue
+		}
+		message := fmt.Sprintf("container %s restarted %d times", status.Name, status.RestartCount)
+		if terminated := status.LastTerminationState.Terminated; terminated != nil {
+			message += fmt.Sprintf(", last exit code %d", terminated.ExitCode)
+			if terminated.Reason != "" {
+				message += " (" + terminated.Reason + ")"
+			}
+		}
+		return message
+	}
+	return ""
+}
+
+// pendingMessage explains why the pod is pending, using reason of waiting
+// container if pod is scheduled or scheduler message otherwise
+func pendingMessage(pod *corev1.Pod) string {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodScheduled && condition.Status != corev1.ConditionTrue {
+			return utils.FirstNonEmpty(condition.Message, condition.Reason, "not scheduled")
+		}
+	}
+	statuses := append(append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+	for _, status := range statuses {
+		if waiting := status.State.Waiting; waiting != nil && waiting.Reason != "" {
+			message := fmt.Sprintf("container %s is waiting: %s", status.Name, waiting.Reason)
+			if waiting.Message != "" {
+				message += ": " + waiting.Message
+			}
+			return message
+		}
+	}
+	return ""
+}
+
+func (r *Report) checkJobs(jobs []*batchv1.Job) {
+	for _, job := range jobs {
+		for _, condition := range job.Status.Conditions {
+			if condition.Type == batchv1.JobFailed && condition.Status == corev1.ConditionTrue {
+				r.FailedJobs = append(r.FailedJobs, FailedJob{
+					Namespace: job.Namespace,
+					Name:      job.Name,
+					Reason:    condition.Reason,
+					Message:   condition.Message,
+				})
+				break
+			}
+		}
+	}
+	sort.Slice(r.FailedJobs, func(i, j int) bool {
+		return r.FailedJobs[i].Namespace+"/"+r.FailedJobs[i].Name < r.FailedJobs[j].Namespace+"/"+r.FailedJobs[j].Name
+	})
+}
+
+func (r *Report) checkDeployments(deployments []*appsv1.Deployment) {
+	for _, deployment := range deployments {
+		desired := int32(1)
+		if deployment.Spec.Replicas != nil {
+			desired = *deployment.Spec.Replicas
+		}
+		unavailable := max(desired-deployment.Status.AvailableReplicas, deployment.Status.UnavailableReplicas)
+		if unavailable <= 0 {
+			continue
+		}
+		r.UnavailableDeployments = append(r.UnavailableDeployments, UnavailableDeployment{
+			Namespace:   deployment.Namespace,
+			Name:        deployment.Name,
+			Desired:     desired,
+			Available:   deployment.Status.AvailableReplicas,
+			Unavailable: unavailable,
+		})
+	}
+	sort.Slice(r.UnavailableDeployments, func(i, j int) bool {
+		a, b := r.UnavailableDeployments[i], r.UnavailableDeployments[j]
+		return a.Namespace+"/"+a.Name < b.Namespace+"/"+b.Name
+	})
+}
+
+func (r *Report) checkEvents(events []*corev1.Event, since time.Time) {
+	r.WarningEvents.Since = since.UTC().Format(time.RFC3339)
+
+	var warnings []WarningEvent
+	for _, event := range events {
+		lastSeen := pod.EventTime(*event)
+		if event.Type != corev1.EventTypeWarning || lastSeen.Before(since) {
+			continue
+		}
+		warnings = append(warnings, WarningEvent{
+			Namespace: event.Namespace,
+			Object:    event.InvolvedObject.Kind + "/" + event.InvolvedObject.Name,
+			Reason:    event.Reason,
+			Message:   strings.TrimSpace(event.Message),
+			Count:     event.Count,
+			LastSeen:  lastSeen.UTC().Format(time.RFC3339),
+			lastSeen:  lastSeen,
+		})
+	}
+
+	sort.SliceStable(warnings, func(i, j int) bool {
+		return warnings[i].lastSeen.After(warnings[j].lastSeen)
+	})
+	r.WarningEvents.Total = len(warnings)
+	if len(warnings) > maxWarningEvents {
+		warnings = warnings[:maxWarningEvents]
+	}
+	r.WarningEvents.Recent = warnings
+}
+
+func (r *Report) summarize() {
+	var problems []string
+	if !r.APIServer.Ready {
+		problems = append(problems, "API server is not ready")
+	}
+	if notReady := r.Nodes.Total - r.Nodes.Ready; notReady > 0 {
+		problems = append(problems, fmt.Sprintf("%d of %d nodes not ready", notReady, r.Nodes.Total))
+	}
+	if withProblems := len(r.Nodes.Problems) - (r.Nodes.Total - r.Nodes.Ready); withProblems > 0 {
+		problems = append(problems, fmt.Sprintf("%d ready nodes under pressure or cordoned", withProblems))
+	}
+	if r.Pods.CrashLooping > 0 {
+		problems = append(problems, fmt.Sprintf("%d crash-looping pods", r.Pods.CrashLooping))
+	}
+	if r.Pods.Pending > 0 {
+		problems = append(problems, fmt.Sprintf("%d pending pods", r.Pods.Pending))
+	}
+	if r.Pods.Evicted > 0 {
+		problems = append(problems, fmt.Sprintf("%d evicted pods", r.Pods.Evicted))
+	}
+	if len(r.FailedJobs) > 0 {
+		problems = append(problems, fmt.Sprintf("%d failed jobs", len(r.FailedJobs)))
+	}
+	if len(r.UnavailableDeployments) > 0 {
+		problems = append(problems, fmt.Sprintf("%d deployments with unavailable replicas", len(r.UnavailableDeployments)))
+	}
+
+	r.Healthy = len(problems) == 0
+	if len(problems) == 0 {
+		r.Summary = "no problems found"
+	} else {
+		r.Summary = strings.Join(problems, ", ")
+	}
+	if r.WarningEvents.Total > 0 {
+		r.Summary += fmt.Sprintf(", %d warning events since %s", r.WarningEvents.Total, r.WarningEvents.Since)
+	}
+}
+
+func formatCondition(problem string, condition corev1.NodeCondition) string {
+	message := problem
+	if condition.Status != corev1.ConditionTrue && condition.Status != corev1.ConditionFalse {
+		message += fmt.Sprintf(" (status %s)", condition.Status)
+	}
+	if detail := utils.FirstNonEmpty(condition.Message, condition.Reason); detail != "" {
+		message += ": " + strings.TrimSpace(detail)
+	}
+	return message
+}
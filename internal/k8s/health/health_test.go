@@ -0,0 +1,212 @@
+package health
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	mock_k8s "github.com/strowk/mcp-k8s-go/internal/k8s/mock"
+	"github.com/strowk/mcp-k8s-go/internal/utils"
+	"go.uber.org/mock/gomock"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/version"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+func TestCheck(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+
+	objects := []runtime.Object{
+		&corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-a"},
+			Status: corev1.NodeStatus{Conditions: []corev1.NodeCondition{
+				{Type: corev1.NodeReady, Status: corev1.ConditionTrue},
+			}},
+		},
+		&corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-b"},
+			Spec:       corev1.NodeSpec{Unschedulable: true},
+			Status: corev1.NodeStatus{Conditions: []corev1.NodeCondition{
+				{Type: corev1.NodeReady, Status: corev1.ConditionUnknown, Message: "Kubelet stopped posting node status."},
+				{Type: corev1.NodeDiskPressure, Status: corev1.ConditionTrue, Reason: "KubeletHasDiskPressure"},
+			}},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "app"},
+			Status: corev1.PodStatus{
+				Phase: corev1.PodRunning,
+				ContainerStatuses: []corev1.ContainerStatus{{
+					Name:                 "web",
+					RestartCount:         5,
+					State:                corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+					LastTerminationState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 137, Reason: "OOMKilled"}},
+				}},
+			},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "worker", Namespace: "app"},
+			Status: corev1.PodStatus{
+				Phase: corev1.PodPending,
+				Conditions: []corev1.PodCondition{{
+					Type: corev1.PodScheduled, Status: corev1.ConditionFalse, Reason: "Unschedulable",
+					Message: "0/2 nodes are available: 2 Insufficient cpu.",
+				}},
+			},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "puller", Namespace: "app"},
+			Status: corev1.PodStatus{
+				Phase: corev1.PodPending,
+				ContainerStatuses: []corev1.ContainerStatus{{
+					Name:  "puller",
+					State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ImagePullBackOff", Message: "Back-off pulling image"}},
+				}},
+			},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "evicted", Namespace: "batch"},
+			Status: corev1.PodStatus{
+				Phase: corev1.PodFailed, Reason: "Evicted",
+				Message: "The node was low on resource: memory.",
+			},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "healthy", Namespace: "app"},
+			Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+		},
+		&batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{Name: "migrate", Namespace: "batch"},
+			Status: batchv1.JobStatus{Conditions: []batchv1.JobCondition{{
+				Type: batchv1.JobFailed, Status: corev1.ConditionTrue,
+				Reason: "BackoffLimitExceeded", Message: "Job has reached the specified backoff limit",
+			}}},
+		},
+		&batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{Name: "done", Namespace: "batch"},
+			Status: batchv1.JobStatus{Conditions: []batchv1.JobCondition{{
+				Type: batchv1.JobComplete, Status: corev1.ConditionTrue,
+			}}},
+		},
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "app"},
+			Spec:       appsv1.DeploymentSpec{Replicas: utils.Ptr(int32(3))},
+			Status:     appsv1.DeploymentStatus{AvailableReplicas: 1, UnavailableReplicas: 2},
+		},
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "app"},
+			Spec:       appsv1.DeploymentSpec{Replicas: utils.Ptr(int32(2))},
+			Status:     appsv1.DeploymentStatus{AvailableReplicas: 2},
+		},
+		&corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: "backoff", Namespace: "app"},
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "web"},
+			Type:           corev1.EventTypeWarning,
+			Reason:         "BackOff",
+			Message:        "Back-off restarting failed container",
+			Count:          4,
+			LastTimestamp:  metav1.NewTime(now.Add(-time.Minute)),
+		},
+		&corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: "old", Namespace: "app"},
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "web"},
+			Type:           corev1.EventTypeWarning,
+			Reason:         "Unhealthy",
+			LastTimestamp:  metav1.NewTime(now.Add(-time.Hour)),
+		},
+		&corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: "normal", Namespace: "app"},
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "web"},
+			Type:           corev1.EventTypeNormal,
+			Reason:         "Pulled",
+			LastTimestamp:  metav1.NewTime(now),
+		},
+	}
+
+	clientset := fake.NewClientset()
+	clientset.Discovery().(*fakediscovery.FakeDiscovery).FakedServerVersion = &version.Info{
+		GitVersion: "v1.31.2", Platform: "linux/amd64",
+	}
+
+	dynamicClient := dynamicfake.NewSimpleDynamicClient(scheme.Scheme, objects...)
+	factory := dynamicinformer.NewDynamicSharedInformerFactory(dynamicClient, 0)
+	resources := map[string]schema.GroupVersionResource{
+		"Node":       {Version: "v1", Resource: "nodes"},
+		"Pod":        {Version: "v1", Resource: "pods"},
+		"Event":      {Version: "v1", Resource: "events"},
+		"Job":        {Group: "batch", Version: "v1", Resource: "jobs"},
+		"Deployment": {Group: "apps", Version: "v1", Resource: "deployments"},
+	}
+
+	ctrl := gomock.NewController(t)
+	pool := mock_k8s.NewMockClientPool(ctrl)
+	pool.EXPECT().GetClientset("test").Return(clientset, nil)
+	for kind, gvr := range resources {
+		informer := factory.ForResource(gvr)
+		pool.EXPECT().GetInformer("test", kind, gvr.Group, gvr.Version).Return(informer, nil)
+	}
+	stop := make(chan struct{})
+	defer close(stop)
+	factory.Start(stop)
+	factory.WaitForCacheSync(stop)
+
+	report, err := Check(context.Background(), pool, "test", Options{WarningEventsSince: 15 * time.Minute})
+	require.NoError(t, err)
+
+	assert.False(t, report.Healthy)
+	assert.Equal(t, APIServer{Version: "v1.31.2", Platform: "linux/amd64", Ready: true}, report.APIServer)
+	assert.Equal(t, NodeHealth{
+		Total: 2,
+		Ready: 1,
+		Problems: []NodeProblem{{Name: "node-b", Problems: []string{
+			"NotReady (status Unknown): Kubelet stopped posting node status.",
+			"DiskPressure: KubeletHasDiskPressure",
+			"Unschedulable: node is cordoned",
+		}}},
+	}, report.Nodes)
+	assert.Equal(t, PodHealth{
+		Total:        5,
+		CrashLooping: 1,
+		Pending:      2,
+		Evicted:      1,
+		Namespaces: []NamespacePods{
+			{
+				Namespace:    "app",
+				CrashLooping: []PodProblem{{Name: "web", Message: "container web restarted 5 times, last exit code 137 (OOMKilled)"}},
+				Pending: []PodProblem{
+					{Name: "puller", Message: "container puller is waiting: ImagePullBackOff: Back-off pulling image"},
+					{Name: "worker", Message: "0/2 nodes are available: 2 Insufficient cpu."},
+				},
+			},
+			{
+				Namespace: "batch",
+				Evicted:   []PodProblem{{Name: "evicted", Message: "The node was low on resource: memory."}},
+			},
+		},
+	}, report.Pods)
+	assert.Equal(t, []FailedJob{{
+		Namespace: "batch", Name: "migrate", Reason: "BackoffLimitExceeded", Message: "Job has reached the specified backoff limit",
+	}}, report.FailedJobs)
+	assert.Equal(t, []UnavailableDeployment{{
+		Namespace: "app", Name: "web", Desired: 3, Available: 1, Unavailable: 2,
+	}}, report.UnavailableDeployments)
+
+	assert.Equal(t, 1, report.WarningEvents.Total)
+	require.Len(t, report.WarningEvents.Recent, 1)
+	assert.Equal(t, "Pod/web", report.WarningEvents.Recent[0].Object)
+	assert.Equal(t, "BackOff", report.WarningEvents.Recent[0].Reason)
+	assert.Equal(t, now.Add(-time.Minute).Format(time.RFC3339), report.WarningEvents.Recent[0].LastSeen)
+
+	assert.Equal(t, "1 of 2 nodes not ready, 1 crash-looping pods, 2 pending pods, 1 evicted pods, "+
+		"1 failed jobs, 1 deployments with unavailable replicas, 1 warning events since "+report.WarningEvents.Since, report.Summary)
+}
@@ -0,0 +1,36 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
+	metricsclientset "k8s.io/metrics/pkg/client/clientset/versioned"
+)
+
+// ListPodMetrics lists usage of pods in the namespace, or in all namespaces if it is empty
+func ListPodMetrics(ctx context.Context, client metricsclientset.Interface, namespace string, labelSelector string) ([]metricsv1beta1.PodMetrics, error) {
+	list, err := client.MetricsV1beta1().PodMetricses(namespace).List(ctx, metav1.ListOptions{LabelSelector: labelSelector})
+	if err != nil {
+		return nil, metricsError(err)
+	}
+	return list.Items, nil
+}
+
+// ListNodeMetrics lists usage of nodes
+func ListNodeMetrics(ctx context.Context, client metricsclientset.Interface, labelSelector string) ([]metricsv1beta1.NodeMetrics, error) {
+	list, err := client.MetricsV1beta1().NodeMetricses().List(ctx, metav1.ListOptions{LabelSelector: labelSelector})
+	if err != nil {
+		return nil, metricsError(err)
+	}
+	return list.Items, nil
+}
+
+func metricsError(err error) error {
+	if apierrors.IsNotFound(err) || apierrors.IsServiceUnavailable(err) {
+		return fmt.Errorf("metrics API is not available, make sure metrics-server is installed and running: %w", err)
+	}
+	return err
+}
@@ -0,0 +1,333 @@
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/strowk/mcp-k8s-go/internal/k8s/core/v1/pod"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	metricsclientset "k8s.io/metrics/pkg/client/clientset/versioned"
+)
+
+// Defaults of recommendations, CPU request follows percentile of usage,
+// so that short spikes are absorbed by bursting, while memory follows
+// maximum usage, as running out of memory kills the container
+const (
+	DefaultCPUPercentile = 0.95
+	DefaultHeadroom      = 0.2
+)
+
+// Statuses of containers compared with recommendations
+const (
+	StatusRightSized       = "right-sized"
+	StatusOverProvisioned  = "over-provisioned"
+	StatusUnderProvisioned = "under-provisioned"
+	StatusNoSamples        = "no-samples"
+)
+
+const (
+	// overProvisionedFactor is how many times request has to exceed
+	// recommendation for container to be flagged as over-provisioned
+	overProvisionedFactor = 2
+	// minSamples is number of samples below which recommendations are not reliable
+	minSamples = 10
+
+	minMilliCPU = 10
+	minMemory   = 16 * 1024 * 1024
+	mebibyte    = 1024 * 1024
+)
+
+type RecommendOptions struct {
+	// Kind of workload: Deployment, StatefulSet or DaemonSet
+	Kind      string
+	Namespace string
+	Name      string
+	// CPUPercentile of CPU usage samples recommended as CPU request
+	CPUPercentile float64
+	// Headroom is fraction added on top of maximum usage for limits and memory request
+	Headroom float64
+}
+
+// Recommendation holds requests and limits recommended for containers of the workload
+// based on usage sampled from its pods, together with strategic merge patch applying them
+type Recommendation struct {
+	Kind       string                    `json:"kind"`
+	Namespace  string                    `json:"namespace"`
+	Name       string                    `json:"name"`
+	Pods       int                       `json:"pods"`
+	Window     string                    `json:"window"`
+	Containers []ContainerRecommendation `json:"containers"`
+	// Patch is strategic merge patch for the workload, only set when some container needs changes
+	Patch     string   `json:"patch,omitempty"`
+	PatchType string   `json:"patchType,omitempty"`
+	Warnings  []string `json:"warnings,omitempty"`
+}
+
+type ContainerRecommendation struct {
+	Name        string        `json:"name"`
+	Status      string        `json:"status"`
+	Samples     int           `json:"samples"`
+	Usage       *SampledUsage `json:"usage,omitempty"`
+	Current     Resources     `json:"current"`
+	Recommended *Resources    `json:"recommended,omitempty"`
+	Reasons     []string      `json:"reasons,omitempty"`
+}
+
+// SampledUsage summarizes usage samples of the container across all pods of the workload
+type SampledUsage struct {
+	CPUPercentile string `json:"cpuPercentile"`
+	CPUMax        string `json:"cpuMax"`
+	MemoryMax     string `json:"memoryMax"`
+}
+
+type Resources struct {
+	CPURequest    string `json:"cpuRequest,omitempty"`
+	CPULimit      string `json:"cpuLimit,omitempty"`
+	MemoryRequest string `json:"memoryRequest,omitempty"`
+	MemoryLimit   string `json:"memoryLimit,omitempty"`
+}
+
+// Recommend collects current usage of pods in the namespace into the sampler
+// and recommends requests and limits for containers of the workload from
+// all samples kept for its pods
+func Recommend(
+	ctx context.Context,
+	client metricsclientset.Interface,
+	clientset kubernetes.Interface,
+	sampler *Sampler,
+	k8sCtx string,
+	options RecommendOptions,
+) (*Recommendation, error) {
+	percentile := options.CPUPercentile
+	if percentile == 0 {
+		percentile = DefaultCPUPercentile
+	}
+	if percentile <= 0 || percentile > 1 {
+		return nil, fmt.Errorf("invalid CPU percentile: %v, expected number above 0 and up to 1", percentile)
+	}
+	headroom := options.Headroom
+	if headroom < 0 {
+		return nil, fmt.Errorf("invalid headroom: %v, expected non-negative number", headroom)
+	}
+
+	kind, template, selector, err := getWorkloadTemplate(ctx, clientset, options.Kind, options.Namespace, options.Name)
+	if err != nil {
+		return nil, err
+	}
+	labelSelector, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		return nil, err
+	}
+	pods, err := clientset.CoreV1().Pods(options.Namespace).List(ctx, metav1.ListOptions{LabelSelector: labelSelector.String()})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := sampler.Collect(ctx, k8sCtx, client, options.Namespace); err != nil {
+		return nil, err
+	}
+
+	recommendation := &Recommendation{
+		Kind:       kind,
+		Namespace:  options.Namespace,
+		Name:       options.Name,
+		Pods:       len(pods.Items),
+		Containers: []ContainerRecommendation{},
+	}
+	var oldest, newest time.Time
+	patchContainers := []map[string]any{}
+	for _, container := range template.Spec.Containers {
+		var samples []Sample
+		for _, pod := range pods.Items {
+			samples = append(samples, sampler.Samples(k8sCtx, pod.Namespace, pod.Name, container.Name)...)
+		}
+		for _, sample := range samples {
+			if oldest.IsZero() || sample.Timestamp.Before(oldest) {
+				oldest = sample.Timestamp
+			}
+			if sample.Timestamp.After(newest) {
+				newest = sample.Timestamp
+			}
+		}
+
+		containerRecommendation := recommendContainer(container, samples, percentile, headroom)
+		recommendation.Containers = append(recommendation.Containers, containerRecommendation)
+		if containerRecommendation.Status == StatusOverProvisioned || containerRecommendation.Status == StatusUnderProvisioned {
+			patchContainers = append(patchContainers, containerPatch(container.Name, containerRecommendation.Recommended))
+		}
+		if containerRecommendation.Samples > 0 && containerRecommendation.Samples < minSamples {
+			recommendation.Warnings = append(recommendation.Warnings, fmt.Sprintf(
+				"container %s has only %d samples, usage is sampled every %s and kept for %s, ask again later for more reliable recommendation",
+				container.Name, containerRecommendation.Samples, sampler.Interval(), sampler.Window(),
+			))
+		}
+	}
+	if !newest.IsZero() {
+		recommendation.Window = newest.Sub(oldest).Round(time.Second).String()
+	} else {
+		recommendation.Window = "0s"
+	}
+
+	if len(patchContainers) > 0 {
+		patch, err := json.Marshal(map[string]any{
+			"spec": map[string]any{
+				"template": map[string]any{
+					"spec": map[string]any{"containers": patchContainers},
+				},
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+		recommendation.Patch = string(patch)
+		recommendation.PatchType = "strategic"
+	}
+	return recommendation, nil
+}
+
+func recommendContainer(container corev1.Container, samples []Sample, percentile float64, headroom float64) ContainerRecommendation {
+	requests, limits := container.Resources.Requests, container.Resources.Limits
+	result := ContainerRecommendation{
+		Name:    container.Name,
+		Samples: len(samples),
+		Current: Resources{
+			CPURequest:    pod.QuantityString(requests, corev1.ResourceCPU),
+			CPULimit:      pod.QuantityString(limits, corev1.ResourceCPU),
+			MemoryRequest: pod.QuantityString(requests, corev1.ResourceMemory),
+			MemoryLimit:   pod.QuantityString(limits, corev1.ResourceMemory),
+		},
+	}
+	if len(samples) == 0 {
+		result.Status = StatusNoSamples
+		result.Reasons = []string{"no usage samples found for the container, make sure its pods are running and metrics-server reports them"}
+		return result
+	}
+
+	cpu := make([]int64, 0, len(samples))
+	var maxCPU, maxMemory int64
+	for _, sample := range samples {
+		cpu = append(cpu, sample.MilliCPU)
+		maxCPU = max(maxCPU, sample.MilliCPU)
+		maxMemory = max(maxMemory, sample.Memory)
+	}
+	percentileCPU := percentileOf(cpu, percentile)
+	result.Usage = &SampledUsage{
+		CPUPercentile: fmt.Sprintf("%dm", percentileCPU),
+		CPUMax:        fmt.Sprintf("%dm", maxCPU),
+		MemoryMax:     fmt.Sprintf("%dMi", ceilDiv(maxMemory, mebibyte)),
+	}
+
+	recommendedCPU := max(percentileCPU, minMilliCPU)
+	recommendedMemory := max(ceilDiv(int64(math.Ceil(float64(maxMemory)*(1+headroom))), mebibyte)*mebibyte, minMemory)
+	result.Recommended = &Resources{
+		CPURequest:    fmt.Sprintf("%dm", recommendedCPU),
+		MemoryRequest: fmt.Sprintf("%dMi", recommendedMemory/mebibyte),
+		MemoryLimit:   fmt.Sprintf("%dMi", recommendedMemory/mebibyte),
+	}
+	// CPU limit is only recommended when it is already set, as it
+	// throttles container even when node has idle CPU
+	if _, ok := limits[corev1.ResourceCPU]; ok {
+		result.Recommended.CPULimit = fmt.Sprintf("%dm", max(int64(math.Ceil(float64(maxCPU)*(1+headroom))), recommendedCPU))
+	}
+
+	var under, over []string
+	if request, ok := requests[corev1.ResourceCPU]; !ok || request.IsZero() {
+		under = append(under, "CPU request is not set")
+	} else if percentileCPU > request.MilliValue() {
+		under = append(under, fmt.Sprintf("p%d CPU usage %dm is above request %s", int(percentile*100), percentileCPU, request.String()))
+	} else if request.MilliValue() > overProvisionedFactor*recommendedCPU {
+		over = append(over, fmt.Sprintf("CPU request %s is more than %d times above recommended %dm", request.String(), overProvisionedFactor, recommendedCPU))
+	}
+	if limit, ok := limits[corev1.ResourceCPU]; ok && !limit.IsZero() && maxCPU >= limit.MilliValue() {
+		under = append(under, fmt.Sprintf("max CPU usage %dm reached limit %s, container is likely throttled", maxCPU, limit.String()))
+	}
+	if request, ok := requests[corev1.ResourceMemory]; !ok || request.IsZero() {
+		under = append(under, "memory request is not set")
+	} else if maxMemory > request.Value() {
+		under = append(under, fmt.Sprintf("max memory usage %s is above request %s", result.Usage.MemoryMax, request.String()))
+	} else if request.Value() > overProvisionedFactor*recommendedMemory {
+		over = append(over, fmt.Sprintf("memory request %s is more than %d times above recommended %s", request.String(), overProvisionedFactor, result.Recommended.MemoryRequest))
+	}
+	if limit, ok := limits[corev1.ResourceMemory]; ok && !limit.IsZero() && float64(maxMemory)*(1+headroom) > float64(limit.Value()) {
+		under = append(under, fmt.Sprintf("max memory usage %s leaves less than %d%% headroom to limit %s, container risks being OOM killed", result.Usage.MemoryMax, int(headroom*100), limit.String()))
+	}
+
+	// under-provisioning is reported first, as it risks throttling, eviction or OOM kills
+	result.Reasons = append(under, over...)
+	switch {
+	case len(under) > 0:
+		result.Status = StatusUnderProvisioned
+	case len(over) > 0:
+		result.Status = StatusOverProvisioned
+	default:
+		result.Status = StatusRightSized
+	}
+	return result
+}
+
+func containerPatch(name string, recommended *Resources) map[string]any {
+	requests := map[string]string{
+		string(corev1.ResourceCPU):    recommended.CPURequest,
+		string(corev1.ResourceMemory): recommended.MemoryRequest,
+	}
+	limits := map[string]string{
+		string(corev1.ResourceMemory): recommended.MemoryLimit,
+	}
+	if recommended.CPULimit != "" {
+		limits[string(corev1.ResourceCPU)] = recommended.CPULimit
+	}
+	return map[string]any{
+		"name": name,
+		"resources": map[string]any{
+			"requests": requests,
+			"limits":   limits,
+		},
+	}
+}
+
+// getWorkloadTemplate returns canonical kind, pod template and selector of the workload
+func getWorkloadTemplate(ctx context.Context, clientset kubernetes.Interface, kind, namespace, name string) (string, *corev1.PodTemplateSpec, *metav1.LabelSelector, error) {
+	apps := clientset.AppsV1()
+	switch strings.ToLower(kind) {
+	case "deployment", "deployments", "deploy":
+		deployment, err := apps.Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return "", nil, nil, err
+		}
+		return "Deployment", &deployment.Spec.Template, deployment.Spec.Selector, nil
+	case "statefulset", "statefulsets", "sts":
+		statefulSet, err := apps.StatefulSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return "", nil, nil, err
+		}
+		return "StatefulSet", &statefulSet.Spec.Template, statefulSet.Spec.Selector, nil
+	case "daemonset", "daemonsets", "ds":
+		daemonSet, err := apps.DaemonSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return "", nil, nil, err
+		}
+		return "DaemonSet", &daemonSet.Spec.Template, daemonSet.Spec.Selector, nil
+	}
+	return "", nil, nil, fmt.Errorf("recommendations are not supported for kind %s, expected one of Deployment, StatefulSet or DaemonSet", kind)
+}
+
+// percentileOf returns nearest-rank percentile of values
+func percentileOf(values []int64, percentile float64) int64 {
+	sorted := append([]int64{}, values...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i] < sorted[j]
+	})
+	rank := int(math.Ceil(percentile*float64(len(sorted)))) - 1
+	return sorted[max(rank, 0)]
+}
+
+func ceilDiv(value, divisor int64) int64 {
+	return (value + divisor - 1) / divisor
+}
@@ -0,0 +1,135 @@
+package metrics
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
+)
+
+func TestRecommend(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	labels := map[string]string{"app": "web"}
+	clientset := fake.NewClientset(
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Spec: appsv1.DeploymentSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: labels},
+				Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{
+					{Name: "app", Resources: corev1.ResourceRequirements{
+						Requests: usage("2", "1Gi"),
+						Limits:   corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("1Gi")},
+					}},
+					{Name: "proxy", Resources: corev1.ResourceRequirements{Requests: usage("50m", "32Mi"), Limits: usage("100m", "64Mi")}},
+				}}},
+			},
+		},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "default", Labels: labels}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-2", Namespace: "default", Labels: labels}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default"}},
+	)
+	client := newFakeMetricsClient(t,
+		&metricsv1beta1.PodMetrics{
+			ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "default"},
+			Timestamp:  metav1.NewTime(now),
+			Containers: []metricsv1beta1.ContainerMetrics{{Name: "proxy", Usage: usage("20m", "60Mi")}},
+		},
+		&metricsv1beta1.PodMetrics{
+			ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default"},
+			Timestamp:  metav1.NewTime(now),
+			Containers: []metricsv1beta1.ContainerMetrics{{Name: "app", Usage: usage("4", "4Gi")}},
+		},
+	)
+
+	sampler := NewSampler(nil)
+	sampler.now = func() time.Time { return now }
+	// sample older than window is dropped and does not affect recommendation
+	sampler.record(containerKey{"context", "default", "web-1", "app"}, Sample{
+		Timestamp: now.Add(-2 * time.Hour), MilliCPU: 5000, Memory: 5 * mebibyte * 1024,
+	})
+	for i := 1; i <= 20; i++ {
+		pod := "web-1"
+		if i%2 == 0 {
+			pod = "web-2"
+		}
+		sample := Sample{Timestamp: now.Add(-time.Duration(i) * time.Minute), MilliCPU: int64(i * 10), Memory: int64(i * 10 * mebibyte)}
+		sampler.record(containerKey{"context", "default", pod, "app"}, sample)
+		// the same sample read again before metrics-server scrapes kubelet is ignored
+		sampler.record(containerKey{"context", "default", pod, "app"}, sample)
+	}
+
+	recommendation, err := Recommend(context.Background(), client, clientset, sampler, "context", RecommendOptions{
+		Kind: "deploy", Namespace: "default", Name: "web", Headroom: DefaultHeadroom,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "Deployment", recommendation.Kind)
+	assert.Equal(t, 2, recommendation.Pods)
+	assert.Equal(t, "20m0s", recommendation.Window)
+	assert.Equal(t, []ContainerRecommendation{
+		{
+			Name:    "app",
+			Status:  StatusOverProvisioned,
+			Samples: 20,
+			Usage:   &SampledUsage{CPUPercentile: "190m", CPUMax: "200m", MemoryMax: "200Mi"},
+			Current: Resources{CPURequest: "2", MemoryRequest: "1Gi", MemoryLimit: "1Gi"},
+			Recommended: &Resources{
+				CPURequest: "190m", MemoryRequest: "240Mi", MemoryLimit: "240Mi",
+			},
+			Reasons: []string{
+				"CPU request 2 is more than 2 times above recommended 190m",
+				"memory request 1Gi is more than 2 times above recommended 240Mi",
+			},
+		},
+		{
+			Name:    "proxy",
+			Status:  StatusUnderProvisioned,
+			Samples: 1,
+			Usage:   &SampledUsage{CPUPercentile: "20m", CPUMax: "20m", MemoryMax: "60Mi"},
+			Current: Resources{CPURequest: "50m", CPULimit: "100m", MemoryRequest: "32Mi", MemoryLimit: "64Mi"},
+			Recommended: &Resources{
+				CPURequest: "20m", CPULimit: "24m", MemoryRequest: "72Mi", MemoryLimit: "72Mi",
+			},
+			Reasons: []string{
+				"max memory usage 60Mi is above request 32Mi",
+				"max memory usage 60Mi leaves less than 20% headroom to limit 64Mi, container risks being OOM killed",
+				"CPU request 50m is more than 2 times above recommended 20m",
+			},
+		},
+	}, recommendation.Containers)
+	assert.Equal(t,
+		`{"spec":{"template":{"spec":{"containers":[`+
+			`{"name":"app","resources":{"limits":{"memory":"240Mi"},"requests":{"cpu":"190m","memory":"240Mi"}}},`+
+			`{"name":"proxy","resources":{"limits":{"cpu":"24m","memory":"72Mi"},"requests":{"cpu":"20m","memory":"72Mi"}}}`+
+			`]}}}}`,
+		recommendation.Patch,
+	)
+	assert.Equal(t, "strategic", recommendation.PatchType)
+	assert.Equal(t, []string{
+		"container proxy has only 1 samples, usage is sampled every 30s and kept for 1h0m0s, ask again later for more reliable recommendation",
+	}, recommendation.Warnings)
+
+	t.Run("no samples", func(t *testing.T) {
+		recommendation, err := Recommend(context.Background(), client, clientset, NewSampler(nil), "other", RecommendOptions{
+			Kind: "Deployment", Namespace: "default", Name: "web", Headroom: DefaultHeadroom,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, StatusNoSamples, recommendation.Containers[0].Status)
+		assert.Nil(t, recommendation.Containers[0].Recommended)
+	})
+
+	t.Run("unsupported kind", func(t *testing.T) {
+		_, err := Recommend(context.Background(), client, clientset, sampler, "context", RecommendOptions{
+			Kind: "Job", Namespace: "default", Name: "web",
+		})
+		assert.EqualError(t, err, "recommendations are not supported for kind Job, expected one of Deployment, StatefulSet or DaemonSet")
+	})
+}
@@ -0,0 +1,230 @@
+package metrics
+
+import (
+	"context"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/strowk/mcp-k8s-go/internal/k8s"
+	corev1 "k8s.io/api/core/v1"
+	metricsclientset "k8s.io/metrics/pkg/client/clientset/versioned"
+)
+
+// Defaults of the sampler, metrics-server itself scrapes kubelets every 15 seconds
+const (
+	DefaultSampleInterval = 30 * time.Second
+	DefaultSampleWindow   = time.Hour
+	// DefaultSampleIdleTimeout is how long namespace is sampled after it was last asked about
+	DefaultSampleIdleTimeout = time.Hour
+)
+
+// Sample is usage of a container at some moment
+type Sample struct {
+	Timestamp time.Time
+	// MilliCPU is CPU usage in millicores
+	MilliCPU int64
+	// Memory is memory usage in bytes
+	Memory int64
+}
+
+// Sampler keeps usage samples of containers read from metrics API in memory,
+// so that recommendations are based on usage over a window and not on a single
+// point, samples older than the window are dropped and nothing is persisted
+type Sampler struct {
+	pool        k8s.ClientPool
+	interval    time.Duration
+	window      time.Duration
+	idleTimeout time.Duration
+	now         func() time.Time
+	ctx         context.Context
+	cancel      context.CancelFunc
+
+	mutex   sync.Mutex
+	samples map[containerKey][]Sample
+	// sampled holds sampled namespaces of every context with time they were last asked about
+	sampled map[string]map[string]time.Time
+}
+
+type containerKey struct {
+	k8sCtx    string
+	namespace string
+	pod       string
+	container string
+}
+
+func NewSampler(pool k8s.ClientPool) *Sampler {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Sampler{
+		pool:        pool,
+		interval:    DefaultSampleInterval,
+		window:      DefaultSampleWindow,
+		idleTimeout: DefaultSampleIdleTimeout,
+		now:         time.Now,
+		ctx:         ctx,
+		cancel:      cancel,
+		samples:     map[containerKey][]Sample{},
+		sampled:     map[string]map[string]time.Time{},
+	}
+}
+
+func (s *Sampler) Interval() time.Duration {
+	return s.interval
+}
+
+func (s *Sampler) Window() time.Duration {
+	return s.window
+}
+
+func (s *Sampler) IdleTimeout() time.Duration {
+	return s.idleTimeout
+}
+
+// Start starts sampling usage of pods in the namespace of the context in background,
+// unless it is already sampled, namespace is sampled until it is not asked about
+// for idle timeout and context is sampled while it has any namespaces left
+func (s *Sampler) Start(k8sCtx, namespace string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.ctx.Err() != nil {
+		return
+	}
+	namespaces, sampling := s.sampled[k8sCtx]
+	if !sampling {
+		namespaces = map[string]time.Time{}
+		s.sampled[k8sCtx] = namespaces
+	}
+	namespaces[namespace] = s.now()
+	if !sampling {
+		go s.sample(k8sCtx)
+	}
+}
+
+// Stop stops sampling of all contexts, samples collected so far are kept
+func (s *Sampler) Stop() {
+	s.cancel()
+}
+
+func (s *Sampler) sample(k8sCtx string) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		namespaces := s.activeNamespaces(k8sCtx)
+		if len(namespaces) == 0 {
+			return
+		}
+		client, err := s.pool.GetMetricsClientset(k8sCtx)
+		if err != nil {
+			log.Printf("failed to sample usage of pods in context %s: %v", k8sCtx, err)
+			continue
+		}
+		for _, namespace := range namespaces {
+			err := s.Collect(s.ctx, k8sCtx, client, namespace)
+			if err != nil && s.ctx.Err() == nil {
+				log.Printf("failed to sample usage of pods in namespace %s of context %s: %v", namespace, k8sCtx, err)
+			}
+		}
+	}
+}
+
+// activeNamespaces forgets namespaces of the context, which were not asked about
+// for idle timeout, and returns the rest, context without namespaces is forgotten
+// as well, so that sampling of it stops and is started again by the next call
+func (s *Sampler) activeNamespaces(k8sCtx string) []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	idleSince := s.now().Add(-s.idleTimeout)
+	var active []string
+	for namespace, lastUsed := range s.sampled[k8sCtx] {
+		if lastUsed.Before(idleSince) {
+			delete(s.sampled[k8sCtx], namespace)
+			continue
+		}
+		active = append(active, namespace)
+	}
+	if len(active) == 0 {
+		delete(s.sampled, k8sCtx)
+	}
+	sort.Strings(active)
+	return active
+}
+
+// Collect reads current usage of pods in the namespace, or in all
+// namespaces if it is empty, and records it as samples
+func (s *Sampler) Collect(ctx context.Context, k8sCtx string, client metricsclientset.Interface, namespace string) error {
+	podMetrics, err := ListPodMetrics(ctx, client, namespace, "")
+	if err != nil {
+		return err
+	}
+	for _, metrics := range podMetrics {
+		timestamp := metrics.Timestamp.Time
+		if timestamp.IsZero() {
+			timestamp = s.now()
+		}
+		for _, container := range metrics.Containers {
+			cpu := container.Usage[corev1.ResourceCPU]
+			memory := container.Usage[corev1.ResourceMemory]
+			s.record(containerKey{k8sCtx, metrics.Namespace, metrics.Name, container.Name}, Sample{
+				Timestamp: timestamp,
+				MilliCPU:  cpu.MilliValue(),
+				Memory:    memory.Value(),
+			})
+		}
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.expire()
+	return nil
+}
+
+// record adds the sample of the container, unless sample with the same
+// timestamp is already known, as metrics-server serves the same
+// usage until it scrapes kubelet again
+func (s *Sampler) record(key containerKey, sample Sample) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	samples := s.samples[key]
+	for _, existing := range samples {
+		if existing.Timestamp.Equal(sample.Timestamp) {
+			return
+		}
+	}
+	samples = append(samples, sample)
+	sort.Slice(samples, func(i, j int) bool {
+		return samples[i].Timestamp.Before(samples[j].Timestamp)
+	})
+	s.samples[key] = samples
+}
+
+// Samples returns samples of the container within the window, oldest first
+func (s *Sampler) Samples(k8sCtx, namespace, pod, container string) []Sample {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.expire()
+	return append([]Sample{}, s.samples[containerKey{k8sCtx, namespace, pod, container}]...)
+}
+
+// expire drops samples older than the window, must be called with mutex locked
+func (s *Sampler) expire() {
+	since := s.now().Add(-s.window)
+	for key, samples := range s.samples {
+		first := sort.Search(len(samples), func(i int) bool {
+			return !samples[i].Timestamp.Before(since)
+		})
+		if first == len(samples) {
+			delete(s.samples, key)
+		} else if first > 0 {
+			s.samples[key] = append([]Sample{}, samples[first:]...)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package metrics
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	mock_k8s "github.com/strowk/mcp-k8s-go/internal/k8s/mock"
+	"go.uber.org/mock/gomock"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
+)
+
+func TestSamplerStart(t *testing.T) {
+	client := newFakeMetricsClient(t,
+		&metricsv1beta1.PodMetrics{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Timestamp:  metav1.Now(),
+			Containers: []metricsv1beta1.ContainerMetrics{{Name: "app", Usage: usage("10m", "10Mi")}},
+		},
+		&metricsv1beta1.PodMetrics{
+			ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "other"},
+			Timestamp:  metav1.Now(),
+			Containers: []metricsv1beta1.ContainerMetrics{{Name: "app", Usage: usage("10m", "10Mi")}},
+		},
+	)
+	poolMock := mock_k8s.NewMockClientPool(gomock.NewController(t))
+	poolMock.EXPECT().GetMetricsClientset("context").Return(client, nil).AnyTimes()
+
+	newSampler := func() *Sampler {
+		sampler := NewSampler(poolMock)
+		sampler.interval = 10 * time.Millisecond
+		sampler.idleTimeout = 100 * time.Millisecond
+		t.Cleanup(sampler.Stop)
+		return sampler
+	}
+
+	t.Run("samples only asked namespace and stops when idle", func(t *testing.T) {
+		sampler := newSampler()
+		sampler.Start("context", "default")
+
+		assert.Eventually(t, func() bool {
+			return len(sampler.Samples("context", "default", "web", "app")) > 0
+		}, time.Second, 10*time.Millisecond)
+		assert.Empty(t, sampler.Samples("context", "other", "db", "app"))
+
+		assert.Eventually(t, func() bool {
+			sampler.mutex.Lock()
+			defer sampler.mutex.Unlock()
+			return len(sampler.sampled) == 0
+		}, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("does not start after stop", func(t *testing.T) {
+		sampler := newSampler()
+		sampler.Stop()
+		sampler.Start("context", "default")
+
+		time.Sleep(50 * time.Millisecond)
+		assert.Empty(t, sampler.Samples("context", "default", "web", "app"))
+		assert.Empty(t, sampler.sampled)
+	})
+}
+
+func TestSamplerCollect(t *testing.T) {
+	now := time.Now()
+	client := newFakeMetricsClient(t, &metricsv1beta1.PodMetrics{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+		Timestamp:  metav1.NewTime(now),
+		Containers: []metricsv1beta1.ContainerMetrics{{Name: "app", Usage: usage("10m", "10Mi")}},
+	})
+
+	sampler := NewSampler(nil)
+	sampler.now = func() time.Time { return now }
+	old := containerKey{"context", "default", "gone", "app"}
+	sampler.record(old, Sample{Timestamp: now.Add(-2 * time.Hour), MilliCPU: 10, Memory: 10})
+
+	require.NoError(t, sampler.Collect(context.Background(), "context", client, "default"))
+	// old samples are dropped by collecting without asking for them
+	assert.NotContains(t, sampler.samples, old)
+	assert.Equal(t, []Sample{{Timestamp: now, MilliCPU: 10, Memory: 10 * 1024 * 1024}}, sampler.Samples("context", "default", "web", "app"))
+}
@@ -0,0 +1,298 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/strowk/mcp-k8s-go/internal/k8s/core/v1/pod"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	metricsclientset "k8s.io/metrics/pkg/client/clientset/versioned"
+)
+
+// Fields to sort usage by
+const (
+	SortByCPU    = "cpu"
+	SortByMemory = "memory"
+)
+
+// DefaultMemoryLimitThreshold is fraction of memory limit,
+// above which container is flagged as being near its limit
+const DefaultMemoryLimitThreshold = 0.9
+
+type TopPodsOptions struct {
+	// Namespace to read usage from, all namespaces if empty
+	Namespace     string
+	LabelSelector string
+	SortBy        string
+	// Containers includes usage of every container of the pod
+	Containers bool
+	// Limit is maximum number of pods returned after sorting, 0 means no limit
+	Limit int
+	// MemoryLimitThreshold is fraction of memory limit to flag containers near it
+	MemoryLimitThreshold float64
+}
+
+// PodUsage is current usage of the pod compared with requests and limits
+// of its containers, percents are only set when requests or limits are set
+type PodUsage struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Usage
+	Containers []ContainerUsage `json:"containers,omitempty"`
+	// Warnings list containers near their memory limit
+	Warnings []string `json:"warnings,omitempty"`
+
+	cpu    resource.Quantity
+	memory resource.Quantity
+}
+
+type ContainerUsage struct {
+	Name string `json:"name"`
+	Usage
+	NearMemoryLimit bool `json:"nearMemoryLimit,omitempty"`
+}
+
+type Usage struct {
+	CPU                  string `json:"cpu"`
+	Memory               string `json:"memory"`
+	CPURequest           string `json:"cpuRequest,omitempty"`
+	CPULimit             string `json:"cpuLimit,omitempty"`
+	MemoryRequest        string `json:"memoryRequest,omitempty"`
+	MemoryLimit          string `json:"memoryLimit,omitempty"`
+	CPURequestPercent    *int   `json:"cpuRequestPercent,omitempty"`
+	CPULimitPercent      *int   `json:"cpuLimitPercent,omitempty"`
+	MemoryRequestPercent *int   `json:"memoryRequestPercent,omitempty"`
+	MemoryLimitPercent   *int   `json:"memoryLimitPercent,omitempty"`
+}
+
+type TopNodesOptions struct {
+	LabelSelector string
+	SortBy        string
+}
+
+// NodeUsage is current usage of the node compared with its allocatable resources
+type NodeUsage struct {
+	Name              string `json:"name"`
+	CPU               string `json:"cpu"`
+	CPUAllocatable    string `json:"cpuAllocatable,omitempty"`
+	CPUPercent        *int   `json:"cpuPercent,omitempty"`
+	Memory            string `json:"memory"`
+	MemoryAllocatable string `json:"memoryAllocatable,omitempty"`
+	MemoryPercent     *int   `json:"memoryPercent,omitempty"`
+
+	cpu    resource.Quantity
+	memory resource.Quantity
+}
+
+// ValidateSortBy checks that usage can be sorted by the field
+func ValidateSortBy(sortBy string) error {
+	if sortBy != "" && sortBy != SortByCPU && sortBy != SortByMemory {
+		return fmt.Errorf("invalid sortBy: %s, expected %s or %s", sortBy, SortByCPU, SortByMemory)
+	}
+	return nil
+}
+
+// TopPods returns usage of pods from metrics API together with
+// requests and limits of their containers, sorted by usage
+func TopPods(ctx context.Context, client metricsclientset.Interface, clientset kubernetes.Interface, options TopPodsOptions) ([]PodUsage, error) {
+	if err := ValidateSortBy(options.SortBy); err != nil {
+		return nil, err
+	}
+	threshold := options.MemoryLimitThreshold
+	if threshold <= 0 {
+		threshold = DefaultMemoryLimitThreshold
+	}
+
+	podMetrics, err := ListPodMetrics(ctx, client, options.Namespace, options.LabelSelector)
+	if err != nil {
+		return nil, err
+	}
+
+	pods, err := clientset.CoreV1().Pods(options.Namespace).List(ctx, metav1.ListOptions{LabelSelector: options.LabelSelector})
+	if err != nil {
+		return nil, err
+	}
+	podsByKey := map[string]*corev1.Pod{}
+	for i := range pods.Items {
+		podsByKey[pods.Items[i].Namespace+"/"+pods.Items[i].Name] = &pods.Items[i]
+	}
+
+	result := make([]PodUsage, 0, len(podMetrics))
+	for _, metrics := range podMetrics {
+		usage := PodUsage{Namespace: metrics.Namespace, Name: metrics.Name}
+		p := podsByKey[metrics.Namespace+"/"+metrics.Name]
+
+		podRequests, podLimits := corev1.ResourceList{}, corev1.ResourceList{}
+		allCPULimited, allMemoryLimited := p != nil, p != nil
+		for _, containerMetrics := range metrics.Containers {
+			cpu := containerMetrics.Usage[corev1.ResourceCPU]
+			memory := containerMetrics.Usage[corev1.ResourceMemory]
+			usage.cpu.Add(cpu)
+			usage.memory.Add(memory)
+
+			var requirements corev1.ResourceRequirements
+			if container := findContainer(p, containerMetrics.Name); container != nil {
+				requirements = container.Resources
+			}
+			pod.AddResources(podRequests, requirements.Requests)
+			pod.AddResources(podLimits, requirements.Limits)
+			_, hasCPULimit := requirements.Limits[corev1.ResourceCPU]
+			_, hasMemoryLimit := requirements.Limits[corev1.ResourceMemory]
+			allCPULimited = allCPULimited && hasCPULimit
+			allMemoryLimited = allMemoryLimited && hasMemoryLimit
+
+			containerUsage := ContainerUsage{
+				Name:  containerMetrics.Name,
+				Usage: newUsage(cpu, memory, requirements.Requests, requirements.Limits),
+			}
+			if memoryLimit, ok := requirements.Limits[corev1.ResourceMemory]; ok && !memoryLimit.IsZero() &&
+				pod.Fraction(memory, memoryLimit) >= threshold {
+				containerUsage.NearMemoryLimit = true
+				usage.Warnings = append(usage.Warnings, fmt.Sprintf(
+					"container %s uses %s, which is %d%% of its memory limit %s",
+					containerMetrics.Name, formatMemory(memory), *containerUsage.MemoryLimitPercent, memoryLimit.String(),
+				))
+			}
+			if options.Containers {
+				usage.Containers = append(usage.Containers, containerUsage)
+			}
+		}
+
+		// pod limit is only meaningful when every container has one
+		if !allCPULimited {
+			delete(podLimits, corev1.ResourceCPU)
+		}
+		if !allMemoryLimited {
+			delete(podLimits, corev1.ResourceMemory)
+		}
+		usage.Usage = newUsage(usage.cpu, usage.memory, podRequests, podLimits)
+		result = append(result, usage)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		if options.SortBy == SortByMemory {
+			return result[i].memory.Cmp(result[j].memory) > 0
+		}
+		if options.SortBy == SortByCPU {
+			return result[i].cpu.Cmp(result[j].cpu) > 0
+		}
+		return result[i].Namespace+"/"+result[i].Name < result[j].Namespace+"/"+result[j].Name
+	})
+	if options.Limit > 0 && len(result) > options.Limit {
+		result = result[:options.Limit]
+	}
+	return result, nil
+}
+
+// TopNodes returns usage of nodes from metrics API compared with allocatable, sorted by usage
+func TopNodes(ctx context.Context, client metricsclientset.Interface, clientset kubernetes.Interface, options TopNodesOptions) ([]NodeUsage, error) {
+	if err := ValidateSortBy(options.SortBy); err != nil {
+		return nil, err
+	}
+
+	nodeMetrics, err := ListNodeMetrics(ctx, client, options.LabelSelector)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes, err := clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{LabelSelector: options.LabelSelector})
+	if err != nil {
+		return nil, err
+	}
+	allocatable := map[string]corev1.ResourceList{}
+	for _, node := range nodes.Items {
+		allocatable[node.Name] = node.Status.Allocatable
+	}
+
+	result := make([]NodeUsage, 0, len(nodeMetrics))
+	for _, metrics := range nodeMetrics {
+		usage := NodeUsage{
+			Name:   metrics.Name,
+			cpu:    metrics.Usage[corev1.ResourceCPU],
+			memory: metrics.Usage[corev1.ResourceMemory],
+		}
+		usage.CPU = formatCPU(usage.cpu)
+		usage.Memory = formatMemory(usage.memory)
+		if cpu, ok := allocatable[metrics.Name][corev1.ResourceCPU]; ok {
+			usage.CPUAllocatable = formatCPU(cpu)
+			usage.CPUPercent = percent(usage.cpu, cpu)
+		}
+		if memory, ok := allocatable[metrics.Name][corev1.ResourceMemory]; ok {
+			usage.MemoryAllocatable = formatMemory(memory)
+			usage.MemoryPercent = percent(usage.memory, memory)
+		}
+		result = append(result, usage)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		if options.SortBy == SortByMemory {
+			return result[i].memory.Cmp(result[j].memory) > 0
+		}
+		if options.SortBy == SortByCPU {
+			return result[i].cpu.Cmp(result[j].cpu) > 0
+		}
+		return result[i].Name < result[j].Name
+	})
+	return result, nil
+}
+
+func newUsage(cpu, memory resource.Quantity, requests, limits corev1.ResourceList) Usage {
+	usage := Usage{CPU: formatCPU(cpu), Memory: formatMemory(memory)}
+	if request, ok := requests[corev1.ResourceCPU]; ok {
+		usage.CPURequest = request.String()
+		usage.CPURequestPercent = percent(cpu, request)
+	}
+	if limit, ok := limits[corev1.ResourceCPU]; ok {
+		usage.CPULimit = limit.String()
+		usage.CPULimitPercent = percent(cpu, limit)
+	}
+	if request, ok := requests[corev1.ResourceMemory]; ok {
+		usage.MemoryRequest = request.String()
+		usage.MemoryRequestPercent = percent(memory, request)
+	}
+	if limit, ok := limits[corev1.ResourceMemory]; ok {
+		usage.MemoryLimit = limit.String()
+		usage.MemoryLimitPercent = percent(memory, limit)
+	}
+	return usage
+}
+
+func findContainer(pod *corev1.Pod, name string) *corev1.Container {
+	if pod == nil {
+		return nil
+	}
+	for i := range pod.Spec.Containers {
+		if pod.Spec.Containers[i].Name == name {
+			return &pod.Spec.Containers[i]
+		}
+	}
+	for i := range pod.Spec.InitContainers {
+		if pod.Spec.InitContainers[i].Name == name {
+			return &pod.Spec.InitContainers[i]
+		}
+	}
+	return nil
+}
+
+// formatCPU formats CPU in millicores like kubectl top does
+func formatCPU(quantity resource.Quantity) string {
+	return fmt.Sprintf("%dm", quantity.MilliValue())
+}
+
+// formatMemory formats memory in mebibytes like kubectl top does
+func formatMemory(quantity resource.Quantity) string {
+	return fmt.Sprintf("%dMi", quantity.Value()/(1024*1024))
+}
+
+func percent(value, total resource.Quantity) *int {
+	if total.IsZero() {
+		return nil
+	}
+	result := int(math.Round(pod.Fraction(value, total) * 100))
+	return &result
+}
@@ -0,0 +1,150 @@
+package metrics
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/strowk/mcp-k8s-go/internal/utils"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
+	metricsfake "k8s.io/metrics/pkg/client/clientset/versioned/fake"
+)
+
+// newFakeMetricsClient creates fake clientset serving metrics.k8s.io, objects
+// are created under pods and nodes resources, as the tracker would otherwise
+// store them under resources guessed from kind, which the clientset does not read
+func newFakeMetricsClient(t *testing.T, objects ...runtime.Object) *metricsfake.Clientset {
+	client := metricsfake.NewSimpleClientset()
+	for _, object := range objects {
+		resource, namespace := "nodes", ""
+		if podMetrics, ok := object.(*metricsv1beta1.PodMetrics); ok {
+			resource, namespace = "pods", podMetrics.Namespace
+		}
+		require.NoError(t, client.Tracker().Create(metricsv1beta1.SchemeGroupVersion.WithResource(resource), object, namespace))
+	}
+	return client
+}
+
+func usage(cpu, memory string) corev1.ResourceList {
+	return corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(cpu), corev1.ResourceMemory: resource.MustParse(memory)}
+}
+
+func TestTopPods(t *testing.T) {
+	client := newFakeMetricsClient(t,
+		&metricsv1beta1.PodMetrics{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Containers: []metricsv1beta1.ContainerMetrics{
+				{Name: "app", Usage: usage("250m", "243Mi")},
+				{Name: "proxy", Usage: usage("10m", "20Mi")},
+			},
+		},
+		&metricsv1beta1.PodMetrics{
+			ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default"},
+			Containers: []metricsv1beta1.ContainerMetrics{{Name: "postgres", Usage: usage("100m", "512Mi")}},
+		},
+		&metricsv1beta1.PodMetrics{
+			ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "other"},
+			Containers: []metricsv1beta1.ContainerMetrics{{Name: "app", Usage: usage("1", "1Gi")}},
+		},
+	)
+	clientset := fake.NewClientset(
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Spec: corev1.PodSpec{Containers: []corev1.Container{
+				{Name: "app", Resources: corev1.ResourceRequirements{Requests: usage("500m", "128Mi"), Limits: usage("1", "256Mi")}},
+				{Name: "proxy", Resources: corev1.ResourceRequirements{Requests: usage("50m", "32Mi")}},
+			}},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default"},
+			Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "postgres"}}},
+		},
+	)
+
+	t.Run("sort by memory with containers", func(t *testing.T) {
+		pods, err := TopPods(context.Background(), client, clientset, TopPodsOptions{
+			Namespace: "default", SortBy: SortByMemory, Containers: true,
+		})
+		require.NoError(t, err)
+		require.Len(t, pods, 2)
+
+		assert.Equal(t, "db", pods[0].Name)
+		assert.Equal(t, Usage{CPU: "100m", Memory: "512Mi"}, pods[0].Usage)
+
+		web := pods[1]
+		assert.Equal(t, Usage{
+			CPU: "260m", Memory: "263Mi",
+			CPURequest: "550m", MemoryRequest: "160Mi",
+			CPURequestPercent: utils.Ptr(47), MemoryRequestPercent: utils.Ptr(164),
+		}, web.Usage)
+		assert.Equal(t, []ContainerUsage{
+			{
+				Name: "app",
+				Usage: Usage{
+					CPU: "250m", Memory: "243Mi",
+					CPURequest: "500m", CPULimit: "1", MemoryRequest: "128Mi", MemoryLimit: "256Mi",
+					CPURequestPercent: utils.Ptr(50), CPULimitPercent: utils.Ptr(25),
+					MemoryRequestPercent: utils.Ptr(190), MemoryLimitPercent: utils.Ptr(95),
+				},
+				NearMemoryLimit: true,
+			},
+			{
+				Name: "proxy",
+				Usage: Usage{
+					CPU: "10m", Memory: "20Mi", CPURequest: "50m", MemoryRequest: "32Mi",
+					CPURequestPercent: utils.Ptr(20), MemoryRequestPercent: utils.Ptr(63),
+				},
+			},
+		}, web.Containers)
+		assert.Equal(t, []string{"container app uses 243Mi, which is 95% of its memory limit 256Mi"}, web.Warnings)
+	})
+
+	t.Run("sort by cpu in all namespaces with limit", func(t *testing.T) {
+		pods, err := TopPods(context.Background(), client, clientset, TopPodsOptions{SortBy: SortByCPU, Limit: 2})
+		require.NoError(t, err)
+		require.Len(t, pods, 2)
+		assert.Equal(t, "other", pods[0].Name)
+		assert.Equal(t, "web", pods[1].Name)
+		assert.Empty(t, pods[1].Containers)
+		assert.Len(t, pods[1].Warnings, 1)
+	})
+
+	t.Run("invalid sort", func(t *testing.T) {
+		_, err := TopPods(context.Background(), client, clientset, TopPodsOptions{SortBy: "disk"})
+		assert.EqualError(t, err, "invalid sortBy: disk, expected cpu or memory")
+	})
+}
+
+func TestTopNodes(t *testing.T) {
+	client := newFakeMetricsClient(t,
+		&metricsv1beta1.NodeMetrics{ObjectMeta: metav1.ObjectMeta{Name: "a"}, Usage: usage("500m", "6Gi")},
+		&metricsv1beta1.NodeMetrics{ObjectMeta: metav1.ObjectMeta{Name: "b"}, Usage: usage("1500m", "2Gi")},
+	)
+	clientset := fake.NewClientset(
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "a"}, Status: corev1.NodeStatus{Allocatable: usage("2", "8Gi")}},
+	)
+
+	nodes, err := TopNodes(context.Background(), client, clientset, TopNodesOptions{SortBy: SortByCPU})
+	require.NoError(t, err)
+	assert.Equal(t, []NodeUsage{
+		{Name: "b", CPU: "1500m", Memory: "2048Mi"},
+		{
+			Name: "a", CPU: "500m", CPUAllocatable: "2000m", CPUPercent: utils.Ptr(25),
+			Memory: "6144Mi", MemoryAllocatable: "8192Mi", MemoryPercent: utils.Ptr(75),
+		},
+	}, withoutQuantities(nodes))
+}
+
+func withoutQuantities(nodes []NodeUsage) []NodeUsage {
+	for i := range nodes {
+		nodes[i].cpu = resource.Quantity{}
+		nodes[i].memory = resource.Quantity{}
+	}
+	return nodes
+}
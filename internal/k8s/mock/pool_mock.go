@@ -14,9 +14,12 @@ import (
 
 	list_mapping "github.com/strowk/mcp-k8s-go/internal/k8s/list_mapping"
 	gomock "go.uber.org/mock/gomock"
+	meta "k8s.io/apimachinery/pkg/api/meta"
 	dynamic "k8s.io/client-go/dynamic"
 	informers "k8s.io/client-go/informers"
 	kubernetes "k8s.io/client-go/kubernetes"
+	metadata "k8s.io/client-go/metadata"
+	versioned "k8s.io/metrics/pkg/client/clientset/versioned"
 )
 
 // MockClientPool is a mock of ClientPool interface.
@@ -101,3 +104,48 @@ func (mr *MockClientPoolMockRecorder) GetListMapping(k8sCtx, kind, group, versio
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetListMapping", reflect.TypeOf((*MockClientPool)(nil).GetListMapping), k8sCtx, kind, group, version)
 }
+
+// GetMetadataClient mocks base method.
+func (m *MockClientPool) GetMetadataClient(k8sContext string) (metadata.Interface, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMetadataClient", k8sContext)
+	ret0, _ := ret[0].(metadata.Interface)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMetadataClient indicates an expected call of GetMetadataClient.
+func (mr *MockClientPoolMockRecorder) GetMetadataClient(k8sContext any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMetadataClient", reflect.TypeOf((*MockClientPool)(nil).GetMetadataClient), k8sContext)
+}
+
+// GetMetricsClientset mocks base method.
+func (m *MockClientPool) GetMetricsClientset(k8sContext string) (versioned.Interface, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMetricsClientset", k8sContext)
+	ret0, _ := ret[0].(versioned.Interface)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMetricsClientset indicates an expected call of GetMetricsClientset.
+func (mr *MockClientPoolMockRecorder) GetMetricsClientset(k8sContext any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMetricsClientset", reflect.TypeOf((*MockClientPool)(nil).GetMetricsClientset), k8sContext)
+}
+
+// GetRESTMapping mocks base method.
+func (m *MockClientPool) GetRESTMapping(k8sCtx, kind, group, version string) (*meta.RESTMapping, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRESTMapping", k8sCtx, kind, group, version)
+	ret0, _ := ret[0].(*meta.RESTMapping)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRESTMapping indicates an expected call of GetRESTMapping.
+func (mr *MockClientPoolMockRecorder) GetRESTMapping(k8sCtx, kind, group, version any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRESTMapping", reflect.TypeOf((*MockClientPool)(nil).GetRESTMapping), k8sCtx, kind, group, version)
+}
@@ -16,8 +16,10 @@ import (
 	"k8s.io/client-go/dynamic/dynamicinformer"
 	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/metadata"
 	"k8s.io/client-go/restmapper"
 	"k8s.io/client-go/tools/cache"
+	metricsclientset "k8s.io/metrics/pkg/client/clientset/versioned"
 )
 
 // ClientPool is a pool of Kubernetes clientsets and informers
@@ -29,6 +31,8 @@ import (
 type ClientPool interface {
 	GetClientset(k8sContext string) (kubernetes.Interface, error)
 	GetDynamicClient(k8sContext string) (dynamic.Interface, error)
+	GetMetadataClient(k8sContext string) (metadata.Interface, error)
+	GetMetricsClientset(k8sContext string) (metricsclientset.Interface, error)
 	GetInformer(
 		k8sCtx string,
 		kind string,
@@ -36,6 +40,7 @@ type ClientPool interface {
 		version string,
 	) (informers.GenericInformer, error)
 	GetListMapping(k8sCtx, kind, group, version string) list_mapping.ListMapping
+	GetRESTMapping(k8sCtx, kind, group, version string) (*meta.RESTMapping, error)
 }
 
 type resolvedResource struct {
@@ -47,14 +52,19 @@ type resolvedResource struct {
 }
 
 type pool struct {
-	clients        map[string]kubernetes.Interface
-	dynamicClients map[string]dynamic.Interface
+	clients         map[string]kubernetes.Interface
+	dynamicClients  map[string]dynamic.Interface
+	metadataClients map[string]metadata.Interface
+	metricsClients  map[string]metricsclientset.Interface
 
-	getClientsetMutex     *sync.Mutex
-	getDynamicClientMutex *sync.Mutex
+	getClientsetMutex        *sync.Mutex
+	getDynamicClientMutex    *sync.Mutex
+	getMetadataClientMutex   *sync.Mutex
+	getMetricsClientsetMutex *sync.Mutex
 
 	keyToResource map[string]*resolvedResource
 	gvkToResource map[schema.GroupVersionKind]*resolvedResource
+	keyToMapping  map[string]*meta.RESTMapping
 
 	getInformerMutex *sync.Mutex
 
@@ -69,8 +79,15 @@ func NewClientPool(listMappingResolvers []list_mapping.ListMappingResolver) Clie
 		dynamicClients:        make(map[string]dynamic.Interface),
 		getDynamicClientMutex: &sync.Mutex{},
 
+		metadataClients:        make(map[string]metadata.Interface),
+		getMetadataClientMutex: &sync.Mutex{},
+
+		metricsClients:           make(map[string]metricsclientset.Interface),
+		getMetricsClientsetMutex: &sync.Mutex{},
+
 		keyToResource:    make(map[string]*resolvedResource),
 		gvkToResource:    make(map[schema.GroupVersionKind]*resolvedResource),
+		keyToMapping:     make(map[string]*meta.RESTMapping),
 		getInformerMutex: &sync.Mutex{},
 
 		listMappingResolvers: listMappingResolvers,
@@ -122,6 +139,33 @@ func (p *pool) GetInformer(
 	return res.informer, nil
 }
 
+// GetRESTMapping resolves kind, group and version to the REST mapping
+// known by the server, without setting up informer for the resource
+func (p *pool) GetRESTMapping(
+	k8sCtx string,
+	kind string,
+	group string,
+	version string,
+) (*meta.RESTMapping, error) {
+	p.getInformerMutex.Lock()
+	defer p.getInformerMutex.Unlock()
+
+	key := fmt.Sprintf("%s/%s/%s/%s", k8sCtx, kind, group, version)
+	if res, ok := p.keyToResource[key]; ok {
+		return res.mapping, nil
+	}
+	if mapping, ok := p.keyToMapping[key]; ok {
+		return mapping, nil
+	}
+
+	res, err := p.resolve(k8sCtx, kind, group, version)
+	if err != nil {
+		return nil, err
+	}
+	p.keyToMapping[key] = res.mapping
+	return res.mapping, nil
+}
+
 func (p *pool) resolve(
 	k8sCtx string,
 	kind string,
@@ -306,3 +350,82 @@ func (p *pool) GetDynamicClient(k8sContext string) (dynamic.Interface, error) {
 	p.dynamicClients[k8sContext] = client
 	return client, nil
 }
+
+// GetMetadataClient returns client, which only fetches metadata of objects,
+// that is useful to count or find objects without transferring their content
+func (p *pool) GetMetadataClient(k8sContext string) (metadata.Interface, error) {
+	p.getMetadataClientMutex.Lock()
+	defer p.getMetadataClientMutex.Unlock()
+
+	var effectiveContext string
+	if k8sContext == "" {
+		var err error
+		effectiveContext, err = GetCurrentContext()
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		effectiveContext = k8sContext
+	}
+
+	if !IsContextAllowed(effectiveContext) {
+		return nil, fmt.Errorf("context %s is not allowed", effectiveContext)
+	}
+
+	if client, ok := p.metadataClients[effectiveContext]; ok {
+		return client, nil
+	}
+	kubeConfig := GetKubeConfigForContext(k8sContext)
+
+	config, err := kubeConfig.ClientConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := metadata.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	p.metadataClients[effectiveContext] = client
+	return client, nil
+}
+
+// GetMetricsClientset returns clientset of metrics.k8s.io API served by metrics-server
+func (p *pool) GetMetricsClientset(k8sContext string) (metricsclientset.Interface, error) {
+	p.getMetricsClientsetMutex.Lock()
+	defer p.getMetricsClientsetMutex.Unlock()
+
+	var effectiveContext string
+	if k8sContext == "" {
+		var err error
+		effectiveContext, err = GetCurrentContext()
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		effectiveContext = k8sContext
+	}
+
+	if !IsContextAllowed(effectiveContext) {
+		return nil, fmt.Errorf("context %s is not allowed", effectiveContext)
+	}
+
+	if client, ok := p.metricsClients[effectiveContext]; ok {
+		return client, nil
+	}
+	kubeConfig := GetKubeConfigForContext(k8sContext)
+
+	config, err := kubeConfig.ClientConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := metricsclientset.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	p.metricsClients[effectiveContext] = client
+	return client, nil
+}
@@ -0,0 +1,695 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/strowk/mcp-k8s-go/internal/k8s/core/v1/pod"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/kubernetes"
+)
+
+// selectedNodeAnnotation is set on claim by scheduler, when
+// volume of WaitForFirstConsumer class is provisioned for a pod
+const selectedNodeAnnotation = "volume.kubernetes.io/selected-node"
+
+// maxClaimEvents limits number of most recent events of the claim
+const maxClaimEvents = 10
+
+// ClaimDiagnosis follows chain from PersistentVolumeClaim through PersistentVolume,
+// StorageClass and VolumeAttachments to nodes of pods mounting the claim
+type ClaimDiagnosis struct {
+	Claim        ClaimInfo         `json:"claim"`
+	Volume       *VolumeInfo       `json:"volume,omitempty"`
+	StorageClass *StorageClassInfo `json:"storageClass,omitempty"`
+	Attachments  []AttachmentInfo  `json:"attachments,omitempty"`
+	Pods         []MountingPod     `json:"pods"`
+	Healthy      bool              `json:"healthy"`
+	Findings     []pod.Finding     `json:"findings"`
+	Events       []string          `json:"events,omitempty"`
+}
+
+type ClaimInfo struct {
+	Name         string   `json:"name"`
+	Namespace    string   `json:"namespace"`
+	Phase        string   `json:"phase"`
+	StorageClass string   `json:"storageClass,omitempty"`
+	AccessModes  []string `json:"accessModes,omitempty"`
+	VolumeMode   string   `json:"volumeMode,omitempty"`
+	Requested    string   `json:"requested,omitempty"`
+	Capacity     string   `json:"capacity,omitempty"`
+	VolumeName   string   `json:"volumeName,omitempty"`
+	SelectedNode string   `json:"selectedNode,omitempty"`
+	Conditions   []string `json:"conditions,omitempty"`
+}
+
+type AttachmentInfo struct {
+	Name        string `json:"name"`
+	Node        string `json:"node"`
+	Attached    bool   `json:"attached"`
+	AttachError string `json:"attachError,omitempty"`
+	DetachError string `json:"detachError,omitempty"`
+}
+
+type MountingPod struct {
+	Name     string `json:"name"`
+	Phase    string `json:"phase"`
+	Node     string `json:"node,omitempty"`
+	Zone     string `json:"zone,omitempty"`
+	ReadOnly bool   `json:"readOnly,omitempty"`
+}
+
+// DiagnoseClaim explains state of the claim and everything it depends on
+func DiagnoseClaim(ctx context.Context, clientset kubernetes.Interface, namespace string, name string) (*ClaimDiagnosis, error) {
+	claim, err := clientset.CoreV1().PersistentVolumeClaims(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	d := &ClaimDiagnosis{
+		Claim:    newClaimInfo(claim),
+		Pods:     []MountingPod{},
+		Findings: []pod.Finding{},
+	}
+
+	events, err := claimEvents(ctx, clientset, claim)
+	if err != nil {
+		return nil, err
+	}
+	for _, event := range events {
+		d.Events = append(d.Events, pod.FormatEvent(event))
+	}
+
+	class, err := d.checkStorageClass(ctx, clientset, claim)
+	if err != nil {
+		return nil, err
+	}
+	pods, nodes, err := d.findPods(ctx, clientset, claim)
+	if err != nil {
+		return nil, err
+	}
+
+	var volume *corev1.PersistentVolume
+	switch claim.Status.Phase {
+	case corev1.ClaimPending:
+		if err := d.checkPending(ctx, clientset, claim, class, pods, events); err != nil {
+			return nil, err
+		}
+	case corev1.ClaimLost:
+		d.add(pod.Finding{
+			Severity: pod.SeverityError,
+			Reason:   "ClaimLost",
+			Message:  fmt.Sprintf("claim lost its PersistentVolume %s, data is not accessible and claim has to be recreated", claim.Spec.VolumeName),
+		})
+	}
+
+	if claim.Spec.VolumeName != "" {
+		volume, err = clientset.CoreV1().PersistentVolumes().Get(ctx, claim.Spec.VolumeName, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			volume = nil
+			d.add(pod.Finding{
+				Severity: pod.SeverityError,
+				Reason:   "VolumeNotFound",
+				Message:  fmt.Sprintf("PersistentVolume %s of the claim does not exist", claim.Spec.VolumeName),
+			})
+		} else if err != nil {
+			return nil, err
+		}
+	}
+
+	if volume != nil {
+		info := NewVolumeInfo(volume)
+		d.Volume = &info
+		d.checkVolume(claim, volume)
+		d.checkNodeAffinity(volume, pods, nodes)
+		if err := d.checkAttachments(ctx, clientset, volume, pods); err != nil {
+			return nil, err
+		}
+	}
+	d.checkExpansion(claim, class)
+	d.checkSharing(claim, pods)
+
+	d.Healthy = true
+	for _, finding := range d.Findings {
+		if finding.Severity == pod.SeverityError {
+			d.Healthy = false
+		}
+	}
+	return d, nil
+}
+
+// DiagnosePodVolumes diagnoses every claim mounted by the pod,
+// including claims created for its generic ephemeral volumes
+func DiagnosePodVolumes(ctx context.Context, clientset kubernetes.Interface, namespace string, name string) ([]ClaimDiagnosis, error) {
+	p, err := clientset.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	diagnoses := []ClaimDiagnosis{}
+	for _, volume := range p.Spec.Volumes {
+		claimName := claimNameOf(p, volume)
+		if claimName == "" {
+			continue
+		}
+		diagnosis, err := DiagnoseClaim(ctx, clientset, namespace, claimName)
+		if apierrors.IsNotFound(err) {
+			diagnoses = append(diagnoses, ClaimDiagnosis{
+				Claim: ClaimInfo{Name: claimName, Namespace: namespace},
+				Pods:  []MountingPod{},
+				Findings: []pod.Finding{{
+					Severity: pod.SeverityError,
+					Reason:   "ClaimNotFound",
+					Message:  fmt.Sprintf("PersistentVolumeClaim %s used by volume %s does not exist", claimName, volume.Name),
+				}},
+			})
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		diagnoses = append(diagnoses, *diagnosis)
+	}
+	return diagnoses, nil
+}
+
+func (d *ClaimDiagnosis) add(finding pod.Finding) {
+	d.Findings = append(d.Findings, finding)
+}
+
+func newClaimInfo(claim *corev1.PersistentVolumeClaim) ClaimInfo {
+	info := ClaimInfo{
+		Name:         claim.Name,
+		Namespace:    claim.Namespace,
+		Phase:        string(claim.Status.Phase),
+		AccessModes:  FormatAccessModes(claim.Spec.AccessModes),
+		VolumeName:   claim.Spec.VolumeName,
+		SelectedNode: claim.Annotations[selectedNodeAnnotation],
+	}
+	if claim.Spec.StorageClassName != nil {
+		info.StorageClass = *claim.Spec.StorageClassName
+	}
+	if claim.Spec.VolumeMode != nil {
+		info.VolumeMode = string(*claim.Spec.VolumeMode)
+	}
+	if requested, ok := claim.Spec.Resources.Requests[corev1.ResourceStorage]; ok {
+		info.Requested = requested.String()
+	}
+	if capacity, ok := claim.Status.Capacity[corev1.ResourceStorage]; ok {
+		info.Capacity = capacity.String()
+	}
+	for _, condition := range claim.Status.Conditions {
+		formatted := fmt.Sprintf("%s=%s", condition.Type, condition.Status)
+		if condition.Message != "" {
+			formatted += ": " + condition.Message
+		}
+		info.Conditions = append(info.Conditions, formatted)
+	}
+	return info
+}
+
+// checkStorageClass resolves StorageClass of the claim, which is
+// the default one when claim does not set class name at all
+func (d *ClaimDiagnosis) checkStorageClass(ctx context.Context, clientset kubernetes.Interface, claim *corev1.PersistentVolumeClaim) (*storagev1.StorageClass, error) {
+	classes := clientset.StorageV1().StorageClasses()
+	if claim.Spec.StorageClassName == nil {
+		list, err := classes.List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		for i := range list.Items {
+			if isDefaultClass(&list.Items[i]) {
+				info := NewStorageClassInfo(&list.Items[i])
+				d.StorageClass = &info
+				return &list.Items[i], nil
+			}
+		}
+		if claim.Status.Phase == corev1.ClaimPending {
+			d.add(pod.Finding{
+				Severity: pod.SeverityError,
+				Reason:   "NoDefaultStorageClass",
+				Message:  "claim does not set storageClassName and cluster has no default StorageClass, so volume cannot be provisioned",
+			})
+		}
+		return nil, nil
+	}
+
+	name := *claim.Spec.StorageClassName
+	if name == "" {
+		// empty class name disables dynamic provisioning
+		return nil, nil
+	}
+	class, err := classes.Get(ctx, name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		severity := pod.SeverityWarning
+		if claim.Status.Phase == corev1.ClaimPending {
+			severity = pod.SeverityError
+		}
+		d.add(pod.Finding{
+			Severity: severity,
+			Reason:   "StorageClassNotFound",
+			Message:  fmt.Sprintf("StorageClass %s of the claim does not exist", name),
+		})
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	info := NewStorageClassInfo(class)
+	d.StorageClass = &info
+	return class, nil
+}
+
+// findPods returns pods mounting the claim and nodes they run on
+func (d *ClaimDiagnosis) findPods(ctx context.Context, clientset kubernetes.Interface, claim *corev1.PersistentVolumeClaim) ([]corev1.Pod, map[string]*corev1.Node, error) {
+	list, err := clientset.CoreV1().Pods(claim.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, nil, err
+	}
+	sort.Slice(list.Items, func(i, j int) bool {
+		return list.Items[i].Name < list.Items[j].Name
+	})
+
+	var pods []corev1.Pod
+	nodes := map[string]*corev1.Node{}
+	for _, p := range list.Items {
+		var readOnly, mounts bool
+		for _, volume := range p.Spec.Volumes {
+			if claimNameOf(&p, volume) == claim.Name {
+				mounts = true
+				readOnly = volume.PersistentVolumeClaim != nil && volume.PersistentVolumeClaim.ReadOnly
+			}
+		}
+		if !mounts || p.Status.Phase == corev1.PodSucceeded || p.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		pods = append(pods, p)
+
+		mounting := MountingPod{Name: p.Name, Phase: string(p.Status.Phase), Node: p.Spec.NodeName, ReadOnly: readOnly}
+		if p.Spec.NodeName != "" {
+			if _, ok := nodes[p.Spec.NodeName]; !ok {
+				node, err := clientset.CoreV1().Nodes().Get(ctx, p.Spec.NodeName, metav1.GetOptions{})
+				if err != nil && !apierrors.IsNotFound(err) {
+					return nil, nil, err
+				}
+				if err != nil {
+					node = nil
+				}
+				nodes[p.Spec.NodeName] = node
+			}
+			mounting.Zone = zoneOf(nodes[p.Spec.NodeName])
+		}
+		d.Pods = append(d.Pods, mounting)
+	}
+	return pods, nodes, nil
+}
+
+// checkPending explains why claim is not bound yet
+func (d *ClaimDiagnosis) checkPending(
+	ctx context.Context,
+	clientset kubernetes.Interface,
+	claim *corev1.PersistentVolumeClaim,
+	class *storagev1.StorageClass,
+	pods []corev1.Pod,
+	events []corev1.Event,
+) error {
+	if failures := pod.EventMessages(events, "ProvisioningFailed"); len(failures) > 0 {
+		d.add(pod.Finding{
+			Severity: pod.SeverityError,
+			Reason:   "ProvisioningFailed",
+			Message:  "provisioner failed to create volume for the claim",
+			Evidence: failures,
+		})
+		return nil
+	}
+
+	if class == nil {
+		if claim.Spec.StorageClassName == nil || *claim.Spec.StorageClassName != "" {
+			// missing class is already reported
+			return nil
+		}
+		available, err := countAvailableVolumes(ctx, clientset, claim)
+		if err != nil {
+			return err
+		}
+		d.add(pod.Finding{
+			Severity: pod.SeverityError,
+			Reason:   "NoMatchingVolume",
+			Message: fmt.Sprintf(
+				"claim disables dynamic provisioning with empty storageClassName and waits for PersistentVolume with matching access modes and capacity, %d Available volumes match",
+				available,
+			),
+		})
+		return nil
+	}
+
+	if class.VolumeBindingMode != nil && *class.VolumeBindingMode == storagev1.VolumeBindingWaitForFirstConsumer {
+		if len(pods) == 0 {
+			d.add(pod.Finding{
+				Severity: pod.SeverityWarning,
+				Reason:   "WaitForFirstConsumer",
+				Message:  fmt.Sprintf("StorageClass %s binds volumes only when a pod using the claim is scheduled, and no pod uses it yet", class.Name),
+			})
+			return nil
+		}
+		var unscheduled []string
+		for _, p := range pods {
+			if p.Spec.NodeName == "" {
+				unscheduled = append(unscheduled, fmt.Sprintf("%s: %s", p.Name, schedulingMessage(&p)))
+			}
+		}
+		if len(unscheduled) > 0 {
+			d.add(pod.Finding{
+				Severity: pod.SeverityError,
+				Reason:   "ConsumerNotScheduled",
+				Message:  "volume is provisioned only after pod using the claim is scheduled, but pods are not scheduled",
+				Evidence: unscheduled,
+			})
+			return nil
+		}
+	}
+
+	evidence := d.Events
+	if messages := pod.EventMessages(events, "ExternalProvisioning"); len(messages) > 0 {
+		evidence = messages
+	}
+	d.add(pod.Finding{
+		Severity: pod.SeverityError,
+		Reason:   "ClaimPending",
+		Message:  fmt.Sprintf("claim is still waiting for volume from provisioner %s", class.Provisioner),
+		Evidence: evidence,
+	})
+	return nil
+}
+
+// checkVolume compares bound volume with what claim asks for
+func (d *ClaimDiagnosis) checkVolume(claim *corev1.PersistentVolumeClaim, volume *corev1.PersistentVolume) {
+	for _, mode := range claim.Spec.AccessModes {
+		if !slices.Contains(volume.Spec.AccessModes, mode) {
+			d.add(pod.Finding{
+				Severity: pod.SeverityError,
+				Reason:   "AccessModeMismatch",
+				Message:  fmt.Sprintf("claim asks for access mode %s, which volume %s does not support", mode, volume.Name),
+				Evidence: []string{"volume access modes: " + strings.Join(FormatAccessModes(volume.Spec.AccessModes), ",")},
+			})
+		}
+	}
+	if ref := volume.Spec.ClaimRef; ref != nil && (ref.Namespace != claim.Namespace || ref.Name != claim.Name) {
+		d.add(pod.Finding{
+			Severity: pod.SeverityError,
+			Reason:   "VolumeBoundToOtherClaim",
+			Message:  fmt.Sprintf("volume %s is reserved for claim %s/%s", volume.Name, ref.Namespace, ref.Name),
+		})
+	}
+	if volume.Status.Phase == corev1.VolumeFailed || volume.Status.Phase == corev1.VolumeReleased {
+		d.add(pod.Finding{
+			Severity: pod.SeverityError,
+			Reason:   "Volume" + string(volume.Status.Phase),
+			Message:  fmt.Sprintf("volume %s is %s: %s", volume.Name, volume.Status.Phase, volume.Status.Message),
+		})
+	}
+}
+
+// checkExpansion reports claims requesting more storage than they have
+func (d *ClaimDiagnosis) checkExpansion(claim *corev1.PersistentVolumeClaim, class *storagev1.StorageClass) {
+	requested, hasRequest := claim.Spec.Resources.Requests[corev1.ResourceStorage]
+	capacity, hasCapacity := claim.Status.Capacity[corev1.ResourceStorage]
+	if claim.Status.Phase != corev1.ClaimBound || !hasRequest || !hasCapacity || requested.Cmp(capacity) <= 0 {
+		return
+	}
+
+	if class != nil && (class.AllowVolumeExpansion == nil || !*class.AllowVolumeExpansion) {
+		d.add(pod.Finding{
+			Severity: pod.SeverityError,
+			Reason:   "ExpansionNotAllowed",
+			Message: fmt.Sprintf(
+				"claim requests %s, but has %s and StorageClass %s does not allow volume expansion",
+				requested.String(), capacity.String(), class.Name,
+			),
+		})
+		return
+	}
+
+	for resource, status := range claim.Status.AllocatedResourceStatuses {
+		if status == corev1.PersistentVolumeClaimControllerResizeInfeasible || status == corev1.PersistentVolumeClaimNodeResizeInfeasible {
+			d.add(pod.Finding{
+				Severity: pod.SeverityError,
+				Reason:   "ExpansionFailed",
+				Message:  fmt.Sprintf("expansion of %s from %s to %s is infeasible: %s", resource, capacity.String(), requested.String(), status),
+				Evidence: d.Claim.Conditions,
+			})
+			return
+		}
+	}
+
+	message := fmt.Sprintf("claim is being expanded from %s to %s", capacity.String(), requested.String())
+	for _, condition := range claim.Status.Conditions {
+		if condition.Type == corev1.PersistentVolumeClaimFileSystemResizePending && condition.Status == corev1.ConditionTrue {
+			message += ", file system is resized when the volume is next mounted by a pod"
+		}
+	}
+	d.add(pod.Finding{
+		Severity: pod.SeverityWarning,
+		Reason:   "ExpansionInProgress",
+		Message:  message,
+		Evidence: d.Claim.Conditions,
+	})
+}
+
+// checkNodeAffinity reports pods, which run or are waiting to run
+// on nodes, from which the volume cannot be reached
+func (d *ClaimDiagnosis) checkNodeAffinity(volume *corev1.PersistentVolume, pods []corev1.Pod, nodes map[string]*corev1.Node) {
+	var selector *corev1.NodeSelector
+	if volume.Spec.NodeAffinity != nil {
+		selector = volume.Spec.NodeAffinity.Required
+	}
+
+	for _, p := range pods {
+		if p.Spec.NodeName == "" {
+			message := schedulingMessage(&p)
+			if strings.Contains(message, "volume node affinity conflict") {
+				d.add(pod.Finding{
+					Severity: pod.SeverityError,
+					Reason:   "ZoneMismatch",
+					Message:  fmt.Sprintf("pod %s cannot be scheduled to any node, from which volume %s is reachable", p.Name, volume.Name),
+					Evidence: append([]string{message}, formatNodeSelectorOrNil(selector)...),
+				})
+			}
+			continue
+		}
+		node := nodes[p.Spec.NodeName]
+		if selector == nil || node == nil || pod.NodeSelectorMatches(selector, node) {
+			continue
+		}
+		d.add(pod.Finding{
+			Severity: pod.SeverityError,
+			Reason:   "ZoneMismatch",
+			Message: fmt.Sprintf(
+				"pod %s runs on node %s in zone %s, but volume %s is only reachable from nodes matching its node affinity",
+				p.Name, node.Name, zoneOrUnknown(node), volume.Name,
+			),
+			Evidence: pod.FormatNodeSelectorTerms(selector),
+		})
+	}
+}
+
+// checkAttachments reads VolumeAttachments of CSI volume and reports
+// errors of attaching and detaching it to nodes
+func (d *ClaimDiagnosis) checkAttachments(ctx context.Context, clientset kubernetes.Interface, volume *corev1.PersistentVolume, pods []corev1.Pod) error {
+	if volume.Spec.CSI == nil {
+		return nil
+	}
+	list, err := clientset.StorageV1().VolumeAttachments().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	sort.Slice(list.Items, func(i, j int) bool {
+		return list.Items[i].Name < list.Items[j].Name
+	})
+
+	podNodes := map[string]bool{}
+	for _, p := range pods {
+		if p.Spec.NodeName != "" {
+			podNodes[p.Spec.NodeName] = true
+		}
+	}
+	for _, attachment := range list.Items {
+		if attachment.Spec.Source.PersistentVolumeName == nil || *attachment.Spec.Source.PersistentVolumeName != volume.Name {
+			continue
+		}
+		info := AttachmentInfo{
+			Name:     attachment.Name,
+			Node:     attachment.Spec.NodeName,
+			Attached: attachment.Status.Attached,
+		}
+		if attachment.Status.AttachError != nil {
+			info.AttachError = attachment.Status.AttachError.Message
+			d.add(pod.Finding{
+				Severity: pod.SeverityError,
+				Reason:   "AttachFailed",
+				Message:  fmt.Sprintf("volume %s cannot be attached to node %s: %s", volume.Name, info.Node, info.AttachError),
+			})
+		}
+		if attachment.Status.DetachError != nil {
+			info.DetachError = attachment.Status.DetachError.Message
+			d.add(pod.Finding{
+				Severity: pod.SeverityWarning,
+				Reason:   "DetachFailed",
+				Message:  fmt.Sprintf("volume %s cannot be detached from node %s: %s", volume.Name, info.Node, info.DetachError),
+			})
+		}
+		if !podNodes[info.Node] && slices.Contains(volume.Spec.AccessModes, corev1.ReadWriteOnce) && len(podNodes) > 0 {
+			d.add(pod.Finding{
+				Severity: pod.SeverityWarning,
+				Reason:   "StaleAttachment",
+				Message:  fmt.Sprintf("volume %s is still attached to node %s, where no pod uses it, which blocks attaching it to other nodes", volume.Name, info.Node),
+			})
+		}
+		d.Attachments = append(d.Attachments, info)
+	}
+	return nil
+}
+
+// checkSharing reports pods, which cannot use the claim at the same time because of its access modes
+func (d *ClaimDiagnosis) checkSharing(claim *corev1.PersistentVolumeClaim, pods []corev1.Pod) {
+	modes := claim.Spec.AccessModes
+	if slices.Contains(modes, corev1.ReadWriteOncePod) && len(pods) > 1 {
+		var names []string
+		for _, p := range pods {
+			names = append(names, p.Name)
+		}
+		d.add(pod.Finding{
+			Severity: pod.SeverityError,
+			Reason:   "ReadWriteOncePodConflict",
+			Message:  "claim with ReadWriteOncePod access mode can only be used by one pod, but more pods use it",
+			Evidence: names,
+		})
+		return
+	}
+	if slices.Contains(modes, corev1.ReadWriteMany) || slices.Contains(modes, corev1.ReadOnlyMany) || !slices.Contains(modes, corev1.ReadWriteOnce) {
+		return
+	}
+	nodes := map[string]bool{}
+	var evidence []string
+	for _, p := range pods {
+		if p.Spec.NodeName != "" {
+			nodes[p.Spec.NodeName] = true
+			evidence = append(evidence, fmt.Sprintf("%s on %s", p.Name, p.Spec.NodeName))
+		}
+	}
+	if len(nodes) > 1 {
+		d.add(pod.Finding{
+			Severity: pod.SeverityError,
+			Reason:   "MultiAttach",
+			Message:  "claim with ReadWriteOnce access mode can only be attached to one node, but pods using it run on different nodes",
+			Evidence: evidence,
+		})
+	}
+}
+
+// claimNameOf returns name of claim used by the volume of the pod, generic
+// ephemeral volumes use claim named after the pod and the volume
+func claimNameOf(p *corev1.Pod, volume corev1.Volume) string {
+	if volume.PersistentVolumeClaim != nil {
+		return volume.PersistentVolumeClaim.ClaimName
+	}
+	if volume.Ephemeral != nil {
+		return p.Name + "-" + volume.Name
+	}
+	return ""
+}
+
+func countAvailableVolumes(ctx context.Context, clientset kubernetes.Interface, claim *corev1.PersistentVolumeClaim) (int, error) {
+	list, err := clientset.CoreV1().PersistentVolumes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return 0, err
+	}
+	requested := claim.Spec.Resources.Requests[corev1.ResourceStorage]
+	count := 0
+	for _, volume := range list.Items {
+		if volume.Status.Phase != corev1.VolumeAvailable || volume.Spec.StorageClassName != "" {
+			continue
+		}
+		capacity := volume.Spec.Capacity[corev1.ResourceStorage]
+		if capacity.Cmp(requested) < 0 {
+			continue
+		}
+		matches := true
+		for _, mode := range claim.Spec.AccessModes {
+			if !slices.Contains(volume.Spec.AccessModes, mode) {
+				matches = false
+			}
+		}
+		if matches {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func schedulingMessage(p *corev1.Pod) string {
+	for _, condition := range p.Status.Conditions {
+		if condition.Type == corev1.PodScheduled && condition.Status == corev1.ConditionFalse {
+			return condition.Message
+		}
+	}
+	return "not scheduled yet"
+}
+
+func zoneOf(node *corev1.Node) string {
+	if node == nil {
+		return ""
+	}
+	if zone, ok := node.Labels[corev1.LabelTopologyZone]; ok {
+		return zone
+	}
+	return node.Labels[corev1.LabelFailureDomainBetaZone]
+}
+
+func zoneOrUnknown(node *corev1.Node) string {
+	if zone := zoneOf(node); zone != "" {
+		return zone
+	}
+	return "unknown"
+}
+
+func formatNodeSelectorOrNil(selector *corev1.NodeSelector) []string {
+	if selector == nil {
+		return nil
+	}
+	return pod.FormatNodeSelectorTerms(selector)
+}
+
+func claimEvents(ctx context.Context, clientset kubernetes.Interface, claim *corev1.PersistentVolumeClaim) ([]corev1.Event, error) {
+	list, err := clientset.CoreV1().Events(claim.Namespace).List(ctx, metav1.ListOptions{
+		FieldSelector: fields.AndSelectors(
+			fields.OneTermEqualSelector("involvedObject.kind", "PersistentVolumeClaim"),
+			fields.OneTermEqualSelector("involvedObject.name", claim.Name),
+		).String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var events []corev1.Event
+	for _, event := range list.Items {
+		if event.InvolvedObject.Kind != "PersistentVolumeClaim" || event.InvolvedObject.Name != claim.Name ||
+			(event.InvolvedObject.UID != "" && event.InvolvedObject.UID != claim.UID) {
+			continue
+		}
+		events = append(events, event)
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return pod.EventTime(events[i]).Before(pod.EventTime(events[j]))
+	})
+	if len(events) > maxClaimEvents {
+		events = events[len(events)-maxClaimEvents:]
+	}
+	return events, nil
+}
@@ -0,0 +1,262 @@
+package storage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/strowk/mcp-k8s-go/internal/k8s/core/v1/pod"
+	"github.com/strowk/mcp-k8s-go/internal/utils"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func storageRequest(size string) corev1.VolumeResourceRequirements {
+	return corev1.VolumeResourceRequirements{
+		Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse(size)},
+	}
+}
+
+func claimPod(name, node, claim string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "app"},
+		Spec: corev1.PodSpec{
+			NodeName: node,
+			Volumes: []corev1.Volume{{
+				Name: "data",
+				VolumeSource: corev1.VolumeSource{
+					PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: claim},
+				},
+			}},
+		},
+		Status: corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+}
+
+func zonedNode(name, zone string) *corev1.Node {
+	return &corev1.Node{ObjectMeta: metav1.ObjectMeta{
+		Name:   name,
+		Labels: map[string]string{corev1.LabelTopologyZone: zone},
+	}}
+}
+
+func boundClaim(name, volume string, modes ...corev1.PersistentVolumeAccessMode) *corev1.PersistentVolumeClaim {
+	return &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "app"},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			StorageClassName: utils.Ptr("standard"),
+			AccessModes:      modes,
+			Resources:        storageRequest("1Gi"),
+			VolumeName:       volume,
+		},
+		Status: corev1.PersistentVolumeClaimStatus{
+			Phase:    corev1.ClaimBound,
+			Capacity: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("1Gi")},
+		},
+	}
+}
+
+func csiVolume(name, claim, zone string, modes ...corev1.PersistentVolumeAccessMode) *corev1.PersistentVolume {
+	return &corev1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: corev1.PersistentVolumeSpec{
+			Capacity:                      corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("1Gi")},
+			AccessModes:                   modes,
+			PersistentVolumeReclaimPolicy: corev1.PersistentVolumeReclaimDelete,
+			StorageClassName:              "standard",
+			ClaimRef:                      &corev1.ObjectReference{Namespace: "app", Name: claim},
+			PersistentVolumeSource: corev1.PersistentVolumeSource{
+				CSI: &corev1.CSIPersistentVolumeSource{Driver: "disk.csi.example.com", VolumeHandle: name},
+			},
+			NodeAffinity: &corev1.VolumeNodeAffinity{Required: &corev1.NodeSelector{
+				NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+					MatchExpressions: []corev1.NodeSelectorRequirement{{
+						Key: corev1.LabelTopologyZone, Operator: corev1.NodeSelectorOpIn, Values: []string{zone},
+					}},
+				}},
+			}},
+		},
+		Status: corev1.PersistentVolumeStatus{Phase: corev1.VolumeBound},
+	}
+}
+
+func reasons(findings []pod.Finding) []string {
+	var result []string
+	for _, finding := range findings {
+		result = append(result, finding.Reason)
+	}
+	return result
+}
+
+func TestDiagnoseClaim(t *testing.T) {
+	waitForConsumer := storagev1.VolumeBindingWaitForFirstConsumer
+	objects := []runtime.Object{
+		&storagev1.StorageClass{
+			ObjectMeta:        metav1.ObjectMeta{Name: "standard", Annotations: map[string]string{isDefaultClassAnnotation: "true"}},
+			Provisioner:       "disk.csi.example.com",
+			VolumeBindingMode: &waitForConsumer,
+		},
+		zonedNode("node-a", "zone-a"),
+		zonedNode("node-b", "zone-b"),
+
+		&corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: "unused", Namespace: "app"},
+			Spec: corev1.PersistentVolumeClaimSpec{
+				AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+				Resources:   storageRequest("1Gi"),
+			},
+			Status: corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimPending},
+		},
+		&corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: "missing-class", Namespace: "app"},
+			Spec: corev1.PersistentVolumeClaimSpec{
+				StorageClassName: utils.Ptr("fast"),
+				Resources:        storageRequest("1Gi"),
+			},
+			Status: corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimPending},
+		},
+
+		boundClaim("zoned", "pv-zoned", corev1.ReadWriteOnce),
+		csiVolume("pv-zoned", "zoned", "zone-a", corev1.ReadWriteOnce),
+		claimPod("zoned-0", "node-b", "zoned"),
+
+		boundClaim("shared", "pv-shared", corev1.ReadWriteOnce),
+		csiVolume("pv-shared", "shared", "zone-a", corev1.ReadWriteOnce),
+		claimPod("shared-0", "node-a", "shared"),
+		claimPod("shared-1", "node-a2", "shared"),
+		zonedNode("node-a2", "zone-a"),
+		&storagev1.VolumeAttachment{
+			ObjectMeta: metav1.ObjectMeta{Name: "csi-shared-a2"},
+			Spec: storagev1.VolumeAttachmentSpec{
+				Attacher: "disk.csi.example.com",
+				NodeName: "node-a2",
+				Source:   storagev1.VolumeAttachmentSource{PersistentVolumeName: utils.Ptr("pv-shared")},
+			},
+			Status: storagev1.VolumeAttachmentStatus{
+				AttachError: &storagev1.VolumeError{Message: "disk is attached to other instance"},
+			},
+		},
+		&storagev1.VolumeAttachment{
+			ObjectMeta: metav1.ObjectMeta{Name: "csi-shared-a"},
+			Spec: storagev1.VolumeAttachmentSpec{
+				Attacher: "disk.csi.example.com",
+				NodeName: "node-a",
+				Source:   storagev1.VolumeAttachmentSource{PersistentVolumeName: utils.Ptr("pv-shared")},
+			},
+			Status: storagev1.VolumeAttachmentStatus{Attached: true},
+		},
+	}
+
+	grown := boundClaim("grown", "pv-grown", corev1.ReadWriteOnce)
+	grown.Spec.Resources = storageRequest("5Gi")
+	objects = append(objects, grown, csiVolume("pv-grown", "grown", "zone-a", corev1.ReadWriteOnce))
+
+	clientset := fake.NewClientset(objects...)
+
+	t.Run("waiting for first consumer with default class", func(t *testing.T) {
+		diagnosis, err := DiagnoseClaim(context.Background(), clientset, "app", "unused")
+		require.NoError(t, err)
+		assert.True(t, diagnosis.Healthy)
+		require.NotNil(t, diagnosis.StorageClass)
+		assert.Equal(t, "standard", diagnosis.StorageClass.Name)
+		assert.True(t, diagnosis.StorageClass.Default)
+		assert.Equal(t, "WaitForFirstConsumer", diagnosis.StorageClass.VolumeBindingMode)
+		assert.Equal(t, []string{"WaitForFirstConsumer"}, reasons(diagnosis.Findings))
+		assert.Empty(t, diagnosis.Pods)
+	})
+
+	t.Run("missing storage class", func(t *testing.T) {
+		diagnosis, err := DiagnoseClaim(context.Background(), clientset, "app", "missing-class")
+		require.NoError(t, err)
+		assert.False(t, diagnosis.Healthy)
+		assert.Nil(t, diagnosis.StorageClass)
+		assert.Equal(t, []string{"StorageClassNotFound"}, reasons(diagnosis.Findings))
+	})
+
+	t.Run("pod in other zone than volume", func(t *testing.T) {
+		diagnosis, err := DiagnoseClaim(context.Background(), clientset, "app", "zoned")
+		require.NoError(t, err)
+		assert.False(t, diagnosis.Healthy)
+		assert.Equal(t, []MountingPod{{Name: "zoned-0", Phase: "Running", Node: "node-b", Zone: "zone-b"}}, diagnosis.Pods)
+		require.Len(t, diagnosis.Findings, 1)
+		assert.Equal(t, pod.Finding{
+			Severity: pod.SeverityError,
+			Reason:   "ZoneMismatch",
+			Message:  "pod zoned-0 runs on node node-b in zone zone-b, but volume pv-zoned is only reachable from nodes matching its node affinity",
+			Evidence: []string{"topology.kubernetes.io/zone In zone-a"},
+		}, diagnosis.Findings[0])
+		require.NotNil(t, diagnosis.Volume)
+		assert.Equal(t, "csi disk.csi.example.com: pv-zoned", diagnosis.Volume.Source)
+	})
+
+	t.Run("read write once volume used from two nodes", func(t *testing.T) {
+		diagnosis, err := DiagnoseClaim(context.Background(), clientset, "app", "shared")
+		require.NoError(t, err)
+		assert.False(t, diagnosis.Healthy)
+		assert.Equal(t, []AttachmentInfo{
+			{Name: "csi-shared-a", Node: "node-a", Attached: true},
+			{Name: "csi-shared-a2", Node: "node-a2", AttachError: "disk is attached to other instance"},
+		}, diagnosis.Attachments)
+		assert.Equal(t, []string{"AttachFailed", "MultiAttach"}, reasons(diagnosis.Findings))
+		assert.Equal(t, []string{"shared-0 on node-a", "shared-1 on node-a2"}, diagnosis.Findings[1].Evidence)
+	})
+
+	t.Run("expansion not allowed by class", func(t *testing.T) {
+		diagnosis, err := DiagnoseClaim(context.Background(), clientset, "app", "grown")
+		require.NoError(t, err)
+		require.Len(t, diagnosis.Findings, 1)
+		assert.Equal(t, "ExpansionNotAllowed", diagnosis.Findings[0].Reason)
+		assert.Equal(t, "claim requests 5Gi, but has 1Gi and StorageClass standard does not allow volume expansion", diagnosis.Findings[0].Message)
+	})
+
+	t.Run("volumes of pod", func(t *testing.T) {
+		ephemeral := claimPod("worker", "node-a", "zoned")
+		ephemeral.Spec.Volumes = append(ephemeral.Spec.Volumes, corev1.Volume{
+			Name:         "scratch",
+			VolumeSource: corev1.VolumeSource{Ephemeral: &corev1.EphemeralVolumeSource{}},
+		})
+		require.NoError(t, clientset.Tracker().Add(ephemeral))
+
+		diagnoses, err := DiagnosePodVolumes(context.Background(), clientset, "app", "worker")
+		require.NoError(t, err)
+		require.Len(t, diagnoses, 2)
+		assert.Equal(t, "zoned", diagnoses[0].Claim.Name)
+		assert.Equal(t, "worker-scratch", diagnoses[1].Claim.Name)
+		assert.Equal(t, []string{"ClaimNotFound"}, reasons(diagnoses[1].Findings))
+	})
+}
+
+func TestListUnboundVolumes(t *testing.T) {
+	released := csiVolume("pv-released", "old", "zone-a", corev1.ReadWriteOnce)
+	released.Spec.PersistentVolumeReclaimPolicy = corev1.PersistentVolumeReclaimRetain
+	released.Status.Phase = corev1.VolumeReleased
+	available := &corev1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: "pv-available"},
+		Spec: corev1.PersistentVolumeSpec{
+			AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteMany},
+			PersistentVolumeSource: corev1.PersistentVolumeSource{
+				NFS: &corev1.NFSVolumeSource{Server: "nfs", Path: "/exports/a"},
+			},
+		},
+		Status: corev1.PersistentVolumeStatus{Phase: corev1.VolumeAvailable},
+	}
+	clientset := fake.NewClientset(released, available, csiVolume("pv-bound", "zoned", "zone-a"))
+
+	volumes, err := ListUnboundVolumes(context.Background(), clientset)
+	require.NoError(t, err)
+	require.Len(t, volumes, 2)
+	assert.Equal(t, "pv-available", volumes[0].Name)
+	assert.Equal(t, []string{"RWX"}, volumes[0].AccessModes)
+	assert.Equal(t, "nfs: nfs:/exports/a", volumes[0].Source)
+	assert.Equal(t, "pv-released", volumes[1].Name)
+	assert.Equal(t, "app/old", volumes[1].Claim)
+	assert.Equal(t,
+		"claim app/old was deleted, but volume keeps its data due to Retain reclaim policy, delete the volume or remove its claimRef to make it Available again",
+		volumes[1].Hint,
+	)
+}
@@ -0,0 +1,186 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/strowk/mcp-k8s-go/internal/k8s/core/v1/pod"
+	"github.com/strowk/mcp-k8s-go/internal/utils"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// VolumeInfo describes PersistentVolume with where its data lives and which nodes can reach it
+type VolumeInfo struct {
+	Name          string   `json:"name"`
+	Phase         string   `json:"phase"`
+	Capacity      string   `json:"capacity,omitempty"`
+	AccessModes   []string `json:"accessModes,omitempty"`
+	VolumeMode    string   `json:"volumeMode,omitempty"`
+	ReclaimPolicy string   `json:"reclaimPolicy,omitempty"`
+	StorageClass  string   `json:"storageClass,omitempty"`
+	Claim         string   `json:"claim,omitempty"`
+	Source        string   `json:"source,omitempty"`
+	NodeAffinity  []string `json:"nodeAffinity,omitempty"`
+	Reason        string   `json:"reason,omitempty"`
+	Message       string   `json:"message,omitempty"`
+	Age           string   `json:"age"`
+	// Hint tells what to do with volume, which is not bound
+	Hint string `json:"hint,omitempty"`
+}
+
+type StorageClassInfo struct {
+	Name                 string   `json:"name"`
+	Provisioner          string   `json:"provisioner"`
+	VolumeBindingMode    string   `json:"volumeBindingMode"`
+	ReclaimPolicy        string   `json:"reclaimPolicy,omitempty"`
+	AllowVolumeExpansion bool     `json:"allowVolumeExpansion"`
+	Default              bool     `json:"default,omitempty"`
+	AllowedTopologies    []string `json:"allowedTopologies,omitempty"`
+}
+
+// Annotations marking default StorageClass, beta one is still honored
+const (
+	isDefaultClassAnnotation     = "storageclass.kubernetes.io/is-default-class"
+	betaIsDefaultClassAnnotation = "storageclass.beta.kubernetes.io/is-default-class"
+)
+
+// ListUnboundVolumes returns PersistentVolumes, which are not bound to any claim,
+// that is Available volumes waiting for claim and Released or Failed volumes
+// still holding data of deleted claims
+func ListUnboundVolumes(ctx context.Context, clientset kubernetes.Interface) ([]VolumeInfo, error) {
+	list, err := clientset.CoreV1().PersistentVolumes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(list.Items, func(i, j int) bool {
+		return list.Items[i].Name < list.Items[j].Name
+	})
+
+	volumes := []VolumeInfo{}
+	for i := range list.Items {
+		volume := &list.Items[i]
+		if volume.Status.Phase == corev1.VolumeBound {
+			continue
+		}
+		info := NewVolumeInfo(volume)
+		switch volume.Status.Phase {
+		case corev1.VolumeAvailable:
+			info.Hint = "volume is not used by any claim and can be bound by claim with matching storage class, access modes and capacity"
+		case corev1.VolumeReleased:
+			info.Hint = fmt.Sprintf(
+				"claim %s was deleted, but volume keeps its data due to %s reclaim policy, delete the volume or remove its claimRef to make it Available again",
+				info.Claim, info.ReclaimPolicy,
+			)
+		case corev1.VolumeFailed:
+			info.Hint = "automatic reclamation of the volume failed, check reason and message and clean it up manually"
+		}
+		volumes = append(volumes, info)
+	}
+	return volumes, nil
+}
+
+func NewVolumeInfo(volume *corev1.PersistentVolume) VolumeInfo {
+	info := VolumeInfo{
+		Name:          volume.Name,
+		Phase:         string(volume.Status.Phase),
+		AccessModes:   FormatAccessModes(volume.Spec.AccessModes),
+		ReclaimPolicy: string(volume.Spec.PersistentVolumeReclaimPolicy),
+		StorageClass:  volume.Spec.StorageClassName,
+		Source:        formatSource(volume.Spec.PersistentVolumeSource),
+		Reason:        volume.Status.Reason,
+		Message:       volume.Status.Message,
+		Age:           utils.FormatAge(time.Since(volume.CreationTimestamp.Time)),
+	}
+	if capacity, ok := volume.Spec.Capacity[corev1.ResourceStorage]; ok {
+		info.Capacity = capacity.String()
+	}
+	if volume.Spec.VolumeMode != nil {
+		info.VolumeMode = string(*volume.Spec.VolumeMode)
+	}
+	if ref := volume.Spec.ClaimRef; ref != nil {
+		info.Claim = ref.Namespace + "/" + ref.Name
+	}
+	if volume.Spec.NodeAffinity != nil && volume.Spec.NodeAffinity.Required != nil {
+		info.NodeAffinity = pod.FormatNodeSelectorTerms(volume.Spec.NodeAffinity.Required)
+	}
+	return info
+}
+
+func NewStorageClassInfo(class *storagev1.StorageClass) StorageClassInfo {
+	info := StorageClassInfo{
+		Name:              class.Name,
+		Provisioner:       class.Provisioner,
+		VolumeBindingMode: string(storagev1.VolumeBindingImmediate),
+		Default:           isDefaultClass(class),
+	}
+	if class.VolumeBindingMode != nil {
+		info.VolumeBindingMode = string(*class.VolumeBindingMode)
+	}
+	if class.ReclaimPolicy != nil {
+		info.ReclaimPolicy = string(*class.ReclaimPolicy)
+	}
+	if class.AllowVolumeExpansion != nil {
+		info.AllowVolumeExpansion = *class.AllowVolumeExpansion
+	}
+	for _, term := range class.AllowedTopologies {
+		var requirements []string
+		for _, expression := range term.MatchLabelExpressions {
+			requirements = append(requirements, fmt.Sprintf("%s in (%s)", expression.Key, strings.Join(expression.Values, ",")))
+		}
+		info.AllowedTopologies = append(info.AllowedTopologies, strings.Join(requirements, " and "))
+	}
+	return info
+}
+
+func isDefaultClass(class *storagev1.StorageClass) bool {
+	return class.Annotations[isDefaultClassAnnotation] == "true" || class.Annotations[betaIsDefaultClassAnnotation] == "true"
+}
+
+// FormatAccessModes abbreviates access modes like kubectl get pv does
+func FormatAccessModes(modes []corev1.PersistentVolumeAccessMode) []string {
+	var formatted []string
+	for _, mode := range modes {
+		switch mode {
+		case corev1.ReadWriteOnce:
+			formatted = append(formatted, "RWO")
+		case corev1.ReadOnlyMany:
+			formatted = append(formatted, "ROX")
+		case corev1.ReadWriteMany:
+			formatted = append(formatted, "RWX")
+		case corev1.ReadWriteOncePod:
+			formatted = append(formatted, "RWOP")
+		default:
+			formatted = append(formatted, string(mode))
+		}
+	}
+	return formatted
+}
+
+// formatSource describes backend of the volume for the most common sources
+func formatSource(source corev1.PersistentVolumeSource) string {
+	switch {
+	case source.CSI != nil:
+		return fmt.Sprintf("csi %s: %s", source.CSI.Driver, source.CSI.VolumeHandle)
+	case source.HostPath != nil:
+		return "hostPath: " + source.HostPath.Path
+	case source.Local != nil:
+		return "local: " + source.Local.Path
+	case source.NFS != nil:
+		return fmt.Sprintf("nfs: %s:%s", source.NFS.Server, source.NFS.Path)
+	case source.ISCSI != nil:
+		return fmt.Sprintf("iscsi: %s %s", source.ISCSI.TargetPortal, source.ISCSI.IQN)
+	case source.FC != nil:
+		return "fc"
+	case source.CephFS != nil:
+		return "cephfs: " + source.CephFS.Path
+	case source.RBD != nil:
+		return "rbd: " + source.RBD.RBDImage
+	}
+	return ""
+}
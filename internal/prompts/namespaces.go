@@ -14,7 +14,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
-func NewListNamespacesPrompt(pool k8s.ClientPool) fxctx.Prompt {
+func NewListNamespacesPrompt(pool k8s.ClientPool, defaults k8s.Defaults) fxctx.Prompt {
 	return fxctx.NewPrompt(
 		mcp.Prompt{
 			Name: "list-k8s-namespaces",
@@ -32,7 +32,7 @@ func NewListNamespacesPrompt(pool k8s.ClientPool) fxctx.Prompt {
 			},
 		},
 		func(ctx context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
-			k8sContext := req.Params.Arguments["context"]
+			k8sContext := defaults.ResolveContext(ctx, req.Params.Arguments["context"])
 			clientset, err := pool.GetClientset(k8sContext)
 			if err != nil {
 				return nil, fmt.Errorf("failed to get k8s client: %w", err)
@@ -51,9 +51,11 @@ func NewListNamespacesPrompt(pool k8s.ClientPool) fxctx.Prompt {
 			})
 
 			ofContextMsg := ""
-			currentContext, err := k8s.GetCurrentContext()
-			if err == nil && currentContext != "" {
-				ofContextMsg = fmt.Sprintf(", context '%s'", currentContext)
+			if k8sContext == "" {
+				k8sContext, _ = k8s.GetCurrentContext()
+			}
+			if k8sContext != "" {
+				ofContextMsg = fmt.Sprintf(", context '%s'", k8sContext)
 			}
 
 			var messages = make(
@@ -15,7 +15,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
-func NewListPodsPrompt(pool k8s.ClientPool) fxctx.Prompt {
+func NewListPodsPrompt(pool k8s.ClientPool, defaults k8s.Defaults) fxctx.Prompt {
 	return fxctx.NewPrompt(
 		mcp.Prompt{
 			Name: "list-k8s-pods",
@@ -26,19 +26,23 @@ func NewListPodsPrompt(pool k8s.ClientPool) fxctx.Prompt {
 				{
 					Name: "namespace",
 					Description: utils.Ptr(
-						"Namespace to list Pods from, defaults to all namespaces",
+						"Namespace to list Pods from, defaults to namespace set for the session or all namespaces",
 					),
 					Required: utils.Ptr(false),
 				},
 			},
 		},
 		func(ctx context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+			k8sContext := defaults.ResolveContext(ctx, "")
 			k8sNamespace := req.Params.Arguments["namespace"]
+			if k8sNamespace == "" {
+				k8sNamespace = defaults.GetSessionDefaults(ctx).Namespace
+			}
 			if k8sNamespace == "" {
 				k8sNamespace = metav1.NamespaceAll
 			}
 
-			clientset, err := pool.GetClientset("")
+			clientset, err := pool.GetClientset(k8sContext)
 			if err != nil {
 				return nil, fmt.Errorf("failed to get k8s client: %w", err)
 			}
@@ -96,9 +100,11 @@ func NewListPodsPrompt(pool k8s.ClientPool) fxctx.Prompt {
 			}
 
 			ofContextMsg := ""
-			currentContext, err := k8s.GetCurrentContext()
-			if err == nil && currentContext != "" {
-				ofContextMsg = fmt.Sprintf(", context '%s'", currentContext)
+			if k8sContext == "" {
+				k8sContext, _ = k8s.GetCurrentContext()
+			}
+			if k8sContext != "" {
+				ofContextMsg = fmt.Sprintf(", context '%s'", k8sContext)
 			}
 
 			return &mcp.GetPromptResult{
@@ -111,7 +117,7 @@ func NewListPodsPrompt(pool k8s.ClientPool) fxctx.Prompt {
 	).WithCompleter(func(ctx context.Context, arg *mcp.PromptArgument, value string) (*mcp.CompleteResult, error) {
 		if arg.Name == "namespace" {
 
-			client, err := pool.GetClientset("")
+			client, err := pool.GetClientset(defaults.ResolveContext(ctx, ""))
 
 			if err != nil {
 				return nil, fmt.Errorf("failed to get k8s client: %w", err)
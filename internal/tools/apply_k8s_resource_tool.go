@@ -22,12 +22,14 @@ import (
 	"k8s.io/client-go/dynamic"
 )
 
-func NewApplyK8sResourceTool(clientPool k8s.ClientPool) fxctx.Tool {
+func NewApplyK8sResourceTool(clientPool k8s.ClientPool, defaults k8s.Defaults) fxctx.Tool {
 	contextProperty := "context"
+	namespaceProperty := "namespace"
 	manifestProperty := "manifest"
 
 	inputSchema := toolinput.NewToolInputSchema(
 		toolinput.WithString(contextProperty, "Name of the Kubernetes context to use, defaults to current context"),
+		toolinput.WithString(namespaceProperty, "Namespace for namespaced resources that do not specify one in the manifest, defaults to namespace of the context"),
 		toolinput.WithRequiredString(manifestProperty, "YAML manifest of the resource to apply"),
 	)
 
@@ -43,7 +45,7 @@ func NewApplyK8sResourceTool(clientPool k8s.ClientPool) fxctx.Tool {
 				return utils.ErrResponse(err)
 			}
 
-			k8sCtx := input.StringOr(contextProperty, "")
+			k8sCtx := defaults.ResolveContext(ctx, input.StringOr(contextProperty, ""))
 			clientset, err := clientPool.GetClientset(k8sCtx)
 			if err != nil {
 				return utils.ErrResponse(err)
@@ -59,6 +61,11 @@ func NewApplyK8sResourceTool(clientPool k8s.ClientPool) fxctx.Tool {
 				return utils.ErrResponse(fmt.Errorf("failed to retrieve dynamic client from the client pool: %w", err))
 			}
 
+			defaultNamespace, err := defaults.ResolveNamespace(ctx, k8sCtx, input.StringOr(namespaceProperty, ""))
+			if err != nil {
+				return utils.ErrResponse(fmt.Errorf("failed to resolve namespace: %w", err))
+			}
+
 			decoder := yaml.NewYAMLOrJSONDecoder(bytes.NewReader([]byte(manifest)), 4096)
 			var results []string
 			for {
@@ -74,19 +81,20 @@ func NewApplyK8sResourceTool(clientPool k8s.ClientPool) fxctx.Tool {
 					continue
 				}
 
-				namespace := obj.GetNamespace()
-				if namespace == "" {
-					namespace = "default"
-				}
-
 				gvk := obj.GroupVersionKind()
 				apiResource, err := findAPIResource(clientset.Discovery(), gvk)
 				if err != nil {
 					return utils.ErrResponse(fmt.Errorf("failed to find API resource: %w", err))
 				}
 
+				namespace := ""
 				var dr dynamic.ResourceInterface
 				if apiResource.Namespaced {
+					namespace = obj.GetNamespace()
+					if namespace == "" {
+						namespace = defaultNamespace
+						obj.SetNamespace(namespace)
+					}
 					dr = dynamicClient.Resource(gvk.GroupVersion().WithResource(apiResource.Name)).Namespace(namespace)
 				} else {
 					dr = dynamicClient.Resource(gvk.GroupVersion().WithResource(apiResource.Name))
@@ -110,11 +118,12 @@ func NewApplyK8sResourceTool(clientPool k8s.ClientPool) fxctx.Tool {
 					return utils.ErrResponse(fmt.Errorf("failed to patch resource: %w", err))
 				}
 
-				resourceText := fmt.Sprintf("%s.%s/%s", strings.ToLower(apiResource.Name), gvk.Group, obj.GetName())
-				if gvk.Group == "" {
-					resourceText = fmt.Sprintf("%s/%s", strings.ToLower(apiResource.Name), obj.GetName())
+				resourceText := formatResourceText(apiResource.Name, gvk.Group, obj.GetName())
+				if namespace != "" {
+					results = append(results, fmt.Sprintf("%s %s in namespace %s", resourceText, action, namespace))
+				} else {
+					results = append(results, fmt.Sprintf("%s %s", resourceText, action))
 				}
-				results = append(results, fmt.Sprintf("%s %s", resourceText, action))
 			}
 
 			return &mcp.CallToolResult{
@@ -129,6 +138,15 @@ func NewApplyK8sResourceTool(clientPool k8s.ClientPool) fxctx.Tool {
 	)
 }
 
+// formatResourceText formats resource similarly to how kubectl does it,
+// for example "deployments.apps/nginx" or "configmaps/settings"
+func formatResourceText(resource string, group string, name string) string {
+	if group == "" {
+		return fmt.Sprintf("%s/%s", strings.ToLower(resource), name)
+	}
+	return fmt.Sprintf("%s.%s/%s", strings.ToLower(resource), group, name)
+}
+
 func findAPIResource(discoveryClient discovery.DiscoveryInterface, gvk schema.GroupVersionKind) (*metav1.APIResource, error) {
 	apiResourceList, err := discoveryClient.ServerResourcesForGroupVersion(gvk.GroupVersion().String())
 	if err != nil {
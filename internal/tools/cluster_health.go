@@ -0,0 +1,62 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/strowk/foxy-contexts/pkg/fxctx"
+	"github.com/strowk/foxy-contexts/pkg/mcp"
+	"github.com/strowk/foxy-contexts/pkg/toolinput"
+	"github.com/strowk/mcp-k8s-go/internal/content"
+	"github.com/strowk/mcp-k8s-go/internal/k8s"
+	"github.com/strowk/mcp-k8s-go/internal/k8s/health"
+	"github.com/strowk/mcp-k8s-go/internal/utils"
+)
+
+const defaultWarningEventsMinutes = 15
+
+func NewClusterHealthTool(pool k8s.ClientPool, defaults k8s.Defaults) fxctx.Tool {
+	inputSchema := toolinput.NewToolInputSchema(
+		toolinput.WithString("context", "Name of the Kubernetes context to use, defaults to current context"),
+		toolinput.WithNumber("warningEventsMinutes", fmt.Sprintf("Report Warning events from this many last minutes, defaults to %d", defaultWarningEventsMinutes)),
+	)
+
+	return fxctx.NewTool(
+		&mcp.Tool{
+			Name:        "k8s-cluster-health",
+			Description: utils.Ptr("Get compact overview of Kubernetes cluster health: API server version and readiness, NotReady or pressured nodes, crash-looping, pending and evicted pods grouped by namespace, failed Jobs, Deployments with unavailable replicas and recent Warning events"),
+			InputSchema: inputSchema.GetMcpToolInputSchema(),
+		},
+		func(ctx context.Context, args map[string]any) *mcp.CallToolResult {
+			input, err := inputSchema.Validate(args)
+			if err != nil {
+				return utils.ErrResponse(err)
+			}
+
+			k8sCtx := defaults.ResolveContext(ctx, input.StringOr("context", ""))
+
+			minutes := input.NumberOr("warningEventsMinutes", defaultWarningEventsMinutes)
+			if minutes < 0 {
+				return utils.ErrResponse(fmt.Errorf("invalid warningEventsMinutes: %v, expected non-negative number", minutes))
+			}
+
+			report, err := health.Check(ctx, pool, k8sCtx, health.Options{
+				WarningEventsSince: time.Duration(minutes * float64(time.Minute)),
+			})
+			if err != nil {
+				return utils.ErrResponse(err)
+			}
+
+			c, err := content.NewJsonContent(report)
+			if err != nil {
+				return utils.ErrResponse(err)
+			}
+			return &mcp.CallToolResult{
+				Meta:    map[string]any{},
+				Content: []any{c},
+				IsError: utils.Ptr(false),
+			}
+		},
+	)
+}
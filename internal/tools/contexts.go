@@ -14,7 +14,7 @@ import (
 	"k8s.io/client-go/tools/clientcmd/api"
 )
 
-func NewListContextsTool() fxctx.Tool {
+func NewListContextsTool(defaults k8s.Defaults) fxctx.Tool {
 	return fxctx.NewTool(
 		&mcp.Tool{
 			Name:        "list-k8s-contexts",
@@ -25,9 +25,8 @@ func NewListContextsTool() fxctx.Tool {
 				Required:   []string{},
 			},
 		},
-		func(_ context.Context, args map[string]interface{}) *mcp.CallToolResult {
-			ctx := k8s.GetKubeConfig()
-			cfg, err := ctx.RawConfig()
+		func(ctx context.Context, args map[string]interface{}) *mcp.CallToolResult {
+			cfg, err := k8s.GetKubeConfig().RawConfig()
 			if err != nil {
 				log.Printf("failed to get kubeconfig: %v", err)
 				return &mcp.CallToolResult{
@@ -39,9 +38,14 @@ func NewListContextsTool() fxctx.Tool {
 				}
 			}
 
+			current := defaults.ResolveContext(ctx, "")
+			if current == "" {
+				current = cfg.CurrentContext
+			}
+
 			return &mcp.CallToolResult{
 				Meta:    map[string]interface{}{},
-				Content: getListContextsToolContent(cfg, cfg.CurrentContext),
+				Content: getListContextsToolContent(cfg, current),
 				IsError: utils.Ptr(false),
 			}
 		},
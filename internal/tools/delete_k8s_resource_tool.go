@@ -0,0 +1,247 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/strowk/foxy-contexts/pkg/fxctx"
+	"github.com/strowk/foxy-contexts/pkg/mcp"
+	"github.com/strowk/foxy-contexts/pkg/toolinput"
+	"github.com/strowk/mcp-k8s-go/internal/config"
+	"github.com/strowk/mcp-k8s-go/internal/k8s"
+	"github.com/strowk/mcp-k8s-go/internal/utils"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+// protectedKinds are kinds, deletion of which usually causes
+// deletion of many other objects, so it must be explicitly forced
+var protectedKinds = []schema.GroupKind{
+	{Group: "", Kind: "Namespace"},
+	{Group: "apiextensions.k8s.io", Kind: "CustomResourceDefinition"},
+}
+
+func NewDeleteK8sResourceTool(pool k8s.ClientPool, defaults k8s.Defaults) fxctx.Tool {
+	contextProperty := "context"
+	namespaceProperty := "namespace"
+	kindProperty := "kind"
+	groupProperty := "group"
+	versionProperty := "version"
+	nameProperty := "name"
+	labelSelectorProperty := "labelSelector"
+	propagationPolicyProperty := "propagationPolicy"
+	gracePeriodSecondsProperty := "gracePeriodSeconds"
+	dryRunProperty := "dryRun"
+	forceProperty := "force"
+
+	inputSchema := toolinput.NewToolInputSchema(
+		toolinput.WithString(contextProperty, "Name of the Kubernetes context to use, defaults to current context"),
+		toolinput.WithString(namespaceProperty, "Namespace to delete resources from, defaults to namespace of the context, ignored for cluster resources"),
+		toolinput.WithString(groupProperty, "API Group of the resource to delete"),
+		toolinput.WithString(versionProperty, "API Version of the resource to delete"),
+		toolinput.WithRequiredString(kindProperty, "Kind of resource to delete"),
+		toolinput.WithString(nameProperty, "Name of the resource to delete, either name or labelSelector must be set"),
+		toolinput.WithString(labelSelectorProperty, "Label selector of resources to delete, for example app=nginx, either name or labelSelector must be set"),
+		toolinput.WithString(propagationPolicyProperty, "How dependents are garbage collected: Background, Foreground or Orphan, defaults to the policy of the resource"),
+		toolinput.WithNumber(gracePeriodSecondsProperty, "Seconds given to the object to terminate gracefully, 0 means immediate deletion, defaults to the value of the resource"),
+		toolinput.WithBoolean(dryRunProperty, "Only run deletion on the server side without persisting it, defaults to false"),
+		toolinput.WithBoolean(forceProperty, "Allow deleting namespaces, custom resource definitions and objects carrying protection label, defaults to false"),
+	)
+
+	return fxctx.NewTool(
+		&mcp.Tool{
+			Name:        "delete-k8s-resource",
+			Description: utils.Ptr("Delete Kubernetes resources by name or label selector, namespaces, custom resource definitions and protected objects are only deleted when forced"),
+			InputSchema: inputSchema.GetMcpToolInputSchema(),
+		},
+		func(ctx context.Context, args map[string]any) *mcp.CallToolResult {
+			input, err := inputSchema.Validate(args)
+			if err != nil {
+				return utils.ErrResponse(err)
+			}
+
+			k8sCtx := defaults.ResolveContext(ctx, input.StringOr(contextProperty, ""))
+
+			kind, err := input.String(kindProperty)
+			if err != nil {
+				return utils.ErrResponse(err)
+			}
+
+			group := input.StringOr(groupProperty, "")
+			version := input.StringOr(versionProperty, "")
+			name := input.StringOr(nameProperty, "")
+			labelSelector := input.StringOr(labelSelectorProperty, "")
+			force := input.BooleanOr(forceProperty, false)
+
+			if (name == "") == (labelSelector == "") {
+				return utils.ErrResponse(fmt.Errorf("exactly one of name or labelSelector must be set"))
+			}
+
+			deleteOptions, err := getDeleteOptions(input, propagationPolicyProperty, gracePeriodSecondsProperty, dryRunProperty)
+			if err != nil {
+				return utils.ErrResponse(err)
+			}
+
+			mapping, err := pool.GetRESTMapping(k8sCtx, kind, group, version)
+			if err != nil {
+				return utils.ErrResponse(err)
+			}
+
+			groupKind := mapping.GroupVersionKind.GroupKind()
+			if !force && isProtectedKind(groupKind) {
+				return utils.ErrResponse(fmt.Errorf("refusing to delete %s without force", groupKind.String()))
+			}
+
+			protectionSelector, err := getProtectionSelector()
+			if err != nil {
+				return utils.ErrResponse(err)
+			}
+
+			dynamicClient, err := pool.GetDynamicClient(k8sCtx)
+			if err != nil {
+				return utils.ErrResponse(fmt.Errorf("failed to retrieve dynamic client from the client pool: %w", err))
+			}
+
+			namespace := ""
+			var dr dynamic.ResourceInterface
+			if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+				namespace, err = defaults.ResolveNamespace(ctx, k8sCtx, input.StringOr(namespaceProperty, ""))
+				if err != nil {
+					return utils.ErrResponse(err)
+				}
+				dr = dynamicClient.Resource(mapping.Resource).Namespace(namespace)
+			} else {
+				dr = dynamicClient.Resource(mapping.Resource)
+			}
+
+			var targets []unstructured.Unstructured
+			if name != "" {
+				obj, err := dr.Get(ctx, name, metav1.GetOptions{})
+				if err != nil {
+					return utils.ErrResponse(err)
+				}
+				targets = append(targets, *obj)
+			} else {
+				list, err := dr.List(ctx, metav1.ListOptions{LabelSelector: labelSelector})
+				if err != nil {
+					return utils.ErrResponse(err)
+				}
+				targets = list.Items
+			}
+
+			if !force {
+				var protected []string
+				for _, target := range targets {
+					if protectionSelector.Matches(labels.Set(target.GetLabels())) {
+						protected = append(protected, target.GetName())
+					}
+				}
+				if len(protected) > 0 {
+					return utils.ErrResponse(fmt.Errorf(
+						"refusing to delete objects carrying protection label %s without force: %s",
+						config.GlobalOptions.ProtectionLabel,
+						strings.Join(protected, ", "),
+					))
+				}
+			}
+
+			var results []string
+			var deleteErr error
+			for _, target := range targets {
+				options := deleteOptions
+				// make sure that we delete exactly the object that was checked above
+				// and not some other one that was recreated with the same name since then
+				options.Preconditions = metav1.NewUIDPreconditions(string(target.GetUID()))
+				err := dr.Delete(ctx, target.GetName(), options)
+				if err != nil {
+					deleteErr = fmt.Errorf("failed to delete %s: %w", target.GetName(), err)
+					break
+				}
+
+				result := fmt.Sprintf("%s deleted", formatResourceText(mapping.Resource.Resource, mapping.Resource.Group, target.GetName()))
+				if namespace != "" {
+					result += fmt.Sprintf(" in namespace %s", namespace)
+				}
+				if len(options.DryRun) > 0 {
+					result += " (server dry run)"
+				}
+				results = append(results, result)
+			}
+
+			if deleteErr != nil {
+				// objects deleted before the failure are gone already, so they are reported as well
+				results = append(results, deleteErr.Error())
+			} else if len(results) == 0 {
+				results = append(results, "no resources found to delete")
+			}
+
+			return &mcp.CallToolResult{
+				Meta: namespaceMeta(namespace),
+				Content: []any{
+					mcp.TextContent{
+						Type: "text",
+						Text: strings.Join(results, "\n"),
+					},
+				},
+				IsError: utils.Ptr(deleteErr != nil),
+			}
+		},
+	)
+}
+
+func getDeleteOptions(
+	input toolinput.ToolInput,
+	propagationPolicyProperty string,
+	gracePeriodSecondsProperty string,
+	dryRunProperty string,
+) (metav1.DeleteOptions, error) {
+	options := metav1.DeleteOptions{}
+
+	if propagationPolicy := input.StringOr(propagationPolicyProperty, ""); propagationPolicy != "" {
+		policy := metav1.DeletionPropagation(propagationPolicy)
+		switch policy {
+		case metav1.DeletePropagationBackground, metav1.DeletePropagationForeground, metav1.DeletePropagationOrphan:
+			options.PropagationPolicy = &policy
+		default:
+			return options, fmt.Errorf("invalid propagationPolicy: %s, expected one of Background, Foreground or Orphan", propagationPolicy)
+		}
+	}
+
+	if gracePeriodSeconds, err := input.Number(gracePeriodSecondsProperty); err == nil {
+		if gracePeriodSeconds < 0 {
+			return options, fmt.Errorf("invalid gracePeriodSeconds: %v, expected non-negative number", gracePeriodSeconds)
+		}
+		options.GracePeriodSeconds = utils.Ptr(int64(gracePeriodSeconds))
+	}
+
+	if input.BooleanOr(dryRunProperty, false) {
+		options.DryRun = []string{metav1.DryRunAll}
+	}
+
+	return options, nil
+}
+
+func isProtectedKind(groupKind schema.GroupKind) bool {
+	for _, protectedKind := range protectedKinds {
+		if strings.EqualFold(protectedKind.Group, groupKind.Group) && strings.EqualFold(protectedKind.Kind, groupKind.Kind) {
+			return true
+		}
+	}
+	return false
+}
+
+func getProtectionSelector() (labels.Selector, error) {
+	if config.GlobalOptions.ProtectionLabel == "" {
+		return labels.Nothing(), nil
+	}
+	selector, err := labels.Parse(config.GlobalOptions.ProtectionLabel)
+	if err != nil {
+		return nil, fmt.Errorf("invalid protection label %s: %w", config.GlobalOptions.ProtectionLabel, err)
+	}
+	return selector, nil
+}
@@ -0,0 +1,179 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/strowk/foxy-contexts/pkg/mcp"
+	"github.com/strowk/foxy-contexts/pkg/session"
+	"github.com/strowk/mcp-k8s-go/internal/config"
+	"github.com/strowk/mcp-k8s-go/internal/k8s"
+	mock_k8s "github.com/strowk/mcp-k8s-go/internal/k8s/mock"
+	"go.uber.org/mock/gomock"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestDeleteK8sResource(t *testing.T) {
+	defer func() {
+		config.GlobalOptions = &config.Options{}
+	}()
+	config.GlobalOptions = &config.Options{ProtectionLabel: config.DefaultProtectionLabel}
+
+	configMapMapping := &meta.RESTMapping{
+		Resource:         schema.GroupVersionResource{Version: "v1", Resource: "configmaps"},
+		GroupVersionKind: schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"},
+		Scope:            meta.RESTScopeNamespace,
+	}
+
+	newConfigMap := func(name string, labels map[string]string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "namespace",
+				Labels:    labels,
+			},
+		}
+	}
+
+	setup := func(t *testing.T) (*mock_k8s.MockClientPool, *dynamicfake.FakeDynamicClient) {
+		cntr := gomock.NewController(t)
+		poolMock := mock_k8s.NewMockClientPool(cntr)
+		dynamicClient := dynamicfake.NewSimpleDynamicClient(scheme.Scheme,
+			newConfigMap("plain", map[string]string{"app": "test"}),
+			newConfigMap("protected", map[string]string{"app": "test", config.DefaultProtectionLabel: "true"}),
+		)
+		return poolMock, dynamicClient
+	}
+
+	t.Run("refuse deleting protected objects without force", func(t *testing.T) {
+		poolMock, dynamicClient := setup(t)
+		poolMock.EXPECT().GetRESTMapping("context", "ConfigMap", "", "").Return(configMapMapping, nil)
+		poolMock.EXPECT().GetDynamicClient("context").Return(dynamicClient, nil)
+
+		tool := NewDeleteK8sResourceTool(poolMock, k8s.NewDefaults(session.NewSessionManager()))
+		resp := tool.Callback(context.Background(), map[string]any{
+			"context":       "context",
+			"namespace":     "namespace",
+			"kind":          "ConfigMap",
+			"labelSelector": "app=test",
+		})
+		require.NotNil(t, resp.IsError)
+		assert.True(t, *resp.IsError)
+		assert.Contains(t, resp.Content[0].(mcp.TextContent).Text, "without force: protected")
+
+		remaining, err := dynamicClient.Resource(configMapMapping.Resource).Namespace("namespace").List(context.Background(), metav1.ListOptions{})
+		require.NoError(t, err)
+		assert.Len(t, remaining.Items, 2)
+	})
+
+	t.Run("delete protected objects with force", func(t *testing.T) {
+		poolMock, dynamicClient := setup(t)
+		poolMock.EXPECT().GetRESTMapping("context", "ConfigMap", "", "").Return(configMapMapping, nil)
+		poolMock.EXPECT().GetDynamicClient("context").Return(dynamicClient, nil)
+
+		tool := NewDeleteK8sResourceTool(poolMock, k8s.NewDefaults(session.NewSessionManager()))
+		resp := tool.Callback(context.Background(), map[string]any{
+			"context":       "context",
+			"namespace":     "namespace",
+			"kind":          "ConfigMap",
+			"labelSelector": "app=test",
+			"force":         true,
+		})
+		require.NotNil(t, resp.IsError)
+		assert.False(t, *resp.IsError)
+		assert.Equal(t,
+			"configmaps/plain deleted in namespace namespace\nconfigmaps/protected deleted in namespace namespace",
+			resp.Content[0].(mcp.TextContent).Text,
+		)
+
+		remaining, err := dynamicClient.Resource(configMapMapping.Resource).Namespace("namespace").List(context.Background(), metav1.ListOptions{})
+		require.NoError(t, err)
+		assert.Empty(t, remaining.Items)
+	})
+
+	t.Run("refuse deleting namespace without force", func(t *testing.T) {
+		poolMock, _ := setup(t)
+		poolMock.EXPECT().GetRESTMapping("context", "namespace", "", "").Return(&meta.RESTMapping{
+			Resource:         schema.GroupVersionResource{Version: "v1", Resource: "namespaces"},
+			GroupVersionKind: schema.GroupVersionKind{Version: "v1", Kind: "Namespace"},
+			Scope:            meta.RESTScopeRoot,
+		}, nil)
+
+		tool := NewDeleteK8sResourceTool(poolMock, k8s.NewDefaults(session.NewSessionManager()))
+		resp := tool.Callback(context.Background(), map[string]any{
+			"context": "context",
+			"kind":    "namespace",
+			"name":    "namespace",
+		})
+		require.NotNil(t, resp.IsError)
+		assert.True(t, *resp.IsError)
+		assert.Equal(t, "refusing to delete Namespace without force", resp.Content[0].(mcp.TextContent).Text)
+	})
+
+	t.Run("reject invalid propagation policy", func(t *testing.T) {
+		poolMock, _ := setup(t)
+
+		tool := NewDeleteK8sResourceTool(poolMock, k8s.NewDefaults(session.NewSessionManager()))
+		resp := tool.Callback(context.Background(), map[string]any{
+			"context":           "context",
+			"kind":              "ConfigMap",
+			"name":              "plain",
+			"propagationPolicy": "Everything",
+		})
+		require.NotNil(t, resp.IsError)
+		assert.True(t, *resp.IsError)
+		assert.Equal(t,
+			"invalid propagationPolicy: Everything, expected one of Background, Foreground or Orphan",
+			resp.Content[0].(mcp.TextContent).Text,
+		)
+	})
+
+	t.Run("report objects deleted before failure", func(t *testing.T) {
+		poolMock, dynamicClient := setup(t)
+		poolMock.EXPECT().GetRESTMapping("context", "ConfigMap", "", "").Return(configMapMapping, nil)
+		poolMock.EXPECT().GetDynamicClient("context").Return(dynamicClient, nil)
+
+		deletes := 0
+		dynamicClient.PrependReactor("delete", "configmaps", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			deletes++
+			if deletes == 2 {
+				return true, nil, errors.New("connection refused")
+			}
+			return false, nil, nil
+		})
+
+		tool := NewDeleteK8sResourceTool(poolMock, k8s.NewDefaults(session.NewSessionManager()))
+		resp := tool.Callback(context.Background(), map[string]any{
+			"context":       "context",
+			"namespace":     "namespace",
+			"kind":          "ConfigMap",
+			"labelSelector": "app=test",
+			"force":         true,
+		})
+		require.NotNil(t, resp.IsError)
+		assert.True(t, *resp.IsError)
+
+		remaining, err := dynamicClient.Resource(configMapMapping.Resource).Namespace("namespace").List(context.Background(), metav1.ListOptions{})
+		require.NoError(t, err)
+		require.Len(t, remaining.Items, 1)
+		deleted := "plain"
+		if remaining.Items[0].GetName() == "plain" {
+			deleted = "protected"
+		}
+		assert.Equal(t,
+			"configmaps/"+deleted+" deleted in namespace namespace\n"+
+				"failed to delete "+remaining.Items[0].GetName()+": connection refused",
+			resp.Content[0].(mcp.TextContent).Text,
+		)
+	})
+}
@@ -0,0 +1,76 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/strowk/foxy-contexts/pkg/fxctx"
+	"github.com/strowk/foxy-contexts/pkg/mcp"
+	"github.com/strowk/foxy-contexts/pkg/toolinput"
+	"github.com/strowk/mcp-k8s-go/internal/content"
+	"github.com/strowk/mcp-k8s-go/internal/k8s"
+	"github.com/strowk/mcp-k8s-go/internal/k8s/core/v1/pod"
+	"github.com/strowk/mcp-k8s-go/internal/utils"
+)
+
+const defaultDiagnosisLogTailLines = 20
+
+func NewDiagnosePodTool(pool k8s.ClientPool, defaults k8s.Defaults) fxctx.Tool {
+	inputSchema := toolinput.NewToolInputSchema(
+		toolinput.WithString("context", "Name of the Kubernetes context to use, defaults to current context"),
+		toolinput.WithString("namespace", "Namespace of the pod, defaults to namespace of the context"),
+		toolinput.WithRequiredString("pod", "Name of the pod to diagnose"),
+		toolinput.WithNumber("logTailLines", fmt.Sprintf("Number of lines of logs of previous container to include for restarted containers, defaults to %d, 0 disables logs", defaultDiagnosisLogTailLines)),
+	)
+
+	return fxctx.NewTool(
+		&mcp.Tool{
+			Name:        "diagnose-k8s-pod",
+			Description: utils.Ptr("Explain why Kubernetes pod is broken by inspecting its container statuses, events, previous logs, probes, referenced ConfigMaps, Secrets and PersistentVolumeClaims and node conditions, returns verdict with evidence. Referenced Secrets are read whole to check their keys, but values are never returned, objects which cannot be read are reported as warnings"),
+			InputSchema: inputSchema.GetMcpToolInputSchema(),
+		},
+		func(ctx context.Context, args map[string]any) *mcp.CallToolResult {
+			input, err := inputSchema.Validate(args)
+			if err != nil {
+				return utils.ErrResponse(err)
+			}
+
+			k8sCtx := defaults.ResolveContext(ctx, input.StringOr("context", ""))
+
+			namespace, err := defaults.ResolveNamespace(ctx, k8sCtx, input.StringOr("namespace", ""))
+			if err != nil {
+				return utils.ErrResponse(err)
+			}
+
+			name, err := input.String("pod")
+			if err != nil {
+				return utils.ErrResponse(err)
+			}
+
+			logTailLines := int64(input.NumberOr("logTailLines", defaultDiagnosisLogTailLines))
+			if logTailLines < 0 {
+				return utils.ErrResponse(fmt.Errorf("invalid logTailLines: %d, expected non-negative number", logTailLines))
+			}
+
+			clientset, err := pool.GetClientset(k8sCtx)
+			if err != nil {
+				return utils.ErrResponse(err)
+			}
+
+			diagnosis, err := pod.Diagnose(ctx, clientset, namespace, name, pod.DiagnoseOptions{LogTailLines: logTailLines})
+			if err != nil {
+				return utils.ErrResponse(err)
+			}
+
+			c, err := content.NewJsonContent(diagnosis)
+			if err != nil {
+				return utils.ErrResponse(err)
+			}
+			return &mcp.CallToolResult{
+				Meta:    namespaceMeta(namespace),
+				Content: []any{c},
+				IsError: utils.Ptr(false),
+			}
+		},
+	)
+}
@@ -0,0 +1,67 @@
+package tools
+
+import (
+	"context"
+
+	"github.com/strowk/foxy-contexts/pkg/fxctx"
+	"github.com/strowk/foxy-contexts/pkg/mcp"
+	"github.com/strowk/foxy-contexts/pkg/toolinput"
+	"github.com/strowk/mcp-k8s-go/internal/content"
+	"github.com/strowk/mcp-k8s-go/internal/k8s"
+	"github.com/strowk/mcp-k8s-go/internal/k8s/core/v1/service"
+	"github.com/strowk/mcp-k8s-go/internal/utils"
+)
+
+func NewDiagnoseServiceTool(pool k8s.ClientPool, defaults k8s.Defaults) fxctx.Tool {
+	inputSchema := toolinput.NewToolInputSchema(
+		toolinput.WithString("context", "Name of the Kubernetes context to use, defaults to current context"),
+		toolinput.WithString("namespace", "Namespace of the service, defaults to namespace of the context"),
+		toolinput.WithRequiredString("service", "Name of the service to diagnose"),
+	)
+
+	return fxctx.NewTool(
+		&mcp.Tool{
+			Name:        "diagnose-k8s-service",
+			Description: utils.Ptr("Check whether Kubernetes service has backends by resolving its selector to pods and EndpointSlices, shows ready and not ready endpoints with reasons and detects selector and label mismatches, target ports not declared by pods and services with no endpoints"),
+			InputSchema: inputSchema.GetMcpToolInputSchema(),
+		},
+		func(ctx context.Context, args map[string]any) *mcp.CallToolResult {
+			input, err := inputSchema.Validate(args)
+			if err != nil {
+				return utils.ErrResponse(err)
+			}
+
+			k8sCtx := defaults.ResolveContext(ctx, input.StringOr("context", ""))
+
+			namespace, err := defaults.ResolveNamespace(ctx, k8sCtx, input.StringOr("namespace", ""))
+			if err != nil {
+				return utils.ErrResponse(err)
+			}
+
+			name, err := input.String("service")
+			if err != nil {
+				return utils.ErrResponse(err)
+			}
+
+			clientset, err := pool.GetClientset(k8sCtx)
+			if err != nil {
+				return utils.ErrResponse(err)
+			}
+
+			health, err := service.CheckEndpoints(ctx, clientset, namespace, name)
+			if err != nil {
+				return utils.ErrResponse(err)
+			}
+
+			c, err := content.NewJsonContent(health)
+			if err != nil {
+				return utils.ErrResponse(err)
+			}
+			return &mcp.CallToolResult{
+				Meta:    namespaceMeta(namespace),
+				Content: []any{c},
+				IsError: utils.Ptr(false),
+			}
+		},
+	)
+}
@@ -0,0 +1,454 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/strowk/mcp-k8s-go/internal/k8s"
+	"github.com/strowk/mcp-k8s-go/internal/utils"
+
+	"github.com/strowk/foxy-contexts/pkg/fxctx"
+	"github.com/strowk/foxy-contexts/pkg/mcp"
+	"github.com/strowk/foxy-contexts/pkg/toolinput"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Relations of objects in the timeline to the requested object
+const (
+	timelineRelationTarget     = "target"
+	timelineRelationOwner      = "owner"
+	timelineRelationOwned      = "owned"
+	timelineRelationAutoscaler = "autoscaler"
+	timelineRelationVolume     = "volume"
+	timelineRelationNode       = "node"
+)
+
+// timelineChildKinds lists kinds of objects, which controllers
+// of the key kind create and own, to walk owner references down
+var timelineChildKinds = map[string][]string{
+	"Deployment":  {"ReplicaSet"},
+	"ReplicaSet":  {"Pod"},
+	"StatefulSet": {"Pod"},
+	"DaemonSet":   {"Pod"},
+	"Job":         {"Pod"},
+	"CronJob":     {"Job"},
+}
+
+func NewEventTimelineTool(pool k8s.ClientPool, defaults k8s.Defaults) fxctx.Tool {
+	schema := toolinput.NewToolInputSchema(
+		toolinput.WithString("context", "Name of the Kubernetes context to use, defaults to current context"),
+		toolinput.WithString("namespace", "Namespace of the object, defaults to namespace of the context, ignored for cluster resources"),
+		toolinput.WithString("group", "API Group of the object"),
+		toolinput.WithString("version", "API Version of the object"),
+		toolinput.WithRequiredString("kind", "Kind of the object, for example Pod or Deployment"),
+		toolinput.WithRequiredString("name", "Name of the object"),
+	)
+	return fxctx.NewTool(
+		&mcp.Tool{
+			Name:        "get-k8s-event-timeline",
+			Description: utils.Ptr("Get chronological timeline of deduplicated events of Kubernetes object, its owners, owned objects, autoscalers, volumes and nodes"),
+			InputSchema: schema.GetMcpToolInputSchema(),
+		},
+		func(ctx context.Context, args map[string]interface{}) *mcp.CallToolResult {
+			input, err := schema.Validate(args)
+			if err != nil {
+				return errResponse(err)
+			}
+
+			k8sCtx := defaults.ResolveContext(ctx, input.StringOr("context", ""))
+
+			kind, err := input.String("kind")
+			if err != nil {
+				return errResponse(err)
+			}
+			name, err := input.String("name")
+			if err != nil {
+				return errResponse(err)
+			}
+
+			mapping, err := pool.GetRESTMapping(k8sCtx, kind, input.StringOr("group", ""), input.StringOr("version", ""))
+			if err != nil {
+				return errResponse(err)
+			}
+
+			namespace := ""
+			if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+				namespace, err = defaults.ResolveNamespace(ctx, k8sCtx, input.StringOr("namespace", ""))
+				if err != nil {
+					return errResponse(err)
+				}
+			}
+
+			clientset, err := pool.GetClientset(k8sCtx)
+			if err != nil {
+				return errResponse(err)
+			}
+			dynamicClient, err := pool.GetDynamicClient(k8sCtx)
+			if err != nil {
+				return errResponse(err)
+			}
+
+			collector := &timelineCollector{
+				pool:          pool,
+				k8sCtx:        k8sCtx,
+				clientset:     clientset,
+				dynamicClient: dynamicClient,
+				namespace:     namespace,
+				seen:          map[string]bool{},
+				lists:         map[string][]metav1.Object{},
+			}
+			timeline, err := collector.collect(ctx, mapping, name)
+			if err != nil {
+				return errResponse(err)
+			}
+
+			content, err := NewJsonContent(timeline)
+			if err != nil {
+				return errResponse(err)
+			}
+			return &mcp.CallToolResult{
+				Meta:    namespaceMeta(namespace),
+				Content: []interface{}{content},
+				IsError: utils.Ptr(false),
+			}
+		},
+	)
+}
+
+// EventTimeline is a list of events of related objects sorted by last occurrence
+type EventTimeline struct {
+	Objects  []TimelineObject `json:"objects"`
+	Events   []EventInList    `json:"events"`
+	Warnings []string         `json:"warnings,omitempty"`
+}
+
+// TimelineObject is an object, which events are included in the timeline
+type TimelineObject struct {
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+	Relation  string `json:"relation"`
+}
+
+func (o TimelineObject) key() string {
+	return fmt.Sprintf("%s/%s/%s", o.Kind, o.Namespace, o.Name)
+}
+
+type timelineCollector struct {
+	pool          k8s.ClientPool
+	k8sCtx        string
+	clientset     kubernetes.Interface
+	dynamicClient dynamic.Interface
+	// namespace of the requested object, empty for cluster resources
+	namespace string
+
+	objects  []TimelineObject
+	pods     []*corev1.Pod
+	warnings []string
+	seen     map[string]bool
+	// lists caches objects of kind listed from namespace to find owned ones
+	lists map[string][]metav1.Object
+}
+
+func (c *timelineCollector) collect(ctx context.Context, mapping *meta.RESTMapping, name string) (*EventTimeline, error) {
+	target, err := c.dynamicClient.Resource(mapping.Resource).Namespace(c.namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	kind := mapping.GroupVersionKind.Kind
+	c.add(TimelineObject{Kind: kind, Name: name, Namespace: c.namespace, Relation: timelineRelationTarget})
+
+	if kind == "Pod" {
+		pod := &corev1.Pod{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(target.Object, pod); err != nil {
+			return nil, err
+		}
+		c.pods = append(c.pods, pod)
+	}
+	if kind == "PersistentVolumeClaim" {
+		c.addVolumeClaimVolume(ctx, name)
+	}
+
+	c.addOwners(ctx, target.GetOwnerReferences())
+	if c.namespace != "" {
+		c.addOwned(ctx, kind, target.GetUID())
+		c.addAutoscalers(ctx)
+	}
+	for _, pod := range c.pods {
+		c.addPodDependencies(ctx, pod)
+	}
+
+	events, err := c.listEvents(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &EventTimeline{
+		Objects:  c.objects,
+		Events:   events,
+		Warnings: c.warnings,
+	}, nil
+}
+
+func (c *timelineCollector) add(object TimelineObject) bool {
+	if c.seen[object.key()] {
+		return false
+	}
+	c.seen[object.key()] = true
+	c.objects = append(c.objects, object)
+	return true
+}
+
+// warn records error of getting related object, which does not prevent
+// building the timeline, objects which are not found are silently skipped
+func (c *timelineCollector) warn(err error, format string, args ...any) {
+	if err == nil || errors.IsNotFound(err) {
+		return
+	}
+	c.warnings = append(c.warnings, fmt.Sprintf("%s: %s", fmt.Sprintf(format, args...), err))
+}
+
+// addOwners walks owner references up to the top level controller
+func (c *timelineCollector) addOwners(ctx context.Context, references []metav1.OwnerReference) {
+	for _, reference := range references {
+		gv, err := schema.ParseGroupVersion(reference.APIVersion)
+		if err != nil {
+			c.warn(err, "failed to parse apiVersion of owner %s %s", reference.Kind, reference.Name)
+			continue
+		}
+		mapping, err := c.pool.GetRESTMapping(c.k8sCtx, reference.Kind, gv.Group, gv.Version)
+		if err != nil {
+			c.warn(err, "failed to resolve owner %s %s", reference.Kind, reference.Name)
+			continue
+		}
+
+		owner := TimelineObject{Kind: reference.Kind, Name: reference.Name, Relation: timelineRelationOwner}
+		if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+			owner.Namespace = c.namespace
+		}
+		if !c.add(owner) {
+			continue
+		}
+
+		object, err := c.dynamicClient.Resource(mapping.Resource).Namespace(owner.Namespace).Get(ctx, owner.Name, metav1.GetOptions{})
+		if err != nil {
+			// owner might be already deleted, but its events can still be there
+			c.warn(err, "failed to get owner %s %s", owner.Kind, owner.Name)
+			continue
+		}
+		c.addOwners(ctx, object.GetOwnerReferences())
+	}
+}
+
+// addOwned walks owner references down from controllers to pods
+func (c *timelineCollector) addOwned(ctx context.Context, kind string, uid types.UID) {
+	for _, childKind := range timelineChildKinds[kind] {
+		children, err := c.list(ctx, childKind)
+		if err != nil {
+			c.warn(err, "failed to list %s objects", childKind)
+			continue
+		}
+		for _, child := range children {
+			if !isOwnedBy(child, uid) {
+				continue
+			}
+			if !c.add(TimelineObject{Kind: childKind, Name: child.GetName(), Namespace: c.namespace, Relation: timelineRelationOwned}) {
+				continue
+			}
+			if pod, ok := child.(*corev1.Pod); ok {
+				c.pods = append(c.pods, pod)
+			}
+			c.addOwned(ctx, childKind, child.GetUID())
+		}
+	}
+}
+
+func isOwnedBy(object metav1.Object, uid types.UID) bool {
+	for _, reference := range object.GetOwnerReferences() {
+		if reference.UID == uid {
+			return true
+		}
+	}
+	return false
+}
+
+// list returns objects of the kind from the namespace of requested object
+func (c *timelineCollector) list(ctx context.Context, kind string) ([]metav1.Object, error) {
+	if objects, ok := c.lists[kind]; ok {
+		return objects, nil
+	}
+
+	var objects []metav1.Object
+	switch kind {
+	case "ReplicaSet":
+		list, err := c.clientset.AppsV1().ReplicaSets(c.namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		for i := range list.Items {
+			objects = append(objects, &list.Items[i])
+		}
+	case "Job":
+		list, err := c.clientset.BatchV1().Jobs(c.namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		for i := range list.Items {
+			objects = append(objects, &list.Items[i])
+		}
+	case "Pod":
+		list, err := c.clientset.CoreV1().Pods(c.namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		for i := range list.Items {
+			objects = append(objects, &list.Items[i])
+		}
+	default:
+		return nil, fmt.Errorf("unsupported kind %s", kind)
+	}
+
+	c.lists[kind] = objects
+	return objects, nil
+}
+
+// addAutoscalers adds horizontal pod autoscalers targeting collected objects
+func (c *timelineCollector) addAutoscalers(ctx context.Context) {
+	autoscalers, err := c.clientset.AutoscalingV2().HorizontalPodAutoscalers(c.namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		c.warn(err, "failed to list horizontal pod autoscalers")
+		return
+	}
+	for _, autoscaler := range autoscalers.Items {
+		target := TimelineObject{
+			Kind:      autoscaler.Spec.ScaleTargetRef.Kind,
+			Name:      autoscaler.Spec.ScaleTargetRef.Name,
+			Namespace: c.namespace,
+		}
+		if c.seen[target.key()] {
+			c.add(TimelineObject{
+				Kind:      "HorizontalPodAutoscaler",
+				Name:      autoscaler.Name,
+				Namespace: c.namespace,
+				Relation:  timelineRelationAutoscaler,
+			})
+		}
+	}
+}
+
+// addPodDependencies adds claims and volumes used by the pod and its node
+func (c *timelineCollector) addPodDependencies(ctx context.Context, pod *corev1.Pod) {
+	for _, volume := range pod.Spec.Volumes {
+		if volume.PersistentVolumeClaim == nil {
+			continue
+		}
+		claim := volume.PersistentVolumeClaim.ClaimName
+		if c.add(TimelineObject{Kind: "PersistentVolumeClaim", Name: claim, Namespace: c.namespace, Relation: timelineRelationVolume}) {
+			c.addVolumeClaimVolume(ctx, claim)
+		}
+	}
+	if pod.Spec.NodeName != "" {
+		c.add(TimelineObject{Kind: "Node", Name: pod.Spec.NodeName, Relation: timelineRelationNode})
+	}
+}
+
+// addVolumeClaimVolume adds persistent volume bound to the claim
+func (c *timelineCollector) addVolumeClaimVolume(ctx context.Context, claim string) {
+	pvc, err := c.clientset.CoreV1().PersistentVolumeClaims(c.namespace).Get(ctx, claim, metav1.GetOptions{})
+	if err != nil {
+		c.warn(err, "failed to get persistent volume claim %s", claim)
+		return
+	}
+	if pvc.Spec.VolumeName != "" {
+		c.add(TimelineObject{Kind: "PersistentVolume", Name: pvc.Spec.VolumeName, Relation: timelineRelationVolume})
+	}
+}
+
+// listEvents returns events of collected objects, where the same
+// event repeated in several event objects is merged into one
+func (c *timelineCollector) listEvents(ctx context.Context) ([]EventInList, error) {
+	var events []EventInList
+	if c.namespace != "" {
+		namespaced, err := listEvents(ctx, c.clientset, c.namespace, eventFilter{})
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, namespaced...)
+	}
+	for _, object := range c.objects {
+		if object.Namespace != "" {
+			continue
+		}
+		// events of cluster resources are stored in default namespace,
+		// so they are searched across all namespaces by the object
+		clusterEvents, err := listEvents(ctx, c.clientset, metav1.NamespaceAll, eventFilter{
+			InvolvedObjectKind: object.Kind,
+			InvolvedObjectName: object.Name,
+		})
+		if err != nil {
+			c.warn(err, "failed to list events of %s %s", object.Kind, object.Name)
+			continue
+		}
+		events = append(events, clusterEvents...)
+	}
+
+	seenEvents := map[string]bool{}
+	merged := map[string]*EventInList{}
+	var timeline []*EventInList
+	for _, event := range events {
+		if seenEvents[event.id] {
+			continue
+		}
+		seenEvents[event.id] = true
+
+		object := TimelineObject{
+			Kind:      event.InvolvedObject.Kind,
+			Name:      event.InvolvedObject.Name,
+			Namespace: event.InvolvedObject.Namespace,
+		}
+		if object.Namespace == "" && !c.seen[object.key()] {
+			// some reporters do not set namespace of involved object
+			object.Namespace = event.Namespace
+		}
+		if !c.seen[object.key()] {
+			continue
+		}
+
+		key := fmt.Sprintf("%s/%s/%s/%s", object.key(), event.Type, event.Reason, event.Message)
+		existing, ok := merged[key]
+		if !ok {
+			event := event
+			merged[key] = &event
+			timeline = append(timeline, &event)
+			continue
+		}
+		existing.Count += event.Count
+		if event.firstOccurred.Before(existing.firstOccurred) {
+			existing.firstOccurred = event.firstOccurred
+			existing.FirstTimestamp = event.FirstTimestamp
+		}
+		if event.lastOccurred.After(existing.lastOccurred) {
+			existing.lastOccurred = event.lastOccurred
+			existing.LastTimestamp = event.LastTimestamp
+		}
+	}
+
+	sort.SliceStable(timeline, func(i, j int) bool {
+		return timeline[i].lastOccurred.Before(timeline[j].lastOccurred)
+	})
+
+	result := make([]EventInList, len(timeline))
+	for i, event := range timeline {
+		result[i] = *event
+	}
+	return result, nil
+}
@@ -0,0 +1,175 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/strowk/foxy-contexts/pkg/mcp"
+	"github.com/strowk/foxy-contexts/pkg/session"
+	"github.com/strowk/mcp-k8s-go/internal/k8s"
+	mock_k8s "github.com/strowk/mcp-k8s-go/internal/k8s/mock"
+	"github.com/strowk/mcp-k8s-go/internal/utils"
+	"go.uber.org/mock/gomock"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+func TestEventTimeline(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	controller := func(kind, name string, uid types.UID) []metav1.OwnerReference {
+		return []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: kind, Name: name, UID: uid, Controller: utils.Ptr(true)}}
+	}
+
+	deployment := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "ns", UID: "deployment-uid"}}
+	replicaSet := &appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{
+		Name: "web-1", Namespace: "ns", UID: "replicaset-uid", OwnerReferences: controller("Deployment", "web", "deployment-uid"),
+	}}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-1-a", Namespace: "ns", UID: "pod-uid", OwnerReferences: controller("ReplicaSet", "web-1", "replicaset-uid")},
+		Spec: corev1.PodSpec{
+			NodeName: "node-1",
+			Volumes: []corev1.Volume{{Name: "data", VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "data"},
+			}}},
+		},
+	}
+	otherPod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "ns"}}
+
+	event := func(name, namespace, kind, objectNamespace, objectName, reason string, last time.Time) *corev1.Event {
+		return &corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Type:           "Normal",
+			Reason:         reason,
+			Message:        reason + " " + objectName,
+			Count:          1,
+			FirstTimestamp: metav1.NewTime(last),
+			LastTimestamp:  metav1.NewTime(last),
+			InvolvedObject: corev1.ObjectReference{Kind: kind, Namespace: objectNamespace, Name: objectName},
+		}
+	}
+
+	clientset := fake.NewClientset(
+		deployment, replicaSet, pod, otherPod,
+		&corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: "data", Namespace: "ns"},
+			Spec:       corev1.PersistentVolumeClaimSpec{VolumeName: "pv-1"},
+		},
+		&autoscalingv2.HorizontalPodAutoscaler{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "ns"},
+			Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+				ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{Kind: "Deployment", Name: "web"},
+			},
+		},
+		event("scaled", "ns", "Deployment", "ns", "web", "ScalingReplicaSet", now.Add(-5*time.Minute)),
+		event("created", "ns", "ReplicaSet", "ns", "web-1", "SuccessfulCreate", now.Add(-4*time.Minute)),
+		event("backoff-1", "ns", "Pod", "ns", "web-1-a", "BackOff", now.Add(-3*time.Minute)),
+		event("backoff-2", "ns", "Pod", "ns", "web-1-a", "BackOff", now.Add(-time.Minute)),
+		event("claim", "ns", "PersistentVolumeClaim", "ns", "data", "ProvisioningSucceeded", now.Add(-6*time.Minute)),
+		event("hpa", "ns", "HorizontalPodAutoscaler", "ns", "web", "SuccessfulRescale", now.Add(-2*time.Minute)),
+		event("node", "default", "Node", "", "node-1", "NodeNotReady", now),
+		event("unrelated", "ns", "Pod", "ns", "other", "BackOff", now),
+	)
+
+	mappings := map[string]*meta.RESTMapping{
+		"Pod": {
+			Resource:         schema.GroupVersionResource{Version: "v1", Resource: "pods"},
+			GroupVersionKind: schema.GroupVersionKind{Version: "v1", Kind: "Pod"},
+			Scope:            meta.RESTScopeNamespace,
+		},
+		"ReplicaSet": {
+			Resource:         schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "replicasets"},
+			GroupVersionKind: schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "ReplicaSet"},
+			Scope:            meta.RESTScopeNamespace,
+		},
+		"Deployment": {
+			Resource:         schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"},
+			GroupVersionKind: schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"},
+			Scope:            meta.RESTScopeNamespace,
+		},
+	}
+
+	call := func(t *testing.T, kind string) EventTimeline {
+		cntr := gomock.NewController(t)
+		poolMock := mock_k8s.NewMockClientPool(cntr)
+		poolMock.EXPECT().GetClientset("context").Return(clientset, nil)
+		poolMock.EXPECT().GetDynamicClient("context").Return(
+			dynamicfake.NewSimpleDynamicClient(scheme.Scheme, []runtime.Object{deployment, replicaSet, pod}...), nil,
+		)
+		poolMock.EXPECT().GetRESTMapping("context", gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ string, kind string, _ string, _ string) (*meta.RESTMapping, error) {
+				return mappings[kind], nil
+			},
+		).AnyTimes()
+
+		tool := NewEventTimelineTool(poolMock, k8s.NewDefaults(session.NewSessionManager()))
+		resp := tool.Callback(context.Background(), map[string]any{
+			"context":   "context",
+			"namespace": "ns",
+			"kind":      kind,
+			"name":      map[string]string{"Pod": "web-1-a", "Deployment": "web"}[kind],
+		})
+		require.NotNil(t, resp.IsError)
+		require.False(t, *resp.IsError, resp.Content)
+
+		var timeline EventTimeline
+		require.NoError(t, json.Unmarshal([]byte(resp.Content[0].(mcp.TextContent).Text), &timeline))
+		return timeline
+	}
+
+	reasons := func(timeline EventTimeline) []string {
+		var reasons []string
+		for _, event := range timeline.Events {
+			reasons = append(reasons, event.Reason)
+		}
+		return reasons
+	}
+
+	t.Run("Timeline of pod walks owners up", func(t *testing.T) {
+		timeline := call(t, "Pod")
+		assert.Equal(t, []TimelineObject{
+			{Kind: "Pod", Name: "web-1-a", Namespace: "ns", Relation: "target"},
+			{Kind: "ReplicaSet", Name: "web-1", Namespace: "ns", Relation: "owner"},
+			{Kind: "Deployment", Name: "web", Namespace: "ns", Relation: "owner"},
+			{Kind: "HorizontalPodAutoscaler", Name: "web", Namespace: "ns", Relation: "autoscaler"},
+			{Kind: "PersistentVolumeClaim", Name: "data", Namespace: "ns", Relation: "volume"},
+			{Kind: "PersistentVolume", Name: "pv-1", Relation: "volume"},
+			{Kind: "Node", Name: "node-1", Relation: "node"},
+		}, timeline.Objects)
+		assert.Equal(t, []string{
+			"ProvisioningSucceeded", "ScalingReplicaSet", "SuccessfulCreate", "SuccessfulRescale", "BackOff", "NodeNotReady",
+		}, reasons(timeline))
+
+		backOff := timeline.Events[4]
+		assert.Equal(t, int32(2), backOff.Count)
+		assert.Equal(t, now.Add(-3*time.Minute).Format(time.RFC3339), backOff.FirstTimestamp)
+		assert.Equal(t, now.Add(-time.Minute).Format(time.RFC3339), backOff.LastTimestamp)
+		assert.Empty(t, timeline.Warnings)
+	})
+
+	t.Run("Timeline of deployment walks owned objects down", func(t *testing.T) {
+		timeline := call(t, "Deployment")
+		assert.Equal(t, []TimelineObject{
+			{Kind: "Deployment", Name: "web", Namespace: "ns", Relation: "target"},
+			{Kind: "ReplicaSet", Name: "web-1", Namespace: "ns", Relation: "owned"},
+			{Kind: "Pod", Name: "web-1-a", Namespace: "ns", Relation: "owned"},
+			{Kind: "HorizontalPodAutoscaler", Name: "web", Namespace: "ns", Relation: "autoscaler"},
+			{Kind: "PersistentVolumeClaim", Name: "data", Namespace: "ns", Relation: "volume"},
+			{Kind: "PersistentVolume", Name: "pv-1", Relation: "volume"},
+			{Kind: "Node", Name: "node-1", Relation: "node"},
+		}, timeline.Objects)
+		assert.Len(t, timeline.Events, 6)
+	})
+}
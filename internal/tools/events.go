@@ -2,6 +2,11 @@ package tools
 
 import (
 	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/strowk/mcp-k8s-go/internal/k8s"
 	"github.com/strowk/mcp-k8s-go/internal/utils"
@@ -10,19 +15,30 @@ import (
 	"github.com/strowk/foxy-contexts/pkg/mcp"
 	"github.com/strowk/foxy-contexts/pkg/toolinput"
 
+	corev1 "k8s.io/api/core/v1"
+	eventsv1 "k8s.io/api/events/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/kubernetes"
 )
 
-func NewListEventsTool(pool k8s.ClientPool) fxctx.Tool {
+func NewListEventsTool(pool k8s.ClientPool, defaults k8s.Defaults) fxctx.Tool {
 	schema := toolinput.NewToolInputSchema(
-		toolinput.WithRequiredString("context", "Name of the Kubernetes context to use"),
-		toolinput.WithRequiredString("namespace", "Name of the namespace to list events from"),
-		toolinput.WithNumber("limit", "Maximum number of events to list"),
+		toolinput.WithString("context", "Name of the Kubernetes context to use, defaults to current context"),
+		toolinput.WithString("namespace", "Name of the namespace to list events from, defaults to namespace of the context"),
+		toolinput.WithBoolean("allNamespaces", "List events from all namespaces, defaults to false"),
+		toolinput.WithString("type", "Only list events of this type, Normal or Warning"),
+		toolinput.WithString("reason", "Only list events with this reason, for example BackOff"),
+		toolinput.WithString("involvedObjectKind", "Only list events about objects of this kind, for example Pod"),
+		toolinput.WithString("involvedObjectName", "Only list events about objects with this name"),
+		toolinput.WithString("involvedObjectUid", "Only list events about object with this UID"),
+		toolinput.WithString("since", "Only list events which last occurred within relative duration like 5m or 1h"),
+		toolinput.WithNumber("limit", "Maximum number of most recent events to list"),
 	)
 	return fxctx.NewTool(
 		&mcp.Tool{
 			Name:        "list-k8s-events",
-			Description: utils.Ptr("List Kubernetes events using specific context in a specified namespace"),
+			Description: utils.Ptr("List Kubernetes events using specific context in a specified namespace or all namespaces, sorted by last occurrence"),
 			InputSchema: schema.GetMcpToolInputSchema(),
 		},
 		func(ctx context.Context, args map[string]interface{}) *mcp.CallToolResult {
@@ -31,14 +47,37 @@ func NewListEventsTool(pool k8s.ClientPool) fxctx.Tool {
 				return errResponse(err)
 			}
 
-			k8sCtx, err := input.String("context")
-			if err != nil {
-				return errResponse(err)
+			k8sCtx := defaults.ResolveContext(ctx, input.StringOr("context", ""))
+
+			k8sNamespace := metav1.NamespaceAll
+			if !input.BooleanOr("allNamespaces", false) {
+				k8sNamespace, err = defaults.ResolveNamespace(ctx, k8sCtx, input.StringOr("namespace", ""))
+				if err != nil {
+					return errResponse(err)
+				}
 			}
 
-			k8sNamespace, err := input.String("namespace")
-			if err != nil {
-				return errResponse(err)
+			filter := eventFilter{
+				Type:               input.StringOr("type", ""),
+				Reason:             input.StringOr("reason", ""),
+				InvolvedObjectKind: input.StringOr("involvedObjectKind", ""),
+				InvolvedObjectName: input.StringOr("involvedObjectName", ""),
+				InvolvedObjectUID:  input.StringOr("involvedObjectUid", ""),
+			}
+			if sinceStr := input.StringOr("since", ""); sinceStr != "" {
+				since, err := time.ParseDuration(sinceStr)
+				if err != nil {
+					return errResponse(fmt.Errorf("invalid since: %s, expected to be in Golang duration format as defined in standard time package", sinceStr))
+				}
+				filter.Since = time.Now().Add(-since)
+			}
+
+			limit := -1
+			if limitNumber, err := input.Number("limit"); err == nil {
+				if limitNumber < 0 || limitNumber > math.MaxInt32 || limitNumber != math.Trunc(limitNumber) {
+					return utils.ErrResponse(fmt.Errorf("invalid limit: %v, expected non-negative integer", limitNumber))
+				}
+				limit = int(limitNumber)
 			}
 
 			clientset, err := pool.GetClientset(k8sCtx)
@@ -46,32 +85,18 @@ func NewListEventsTool(pool k8s.ClientPool) fxctx.Tool {
 				return errResponse(err)
 			}
 
-			options := metav1.ListOptions{}
-			if limit, err := input.Number("limit"); err == nil {
-				options.Limit = int64(limit)
-			}
-
-			events, err := clientset.
-				CoreV1().
-				Events(k8sNamespace).
-				List(ctx, options)
+			events, err := listEvents(ctx, clientset, k8sNamespace, filter)
 			if err != nil {
 				return errResponse(err)
 			}
 
-			var contents = make([]interface{}, len(events.Items))
-			for i, event := range events.Items {
-				eventInList := EventInList{
-					Action:  event.Action,
-					Message: event.Message,
-					Type:    event.Type,
-					Reason:  event.Reason,
-					InvolvedObject: InvolvedObject{
-						Kind: event.InvolvedObject.Kind,
-						Name: event.InvolvedObject.Name,
-					},
-				}
-				content, err := NewJsonContent(eventInList)
+			if limit >= 0 && limit < len(events) {
+				events = events[len(events)-limit:]
+			}
+
+			var contents = make([]interface{}, len(events))
+			for i, event := range events {
+				content, err := NewJsonContent(event)
 				if err != nil {
 					return errResponse(err)
 				}
@@ -79,8 +104,8 @@ func NewListEventsTool(pool k8s.ClientPool) fxctx.Tool {
 			}
 
 			return &mcp.CallToolResult{
-				Meta:    map[string]interface{}{},
-				Content: contents,
+				Meta:    namespaceMeta(k8sNamespace),
+				Content: withNamespaceContent(contents, k8sNamespace),
 				IsError: utils.Ptr(false),
 			}
 		},
@@ -88,14 +113,183 @@ func NewListEventsTool(pool k8s.ClientPool) fxctx.Tool {
 }
 
 type InvolvedObject struct {
-	Kind string `json:"kind"`
-	Name string `json:"name"`
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+	UID       string `json:"uid,omitempty"`
 }
 
 type EventInList struct {
-	Action         string         `json:"action"`
-	Message        string         `json:"message"`
-	Type           string         `json:"type"`
-	Reason         string         `json:"reason"`
-	InvolvedObject InvolvedObject `json:"involvedObject"`
+	Namespace          string         `json:"namespace"`
+	Action             string         `json:"action"`
+	Message            string         `json:"message"`
+	Type               string         `json:"type"`
+	Reason             string         `json:"reason"`
+	Count              int32          `json:"count"`
+	FirstTimestamp     string         `json:"firstTimestamp,omitempty"`
+	LastTimestamp      string         `json:"lastTimestamp,omitempty"`
+	ReportingComponent string         `json:"reportingComponent,omitempty"`
+	InvolvedObject     InvolvedObject `json:"involvedObject"`
+
+	// id is namespace and name of the event object
+	id            string
+	firstOccurred time.Time
+	lastOccurred  time.Time
+}
+
+// eventFilter selects events, empty fields match any event
+type eventFilter struct {
+	Type               string
+	Reason             string
+	InvolvedObjectKind string
+	InvolvedObjectName string
+	InvolvedObjectUID  string
+	// Since excludes events, which last occurred before it
+	Since time.Time
+}
+
+func (f eventFilter) matches(event EventInList) bool {
+	return (f.Type == "" || strings.EqualFold(f.Type, event.Type)) &&
+		(f.Reason == "" || f.Reason == event.Reason) &&
+		(f.InvolvedObjectKind == "" || strings.EqualFold(f.InvolvedObjectKind, event.InvolvedObject.Kind)) &&
+		(f.InvolvedObjectName == "" || f.InvolvedObjectName == event.InvolvedObject.Name) &&
+		(f.InvolvedObjectUID == "" || f.InvolvedObjectUID == event.InvolvedObject.UID) &&
+		(f.Since.IsZero() || !event.lastOccurred.Before(f.Since))
+}
+
+// fieldSelector narrows down events on the server side, the same field
+// names are supported for both core and events.k8s.io events, where
+// involvedObject fields are mapped to regarding ones by the server
+func (f eventFilter) fieldSelector(involvedObjectField string) string {
+	var selectors []fields.Selector
+	if f.Reason != "" {
+		selectors = append(selectors, fields.OneTermEqualSelector("reason", f.Reason))
+	}
+	if f.InvolvedObjectName != "" {
+		selectors = append(selectors, fields.OneTermEqualSelector(involvedObjectField+".name", f.InvolvedObjectName))
+	}
+	if f.InvolvedObjectUID != "" {
+		selectors = append(selectors, fields.OneTermEqualSelector(involvedObjectField+".uid", f.InvolvedObjectUID))
+	}
+	// type and kind are compared case-insensitively, so they are only filtered locally
+	return fields.AndSelectors(selectors...).String()
+}
+
+// listEvents returns events matching the filter sorted by last occurrence,
+// events.k8s.io/v1 API is used when the server supports it, as it
+// provides event series, otherwise events are read from core API
+func listEvents(ctx context.Context, clientset kubernetes.Interface, namespace string, filter eventFilter) ([]EventInList, error) {
+	var events []EventInList
+	if _, err := clientset.Discovery().ServerResourcesForGroupVersion(eventsv1.SchemeGroupVersion.String()); err == nil {
+		list, err := clientset.EventsV1().Events(namespace).List(ctx, metav1.ListOptions{
+			FieldSelector: filter.fieldSelector("regarding"),
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, event := range list.Items {
+			events = append(events, eventFromEventsV1(event))
+		}
+	} else {
+		list, err := clientset.CoreV1().Events(namespace).List(ctx, metav1.ListOptions{
+			FieldSelector: filter.fieldSelector("involvedObject"),
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, event := range list.Items {
+			events = append(events, eventFromCoreV1(event))
+		}
+	}
+
+	matching := []EventInList{}
+	for _, event := range events {
+		if filter.matches(event) {
+			matching = append(matching, event)
+		}
+	}
+
+	sort.SliceStable(matching, func(i, j int) bool {
+		return matching[i].lastOccurred.Before(matching[j].lastOccurred)
+	})
+	return matching, nil
+}
+
+func eventFromCoreV1(event corev1.Event) EventInList {
+	first := firstNonZeroTime(event.FirstTimestamp.Time, event.EventTime.Time)
+	last := firstNonZeroTime(event.LastTimestamp.Time, event.EventTime.Time, event.CreationTimestamp.Time)
+	count := event.Count
+	if event.Series != nil {
+		last = firstNonZeroTime(event.Series.LastObservedTime.Time, last)
+		count = event.Series.Count
+	}
+
+	return newEventInList(event.ObjectMeta, event.Action, event.Message, event.Type, event.Reason, count,
+		first, last, utils.FirstNonEmpty(event.ReportingController, event.Source.Component), event.InvolvedObject)
+}
+
+func eventFromEventsV1(event eventsv1.Event) EventInList {
+	first := firstNonZeroTime(event.DeprecatedFirstTimestamp.Time, event.EventTime.Time)
+	last := firstNonZeroTime(event.DeprecatedLastTimestamp.Time, event.EventTime.Time, event.CreationTimestamp.Time)
+	count := event.DeprecatedCount
+	if event.Series != nil {
+		last = firstNonZeroTime(event.Series.LastObservedTime.Time, last)
+		count = event.Series.Count
+	}
+
+	return newEventInList(event.ObjectMeta, event.Action, event.Note, event.Type, event.Reason, count,
+		first, last, utils.FirstNonEmpty(event.ReportingController, event.DeprecatedSource.Component), event.Regarding)
+}
+
+func newEventInList(
+	meta metav1.ObjectMeta,
+	action, message, eventType, reason string,
+	count int32,
+	first, last time.Time,
+	reportingComponent string,
+	regarding corev1.ObjectReference,
+) EventInList {
+	if count == 0 {
+		// single events created with events.k8s.io API do not have count
+		count = 1
+	}
+	if first.IsZero() {
+		first = last
+	}
+	return EventInList{
+		Namespace:          meta.Namespace,
+		Action:             action,
+		Message:            message,
+		Type:               eventType,
+		Reason:             reason,
+		Count:              count,
+		FirstTimestamp:     formatEventTime(first),
+		LastTimestamp:      formatEventTime(last),
+		ReportingComponent: reportingComponent,
+		InvolvedObject: InvolvedObject{
+			Kind:      regarding.Kind,
+			Name:      regarding.Name,
+			Namespace: regarding.Namespace,
+			UID:       string(regarding.UID),
+		},
+		id:            meta.Namespace + "/" + meta.Name,
+		firstOccurred: first,
+		lastOccurred:  last,
+	}
+}
+
+func formatEventTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func firstNonZeroTime(times ...time.Time) time.Time {
+	for _, t := range times {
+		if !t.IsZero() {
+			return t
+		}
+	}
+	return time.Time{}
 }
@@ -0,0 +1,155 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/strowk/foxy-contexts/pkg/mcp"
+	"github.com/strowk/foxy-contexts/pkg/session"
+	"github.com/strowk/mcp-k8s-go/internal/k8s"
+	mock_k8s "github.com/strowk/mcp-k8s-go/internal/k8s/mock"
+	"go.uber.org/mock/gomock"
+	corev1 "k8s.io/api/core/v1"
+	eventsv1 "k8s.io/api/events/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestListEvents(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	coreEvent := func(name, namespace, eventType, reason, podName string, last time.Time) *corev1.Event {
+		return &corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Type:           eventType,
+			Reason:         reason,
+			Message:        reason + " " + podName,
+			Count:          2,
+			FirstTimestamp: metav1.NewTime(last.Add(-time.Minute)),
+			LastTimestamp:  metav1.NewTime(last),
+			Source:         corev1.EventSource{Component: "kubelet"},
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: podName, Namespace: namespace, UID: types.UID("uid-" + podName)},
+		}
+	}
+
+	call := func(t *testing.T, clientset *fake.Clientset, args map[string]any) []EventInList {
+		cntr := gomock.NewController(t)
+		poolMock := mock_k8s.NewMockClientPool(cntr)
+		poolMock.EXPECT().GetClientset("context").Return(clientset, nil)
+
+		tool := NewListEventsTool(poolMock, k8s.NewDefaults(session.NewSessionManager()))
+		args["context"] = "context"
+		resp := tool.Callback(context.Background(), args)
+		require.NotNil(t, resp.IsError)
+		require.False(t, *resp.IsError, resp.Content)
+
+		contents := resp.Content
+		if namespace, ok := args["namespace"]; ok {
+			require.NotEmpty(t, contents)
+			assert.Equal(t, "served from namespace "+namespace.(string), contents[len(contents)-1].(mcp.TextContent).Text)
+			contents = contents[:len(contents)-1]
+		}
+
+		events := []EventInList{}
+		for _, content := range contents {
+			var event EventInList
+			require.NoError(t, json.Unmarshal([]byte(content.(mcp.TextContent).Text), &event))
+			events = append(events, event)
+		}
+		return events
+	}
+
+	coreEvents := func() *fake.Clientset {
+		return fake.NewClientset(
+			coreEvent("newest", "a", "Warning", "BackOff", "web", now),
+			coreEvent("oldest", "b", "Normal", "Pulled", "web", now.Add(-2*time.Hour)),
+			coreEvent("middle", "a", "Normal", "Started", "db", now.Add(-time.Minute)),
+		)
+	}
+
+	t.Run("List events from all namespaces sorted by last occurrence", func(t *testing.T) {
+		events := call(t, coreEvents(), map[string]any{"allNamespaces": true})
+		if assert.Len(t, events, 3) {
+			assert.Equal(t, "Pulled", events[0].Reason)
+			assert.Equal(t, "Started", events[1].Reason)
+			assert.Equal(t, EventInList{
+				Namespace:          "a",
+				Message:            "BackOff web",
+				Type:               "Warning",
+				Reason:             "BackOff",
+				Count:              2,
+				FirstTimestamp:     now.Add(-time.Minute).Format(time.RFC3339),
+				LastTimestamp:      now.Format(time.RFC3339),
+				ReportingComponent: "kubelet",
+				InvolvedObject:     InvolvedObject{Kind: "Pod", Name: "web", Namespace: "a", UID: "uid-web"},
+			}, events[2])
+		}
+	})
+
+	t.Run("Filter events", func(t *testing.T) {
+		events := call(t, coreEvents(), map[string]any{"allNamespaces": true, "type": "normal", "involvedObjectKind": "pod", "involvedObjectName": "web"})
+		if assert.Len(t, events, 1) {
+			assert.Equal(t, "Pulled", events[0].Reason)
+		}
+
+		events = call(t, coreEvents(), map[string]any{"namespace": "a", "since": "10m"})
+		assert.Len(t, events, 2)
+
+		events = call(t, coreEvents(), map[string]any{"allNamespaces": true, "involvedObjectUid": "uid-db"})
+		if assert.Len(t, events, 1) {
+			assert.Equal(t, "Started", events[0].Reason)
+		}
+	})
+
+	t.Run("Limit keeps most recent events", func(t *testing.T) {
+		events := call(t, coreEvents(), map[string]any{"allNamespaces": true, "limit": 1.0})
+		if assert.Len(t, events, 1) {
+			assert.Equal(t, "BackOff", events[0].Reason)
+		}
+	})
+
+	t.Run("Reject invalid limit", func(t *testing.T) {
+		tool := NewListEventsTool(mock_k8s.NewMockClientPool(gomock.NewController(t)), k8s.NewDefaults(session.NewSessionManager()))
+		for _, limit := range []float64{-1, 1.5} {
+			resp := tool.Callback(context.Background(), map[string]any{"context": "context", "allNamespaces": true, "limit": limit})
+			require.NotNil(t, resp.IsError)
+			assert.True(t, *resp.IsError)
+			assert.Contains(t, resp.Content[0].(mcp.TextContent).Text, "invalid limit")
+		}
+	})
+
+	t.Run("Read events from events.k8s.io when available", func(t *testing.T) {
+		clientset := fake.NewClientset(
+			&eventsv1.Event{
+				ObjectMeta:          metav1.ObjectMeta{Name: "series", Namespace: "a"},
+				EventTime:           metav1.NewMicroTime(now.Add(-time.Hour)),
+				Series:              &eventsv1.EventSeries{Count: 5, LastObservedTime: metav1.NewMicroTime(now)},
+				ReportingController: "kubelet",
+				Action:              "Pulling",
+				Reason:              "BackOff",
+				Note:                "Back-off pulling image",
+				Type:                "Warning",
+				Regarding:           corev1.ObjectReference{Kind: "Pod", Name: "web", Namespace: "a"},
+			},
+		)
+		clientset.Resources = []*metav1.APIResourceList{{GroupVersion: "events.k8s.io/v1"}}
+
+		events := call(t, clientset, map[string]any{"namespace": "a"})
+		assert.Equal(t, []EventInList{{
+			Namespace:          "a",
+			Action:             "Pulling",
+			Message:            "Back-off pulling image",
+			Type:               "Warning",
+			Reason:             "BackOff",
+			Count:              5,
+			FirstTimestamp:     now.Add(-time.Hour).Format(time.RFC3339),
+			LastTimestamp:      now.Format(time.RFC3339),
+			ReportingComponent: "kubelet",
+			InvolvedObject:     InvolvedObject{Kind: "Pod", Name: "web", Namespace: "a"},
+		}}, events)
+	})
+}
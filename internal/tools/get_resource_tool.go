@@ -13,10 +13,11 @@ import (
 	"github.com/strowk/mcp-k8s-go/internal/content"
 	"github.com/strowk/mcp-k8s-go/internal/k8s"
 	"github.com/strowk/mcp-k8s-go/internal/utils"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 )
 
-func NewGetResourceTool(pool k8s.ClientPool) fxctx.Tool {
+func NewGetResourceTool(pool k8s.ClientPool, defaults k8s.Defaults) fxctx.Tool {
 	contextProperty := "context"
 	namespaceProperty := "namespace"
 	kindProperty := "kind"
@@ -27,7 +28,7 @@ func NewGetResourceTool(pool k8s.ClientPool) fxctx.Tool {
 
 	inputSchema := toolinput.NewToolInputSchema(
 		toolinput.WithString(contextProperty, "Name of the Kubernetes context to use, defaults to current context"),
-		toolinput.WithString(namespaceProperty, "Namespace to get resource from, skip for cluster resources"),
+		toolinput.WithString(namespaceProperty, "Namespace to get resource from, defaults to namespace of the context, ignored for cluster resources"),
 		toolinput.WithString(groupProperty, "API Group of the resource to get"),
 		toolinput.WithString(versionProperty, "API Version of the resource to get"),
 		toolinput.WithRequiredString(kindProperty, "Kind of resource to get"),
@@ -41,13 +42,13 @@ func NewGetResourceTool(pool k8s.ClientPool) fxctx.Tool {
 			Description: utils.Ptr("Get details of any Kubernetes resource like pod, node or service - completely as JSON or rendered using template"),
 			InputSchema: inputSchema.GetMcpToolInputSchema(),
 		},
-		func(_ context.Context, args map[string]any) *mcp.CallToolResult {
+		func(ctx context.Context, args map[string]any) *mcp.CallToolResult {
 			input, err := inputSchema.Validate(args)
 			if err != nil {
 				return utils.ErrResponse(err)
 			}
 
-			k8sCtx := input.StringOr(contextProperty, "")
+			k8sCtx := defaults.ResolveContext(ctx, input.StringOr(contextProperty, ""))
 			namespace := input.StringOr(namespaceProperty, "")
 
 			kind, err := input.String(kindProperty)
@@ -70,11 +71,21 @@ func NewGetResourceTool(pool k8s.ClientPool) fxctx.Tool {
 				return utils.ErrResponse(err)
 			}
 
+			mapping, err := pool.GetRESTMapping(k8sCtx, kind, group, version)
+			if err != nil {
+				return utils.ErrResponse(err)
+			}
+
 			var key string
 
-			if namespace == "" {
+			if mapping.Scope.Name() != meta.RESTScopeNameNamespace {
+				namespace = ""
 				key = name
 			} else {
+				namespace, err = defaults.ResolveNamespace(ctx, k8sCtx, namespace)
+				if err != nil {
+					return utils.ErrResponse(err)
+				}
 				key = fmt.Sprintf("%s/%s", namespace, name)
 			}
 			accumulator, exist, err := informer.Informer().GetIndexer().GetByKey(key)
@@ -130,8 +141,8 @@ func NewGetResourceTool(pool k8s.ClientPool) fxctx.Tool {
 			var contents = []any{cnt}
 
 			return &mcp.CallToolResult{
-				Meta:    map[string]any{},
-				Content: contents,
+				Meta:    namespaceMeta(namespace),
+				Content: withNamespaceContent(contents, namespace),
 				IsError: utils.Ptr(false),
 			}
 		},
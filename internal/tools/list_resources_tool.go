@@ -11,21 +11,24 @@ import (
 	"github.com/strowk/mcp-k8s-go/internal/k8s"
 	"github.com/strowk/mcp-k8s-go/internal/k8s/list_mapping"
 	"github.com/strowk/mcp-k8s-go/internal/utils"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
-func NewListResourcesTool(pool k8s.ClientPool) fxctx.Tool {
+func NewListResourcesTool(pool k8s.ClientPool, defaults k8s.Defaults) fxctx.Tool {
 	contextProperty := "context"
 	namespaceProperty := "namespace"
+	allNamespacesProperty := "allNamespaces"
 	kindProperty := "kind"
 	groupProperty := "group"
 	versionProperty := "version"
 
 	inputSchema := toolinput.NewToolInputSchema(
 		toolinput.WithString(contextProperty, "Name of the Kubernetes context to use, defaults to current context"),
-		toolinput.WithString(namespaceProperty, "Namespace to list resources from, defaults to all namespaces"),
+		toolinput.WithString(namespaceProperty, "Namespace to list resources from, defaults to namespace of the context, ignored for cluster resources"),
+		toolinput.WithBoolean(allNamespacesProperty, "List resources from all namespaces, defaults to false"),
 		toolinput.WithString(groupProperty, "API Group of resources to list"),
 		toolinput.WithString(versionProperty, "API Version of resources to list"),
 		toolinput.WithRequiredString(kindProperty, "Kind of resources to list"),
@@ -43,8 +46,9 @@ func NewListResourcesTool(pool k8s.ClientPool) fxctx.Tool {
 				return utils.ErrResponse(err)
 			}
 
-			k8sCtx := input.StringOr(contextProperty, "")
-			namespace := input.StringOr(namespaceProperty, metav1.NamespaceAll)
+			k8sCtx := defaults.ResolveContext(ctx, input.StringOr(contextProperty, ""))
+			namespace := input.StringOr(namespaceProperty, "")
+			allNamespaces := input.BooleanOr(allNamespacesProperty, false)
 
 			kind, err := input.String(kindProperty)
 			if err != nil {
@@ -59,6 +63,20 @@ func NewListResourcesTool(pool k8s.ClientPool) fxctx.Tool {
 				return utils.ErrResponse(err)
 			}
 
+			mapping, err := pool.GetRESTMapping(k8sCtx, kind, group, version)
+			if err != nil {
+				return utils.ErrResponse(err)
+			}
+
+			if allNamespaces || mapping.Scope.Name() != meta.RESTScopeNameNamespace {
+				namespace = metav1.NamespaceAll
+			} else {
+				namespace, err = defaults.ResolveNamespace(ctx, k8sCtx, namespace)
+				if err != nil {
+					return utils.ErrResponse(err)
+				}
+			}
+
 			listMapping := pool.GetListMapping(k8sCtx, kind, group, version)
 			var unstructuredList []runtime.Object
 
@@ -132,8 +150,8 @@ func NewListResourcesTool(pool k8s.ClientPool) fxctx.Tool {
 			}
 
 			return &mcp.CallToolResult{
-				Meta:    map[string]any{},
-				Content: contents,
+				Meta:    namespaceMeta(namespace),
+				Content: withNamespaceContent(contents, namespace),
 				IsError: utils.Ptr(false),
 			}
 		},
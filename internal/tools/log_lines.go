@@ -0,0 +1,80 @@
+package tools
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// maxLogLineBytes is the longest line of logs, which can be split off,
+// longer lines fail parsing instead of being silently dropped
+const maxLogLineBytes = 16 * 1024 * 1024
+
+// logLine is a line of container logs requested with timestamps,
+// which kubelet puts in front of every line
+type logLine struct {
+	timestamp time.Time
+	// source is pod and container, which produced the line, like pod/container
+	source string
+	text   string
+}
+
+// parseLogLines splits logs requested with timestamps into lines,
+// lines without timestamp get timestamp of the previous line,
+// lines parsed before an error are returned together with it
+func parseLogLines(source string, data []byte) ([]logLine, error) {
+	var lines []logLine
+	var lastTimestamp time.Time
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLogLineBytes)
+	for scanner.Scan() {
+		text := scanner.Text()
+		timestamp := lastTimestamp
+		if prefix, rest, found := strings.Cut(text, " "); found {
+			if parsed, err := time.Parse(time.RFC3339Nano, prefix); err == nil {
+				timestamp = parsed
+				text = rest
+			}
+		} else if parsed, err := time.Parse(time.RFC3339Nano, text); err == nil {
+			// empty line with timestamp only
+			timestamp = parsed
+			text = ""
+		}
+		lastTimestamp = timestamp
+		lines = append(lines, logLine{
+			timestamp: timestamp,
+			source:    source,
+			text:      text,
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return lines, fmt.Errorf("failed to split logs of %s into lines: %w", source, err)
+	}
+	return lines, nil
+}
+
+func formatLogLine(line logLine, timestamps bool) string {
+	if timestamps {
+		return fmt.Sprintf("[%s] %s %s", line.source, line.timestamp.Format(time.RFC3339Nano), line.text)
+	}
+	return fmt.Sprintf("[%s] %s", line.source, line.text)
+}
+
+// formatLogLines formats lines, which fit into maxBytes, keeping the newest
+// ones, and returns number of older lines omitted as they do not fit
+func formatLogLines(lines []logLine, timestamps bool, maxBytes int) (string, int) {
+	formatted := make([]string, len(lines))
+	first, size := len(lines), 0
+	for i := len(lines) - 1; i >= 0; i-- {
+		line := formatLogLine(lines[i], timestamps) + "\n"
+		if size+len(line) > maxBytes {
+			break
+		}
+		size += len(line)
+		formatted[i] = line
+		first = i
+	}
+	return strings.Join(formatted[first:], ""), first
+}
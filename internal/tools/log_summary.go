@@ -0,0 +1,209 @@
+package tools
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// drainDepth is depth of the parse tree, first level groups lines by number
+	// of tokens and following levels by leading tokens, so depth 4 uses 2 of them
+	drainDepth = 4
+	// drainSimilarity is minimal share of tokens, which must be equal
+	// to template of the cluster for the line to join it
+	drainSimilarity = 0.4
+	// drainMaxChildren limits number of children of a tree node,
+	// further tokens are routed to the wildcard child
+	drainMaxChildren = 100
+	// maxSummaryClusters limits number of returned clusters, most frequent are kept
+	maxSummaryClusters = 100
+
+	drainWildcard = "<*>"
+)
+
+// variableTokenPatterns match tokens, which are replaced with wildcard
+// before clustering, as they are almost always variable parts of messages
+var variableTokenPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`^[-+]?\d+([.,]\d+)?(ns|us|µs|ms|s|m|h|%|b|kb|mb|gb|KiB|MiB|GiB)?[,;.]?$`),
+	regexp.MustCompile(`^(\d{1,3}\.){3}\d{1,3}(:\d+)?$`),
+	regexp.MustCompile(`^(0x)?[0-9a-fA-F]*[0-9][0-9a-fA-F]*$`),
+	regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`),
+	regexp.MustCompile(`^\d{4}-\d{2}-\d{2}([T ]\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:?\d{2})?)?$`),
+	regexp.MustCompile(`^\d{2}:\d{2}:\d{2}(\.\d+)?$`),
+}
+
+// LogSummary describes logs clustered into templates of similar lines
+type LogSummary struct {
+	Container       string       `json:"container,omitempty"`
+	Lines           int          `json:"lines"`
+	Clusters        []LogCluster `json:"clusters"`
+	OmittedClusters int          `json:"omittedClusters,omitempty"`
+}
+
+// LogCluster is a group of log lines matching the same template,
+// where variable parts are replaced with <*>
+type LogCluster struct {
+	Template  string `json:"template"`
+	Count     int    `json:"count"`
+	FirstSeen string `json:"firstSeen,omitempty"`
+	LastSeen  string `json:"lastSeen,omitempty"`
+	Example   string `json:"example"`
+}
+
+// summarizeLogLines clusters lines using Drain algorithm for log parsing,
+// see "Drain: An Online Log Parsing Approach with Fixed Depth Tree" by He et al.
+func summarizeLogLines(lines []logLine) LogSummary {
+	parser := &drainParser{root: map[int]*drainNode{}}
+	summary := LogSummary{}
+	for _, line := range lines {
+		if strings.TrimSpace(line.text) == "" {
+			continue
+		}
+		summary.Lines++
+		parser.add(line)
+	}
+
+	sort.SliceStable(parser.clusters, func(i, j int) bool {
+		return parser.clusters[i].count > parser.clusters[j].count
+	})
+
+	summary.Clusters = []LogCluster{}
+	for i, cluster := range parser.clusters {
+		if i >= maxSummaryClusters {
+			summary.OmittedClusters = len(parser.clusters) - maxSummaryClusters
+			break
+		}
+		summaryCluster := LogCluster{
+			Template: strings.Join(cluster.template, " "),
+			Count:    cluster.count,
+			Example:  cluster.example,
+		}
+		if !cluster.firstSeen.IsZero() {
+			summaryCluster.FirstSeen = cluster.firstSeen.Format(time.RFC3339Nano)
+			summaryCluster.LastSeen = cluster.lastSeen.Format(time.RFC3339Nano)
+		}
+		summary.Clusters = append(summary.Clusters, summaryCluster)
+	}
+	return summary
+}
+
+type drainCluster struct {
+	template  []string
+	count     int
+	firstSeen time.Time
+	lastSeen  time.Time
+	example   string
+}
+
+type drainNode struct {
+	children map[string]*drainNode
+	clusters []*drainCluster
+}
+
+type drainParser struct {
+	// root groups nodes by number of tokens in the line
+	root     map[int]*drainNode
+	clusters []*drainCluster
+}
+
+func (p *drainParser) add(line logLine) {
+	tokens := tokenizeLogLine(line.text)
+
+	leaf := p.leaf(tokens)
+	cluster := bestDrainCluster(leaf.clusters, tokens)
+	if cluster == nil {
+		cluster = &drainCluster{
+			template:  tokens,
+			firstSeen: line.timestamp,
+			example:   line.text,
+		}
+		leaf.clusters = append(leaf.clusters, cluster)
+		p.clusters = append(p.clusters, cluster)
+	} else {
+		for i, token := range tokens {
+			if cluster.template[i] != token {
+				cluster.template[i] = drainWildcard
+			}
+		}
+	}
+
+	cluster.count++
+	if cluster.firstSeen.IsZero() || line.timestamp.Before(cluster.firstSeen) {
+		cluster.firstSeen = line.timestamp
+	}
+	if line.timestamp.After(cluster.lastSeen) {
+		cluster.lastSeen = line.timestamp
+	}
+}
+
+// leaf finds or creates leaf node of the tree for the tokens
+func (p *drainParser) leaf(tokens []string) *drainNode {
+	node, ok := p.root[len(tokens)]
+	if !ok {
+		node = &drainNode{children: map[string]*drainNode{}}
+		p.root[len(tokens)] = node
+	}
+
+	for i := 0; i < drainDepth-2 && i < len(tokens); i++ {
+		key := tokens[i]
+		if hasDigit(key) {
+			key = drainWildcard
+		}
+		if _, ok := node.children[key]; !ok && len(node.children) >= drainMaxChildren {
+			key = drainWildcard
+		}
+		child, ok := node.children[key]
+		if !ok {
+			child = &drainNode{children: map[string]*drainNode{}}
+			node.children[key] = child
+		}
+		node = child
+	}
+	return node
+}
+
+func bestDrainCluster(clusters []*drainCluster, tokens []string) *drainCluster {
+	var best *drainCluster
+	bestSimilarity := -1.0
+	bestWildcards := -1
+	for _, cluster := range clusters {
+		equal, wildcards := 0, 0
+		for i, token := range cluster.template {
+			if token == drainWildcard {
+				wildcards++
+			} else if token == tokens[i] {
+				equal++
+			}
+		}
+		similarity := 1.0
+		if len(tokens) > 0 {
+			similarity = float64(equal) / float64(len(tokens))
+		}
+		if similarity > bestSimilarity || (similarity == bestSimilarity && wildcards > bestWildcards) {
+			best, bestSimilarity, bestWildcards = cluster, similarity, wildcards
+		}
+	}
+	if bestSimilarity < drainSimilarity {
+		return nil
+	}
+	return best
+}
+
+func tokenizeLogLine(text string) []string {
+	tokens := strings.Fields(text)
+	for i, token := range tokens {
+		for _, pattern := range variableTokenPatterns {
+			if pattern.MatchString(token) {
+				tokens[i] = drainWildcard
+				break
+			}
+		}
+	}
+	return tokens
+}
+
+func hasDigit(token string) bool {
+	return strings.ContainsAny(token, "0123456789")
+}
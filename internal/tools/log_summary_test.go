@@ -0,0 +1,61 @@
+package tools
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummarizeLogLines(t *testing.T) {
+	var logs strings.Builder
+	for i := 0; i < 5; i++ {
+		fmt.Fprintf(&logs, "2024-12-01T19:00:0%dZ connection refused to db:5432 after %dms\n", i, 100+i)
+		fmt.Fprintf(&logs, "2024-12-01T19:00:0%dZ retrying request for user %s\n", i, []string{"alice", "bob", "carol", "dave", "eve"}[i])
+	}
+	logs.WriteString("2024-12-01T19:00:09Z server started on 10.0.0.1:8080\n")
+	logs.WriteString("2024-12-01T19:00:09Z \n")
+
+	lines, err := parseLogLines("app", []byte(logs.String()))
+	require.NoError(t, err)
+	summary := summarizeLogLines(lines)
+
+	assert.Equal(t, LogSummary{
+		Lines: 11,
+		Clusters: []LogCluster{
+			{
+				Template:  "connection refused to db:5432 after <*>",
+				Count:     5,
+				FirstSeen: "2024-12-01T19:00:00Z",
+				LastSeen:  "2024-12-01T19:00:04Z",
+				Example:   "connection refused to db:5432 after 100ms",
+			},
+			{
+				Template:  "retrying request for user <*>",
+				Count:     5,
+				FirstSeen: "2024-12-01T19:00:00Z",
+				LastSeen:  "2024-12-01T19:00:04Z",
+				Example:   "retrying request for user alice",
+			},
+			{
+				Template:  "server started on <*>",
+				Count:     1,
+				FirstSeen: "2024-12-01T19:00:09Z",
+				LastSeen:  "2024-12-01T19:00:09Z",
+				Example:   "server started on 10.0.0.1:8080",
+			},
+		},
+	}, summary)
+}
+
+func TestSummarizeLogLinesWithoutTimestamps(t *testing.T) {
+	lines, err := parseLogLines("app", []byte("fake logs"))
+	require.NoError(t, err)
+	summary := summarizeLogLines(lines)
+	assert.Equal(t, LogSummary{
+		Lines:    1,
+		Clusters: []LogCluster{{Template: "fake logs", Count: 1, Example: "fake logs"}},
+	}, summary)
+}
@@ -0,0 +1,30 @@
+package tools
+
+import (
+	"fmt"
+
+	"github.com/strowk/foxy-contexts/pkg/mcp"
+)
+
+// namespaceMeta returns metadata for tool response, which states
+// namespace that was used to serve the request, if there was any
+func namespaceMeta(namespace string) map[string]any {
+	meta := map[string]any{}
+	if namespace != "" {
+		meta["namespace"] = namespace
+	}
+	return meta
+}
+
+// withNamespaceContent appends text content stating namespace that
+// was used to serve the request, if there was any, so that clients
+// which do not read metadata could still see the resolved namespace
+func withNamespaceContent(contents []interface{}, namespace string) []interface{} {
+	if namespace == "" {
+		return contents
+	}
+	return append(contents, mcp.TextContent{
+		Type: "text",
+		Text: fmt.Sprintf("served from namespace %s", namespace),
+	})
+}
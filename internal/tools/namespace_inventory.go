@@ -0,0 +1,69 @@
+package tools
+
+import (
+	"context"
+
+	"github.com/strowk/foxy-contexts/pkg/fxctx"
+	"github.com/strowk/foxy-contexts/pkg/mcp"
+	"github.com/strowk/foxy-contexts/pkg/toolinput"
+	"github.com/strowk/mcp-k8s-go/internal/content"
+	"github.com/strowk/mcp-k8s-go/internal/k8s"
+	"github.com/strowk/mcp-k8s-go/internal/k8s/core/v1/namespace"
+	"github.com/strowk/mcp-k8s-go/internal/utils"
+)
+
+func NewNamespaceInventoryTool(pool k8s.ClientPool, defaults k8s.Defaults) fxctx.Tool {
+	inputSchema := toolinput.NewToolInputSchema(
+		toolinput.WithString("context", "Name of the Kubernetes context to use, defaults to current context"),
+		toolinput.WithString("namespace", "Name of the namespace to get inventory of, defaults to all namespaces"),
+		toolinput.WithString("labelSelector", "Only get inventory of namespaces matching this label selector"),
+	)
+
+	return fxctx.NewTool(
+		&mcp.Tool{
+			Name:        "get-k8s-namespace-inventory",
+			Description: utils.Ptr("Get inventory of Kubernetes namespaces with phase, labels, annotations, age, counts of pods by phase, deployments, services, persistent volume claims and secrets, resource quota usage against hard limits and limit ranges"),
+			InputSchema: inputSchema.GetMcpToolInputSchema(),
+		},
+		func(ctx context.Context, args map[string]any) *mcp.CallToolResult {
+			input, err := inputSchema.Validate(args)
+			if err != nil {
+				return utils.ErrResponse(err)
+			}
+
+			k8sCtx := defaults.ResolveContext(ctx, input.StringOr("context", ""))
+
+			clientset, err := pool.GetClientset(k8sCtx)
+			if err != nil {
+				return utils.ErrResponse(err)
+			}
+
+			metadataClient, err := pool.GetMetadataClient(k8sCtx)
+			if err != nil {
+				return utils.ErrResponse(err)
+			}
+
+			inventories, err := namespace.GetInventory(ctx, clientset, metadataClient, namespace.InventoryOptions{
+				Namespace:     input.StringOr("namespace", ""),
+				LabelSelector: input.StringOr("labelSelector", ""),
+			})
+			if err != nil {
+				return utils.ErrResponse(err)
+			}
+
+			contents := make([]any, 0, len(inventories))
+			for _, inventory := range inventories {
+				c, err := content.NewJsonContent(inventory)
+				if err != nil {
+					return utils.ErrResponse(err)
+				}
+				contents = append(contents, c)
+			}
+			return &mcp.CallToolResult{
+				Meta:    map[string]any{},
+				Content: contents,
+				IsError: utils.Ptr(false),
+			}
+		},
+	)
+}
@@ -14,7 +14,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
-func NewListNamespacesTool(pool k8s.ClientPool) fxctx.Tool {
+func NewListNamespacesTool(pool k8s.ClientPool, defaults k8s.Defaults) fxctx.Tool {
 	contextProperty := "context"
 	schema := toolinput.NewToolInputSchema(
 		toolinput.WithString(contextProperty, "Name of the Kubernetes context to use, defaults to current context"),
@@ -30,7 +30,7 @@ func NewListNamespacesTool(pool k8s.ClientPool) fxctx.Tool {
 			if err != nil {
 				return errResponse(err)
 			}
-			k8sCtx := input.StringOr(contextProperty, "")
+			k8sCtx := defaults.ResolveContext(ctx, input.StringOr(contextProperty, ""))
 
 			clientset, err := pool.GetClientset(k8sCtx)
 			if err != nil {
@@ -0,0 +1,73 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/strowk/foxy-contexts/pkg/fxctx"
+	"github.com/strowk/foxy-contexts/pkg/mcp"
+	"github.com/strowk/foxy-contexts/pkg/toolinput"
+	"github.com/strowk/mcp-k8s-go/internal/content"
+	"github.com/strowk/mcp-k8s-go/internal/k8s"
+	"github.com/strowk/mcp-k8s-go/internal/k8s/core/v1/node"
+	"github.com/strowk/mcp-k8s-go/internal/utils"
+)
+
+const defaultTopConsumers = 3
+
+func NewNodeAllocationTool(pool k8s.ClientPool, defaults k8s.Defaults) fxctx.Tool {
+	inputSchema := toolinput.NewToolInputSchema(
+		toolinput.WithString("context", "Name of the Kubernetes context to use, defaults to current context"),
+		toolinput.WithString("node", "Name of the node to report, defaults to all nodes"),
+		toolinput.WithString("labelSelector", "Only report nodes matching this label selector"),
+		toolinput.WithString("poolLabel", fmt.Sprintf("Label grouping nodes into pools, defaults to first found of %s", strings.Join(node.DefaultPoolLabels, ", "))),
+		toolinput.WithNumber("topConsumers", fmt.Sprintf("Number of pods with the biggest requests to report per node, defaults to %d", defaultTopConsumers)),
+	)
+
+	return fxctx.NewTool(
+		&mcp.Tool{
+			Name:        "get-k8s-node-allocation",
+			Description: utils.Ptr("Get resource allocation of Kubernetes nodes: sums of CPU, memory, ephemeral storage and GPU requests and limits of non-terminated pods against allocatable with overcommit ratios and top consumers per node, rolled up by node pools and the whole cluster"),
+			InputSchema: inputSchema.GetMcpToolInputSchema(),
+		},
+		func(ctx context.Context, args map[string]any) *mcp.CallToolResult {
+			input, err := inputSchema.Validate(args)
+			if err != nil {
+				return utils.ErrResponse(err)
+			}
+
+			k8sCtx := defaults.ResolveContext(ctx, input.StringOr("context", ""))
+
+			topConsumers := int(input.NumberOr("topConsumers", defaultTopConsumers))
+			if topConsumers < 0 {
+				return utils.ErrResponse(fmt.Errorf("invalid topConsumers: %d, expected non-negative number", topConsumers))
+			}
+
+			clientset, err := pool.GetClientset(k8sCtx)
+			if err != nil {
+				return utils.ErrResponse(err)
+			}
+
+			report, err := node.GetAllocation(ctx, clientset, node.AllocationOptions{
+				NodeName:      input.StringOr("node", ""),
+				LabelSelector: input.StringOr("labelSelector", ""),
+				PoolLabel:     input.StringOr("poolLabel", ""),
+				TopConsumers:  topConsumers,
+			})
+			if err != nil {
+				return utils.ErrResponse(err)
+			}
+
+			c, err := content.NewJsonContent(report)
+			if err != nil {
+				return utils.ErrResponse(err)
+			}
+			return &mcp.CallToolResult{
+				Meta:    map[string]any{},
+				Content: []any{c},
+				IsError: utils.Ptr(false),
+			}
+		},
+	)
+}
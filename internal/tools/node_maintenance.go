@@ -0,0 +1,152 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/strowk/foxy-contexts/pkg/fxctx"
+	"github.com/strowk/foxy-contexts/pkg/mcp"
+	"github.com/strowk/foxy-contexts/pkg/toolinput"
+	"github.com/strowk/mcp-k8s-go/internal/content"
+	"github.com/strowk/mcp-k8s-go/internal/k8s"
+	"github.com/strowk/mcp-k8s-go/internal/k8s/core/v1/node"
+	"github.com/strowk/mcp-k8s-go/internal/utils"
+)
+
+func NewCordonNodeTool(pool k8s.ClientPool, defaults k8s.Defaults) fxctx.Tool {
+	return newSetUnschedulableTool(pool, defaults, true)
+}
+
+func NewUncordonNodeTool(pool k8s.ClientPool, defaults k8s.Defaults) fxctx.Tool {
+	return newSetUnschedulableTool(pool, defaults, false)
+}
+
+func newSetUnschedulableTool(pool k8s.ClientPool, defaults k8s.Defaults, unschedulable bool) fxctx.Tool {
+	action := "cordon"
+	description := "Mark Kubernetes node as unschedulable, so that no new pods are scheduled to it"
+	if !unschedulable {
+		action = "uncordon"
+		description = "Mark Kubernetes node as schedulable again"
+	}
+
+	inputSchema := toolinput.NewToolInputSchema(
+		toolinput.WithString("context", "Name of the Kubernetes context to use, defaults to current context"),
+		toolinput.WithRequiredString("name", fmt.Sprintf("Name of the node to %s", action)),
+		toolinput.WithBoolean("dryRun", "Only run changes on the server side without persisting them, defaults to false"),
+	)
+
+	return fxctx.NewTool(
+		&mcp.Tool{
+			Name:        fmt.Sprintf("%s-k8s-node", action),
+			Description: utils.Ptr(description),
+			InputSchema: inputSchema.GetMcpToolInputSchema(),
+		},
+		func(ctx context.Context, args map[string]any) *mcp.CallToolResult {
+			input, err := inputSchema.Validate(args)
+			if err != nil {
+				return utils.ErrResponse(err)
+			}
+
+			k8sCtx := defaults.ResolveContext(ctx, input.StringOr("context", ""))
+
+			name, err := input.String("name")
+			if err != nil {
+				return utils.ErrResponse(err)
+			}
+
+			dryRun := input.BooleanOr("dryRun", false)
+
+			clientset, err := pool.GetClientset(k8sCtx)
+			if err != nil {
+				return utils.ErrResponse(err)
+			}
+
+			changed, err := node.SetUnschedulable(ctx, clientset, name, unschedulable, dryRun)
+			if err != nil {
+				return utils.ErrResponse(err)
+			}
+
+			text := fmt.Sprintf("node/%s %sed", name, action)
+			if !changed {
+				text = fmt.Sprintf("node/%s already %sed", name, action)
+			} else if dryRun {
+				text += " (server dry run)"
+			}
+
+			return &mcp.CallToolResult{
+				Meta: map[string]any{},
+				Content: []any{
+					mcp.TextContent{
+						Type: "text",
+						Text: text,
+					},
+				},
+				IsError: utils.Ptr(false),
+			}
+		},
+	)
+}
+
+func NewDrainNodeTool(pool k8s.ClientPool, defaults k8s.Defaults) fxctx.Tool {
+	inputSchema := toolinput.NewToolInputSchema(
+		toolinput.WithString("context", "Name of the Kubernetes context to use, defaults to current context"),
+		toolinput.WithRequiredString("name", "Name of the node to drain"),
+		toolinput.WithBoolean("dryRun", "Only list pods, which would be evicted, without cordoning the node and evicting them, defaults to false"),
+		toolinput.WithBoolean("force", "Evict also pods, which are not managed by any controller and would not be recreated, defaults to false"),
+		toolinput.WithBoolean("deleteEmptyDirData", "Evict also pods using emptyDir volumes, which data would be lost, defaults to false"),
+		toolinput.WithNumber("gracePeriodSeconds", "Seconds given to each pod to terminate gracefully, defaults to the value of the pod"),
+	)
+
+	return fxctx.NewTool(
+		&mcp.Tool{
+			Name:        "drain-k8s-node",
+			Description: utils.Ptr("Cordon Kubernetes node and evict its pods using Eviction API respecting pod disruption budgets, DaemonSet, mirror and terminating pods are skipped, reports pods, which eviction is blocked"),
+			InputSchema: inputSchema.GetMcpToolInputSchema(),
+		},
+		func(ctx context.Context, args map[string]any) *mcp.CallToolResult {
+			input, err := inputSchema.Validate(args)
+			if err != nil {
+				return utils.ErrResponse(err)
+			}
+
+			k8sCtx := defaults.ResolveContext(ctx, input.StringOr("context", ""))
+
+			name, err := input.String("name")
+			if err != nil {
+				return utils.ErrResponse(err)
+			}
+
+			options := node.DrainOptions{
+				DryRun:             input.BooleanOr("dryRun", false),
+				Force:              input.BooleanOr("force", false),
+				DeleteEmptyDirData: input.BooleanOr("deleteEmptyDirData", false),
+			}
+			if gracePeriodSeconds, err := input.Number("gracePeriodSeconds"); err == nil {
+				if gracePeriodSeconds < 0 {
+					return utils.ErrResponse(fmt.Errorf("invalid gracePeriodSeconds: %v, expected non-negative number", gracePeriodSeconds))
+				}
+				options.GracePeriodSeconds = utils.Ptr(int64(gracePeriodSeconds))
+			}
+
+			clientset, err := pool.GetClientset(k8sCtx)
+			if err != nil {
+				return utils.ErrResponse(err)
+			}
+
+			report, err := node.Drain(ctx, clientset, name, options)
+			if err != nil {
+				return utils.ErrResponse(err)
+			}
+
+			c, err := content.NewJsonContent(report)
+			if err != nil {
+				return utils.ErrResponse(err)
+			}
+			return &mcp.CallToolResult{
+				Meta:    map[string]any{},
+				Content: []any{c},
+				IsError: utils.Ptr(false),
+			}
+		},
+	)
+}
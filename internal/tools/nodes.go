@@ -3,7 +3,9 @@ package tools
 import (
 	"context"
 	"fmt"
+	"slices"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/strowk/mcp-k8s-go/internal/k8s"
@@ -13,18 +15,21 @@ import (
 	"github.com/strowk/foxy-contexts/pkg/mcp"
 	"github.com/strowk/foxy-contexts/pkg/toolinput"
 
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
-func NewListNodesTool(pool k8s.ClientPool) fxctx.Tool {
+func NewListNodesTool(pool k8s.ClientPool, defaults k8s.Defaults) fxctx.Tool {
 	contextProperty := "context"
+	labelSelectorProperty := "labelSelector"
 	schema := toolinput.NewToolInputSchema(
 		toolinput.WithString(contextProperty, "Name of the Kubernetes context to use, defaults to current context"),
+		toolinput.WithString(labelSelectorProperty, "Only list nodes matching this label selector, like node-role.kubernetes.io/control-plane or topology.kubernetes.io/zone=eu-west-1a"),
 	)
 	return fxctx.NewTool(
 		&mcp.Tool{
 			Name:        "list-k8s-nodes",
-			Description: utils.Ptr("List Kubernetes nodes using specific context"),
+			Description: utils.Ptr("List Kubernetes nodes using specific context with their roles, versions, addresses, capacity, taints, topology and conditions"),
 			InputSchema: schema.GetMcpToolInputSchema(),
 		},
 		func(ctx context.Context, args map[string]interface{}) *mcp.CallToolResult {
@@ -32,7 +37,7 @@ func NewListNodesTool(pool k8s.ClientPool) fxctx.Tool {
 			if err != nil {
 				return errResponse(err)
 			}
-			k8sCtx := input.StringOr(contextProperty, "")
+			k8sCtx := defaults.ResolveContext(ctx, input.StringOr(contextProperty, ""))
 
 			clientset, err := pool.GetClientset(k8sCtx)
 			if err != nil {
@@ -42,7 +47,7 @@ func NewListNodesTool(pool k8s.ClientPool) fxctx.Tool {
 			nodes, err := clientset.
 				CoreV1().
 				Nodes().
-				List(ctx, metav1.ListOptions{})
+				List(ctx, metav1.ListOptions{LabelSelector: input.StringOr(labelSelectorProperty, "")})
 			if err != nil {
 				return errResponse(err)
 			}
@@ -52,29 +57,8 @@ func NewListNodesTool(pool k8s.ClientPool) fxctx.Tool {
 			})
 
 			var contents = make([]interface{}, len(nodes.Items))
-			for i, ns := range nodes.Items {
-				// Calculate age
-				age := time.Since(ns.CreationTimestamp.Time)
-
-				// Determine status
-				status := "NotReady"
-				for _, condition := range ns.Status.Conditions {
-					if condition.Type == "Ready" {
-						if condition.Status == "True" {
-							status = "Ready"
-						} else {
-							status = "NotReady"
-						}
-						break
-					}
-				}
-
-				content, err := NewJsonContent(NodeInList{
-					Name:      ns.Name,
-					Status:    status,
-					Age:       formatAge(age),
-					CreatedAt: ns.CreationTimestamp.Time,
-				})
+			for i := range nodes.Items {
+				content, err := NewJsonContent(NewNodeInList(&nodes.Items[i]))
 				if err != nil {
 					return errResponse(err)
 				}
@@ -92,10 +76,124 @@ func NewListNodesTool(pool k8s.ClientPool) fxctx.Tool {
 
 // NodeInList provides a structured representation of node information
 type NodeInList struct {
-	Name      string    `json:"name"`
-	Status    string    `json:"status"`
-	Age       string    `json:"age"`
-	CreatedAt time.Time `json:"created_at"`
+	Name                    string         `json:"name"`
+	Status                  string         `json:"status"`
+	Age                     string         `json:"age"`
+	CreatedAt               time.Time      `json:"created_at"`
+	Roles                   []string       `json:"roles,omitempty"`
+	Unschedulable           bool           `json:"unschedulable,omitempty"`
+	KubeletVersion          string         `json:"kubelet_version,omitempty"`
+	OSImage                 string         `json:"os_image,omitempty"`
+	KernelVersion           string         `json:"kernel_version,omitempty"`
+	ContainerRuntimeVersion string         `json:"container_runtime_version,omitempty"`
+	InternalIPs             []string       `json:"internal_ips,omitempty"`
+	ExternalIPs             []string       `json:"external_ips,omitempty"`
+	Zone                    string         `json:"zone,omitempty"`
+	Region                  string         `json:"region,omitempty"`
+	Capacity                *NodeResources `json:"capacity,omitempty"`
+	Allocatable             *NodeResources `json:"allocatable,omitempty"`
+	Taints                  []string       `json:"taints,omitempty"`
+	Conditions              []string       `json:"conditions,omitempty"`
+}
+
+// NodeResources holds amounts of resources, which are most often needed
+type NodeResources struct {
+	CPU    string `json:"cpu,omitempty"`
+	Memory string `json:"memory,omitempty"`
+	Pods   string `json:"pods,omitempty"`
+}
+
+const nodeRoleLabelPrefix = "node-role.kubernetes.io/"
+
+func NewNodeInList(node *corev1.Node) NodeInList {
+	// Determine status
+	status := "NotReady"
+	var conditions []string
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == corev1.NodeReady {
+			if condition.Status == corev1.ConditionTrue {
+				status = "Ready"
+			}
+			continue
+		}
+		// all other conditions, like MemoryPressure, DiskPressure, PIDPressure
+		// and NetworkUnavailable, are only reported when they are not False
+		if condition.Status != corev1.ConditionFalse {
+			formatted := fmt.Sprintf("%s=%s", condition.Type, condition.Status)
+			if condition.Message != "" {
+				formatted += ": " + condition.Message
+			}
+			conditions = append(conditions, formatted)
+		}
+	}
+
+	nodeInList := NodeInList{
+		Name:                    node.Name,
+		Status:                  status,
+		Age:                     formatAge(time.Since(node.CreationTimestamp.Time)),
+		CreatedAt:               node.CreationTimestamp.Time,
+		Roles:                   nodeRoles(node.Labels),
+		Unschedulable:           node.Spec.Unschedulable,
+		KubeletVersion:          node.Status.NodeInfo.KubeletVersion,
+		OSImage:                 node.Status.NodeInfo.OSImage,
+		KernelVersion:           node.Status.NodeInfo.KernelVersion,
+		ContainerRuntimeVersion: node.Status.NodeInfo.ContainerRuntimeVersion,
+		Zone:                    utils.FirstNonEmpty(node.Labels[corev1.LabelTopologyZone], node.Labels[corev1.LabelFailureDomainBetaZone]),
+		Region:                  utils.FirstNonEmpty(node.Labels[corev1.LabelTopologyRegion], node.Labels[corev1.LabelFailureDomainBetaRegion]),
+		Capacity:                newNodeResources(node.Status.Capacity),
+		Allocatable:             newNodeResources(node.Status.Allocatable),
+		Conditions:              conditions,
+	}
+
+	for _, address := range node.Status.Addresses {
+		switch address.Type {
+		case corev1.NodeInternalIP:
+			nodeInList.InternalIPs = append(nodeInList.InternalIPs, address.Address)
+		case corev1.NodeExternalIP:
+			nodeInList.ExternalIPs = append(nodeInList.ExternalIPs, address.Address)
+		}
+	}
+
+	for _, taint := range node.Spec.Taints {
+		nodeInList.Taints = append(nodeInList.Taints, taint.ToString())
+	}
+
+	return nodeInList
+}
+
+// nodeRoles returns roles of the node from node-role.kubernetes.io/<role>
+// labels and from legacy kubernetes.io/role label
+func nodeRoles(labels map[string]string) []string {
+	roles := []string{}
+	for key, value := range labels {
+		if role, ok := strings.CutPrefix(key, nodeRoleLabelPrefix); ok && role != "" {
+			roles = append(roles, role)
+		} else if key == "kubernetes.io/role" && value != "" {
+			roles = append(roles, value)
+		}
+	}
+	if len(roles) == 0 {
+		return nil
+	}
+	sort.Strings(roles)
+	return slices.Compact(roles)
+}
+
+func newNodeResources(resources corev1.ResourceList) *NodeResources {
+	if len(resources) == 0 {
+		return nil
+	}
+	nodeResources := &NodeResources{}
+	if cpu, ok := resources[corev1.ResourceCPU]; ok {
+		nodeResources.CPU = cpu.String()
+	}
+	if memory, ok := resources[corev1.ResourceMemory]; ok {
+		nodeResources.Memory = memory.String()
+	}
+	if pods, ok := resources[corev1.ResourcePods]; ok {
+		nodeResources.Pods = pods.String()
+	}
+	return nodeResources
 }
 
 // formatAge converts a duration to a human-readable age string
@@ -0,0 +1,141 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/strowk/foxy-contexts/pkg/mcp"
+	"github.com/strowk/foxy-contexts/pkg/session"
+	"github.com/strowk/mcp-k8s-go/internal/k8s"
+	mock_k8s "github.com/strowk/mcp-k8s-go/internal/k8s/mock"
+	"go.uber.org/mock/gomock"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestListNodes(t *testing.T) {
+	created := time.Date(2024, 12, 1, 19, 0, 8, 0, time.UTC)
+	worker := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "worker",
+			CreationTimestamp: metav1.NewTime(created),
+			Labels: map[string]string{
+				"node-role.kubernetes.io/worker": "",
+				"node-role.kubernetes.io/gpu":    "true",
+				corev1.LabelTopologyZone:         "eu-west-1a",
+				corev1.LabelTopologyRegion:       "eu-west-1",
+			},
+		},
+		Spec: corev1.NodeSpec{
+			Unschedulable: true,
+			Taints: []corev1.Taint{
+				{Key: "nvidia.com/gpu", Value: "present", Effect: corev1.TaintEffectNoSchedule},
+				{Key: "node.kubernetes.io/unschedulable", Effect: corev1.TaintEffectNoSchedule},
+			},
+		},
+		Status: corev1.NodeStatus{
+			NodeInfo: corev1.NodeSystemInfo{
+				KubeletVersion:          "v1.31.2",
+				OSImage:                 "Ubuntu 22.04.4 LTS",
+				KernelVersion:           "6.5.0-1020-aws",
+				ContainerRuntimeVersion: "containerd://1.7.22",
+			},
+			Addresses: []corev1.NodeAddress{
+				{Type: corev1.NodeInternalIP, Address: "10.0.0.12"},
+				{Type: corev1.NodeExternalIP, Address: "52.1.2.3"},
+				{Type: corev1.NodeHostName, Address: "worker"},
+			},
+			Capacity: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("8"),
+				corev1.ResourceMemory: resource.MustParse("32Gi"),
+				corev1.ResourcePods:   resource.MustParse("110"),
+			},
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("7910m"),
+				corev1.ResourceMemory: resource.MustParse("31Gi"),
+				corev1.ResourcePods:   resource.MustParse("110"),
+			},
+			Conditions: []corev1.NodeCondition{
+				{Type: corev1.NodeReady, Status: corev1.ConditionTrue},
+				{Type: corev1.NodeMemoryPressure, Status: corev1.ConditionTrue, Message: "kubelet has insufficient memory available"},
+				{Type: corev1.NodeDiskPressure, Status: corev1.ConditionFalse},
+				{Type: corev1.NodePIDPressure, Status: corev1.ConditionUnknown},
+			},
+		},
+	}
+	controlPlane := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "control-plane",
+			Labels: map[string]string{"node-role.kubernetes.io/control-plane": "true", "kubernetes.io/role": "master"},
+		},
+		Status: corev1.NodeStatus{Conditions: []corev1.NodeCondition{
+			{Type: corev1.NodeReady, Status: corev1.ConditionFalse},
+		}},
+	}
+
+	call := func(t *testing.T, args map[string]any) []NodeInList {
+		cntr := gomock.NewController(t)
+		poolMock := mock_k8s.NewMockClientPool(cntr)
+		poolMock.EXPECT().GetClientset("context").Return(fake.NewClientset(worker, controlPlane), nil)
+
+		tool := NewListNodesTool(poolMock, k8s.NewDefaults(session.NewSessionManager()))
+		args["context"] = "context"
+		resp := tool.Callback(context.Background(), args)
+		require.NotNil(t, resp.IsError)
+		require.False(t, *resp.IsError, resp.Content)
+
+		nodes := []NodeInList{}
+		for _, content := range resp.Content {
+			var node NodeInList
+			require.NoError(t, json.Unmarshal([]byte(content.(mcp.TextContent).Text), &node))
+			nodes = append(nodes, node)
+		}
+		return nodes
+	}
+
+	t.Run("List all nodes with details", func(t *testing.T) {
+		nodes := call(t, map[string]any{})
+		require.Len(t, nodes, 2)
+
+		assert.Equal(t, "control-plane", nodes[0].Name)
+		assert.Equal(t, "NotReady", nodes[0].Status)
+		assert.Equal(t, []string{"control-plane", "master"}, nodes[0].Roles)
+		assert.Nil(t, nodes[0].Capacity)
+
+		nodes[1].Age = ""
+		assert.Equal(t, NodeInList{
+			Name:                    "worker",
+			Status:                  "Ready",
+			CreatedAt:               created,
+			Roles:                   []string{"gpu", "worker"},
+			Unschedulable:           true,
+			KubeletVersion:          "v1.31.2",
+			OSImage:                 "Ubuntu 22.04.4 LTS",
+			KernelVersion:           "6.5.0-1020-aws",
+			ContainerRuntimeVersion: "containerd://1.7.22",
+			InternalIPs:             []string{"10.0.0.12"},
+			ExternalIPs:             []string{"52.1.2.3"},
+			Zone:                    "eu-west-1a",
+			Region:                  "eu-west-1",
+			Capacity:                &NodeResources{CPU: "8", Memory: "32Gi", Pods: "110"},
+			Allocatable:             &NodeResources{CPU: "7910m", Memory: "31Gi", Pods: "110"},
+			Taints:                  []string{"nvidia.com/gpu=present:NoSchedule", "node.kubernetes.io/unschedulable:NoSchedule"},
+			Conditions: []string{
+				"MemoryPressure=True: kubelet has insufficient memory available",
+				"PIDPressure=Unknown",
+			},
+		}, nodes[1])
+	})
+
+	t.Run("List nodes matching label selector", func(t *testing.T) {
+		nodes := call(t, map[string]any{"labelSelector": "node-role.kubernetes.io/worker"})
+		require.Len(t, nodes, 1)
+		assert.Equal(t, "worker", nodes[0].Name)
+	})
+}
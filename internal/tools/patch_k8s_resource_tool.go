@@ -0,0 +1,205 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/strowk/foxy-contexts/pkg/fxctx"
+	"github.com/strowk/foxy-contexts/pkg/mcp"
+	"github.com/strowk/foxy-contexts/pkg/toolinput"
+	"github.com/strowk/mcp-k8s-go/internal/config"
+	"github.com/strowk/mcp-k8s-go/internal/content"
+	"github.com/strowk/mcp-k8s-go/internal/k8s"
+	"github.com/strowk/mcp-k8s-go/internal/utils"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/dynamic"
+)
+
+var patchTypes = map[string]types.PatchType{
+	"json":      types.JSONPatchType,
+	"merge":     types.MergePatchType,
+	"strategic": types.StrategicMergePatchType,
+}
+
+var patchSubresources = []string{"status", "scale"}
+
+type PatchResultContent struct {
+	Resource    string         `json:"resource"`
+	Namespace   string         `json:"namespace,omitempty"`
+	Subresource string         `json:"subresource,omitempty"`
+	DryRun      bool           `json:"dryRun"`
+	Changes     []utils.Change `json:"changes"`
+}
+
+func NewPatchK8sResourceTool(pool k8s.ClientPool, defaults k8s.Defaults) fxctx.Tool {
+	contextProperty := "context"
+	namespaceProperty := "namespace"
+	kindProperty := "kind"
+	groupProperty := "group"
+	versionProperty := "version"
+	nameProperty := "name"
+	patchProperty := "patch"
+	patchTypeProperty := "patchType"
+	subresourceProperty := "subresource"
+	dryRunProperty := "dryRun"
+
+	inputSchema := toolinput.NewToolInputSchema(
+		toolinput.WithString(contextProperty, "Name of the Kubernetes context to use, defaults to current context"),
+		toolinput.WithString(namespaceProperty, "Namespace of the resource to patch, defaults to namespace of the context, ignored for cluster resources"),
+		toolinput.WithString(groupProperty, "API Group of the resource to patch"),
+		toolinput.WithString(versionProperty, "API Version of the resource to patch"),
+		toolinput.WithRequiredString(kindProperty, "Kind of resource to patch"),
+		toolinput.WithRequiredString(nameProperty, "Name of the resource to patch"),
+		toolinput.WithRequiredString(patchProperty, "Patch document in JSON or YAML format"),
+		toolinput.WithString(patchTypeProperty, "Type of the patch: json (RFC 6902 JSON Patch), merge (RFC 7386 JSON Merge Patch) or strategic (strategic merge patch, only supported by built-in kinds), defaults to strategic"),
+		toolinput.WithString(subresourceProperty, "Subresource to patch: status or scale, defaults to the main resource"),
+		toolinput.WithBoolean(dryRunProperty, "Only run patch on the server side without persisting it, defaults to false"),
+	)
+
+	return fxctx.NewTool(
+		&mcp.Tool{
+			Name:        "patch-k8s-resource",
+			Description: utils.Ptr("Patch Kubernetes resource or its status or scale subresource using JSON Patch, JSON Merge Patch or strategic merge patch and return changes made by the patch"),
+			InputSchema: inputSchema.GetMcpToolInputSchema(),
+		},
+		func(ctx context.Context, args map[string]any) *mcp.CallToolResult {
+			input, err := inputSchema.Validate(args)
+			if err != nil {
+				return utils.ErrResponse(err)
+			}
+
+			k8sCtx := defaults.ResolveContext(ctx, input.StringOr(contextProperty, ""))
+
+			kind, err := input.String(kindProperty)
+			if err != nil {
+				return utils.ErrResponse(err)
+			}
+
+			name, err := input.String(nameProperty)
+			if err != nil {
+				return utils.ErrResponse(err)
+			}
+
+			patch, err := input.String(patchProperty)
+			if err != nil {
+				return utils.ErrResponse(err)
+			}
+
+			patchTypeName := input.StringOr(patchTypeProperty, "strategic")
+			patchType, ok := patchTypes[patchTypeName]
+			if !ok {
+				return utils.ErrResponse(fmt.Errorf("invalid patchType: %s, expected one of json, merge or strategic", patchTypeName))
+			}
+
+			var subresources []string
+			if subresource := input.StringOr(subresourceProperty, ""); subresource != "" {
+				if !isPatchSubresource(subresource) {
+					return utils.ErrResponse(fmt.Errorf("invalid subresource: %s, expected one of status or scale", subresource))
+				}
+				subresources = append(subresources, subresource)
+			}
+
+			// accept YAML as well, since it is often more convenient to write,
+			// JSON is passed through unchanged
+			patchData, err := yaml.ToJSON([]byte(patch))
+			if err != nil {
+				return utils.ErrResponse(fmt.Errorf("failed to parse patch: %w", err))
+			}
+
+			group := input.StringOr(groupProperty, "")
+			version := input.StringOr(versionProperty, "")
+			dryRun := input.BooleanOr(dryRunProperty, false)
+
+			mapping, err := pool.GetRESTMapping(k8sCtx, kind, group, version)
+			if err != nil {
+				return utils.ErrResponse(err)
+			}
+
+			dynamicClient, err := pool.GetDynamicClient(k8sCtx)
+			if err != nil {
+				return utils.ErrResponse(fmt.Errorf("failed to retrieve dynamic client from the client pool: %w", err))
+			}
+
+			namespace := ""
+			var dr dynamic.ResourceInterface
+			if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+				namespace, err = defaults.ResolveNamespace(ctx, k8sCtx, input.StringOr(namespaceProperty, ""))
+				if err != nil {
+					return utils.ErrResponse(err)
+				}
+				dr = dynamicClient.Resource(mapping.Resource).Namespace(namespace)
+			} else {
+				dr = dynamicClient.Resource(mapping.Resource)
+			}
+
+			before, err := dr.Get(ctx, name, metav1.GetOptions{}, subresources...)
+			if err != nil {
+				return utils.ErrResponse(err)
+			}
+
+			patchOptions := metav1.PatchOptions{}
+			if dryRun {
+				patchOptions.DryRun = []string{metav1.DryRunAll}
+			}
+
+			after, err := dr.Patch(ctx, name, patchType, patchData, patchOptions, subresources...)
+			if err != nil {
+				return utils.ErrResponse(fmt.Errorf("failed to patch %s: %w", name, err))
+			}
+
+			beforeContent, afterContent := patchDiffContent(before), patchDiffContent(after)
+			if config.GlobalOptions.MaskSecrets &&
+				mapping.GroupVersionKind.Group == "" && mapping.GroupVersionKind.Kind == "Secret" {
+				// values are masked on both sides, so only added and removed keys show up in changes
+				for _, object := range []map[string]any{beforeContent, afterContent} {
+					maskSecrets(object, "data")
+					maskSecrets(object, "stringData")
+					dropSensitiveAnnotationsForSecrets(object)
+				}
+			}
+
+			result := PatchResultContent{
+				Resource:  formatResourceText(mapping.Resource.Resource, mapping.Resource.Group, name),
+				Namespace: namespace,
+				DryRun:    dryRun,
+				Changes:   utils.Diff(beforeContent, afterContent),
+			}
+			if len(subresources) > 0 {
+				result.Subresource = subresources[0]
+			}
+
+			c, err := content.NewJsonContent(result)
+			if err != nil {
+				return utils.ErrResponse(err)
+			}
+
+			return &mcp.CallToolResult{
+				Meta:    namespaceMeta(namespace),
+				Content: []any{c},
+				IsError: utils.Ptr(false),
+			}
+		},
+	)
+}
+
+func isPatchSubresource(subresource string) bool {
+	for _, s := range patchSubresources {
+		if s == subresource {
+			return true
+		}
+	}
+	return false
+}
+
+// patchDiffContent returns content of the object without fields
+// that are changed by the server on every write and only add noise to diff
+func patchDiffContent(obj *unstructured.Unstructured) map[string]any {
+	obj = obj.DeepCopy()
+	obj.SetManagedFields(nil)
+	obj.SetResourceVersion("")
+	return obj.Object
+}
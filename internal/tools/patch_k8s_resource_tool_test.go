@@ -0,0 +1,120 @@
+package tools
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/strowk/foxy-contexts/pkg/mcp"
+	"github.com/strowk/foxy-contexts/pkg/session"
+	"github.com/strowk/mcp-k8s-go/internal/config"
+	"github.com/strowk/mcp-k8s-go/internal/k8s"
+	mock_k8s "github.com/strowk/mcp-k8s-go/internal/k8s/mock"
+	"go.uber.org/mock/gomock"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+func TestPatchK8sResource(t *testing.T) {
+	configMapMapping := &meta.RESTMapping{
+		Resource:         schema.GroupVersionResource{Version: "v1", Resource: "configmaps"},
+		GroupVersionKind: schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"},
+		Scope:            meta.RESTScopeNamespace,
+	}
+
+	setup := func(t *testing.T) (*mock_k8s.MockClientPool, *dynamicfake.FakeDynamicClient) {
+		cntr := gomock.NewController(t)
+		poolMock := mock_k8s.NewMockClientPool(cntr)
+		dynamicClient := dynamicfake.NewSimpleDynamicClient(scheme.Scheme, &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "config",
+				Namespace: "namespace",
+			},
+			Data: map[string]string{"key": "value", "removed": "value"},
+		})
+		return poolMock, dynamicClient
+	}
+
+	t.Run("return changes made by merge patch", func(t *testing.T) {
+		poolMock, dynamicClient := setup(t)
+		poolMock.EXPECT().GetRESTMapping("context", "ConfigMap", "", "").Return(configMapMapping, nil)
+		poolMock.EXPECT().GetDynamicClient("context").Return(dynamicClient, nil)
+
+		tool := NewPatchK8sResourceTool(poolMock, k8s.NewDefaults(session.NewSessionManager()))
+		resp := tool.Callback(context.Background(), map[string]any{
+			"context":   "context",
+			"namespace": "namespace",
+			"kind":      "ConfigMap",
+			"name":      "config",
+			"patchType": "merge",
+			"patch":     "data:\n  key: patched\n  removed: null\n",
+		})
+		require.NotNil(t, resp.IsError)
+		assert.False(t, *resp.IsError)
+		assert.Equal(t,
+			`{"resource":"configmaps/config","namespace":"namespace","dryRun":false,"changes":[`+
+				`{"path":"data.key","before":"value","after":"patched"},`+
+				`{"path":"data.removed","before":"value"}]}`,
+			resp.Content[0].(mcp.TextContent).Text,
+		)
+	})
+
+	t.Run("reject unknown subresource", func(t *testing.T) {
+		poolMock, _ := setup(t)
+
+		tool := NewPatchK8sResourceTool(poolMock, k8s.NewDefaults(session.NewSessionManager()))
+		resp := tool.Callback(context.Background(), map[string]any{
+			"context":     "context",
+			"kind":        "ConfigMap",
+			"name":        "config",
+			"patch":       "{}",
+			"subresource": "exec",
+		})
+		require.NotNil(t, resp.IsError)
+		assert.True(t, *resp.IsError)
+		assert.Equal(t, "invalid subresource: exec, expected one of status or scale", resp.Content[0].(mcp.TextContent).Text)
+	})
+	t.Run("mask values of secret in changes", func(t *testing.T) {
+		maskSecrets := config.GlobalOptions.MaskSecrets
+		config.GlobalOptions.MaskSecrets = true
+		t.Cleanup(func() { config.GlobalOptions.MaskSecrets = maskSecrets })
+
+		cntr := gomock.NewController(t)
+		poolMock := mock_k8s.NewMockClientPool(cntr)
+		dynamicClient := dynamicfake.NewSimpleDynamicClient(scheme.Scheme, &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "credentials", Namespace: "namespace"},
+			Data:       map[string][]byte{"password": []byte("old-password")},
+		})
+		poolMock.EXPECT().GetRESTMapping("context", "Secret", "", "").Return(&meta.RESTMapping{
+			Resource:         schema.GroupVersionResource{Version: "v1", Resource: "secrets"},
+			GroupVersionKind: schema.GroupVersionKind{Version: "v1", Kind: "Secret"},
+			Scope:            meta.RESTScopeNamespace,
+		}, nil)
+		poolMock.EXPECT().GetDynamicClient("context").Return(dynamicClient, nil)
+
+		tool := NewPatchK8sResourceTool(poolMock, k8s.NewDefaults(session.NewSessionManager()))
+		resp := tool.Callback(context.Background(), map[string]any{
+			"context":   "context",
+			"namespace": "namespace",
+			"kind":      "Secret",
+			"name":      "credentials",
+			"patchType": "merge",
+			"patch":     `{"data":{"password":"bmV3LXBhc3N3b3Jk","token":"dG9rZW4="}}`,
+		})
+		require.NotNil(t, resp.IsError)
+		assert.False(t, *resp.IsError)
+		text := resp.Content[0].(mcp.TextContent).Text
+		assert.Equal(t,
+			`{"resource":"secrets/credentials","namespace":"namespace","dryRun":false,"changes":[`+
+				`{"path":"data.token","after":"MASKED"}]}`,
+			text,
+		)
+		assert.NotContains(t, text, "bmV3LXBhc3N3b3Jk")
+		assert.NotContains(t, text, "b2xkLXBhc3N3b3Jk")
+	})
+}
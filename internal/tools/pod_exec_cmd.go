@@ -23,7 +23,7 @@ import (
 
 const timeout = 5 * time.Second
 
-func NewPodExecCommandTool(pool k8s.ClientPool) fxctx.Tool {
+func NewPodExecCommandTool(pool k8s.ClientPool, defaults k8s.Defaults) fxctx.Tool {
 	k8sNamespace := "namespace"
 	k8sPodName := "pod"
 	execCommand := "command"
@@ -31,7 +31,7 @@ func NewPodExecCommandTool(pool k8s.ClientPool) fxctx.Tool {
 	stdin := "stdin"
 	schema := toolinput.NewToolInputSchema(
 		toolinput.WithString(k8sContext, "Kubernetes context name, defaults to current context"),
-		toolinput.WithRequiredString(k8sNamespace, "Namespace where pod is located"),
+		toolinput.WithString(k8sNamespace, "Namespace where pod is located, defaults to namespace of the context"),
 		toolinput.WithRequiredString(k8sPodName, "Name of the pod to execute command in"),
 		toolinput.WithRequiredString(execCommand, "Command to be executed"),
 		toolinput.WithString(stdin, "Standard input to the command, defaults to empty string"),
@@ -47,10 +47,6 @@ func NewPodExecCommandTool(pool k8s.ClientPool) fxctx.Tool {
 			if err != nil {
 				return errResponse(err)
 			}
-			k8sNamespace, err := input.String(k8sNamespace)
-			if err != nil {
-				return errResponse(fmt.Errorf("invalid input namespace: %w", err))
-			}
 			k8sPodName, err := input.String(k8sPodName)
 			if err != nil {
 				return errResponse(fmt.Errorf("invalid input pod: %w", err))
@@ -59,9 +55,14 @@ func NewPodExecCommandTool(pool k8s.ClientPool) fxctx.Tool {
 			if err != nil {
 				return errResponse(fmt.Errorf("invalid input command: %w", err))
 			}
-			k8sContext := input.StringOr(k8sContext, "")
+			k8sContext := defaults.ResolveContext(ctx, input.StringOr(k8sContext, ""))
 			stdin := input.StringOr(stdin, "")
 
+			k8sNamespace, err := defaults.ResolveNamespace(ctx, k8sContext, input.StringOr(k8sNamespace, ""))
+			if err != nil {
+				return errResponse(fmt.Errorf("invalid input namespace: %w", err))
+			}
+
 			kubeconfig := k8s.GetKubeConfigForContext(k8sContext)
 			config, err := kubeconfig.ClientConfig()
 			if err != nil {
@@ -81,8 +82,8 @@ func NewPodExecCommandTool(pool k8s.ClientPool) fxctx.Tool {
 			contents = append(contents, content)
 
 			return &mcp.CallToolResult{
-				Meta:    map[string]interface{}{},
-				Content: contents,
+				Meta:    namespaceMeta(k8sNamespace),
+				Content: withNamespaceContent(contents, k8sNamespace),
 				IsError: utils.Ptr(false),
 			}
 		},
@@ -1,11 +1,17 @@
 package tools
 
 import (
+	"bufio"
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"strings"
 	"time"
 
 	"github.com/strowk/mcp-k8s-go/internal/k8s"
+	"github.com/strowk/mcp-k8s-go/internal/transport"
 	"github.com/strowk/mcp-k8s-go/internal/utils"
 
 	"github.com/strowk/foxy-contexts/pkg/fxctx"
@@ -15,18 +21,40 @@ import (
 	v1 "k8s.io/api/core/v1"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
 )
 
-func NewPodLogsTool(pool k8s.ClientPool) fxctx.Tool {
+// defaultContainerAnnotation is used by kubectl to choose container
+// in multi-container pods when container is not specified
+const defaultContainerAnnotation = "kubectl.kubernetes.io/default-container"
+
+const (
+	// requests are handled one at a time, so following is bounded
+	// to not block other requests for long, though the client can
+	// cancel following earlier and get no result
+	defaultFollowDuration = 10 * time.Second
+	maxFollowDuration     = 5 * time.Minute
+	// defaultFollowLimitBytes protects from streaming unbounded
+	// amount of logs when limitBytes is not set explicitly
+	defaultFollowLimitBytes = 1024 * 1024
+)
+
+func NewPodLogsTool(pool k8s.ClientPool, defaults k8s.Defaults) fxctx.Tool {
 	schema := toolinput.NewToolInputSchema(
-		toolinput.WithRequiredString("context", "Name of the Kubernetes context to use"),
-		toolinput.WithRequiredString("namespace", "Name of the namespace where the pod is located"),
+		toolinput.WithString("context", "Name of the Kubernetes context to use, defaults to current context"),
+		toolinput.WithString("namespace", "Name of the namespace where the pod is located, defaults to namespace of the context"),
 		toolinput.WithRequiredString("pod", "Name of the pod to get logs from"),
 		toolinput.WithString("sinceDuration", "Only return logs newer than a relative duration like 5s, 2m, or 3h. Only one of sinceTime or sinceDuration may be set."),
 		toolinput.WithString("sinceTime", "Only return logs after a specific date (RFC3339). Only one of sinceTime or sinceDuration may be set."),
 		toolinput.WithNumber("limitBytes", "Maximum bytes of logs to return. Defaults to no limit."),
+		toolinput.WithNumber("tailLines", "Number of lines from the end of the logs to return. Defaults to all lines."),
+		toolinput.WithBoolean("timestamps", "Prefix every line of logs with RFC3339 timestamp, defaults to false."),
 		toolinput.WithBoolean("previousContainer", "Return previous terminated container logs, defaults to false."),
-		toolinput.WithString("containerName", "Name of the container within the pod to get logs from (optional)"),
+		toolinput.WithString("containerName", "Name of the container within the pod to get logs from, defaults to the only container of the pod or the one from kubectl.kubernetes.io/default-container annotation"),
+		toolinput.WithBoolean("allContainers", "Return logs of all containers of the pod, including init and ephemeral containers, each as separate content, defaults to false."),
+		toolinput.WithBoolean("summarize", "Instead of raw logs return clusters of similar lines with templates, counts, first and last timestamps and an example line, defaults to false."),
+		toolinput.WithBoolean("follow", "Stream new logs until followDuration passes, limitBytes is reached or the request is cancelled and return collected logs at the end, every new line is sent as progress notification if the request has progress token, other requests wait until following stops, limitBytes defaults to 1MiB in this mode. Cannot be used with allContainers, defaults to false."),
+		toolinput.WithString("followDuration", "How long to follow logs, like 60s, defaults to 10s, at most 5m"),
 	)
 	return fxctx.NewTool(
 		&mcp.Tool{
@@ -40,14 +68,11 @@ func NewPodLogsTool(pool k8s.ClientPool) fxctx.Tool {
 				return errResponse(fmt.Errorf("invalid input: %w", err))
 			}
 
-			k8sCtx, err := input.String("context")
-			if err != nil {
-				return errResponse(fmt.Errorf("invalid input: %w", err))
-			}
+			k8sCtx := defaults.ResolveContext(ctx, input.StringOr("context", ""))
 
-			k8sNamespace, err := input.String("namespace")
+			k8sNamespace, err := defaults.ResolveNamespace(ctx, k8sCtx, input.StringOr("namespace", ""))
 			if err != nil {
-				return errResponse(fmt.Errorf("invalid input: %w", err))
+				return errResponse(err)
 			}
 
 			k8sPod, err := input.String("pod")
@@ -55,46 +80,39 @@ func NewPodLogsTool(pool k8s.ClientPool) fxctx.Tool {
 				return errResponse(fmt.Errorf("invalid input: %w", err))
 			}
 
-			var noLimit int64 = -1
-			limitBytesF64 := input.NumberOr("limitBytes", float64(noLimit))
-			limitBytes := int64(limitBytesF64)
-
-			sinceDurationStr := input.StringOr("sinceDuration", "")
-
-			sinceTimeStr := ""
-
-			sinceTimeStr = input.StringOr("sinceTime", "")
-
-			if sinceDurationStr != "" && sinceTimeStr != "" {
-				return errResponse(fmt.Errorf("only one of sinceDuration or sinceTime may be set"))
+			options, err := podLogOptionsFromInput(input)
+			if err != nil {
+				return errResponse(err)
 			}
 
-			previousContainer := input.BooleanOr("previousContainer", false)
+			summarize := input.BooleanOr("summarize", false)
+			if summarize {
+				// timestamps are needed to tell when lines of each cluster were seen
+				options.Timestamps = true
+			}
 
 			containerName := input.StringOr("containerName", "")
-			options := &v1.PodLogOptions{
-				Previous: previousContainer,
+			allContainers := input.BooleanOr("allContainers", false)
+			if containerName != "" && allContainers {
+				return errResponse(fmt.Errorf("only one of containerName or allContainers may be set"))
 			}
-			if limitBytes != noLimit {
-				options.LimitBytes = &limitBytes
-			}
-			if containerName != "" {
-				options.Container = containerName
-			}
-			if sinceDurationStr != "" {
-				sinceDuration, err := time.ParseDuration(sinceDurationStr)
-				if err != nil {
-					return errResponse(fmt.Errorf("invalid duration: %s, expected to be in Golang duration format as defined in standard time package", sinceDurationStr))
-				}
 
-				options.SinceSeconds = utils.Ptr(int64(sinceDuration.Seconds()))
-			} else if sinceTimeStr != "" {
-				sinceTime, err := time.Parse(time.RFC3339, sinceTimeStr)
-				if err != nil {
-					return errResponse(fmt.Errorf("invalid time: '%s', expected to be in RFC3339 format, for example 2024-12-01T19:00:08Z", sinceTimeStr))
+			follow := input.BooleanOr("follow", false)
+			followDuration := defaultFollowDuration
+			if follow {
+				if allContainers {
+					return errResponse(fmt.Errorf("follow cannot be used with allContainers"))
+				}
+				if followDurationStr := input.StringOr("followDuration", ""); followDurationStr != "" {
+					followDuration, err = time.ParseDuration(followDurationStr)
+					if err != nil || followDuration <= 0 || followDuration > maxFollowDuration {
+						return errResponse(fmt.Errorf("invalid followDuration: %s, expected positive Golang duration not longer than %s", followDurationStr, maxFollowDuration))
+					}
+				}
+				options.Follow = true
+				if options.LimitBytes == nil {
+					options.LimitBytes = utils.Ptr(int64(defaultFollowLimitBytes))
 				}
-
-				options.SinceTime = &metav1.Time{Time: sinceTime}
 			}
 
 			clientset, err := pool.GetClientset(k8sCtx)
@@ -102,32 +120,278 @@ func NewPodLogsTool(pool k8s.ClientPool) fxctx.Tool {
 				return errResponse(err)
 			}
 
-			podLogs := clientset.
-				CoreV1().
-				Pods(k8sNamespace).
-				GetLogs(k8sPod, options).
-				Do(ctx)
+			pod, err := clientset.CoreV1().Pods(k8sNamespace).Get(ctx, k8sPod, metav1.GetOptions{})
+			if err != nil {
+				return errResponse(err)
+			}
+
+			containers := getPodContainers(pod)
+
+			if allContainers {
+				var contents []interface{}
+				for _, container := range containers {
+					containerOptions := *options
+					containerOptions.Container = container.name
+					data, err := getPodLogs(ctx, clientset, pod, &containerOptions)
+					header := fmt.Sprintf("=== %s ===\n", container.String())
+					if err != nil {
+						// containers that have not started yet or were not restarted
+						// do not have logs, which should not hide logs of others
+						contents = append(contents, mcp.TextContent{
+							Type: "text",
+							Text: header + fmt.Sprintf("error: %s\n", err),
+						})
+						continue
+					}
 
-			err = podLogs.Error()
+					if summarize {
+						lines, err := parseLogLines(container.name, data)
+						if err != nil {
+							contents = append(contents, mcp.TextContent{
+								Type: "text",
+								Text: header + fmt.Sprintf("error: %s\n", err),
+							})
+							continue
+						}
+						summary := summarizeLogLines(lines)
+						summary.Container = container.String()
+						content, err := NewJsonContent(summary)
+						if err != nil {
+							return errResponse(err)
+						}
+						contents = append(contents, content)
+						continue
+					}
 
+					contents = append(contents, mcp.TextContent{
+						Type: "text",
+						Text: header + string(data),
+					})
+				}
+
+				return &mcp.CallToolResult{
+					Meta:    namespaceMeta(k8sNamespace),
+					Content: withNamespaceContent(contents, k8sNamespace),
+					IsError: utils.Ptr(false),
+				}
+			}
+
+			options.Container, err = selectPodContainer(pod, containers, containerName)
 			if err != nil {
 				return errResponse(err)
 			}
 
-			data, err := podLogs.Raw()
+			var data []byte
+			if follow {
+				data, err = followPodLogs(ctx, clientset, pod, options, followDuration)
+			} else {
+				data, err = getPodLogs(ctx, clientset, pod, options)
+			}
 			if err != nil {
 				return errResponse(err)
 			}
 
+			if summarize {
+				lines, err := parseLogLines(options.Container, data)
+				if err != nil {
+					return errResponse(err)
+				}
+				content, err := NewJsonContent(summarizeLogLines(lines))
+				if err != nil {
+					return errResponse(err)
+				}
+				return &mcp.CallToolResult{
+					Meta:    namespaceMeta(k8sNamespace),
+					Content: withNamespaceContent([]interface{}{content}, k8sNamespace),
+					IsError: utils.Ptr(false),
+				}
+			}
+
 			content := mcp.TextContent{
 				Type: "text",
 				Text: string(data),
 			}
 
 			return &mcp.CallToolResult{
-				Content: []interface{}{content},
+				Meta:    namespaceMeta(k8sNamespace),
+				Content: withNamespaceContent([]interface{}{content}, k8sNamespace),
 				IsError: utils.Ptr(false),
 			}
 		},
 	)
 }
+
+// podLogOptionsFromInput reads options common for all containers from tool input
+func podLogOptionsFromInput(input toolinput.ToolInput) (*v1.PodLogOptions, error) {
+	var noLimit int64 = -1
+	limitBytesF64 := input.NumberOr("limitBytes", float64(noLimit))
+	limitBytes := int64(limitBytesF64)
+
+	tailLines := int64(input.NumberOr("tailLines", float64(noLimit)))
+
+	sinceDurationStr := input.StringOr("sinceDuration", "")
+	sinceTimeStr := input.StringOr("sinceTime", "")
+
+	if sinceDurationStr != "" && sinceTimeStr != "" {
+		return nil, fmt.Errorf("only one of sinceDuration or sinceTime may be set")
+	}
+
+	options := &v1.PodLogOptions{
+		Previous:   input.BooleanOr("previousContainer", false),
+		Timestamps: input.BooleanOr("timestamps", false),
+	}
+	if limitBytes != noLimit {
+		options.LimitBytes = &limitBytes
+	}
+	if tailLines != noLimit {
+		if tailLines < 0 {
+			return nil, fmt.Errorf("invalid tailLines: %d, expected non-negative number", tailLines)
+		}
+		options.TailLines = &tailLines
+	}
+	if sinceDurationStr != "" {
+		sinceDuration, err := time.ParseDuration(sinceDurationStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid duration: %s, expected to be in Golang duration format as defined in standard time package", sinceDurationStr)
+		}
+
+		options.SinceSeconds = utils.Ptr(int64(sinceDuration.Seconds()))
+	} else if sinceTimeStr != "" {
+		sinceTime, err := time.Parse(time.RFC3339, sinceTimeStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid time: '%s', expected to be in RFC3339 format, for example 2024-12-01T19:00:08Z", sinceTimeStr)
+		}
+
+		options.SinceTime = &metav1.Time{Time: sinceTime}
+	}
+	return options, nil
+}
+
+func getPodLogs(ctx context.Context, clientset kubernetes.Interface, pod *v1.Pod, options *v1.PodLogOptions) ([]byte, error) {
+	podLogs := clientset.
+		CoreV1().
+		Pods(pod.Namespace).
+		GetLogs(pod.Name, options).
+		Do(ctx)
+
+	if err := podLogs.Error(); err != nil {
+		return nil, err
+	}
+
+	return podLogs.Raw()
+}
+
+// followPodLogs streams logs until the stream ends, duration passes,
+// options.LimitBytes are read or ctx is cancelled, in all these cases logs
+// collected so far are returned without error, every line is reported
+// as progress of the request as soon as it is read
+func followPodLogs(ctx context.Context, clientset kubernetes.Interface, pod *v1.Pod, options *v1.PodLogOptions, duration time.Duration) ([]byte, error) {
+	followCtx, cancel := context.WithTimeout(ctx, duration)
+	defer cancel()
+
+	stream, err := clientset.
+		CoreV1().
+		Pods(pod.Namespace).
+		GetLogs(pod.Name, options).
+		Stream(followCtx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = stream.Close()
+	}()
+
+	var reader io.Reader = stream
+	if options.LimitBytes != nil {
+		// server is expected to respect the limit, but it is
+		// enforced here as well to not depend on that
+		reader = io.LimitReader(stream, *options.LimitBytes)
+	}
+
+	var data bytes.Buffer
+	lines := bufio.NewReader(reader)
+	for {
+		line, err := lines.ReadBytes('\n')
+		data.Write(line)
+		if len(line) > 0 {
+			transport.ReportProgress(ctx, strings.TrimSuffix(string(line), "\n"))
+		}
+		if errors.Is(err, io.EOF) || followCtx.Err() != nil {
+			return data.Bytes(), nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// podContainer is a container of the pod labelled with its type
+type podContainer struct {
+	name string
+	// kind is empty for regular containers, "init" or "ephemeral" otherwise
+	kind string
+}
+
+func (c podContainer) String() string {
+	if c.kind == "" {
+		return fmt.Sprintf("container %s", c.name)
+	}
+	return fmt.Sprintf("%s container %s", c.kind, c.name)
+}
+
+// getPodContainers returns containers of the pod in the order they are started
+func getPodContainers(pod *v1.Pod) []podContainer {
+	var containers []podContainer
+	for _, container := range pod.Spec.InitContainers {
+		containers = append(containers, podContainer{name: container.Name, kind: "init"})
+	}
+	for _, container := range pod.Spec.Containers {
+		containers = append(containers, podContainer{name: container.Name})
+	}
+	for _, container := range pod.Spec.EphemeralContainers {
+		containers = append(containers, podContainer{name: container.Name, kind: "ephemeral"})
+	}
+	return containers
+}
+
+// selectPodContainer validates requested container name or chooses
+// default container when name is not given and the pod has several of them
+func selectPodContainer(pod *v1.Pod, containers []podContainer, containerName string) (string, error) {
+	if containerName != "" {
+		for _, container := range containers {
+			if container.name == containerName {
+				return containerName, nil
+			}
+		}
+		return "", fmt.Errorf(
+			"container %s is not found in pod %s, valid containers are: %s",
+			containerName, pod.Name, formatPodContainers(containers),
+		)
+	}
+
+	if len(pod.Spec.Containers) <= 1 {
+		// let the server choose the only container
+		return "", nil
+	}
+
+	if defaultContainer, ok := pod.Annotations[defaultContainerAnnotation]; ok {
+		return defaultContainer, nil
+	}
+
+	return "", fmt.Errorf(
+		"pod %s has multiple containers, set containerName or allContainers, valid containers are: %s",
+		pod.Name, formatPodContainers(containers),
+	)
+}
+
+func formatPodContainers(containers []podContainer) string {
+	var names []string
+	for _, container := range containers {
+		if container.kind == "" {
+			names = append(names, container.name)
+		} else {
+			names = append(names, fmt.Sprintf("%s (%s)", container.name, container.kind))
+		}
+	}
+	return strings.Join(names, ", ")
+}
@@ -5,6 +5,9 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/strowk/foxy-contexts/pkg/mcp"
+	"github.com/strowk/foxy-contexts/pkg/session"
+	"github.com/strowk/mcp-k8s-go/internal/k8s"
 	mock_k8s "github.com/strowk/mcp-k8s-go/internal/k8s/mock"
 	"github.com/strowk/mcp-k8s-go/internal/tests"
 	"go.uber.org/mock/gomock"
@@ -17,7 +20,7 @@ func TestPodLogs(t *testing.T) {
 	cntr := gomock.NewController(t)
 	poolMock := mock_k8s.NewMockClientPool(cntr)
 
-	tool := NewPodLogsTool(poolMock)
+	tool := NewPodLogsTool(poolMock, k8s.NewDefaults(session.NewSessionManager()))
 	t.Run("Call with invalid type of previousContainer", func(t *testing.T) {
 		args := map[string]any{
 			"context":           "context",
@@ -47,7 +50,8 @@ func TestPodLogs(t *testing.T) {
 			},
 		), nil)
 		resp := tool.Callback(context.Background(), args)
-		tests.AssertTextContentContainsInFirstString(t, "fake logs", resp.Content)
+		tests.AssertTextContentContainsInFirstString(t, "fake logs", resp.Content[:1])
+		assert.Equal(t, "served from namespace namespace", resp.Content[1].(mcp.TextContent).Text)
 	})
 
 	t.Run("Call with empty string for previousContainer", func(t *testing.T) {
@@ -66,6 +70,120 @@ func TestPodLogs(t *testing.T) {
 			},
 		), nil)
 		resp := tool.Callback(context.Background(), args)
-		tests.AssertTextContentContainsInFirstString(t, "fake logs", resp.Content)
+		tests.AssertTextContentContainsInFirstString(t, "fake logs", resp.Content[:1])
+		assert.Equal(t, "served from namespace namespace", resp.Content[1].(mcp.TextContent).Text)
+	})
+
+	multiContainerPod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pod",
+			Namespace: "namespace",
+		},
+		Spec: v1.PodSpec{
+			InitContainers:      []v1.Container{{Name: "init"}},
+			Containers:          []v1.Container{{Name: "app"}, {Name: "sidecar"}},
+			EphemeralContainers: []v1.EphemeralContainer{{EphemeralContainerCommon: v1.EphemeralContainerCommon{Name: "debugger"}}},
+		},
+	}
+
+	t.Run("Call with unknown containerName", func(t *testing.T) {
+		args := map[string]any{
+			"context":       "context",
+			"namespace":     "namespace",
+			"pod":           "pod",
+			"containerName": "unknown",
+		}
+		poolMock.EXPECT().GetClientset("context").Return(fake.NewClientset(multiContainerPod), nil)
+		resp := tool.Callback(context.Background(), args)
+		if assert.NotNil(t, resp.IsError) {
+			assert.True(t, *resp.IsError)
+		}
+		assert.Equal(t,
+			"container unknown is not found in pod pod, valid containers are: init (init), app, sidecar, debugger (ephemeral)",
+			resp.Content[0].(mcp.TextContent).Text,
+		)
+	})
+
+	t.Run("Call without containerName for multi-container pod", func(t *testing.T) {
+		args := map[string]any{
+			"context":   "context",
+			"namespace": "namespace",
+			"pod":       "pod",
+		}
+		poolMock.EXPECT().GetClientset("context").Return(fake.NewClientset(multiContainerPod), nil)
+		resp := tool.Callback(context.Background(), args)
+		if assert.NotNil(t, resp.IsError) {
+			assert.True(t, *resp.IsError)
+		}
+		assert.Contains(t, resp.Content[0].(mcp.TextContent).Text, "pod pod has multiple containers, set containerName or allContainers")
+	})
+
+	t.Run("Call with allContainers", func(t *testing.T) {
+		args := map[string]any{
+			"context":       "context",
+			"namespace":     "namespace",
+			"pod":           "pod",
+			"allContainers": true,
+			"tailLines":     10.0,
+		}
+		poolMock.EXPECT().GetClientset("context").Return(fake.NewClientset(multiContainerPod), nil)
+		resp := tool.Callback(context.Background(), args)
+		if assert.NotNil(t, resp.IsError) {
+			assert.False(t, *resp.IsError)
+		}
+		if assert.Len(t, resp.Content, 5) {
+			assert.Equal(t, "=== init container init ===\nfake logs", resp.Content[0].(mcp.TextContent).Text)
+			assert.Equal(t, "=== container app ===\nfake logs", resp.Content[1].(mcp.TextContent).Text)
+			assert.Equal(t, "=== container sidecar ===\nfake logs", resp.Content[2].(mcp.TextContent).Text)
+			assert.Equal(t, "=== ephemeral container debugger ===\nfake logs", resp.Content[3].(mcp.TextContent).Text)
+			assert.Equal(t, "served from namespace namespace", resp.Content[4].(mcp.TextContent).Text)
+		}
+	})
+
+	t.Run("Call with follow", func(t *testing.T) {
+		args := map[string]any{
+			"context":        "context",
+			"namespace":      "namespace",
+			"pod":            "pod",
+			"containerName":  "app",
+			"follow":         true,
+			"followDuration": "5s",
+		}
+		poolMock.EXPECT().GetClientset("context").Return(fake.NewClientset(multiContainerPod), nil)
+		resp := tool.Callback(context.Background(), args)
+		if assert.NotNil(t, resp.IsError) {
+			assert.False(t, *resp.IsError)
+		}
+		assert.Equal(t, "fake logs", resp.Content[0].(mcp.TextContent).Text)
+	})
+
+	t.Run("Call with follow and allContainers", func(t *testing.T) {
+		args := map[string]any{
+			"context":       "context",
+			"namespace":     "namespace",
+			"pod":           "pod",
+			"follow":        true,
+			"allContainers": true,
+		}
+		resp := tool.Callback(context.Background(), args)
+		if assert.NotNil(t, resp.IsError) {
+			assert.True(t, *resp.IsError)
+		}
+		assert.Equal(t, "follow cannot be used with allContainers", resp.Content[0].(mcp.TextContent).Text)
+	})
+
+	t.Run("Call with too long followDuration", func(t *testing.T) {
+		args := map[string]any{
+			"context":        "context",
+			"namespace":      "namespace",
+			"pod":            "pod",
+			"follow":         true,
+			"followDuration": "10m",
+		}
+		resp := tool.Callback(context.Background(), args)
+		if assert.NotNil(t, resp.IsError) {
+			assert.True(t, *resp.IsError)
+		}
+		assert.Contains(t, resp.Content[0].(mcp.TextContent).Text, "invalid followDuration: 10m")
 	})
 }
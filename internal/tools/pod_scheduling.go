@@ -0,0 +1,67 @@
+package tools
+
+import (
+	"context"
+
+	"github.com/strowk/foxy-contexts/pkg/fxctx"
+	"github.com/strowk/foxy-contexts/pkg/mcp"
+	"github.com/strowk/foxy-contexts/pkg/toolinput"
+	"github.com/strowk/mcp-k8s-go/internal/content"
+	"github.com/strowk/mcp-k8s-go/internal/k8s"
+	"github.com/strowk/mcp-k8s-go/internal/k8s/core/v1/pod"
+	"github.com/strowk/mcp-k8s-go/internal/utils"
+)
+
+func NewExplainPodSchedulingTool(pool k8s.ClientPool, defaults k8s.Defaults) fxctx.Tool {
+	inputSchema := toolinput.NewToolInputSchema(
+		toolinput.WithString("context", "Name of the Kubernetes context to use, defaults to current context"),
+		toolinput.WithString("namespace", "Namespace of the pod, defaults to namespace of the context"),
+		toolinput.WithRequiredString("pod", "Name of the pod, usually a Pending one, to explain scheduling of"),
+	)
+
+	return fxctx.NewTool(
+		&mcp.Tool{
+			Name:        "explain-k8s-pod-scheduling",
+			Description: utils.Ptr("Explain why Kubernetes pod cannot be scheduled by evaluating every node against its nodeSelector, affinity and anti-affinity, taints and tolerations, topology spread constraints, resource requests and volumes, returns reasons of rejection per node"),
+			InputSchema: inputSchema.GetMcpToolInputSchema(),
+		},
+		func(ctx context.Context, args map[string]any) *mcp.CallToolResult {
+			input, err := inputSchema.Validate(args)
+			if err != nil {
+				return utils.ErrResponse(err)
+			}
+
+			k8sCtx := defaults.ResolveContext(ctx, input.StringOr("context", ""))
+
+			namespace, err := defaults.ResolveNamespace(ctx, k8sCtx, input.StringOr("namespace", ""))
+			if err != nil {
+				return utils.ErrResponse(err)
+			}
+
+			name, err := input.String("pod")
+			if err != nil {
+				return utils.ErrResponse(err)
+			}
+
+			clientset, err := pool.GetClientset(k8sCtx)
+			if err != nil {
+				return utils.ErrResponse(err)
+			}
+
+			explanation, err := pod.ExplainScheduling(ctx, clientset, namespace, name)
+			if err != nil {
+				return utils.ErrResponse(err)
+			}
+
+			c, err := content.NewJsonContent(explanation)
+			if err != nil {
+				return utils.ErrResponse(err)
+			}
+			return &mcp.CallToolResult{
+				Meta:    namespaceMeta(namespace),
+				Content: []any{c},
+				IsError: utils.Ptr(false),
+			}
+		},
+	)
+}
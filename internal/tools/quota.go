@@ -0,0 +1,80 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/strowk/foxy-contexts/pkg/fxctx"
+	"github.com/strowk/foxy-contexts/pkg/mcp"
+	"github.com/strowk/foxy-contexts/pkg/toolinput"
+	"github.com/strowk/mcp-k8s-go/internal/content"
+	"github.com/strowk/mcp-k8s-go/internal/k8s"
+	"github.com/strowk/mcp-k8s-go/internal/k8s/core/v1/quota"
+	"github.com/strowk/mcp-k8s-go/internal/utils"
+)
+
+func NewAnalyzeQuotaTool(pool k8s.ClientPool, defaults k8s.Defaults) fxctx.Tool {
+	inputSchema := toolinput.NewToolInputSchema(
+		toolinput.WithString("context", "Name of the Kubernetes context to use, defaults to current context"),
+		toolinput.WithString("namespace", "Namespace to analyze, defaults to namespace of the context"),
+		toolinput.WithString("manifest", "Manifest of Pod, Deployment, ReplicaSet, StatefulSet, Job or CronJob in YAML or JSON format to check whether its pods would fit, replicas of existing workloads are only counted above current replicas, while changed template of existing Deployment or StatefulSet adds rolling update surge pods and increase of usage of replaced pods"),
+		toolinput.WithString("kind", "Kind of existing workload to check whether scaling it would fit: Deployment, ReplicaSet or StatefulSet, must be set together with name and replicas"),
+		toolinput.WithString("name", "Name of existing workload to scale"),
+		toolinput.WithNumber("replicas", "Number of replicas to scale workload to"),
+	)
+
+	return fxctx.NewTool(
+		&mcp.Tool{
+			Name:        "analyze-k8s-quota",
+			Description: utils.Ptr("Show used versus hard values of every ResourceQuota and LimitRanges in Kubernetes namespace, project whether pods of a manifest or scaled workload would fit into quotas and explain which LimitRange defaults would be injected into their containers"),
+			InputSchema: inputSchema.GetMcpToolInputSchema(),
+		},
+		func(ctx context.Context, args map[string]any) *mcp.CallToolResult {
+			input, err := inputSchema.Validate(args)
+			if err != nil {
+				return utils.ErrResponse(err)
+			}
+
+			k8sCtx := defaults.ResolveContext(ctx, input.StringOr("context", ""))
+			namespace, err := defaults.ResolveNamespace(ctx, k8sCtx, input.StringOr("namespace", ""))
+			if err != nil {
+				return utils.ErrResponse(err)
+			}
+
+			options := quota.AnalysisOptions{
+				Namespace: namespace,
+				Manifest:  input.StringOr("manifest", ""),
+				Kind:      input.StringOr("kind", ""),
+				Name:      input.StringOr("name", ""),
+			}
+			if options.Kind != "" {
+				replicas := input.NumberOr("replicas", -1)
+				if replicas < 0 || replicas > math.MaxInt32 || replicas != math.Trunc(replicas) {
+					return utils.ErrResponse(fmt.Errorf("replicas must be set to non-negative integer together with kind"))
+				}
+				options.Replicas = int32(replicas)
+			}
+
+			clientset, err := pool.GetClientset(k8sCtx)
+			if err != nil {
+				return utils.ErrResponse(err)
+			}
+
+			analysis, err := quota.Analyze(ctx, clientset, options)
+			if err != nil {
+				return utils.ErrResponse(err)
+			}
+
+			c, err := content.NewJsonContent(analysis)
+			if err != nil {
+				return utils.ErrResponse(err)
+			}
+			return &mcp.CallToolResult{
+				Meta:    namespaceMeta(namespace),
+				Content: []any{c},
+				IsError: utils.Ptr(false),
+			}
+		},
+	)
+}
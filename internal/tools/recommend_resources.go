@@ -0,0 +1,94 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/strowk/foxy-contexts/pkg/fxctx"
+	"github.com/strowk/foxy-contexts/pkg/mcp"
+	"github.com/strowk/foxy-contexts/pkg/toolinput"
+	"github.com/strowk/mcp-k8s-go/internal/config"
+	"github.com/strowk/mcp-k8s-go/internal/content"
+	"github.com/strowk/mcp-k8s-go/internal/k8s"
+	"github.com/strowk/mcp-k8s-go/internal/k8s/metrics"
+	"github.com/strowk/mcp-k8s-go/internal/utils"
+)
+
+func NewRecommendResourcesTool(pool k8s.ClientPool, defaults k8s.Defaults, sampler *metrics.Sampler) fxctx.Tool {
+	inputSchema := toolinput.NewToolInputSchema(
+		toolinput.WithString("context", "Name of the Kubernetes context to use, defaults to current context"),
+		toolinput.WithString("namespace", "Namespace of the workload, defaults to namespace of the context"),
+		toolinput.WithRequiredString("kind", "Kind of the workload: Deployment, StatefulSet or DaemonSet"),
+		toolinput.WithRequiredString("name", "Name of the workload"),
+		toolinput.WithNumber("cpuPercentile", fmt.Sprintf("Percentile of CPU usage samples recommended as CPU request, defaults to %d", int(metrics.DefaultCPUPercentile*100))),
+		toolinput.WithNumber("headroom", fmt.Sprintf("Percent added on top of maximum usage for memory request and limits, defaults to %d", int(metrics.DefaultHeadroom*100))),
+	)
+
+	return fxctx.NewTool(
+		&mcp.Tool{
+			Name: "recommend-k8s-resources",
+			Description: utils.Ptr(fmt.Sprintf(
+				"Recommend requests and limits for containers of Kubernetes workload from CPU and memory usage sampled from metrics API, "+
+					"flag over-provisioned and under-provisioned containers and return strategic merge patch applying recommendations, "+
+					"usage of the namespace is sampled in memory of the server every %s while it is asked about at least once per %s "+
+					"and samples are kept for %s, requires metrics-server",
+				sampler.Interval(), sampler.IdleTimeout(), sampler.Window(),
+			)),
+			InputSchema: inputSchema.GetMcpToolInputSchema(),
+		},
+		func(ctx context.Context, args map[string]any) *mcp.CallToolResult {
+			input, err := inputSchema.Validate(args)
+			if err != nil {
+				return utils.ErrResponse(err)
+			}
+
+			k8sCtx := defaults.ResolveContext(ctx, input.StringOr("context", ""))
+			namespace, err := defaults.ResolveNamespace(ctx, k8sCtx, input.StringOr("namespace", ""))
+			if err != nil {
+				return utils.ErrResponse(err)
+			}
+			kind, err := input.String("kind")
+			if err != nil {
+				return utils.ErrResponse(err)
+			}
+			name, err := input.String("name")
+			if err != nil {
+				return utils.ErrResponse(err)
+			}
+
+			metricsClient, err := pool.GetMetricsClientset(k8sCtx)
+			if err != nil {
+				return utils.ErrResponse(err)
+			}
+			clientset, err := pool.GetClientset(k8sCtx)
+			if err != nil {
+				return utils.ErrResponse(err)
+			}
+
+			recommendation, err := metrics.Recommend(ctx, metricsClient, clientset, sampler, k8sCtx, metrics.RecommendOptions{
+				Kind:          kind,
+				Namespace:     namespace,
+				Name:          name,
+				CPUPercentile: input.NumberOr("cpuPercentile", metrics.DefaultCPUPercentile*100) / 100,
+				Headroom:      input.NumberOr("headroom", metrics.DefaultHeadroom*100) / 100,
+			})
+			if err != nil {
+				return utils.ErrResponse(err)
+			}
+			// keep sampling the namespace, so that next recommendations are based on longer window
+			if config.GlobalOptions.SampleMetrics {
+				sampler.Start(k8sCtx, namespace)
+			}
+
+			c, err := content.NewJsonContent(recommendation)
+			if err != nil {
+				return utils.ErrResponse(err)
+			}
+			return &mcp.CallToolResult{
+				Meta:    namespaceMeta(namespace),
+				Content: []any{c},
+				IsError: utils.Ptr(false),
+			}
+		},
+	)
+}
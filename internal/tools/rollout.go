@@ -0,0 +1,302 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/strowk/foxy-contexts/pkg/fxctx"
+	"github.com/strowk/foxy-contexts/pkg/mcp"
+	"github.com/strowk/foxy-contexts/pkg/toolinput"
+	"github.com/strowk/mcp-k8s-go/internal/content"
+	"github.com/strowk/mcp-k8s-go/internal/k8s"
+	"github.com/strowk/mcp-k8s-go/internal/k8s/apps/v1/rollout"
+	"github.com/strowk/mcp-k8s-go/internal/utils"
+	"k8s.io/client-go/kubernetes"
+)
+
+// maxRolloutTimeoutSeconds limits how long rollout status is waited for,
+// as the server does not handle other requests meanwhile
+const maxRolloutTimeoutSeconds = 300
+
+// rolloutTarget is workload resolved from common input of rollout tools
+type rolloutTarget struct {
+	clientset kubernetes.Interface
+	kind      rollout.Kind
+	namespace string
+	name      string
+}
+
+func rolloutInputOptions() []toolinput.ToolInputSchemaOption {
+	return []toolinput.ToolInputSchemaOption{
+		toolinput.WithString("context", "Name of the Kubernetes context to use, defaults to current context"),
+		toolinput.WithString("namespace", "Namespace of the workload, defaults to namespace of the context"),
+		toolinput.WithRequiredString("kind", "Kind of the workload: Deployment, StatefulSet or DaemonSet"),
+		toolinput.WithRequiredString("name", "Name of the workload"),
+	}
+}
+
+func resolveRolloutTarget(
+	ctx context.Context,
+	pool k8s.ClientPool,
+	defaults k8s.Defaults,
+	input toolinput.ToolInput,
+) (*rolloutTarget, error) {
+	k8sCtx := defaults.ResolveContext(ctx, input.StringOr("context", ""))
+
+	kindName, err := input.String("kind")
+	if err != nil {
+		return nil, err
+	}
+	kind, err := rollout.ParseKind(kindName)
+	if err != nil {
+		return nil, err
+	}
+
+	name, err := input.String("name")
+	if err != nil {
+		return nil, err
+	}
+
+	namespace, err := defaults.ResolveNamespace(ctx, k8sCtx, input.StringOr("namespace", ""))
+	if err != nil {
+		return nil, err
+	}
+
+	clientset, err := pool.GetClientset(k8sCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rolloutTarget{
+		clientset: clientset,
+		kind:      kind,
+		namespace: namespace,
+		name:      name,
+	}, nil
+}
+
+func NewGetRolloutStatusTool(pool k8s.ClientPool, defaults k8s.Defaults) fxctx.Tool {
+	inputSchema := toolinput.NewToolInputSchema(append(
+		rolloutInputOptions(),
+		toolinput.WithNumber("timeoutSeconds", fmt.Sprintf("Wait up to this number of seconds for rollout to finish, defaults to 0, which returns current status immediately, at most %d", maxRolloutTimeoutSeconds)),
+	)...)
+
+	return fxctx.NewTool(
+		&mcp.Tool{
+			Name:        "get-k8s-rollout-status",
+			Description: utils.Ptr("Get status of rollout of Deployment, StatefulSet or DaemonSet, optionally waiting for it to finish"),
+			InputSchema: inputSchema.GetMcpToolInputSchema(),
+		},
+		func(ctx context.Context, args map[string]any) *mcp.CallToolResult {
+			input, err := inputSchema.Validate(args)
+			if err != nil {
+				return utils.ErrResponse(err)
+			}
+
+			timeoutSeconds := input.NumberOr("timeoutSeconds", 0)
+			if timeoutSeconds < 0 || timeoutSeconds > maxRolloutTimeoutSeconds {
+				return utils.ErrResponse(fmt.Errorf("invalid timeoutSeconds: %v, expected non-negative number not greater than %d", timeoutSeconds, maxRolloutTimeoutSeconds))
+			}
+
+			target, err := resolveRolloutTarget(ctx, pool, defaults, input)
+			if err != nil {
+				return utils.ErrResponse(err)
+			}
+
+			var status *rollout.Status
+			if timeoutSeconds > 0 {
+				timeout := time.Duration(timeoutSeconds * float64(time.Second))
+				status, err = rollout.WaitForStatus(ctx, target.clientset, target.kind, target.namespace, target.name, timeout)
+			} else {
+				status, err = rollout.GetStatus(ctx, target.clientset, target.kind, target.namespace, target.name)
+			}
+			if err != nil {
+				return utils.ErrResponse(err)
+			}
+
+			c, err := content.NewJsonContent(status)
+			if err != nil {
+				return utils.ErrResponse(err)
+			}
+			return &mcp.CallToolResult{
+				Meta:    namespaceMeta(target.namespace),
+				Content: []any{c},
+				IsError: utils.Ptr(false),
+			}
+		},
+	)
+}
+
+func NewGetRolloutHistoryTool(pool k8s.ClientPool, defaults k8s.Defaults) fxctx.Tool {
+	inputSchema := toolinput.NewToolInputSchema(append(
+		rolloutInputOptions(),
+		toolinput.WithNumber("revision", "Only return this revision, defaults to all revisions"),
+	)...)
+
+	return fxctx.NewTool(
+		&mcp.Tool{
+			Name:        "get-k8s-rollout-history",
+			Description: utils.Ptr("Get revision history of Deployment, StatefulSet or DaemonSet with container images and image changes between revisions"),
+			InputSchema: inputSchema.GetMcpToolInputSchema(),
+		},
+		func(ctx context.Context, args map[string]any) *mcp.CallToolResult {
+			input, err := inputSchema.Validate(args)
+			if err != nil {
+				return utils.ErrResponse(err)
+			}
+
+			target, err := resolveRolloutTarget(ctx, pool, defaults, input)
+			if err != nil {
+				return utils.ErrResponse(err)
+			}
+
+			history, err := rollout.GetHistory(ctx, target.clientset, target.kind, target.namespace, target.name)
+			if err != nil {
+				return utils.ErrResponse(err)
+			}
+
+			var contents []any
+			revision := int64(input.NumberOr("revision", 0))
+			for _, entry := range history {
+				if revision != 0 && entry.Revision != revision {
+					continue
+				}
+				c, err := content.NewJsonContent(entry)
+				if err != nil {
+					return utils.ErrResponse(err)
+				}
+				contents = append(contents, c)
+			}
+			if revision != 0 && len(contents) == 0 {
+				return utils.ErrResponse(fmt.Errorf("unable to find revision %d in rollout history", revision))
+			}
+
+			return &mcp.CallToolResult{
+				Meta:    namespaceMeta(target.namespace),
+				Content: contents,
+				IsError: utils.Ptr(false),
+			}
+		},
+	)
+}
+
+func NewRolloutK8sResourceTool(pool k8s.ClientPool, defaults k8s.Defaults) fxctx.Tool {
+	inputSchema := toolinput.NewToolInputSchema(append(
+		rolloutInputOptions(),
+		toolinput.WithRequiredString("action", "Rollout action: restart, undo, pause or resume, pause and resume are only supported for Deployments"),
+		toolinput.WithNumber("toRevision", "Revision to roll back to with undo action, defaults to the previous revision"),
+		toolinput.WithBoolean("dryRun", "Only run changes on the server side without persisting them, defaults to false"),
+	)...)
+
+	return fxctx.NewTool(
+		&mcp.Tool{
+			Name:        "rollout-k8s-resource",
+			Description: utils.Ptr("Restart, undo, pause or resume rollout of Deployment, StatefulSet or DaemonSet"),
+			InputSchema: inputSchema.GetMcpToolInputSchema(),
+		},
+		func(ctx context.Context, args map[string]any) *mcp.CallToolResult {
+			input, err := inputSchema.Validate(args)
+			if err != nil {
+				return utils.ErrResponse(err)
+			}
+
+			action, err := input.String("action")
+			if err != nil {
+				return utils.ErrResponse(err)
+			}
+
+			target, err := resolveRolloutTarget(ctx, pool, defaults, input)
+			if err != nil {
+				return utils.ErrResponse(err)
+			}
+
+			dryRun := input.BooleanOr("dryRun", false)
+
+			var result *rollout.Result
+			switch action {
+			case "restart":
+				result, err = rollout.Restart(ctx, target.clientset, target.kind, target.namespace, target.name, dryRun)
+			case "undo":
+				toRevision := input.NumberOr("toRevision", 0)
+				if toRevision < 0 {
+					return utils.ErrResponse(fmt.Errorf("invalid toRevision: %v, expected positive number", toRevision))
+				}
+				result, err = rollout.Undo(ctx, target.clientset, target.kind, target.namespace, target.name, int64(toRevision), dryRun)
+			case "pause":
+				result, err = rollout.Pause(ctx, target.clientset, target.kind, target.namespace, target.name, dryRun)
+			case "resume":
+				result, err = rollout.Resume(ctx, target.clientset, target.kind, target.namespace, target.name, dryRun)
+			default:
+				return utils.ErrResponse(fmt.Errorf("invalid action: %s, expected one of restart, undo, pause or resume", action))
+			}
+			if err != nil {
+				return utils.ErrResponse(err)
+			}
+
+			c, err := content.NewJsonContent(result)
+			if err != nil {
+				return utils.ErrResponse(err)
+			}
+			return &mcp.CallToolResult{
+				Meta:    namespaceMeta(target.namespace),
+				Content: []any{c},
+				IsError: utils.Ptr(false),
+			}
+		},
+	)
+}
+
+func NewDiagnoseDeploymentRolloutTool(pool k8s.ClientPool, defaults k8s.Defaults) fxctx.Tool {
+	inputSchema := toolinput.NewToolInputSchema(
+		toolinput.WithString("context", "Name of the Kubernetes context to use, defaults to current context"),
+		toolinput.WithString("namespace", "Namespace of the Deployment, defaults to namespace of the context"),
+		toolinput.WithRequiredString("name", "Name of the Deployment"),
+	)
+
+	return fxctx.NewTool(
+		&mcp.Tool{
+			Name:        "diagnose-k8s-deployment-rollout",
+			Description: utils.Ptr("Explain why rollout of Deployment is stuck by comparing new and old ReplicaSets, surfacing ProgressDeadlineExceeded, diagnosing unready new pods, diffing pod templates between revisions and checking maxSurge and maxUnavailable against ResourceQuotas"),
+			InputSchema: inputSchema.GetMcpToolInputSchema(),
+		},
+		func(ctx context.Context, args map[string]any) *mcp.CallToolResult {
+			input, err := inputSchema.Validate(args)
+			if err != nil {
+				return utils.ErrResponse(err)
+			}
+
+			k8sCtx := defaults.ResolveContext(ctx, input.StringOr("context", ""))
+
+			namespace, err := defaults.ResolveNamespace(ctx, k8sCtx, input.StringOr("namespace", ""))
+			if err != nil {
+				return utils.ErrResponse(err)
+			}
+
+			name, err := input.String("name")
+			if err != nil {
+				return utils.ErrResponse(err)
+			}
+
+			clientset, err := pool.GetClientset(k8sCtx)
+			if err != nil {
+				return utils.ErrResponse(err)
+			}
+
+			diagnosis, err := rollout.DiagnoseDeployment(ctx, clientset, namespace, name)
+			if err != nil {
+				return utils.ErrResponse(err)
+			}
+
+			c, err := content.NewJsonContent(diagnosis)
+			if err != nil {
+				return utils.ErrResponse(err)
+			}
+			return &mcp.CallToolResult{
+				Meta:    namespaceMeta(namespace),
+				Content: []any{c},
+				IsError: utils.Ptr(false),
+			}
+		},
+	)
+}
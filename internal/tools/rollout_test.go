@@ -0,0 +1,32 @@
+package tools
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/strowk/foxy-contexts/pkg/mcp"
+	"github.com/strowk/foxy-contexts/pkg/session"
+	"github.com/strowk/mcp-k8s-go/internal/k8s"
+	mock_k8s "github.com/strowk/mcp-k8s-go/internal/k8s/mock"
+	"go.uber.org/mock/gomock"
+)
+
+func TestGetRolloutStatus(t *testing.T) {
+	poolMock := mock_k8s.NewMockClientPool(gomock.NewController(t))
+	tool := NewGetRolloutStatusTool(poolMock, k8s.NewDefaults(session.NewSessionManager()))
+
+	for _, timeoutSeconds := range []float64{-1, 301} {
+		resp := tool.Callback(context.Background(), map[string]any{
+			"context":        "context",
+			"namespace":      "namespace",
+			"kind":           "Deployment",
+			"name":           "web",
+			"timeoutSeconds": timeoutSeconds,
+		})
+		if assert.NotNil(t, resp.IsError) {
+			assert.True(t, *resp.IsError)
+		}
+		assert.Contains(t, resp.Content[0].(mcp.TextContent).Text, "expected non-negative number not greater than 300")
+	}
+}
@@ -0,0 +1,258 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/strowk/foxy-contexts/pkg/fxctx"
+	"github.com/strowk/foxy-contexts/pkg/mcp"
+	"github.com/strowk/foxy-contexts/pkg/toolinput"
+	"github.com/strowk/mcp-k8s-go/internal/content"
+	"github.com/strowk/mcp-k8s-go/internal/k8s"
+	"github.com/strowk/mcp-k8s-go/internal/k8s/core/v1/pod"
+	"github.com/strowk/mcp-k8s-go/internal/utils"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+)
+
+// scalePollInterval is how often pods are checked while waiting for them to be ready
+var scalePollInterval = 2 * time.Second
+
+// maxScaleWaitTimeoutSeconds limits how long replicas are waited for,
+// as the server does not handle other requests meanwhile
+const maxScaleWaitTimeoutSeconds = 300
+
+type ScaleResultContent struct {
+	Resource         string `json:"resource"`
+	Namespace        string `json:"namespace,omitempty"`
+	PreviousReplicas int64  `json:"previousReplicas"`
+	Replicas         int64  `json:"replicas"`
+	ReadyReplicas    *int64 `json:"readyReplicas,omitempty"`
+	Message          string `json:"message"`
+}
+
+func NewScaleK8sResourceTool(pool k8s.ClientPool, defaults k8s.Defaults) fxctx.Tool {
+	contextProperty := "context"
+	namespaceProperty := "namespace"
+	kindProperty := "kind"
+	groupProperty := "group"
+	versionProperty := "version"
+	nameProperty := "name"
+	replicasProperty := "replicas"
+	currentReplicasProperty := "currentReplicas"
+	waitTimeoutSecondsProperty := "waitTimeoutSeconds"
+
+	inputSchema := toolinput.NewToolInputSchema(
+		toolinput.WithString(contextProperty, "Name of the Kubernetes context to use, defaults to current context"),
+		toolinput.WithString(namespaceProperty, "Namespace of the resource to scale, defaults to namespace of the context"),
+		toolinput.WithString(groupProperty, "API Group of the resource to scale"),
+		toolinput.WithString(versionProperty, "API Version of the resource to scale"),
+		toolinput.WithRequiredString(kindProperty, "Kind of resource to scale, must support scale subresource, for example Deployment, StatefulSet or ReplicaSet"),
+		toolinput.WithRequiredString(nameProperty, "Name of the resource to scale"),
+		toolinput.WithRequiredNumber(replicasProperty, "Desired number of replicas"),
+		toolinput.WithNumber(currentReplicasProperty, "Only scale if current number of replicas matches this value"),
+		toolinput.WithNumber(waitTimeoutSecondsProperty, fmt.Sprintf("Wait up to this number of seconds until desired number of replicas is ready, defaults to 0, which does not wait, at most %d", maxScaleWaitTimeoutSeconds)),
+	)
+
+	return fxctx.NewTool(
+		&mcp.Tool{
+			Name:        "scale-k8s-resource",
+			Description: utils.Ptr("Scale Kubernetes resource supporting scale subresource, such as Deployment, StatefulSet, ReplicaSet or custom resource, optionally waiting for replicas to be ready"),
+			InputSchema: inputSchema.GetMcpToolInputSchema(),
+		},
+		func(ctx context.Context, args map[string]any) *mcp.CallToolResult {
+			input, err := inputSchema.Validate(args)
+			if err != nil {
+				return utils.ErrResponse(err)
+			}
+
+			k8sCtx := defaults.ResolveContext(ctx, input.StringOr(contextProperty, ""))
+
+			kind, err := input.String(kindProperty)
+			if err != nil {
+				return utils.ErrResponse(err)
+			}
+
+			name, err := input.String(nameProperty)
+			if err != nil {
+				return utils.ErrResponse(err)
+			}
+
+			replicas, err := input.Number(replicasProperty)
+			if err != nil {
+				return utils.ErrResponse(err)
+			}
+			if replicas < 0 || replicas > math.MaxInt32 || replicas != math.Trunc(replicas) {
+				return utils.ErrResponse(fmt.Errorf("invalid replicas: %v, expected non-negative integer", replicas))
+			}
+
+			currentReplicas, currentReplicasErr := input.Number(currentReplicasProperty)
+			checkCurrentReplicas := currentReplicasErr == nil
+			if checkCurrentReplicas && (currentReplicas < 0 || currentReplicas > math.MaxInt32 || currentReplicas != math.Trunc(currentReplicas)) {
+				return utils.ErrResponse(fmt.Errorf("invalid currentReplicas: %v, expected non-negative integer", currentReplicas))
+			}
+
+			waitTimeoutSeconds := input.NumberOr(waitTimeoutSecondsProperty, 0)
+			if waitTimeoutSeconds < 0 || waitTimeoutSeconds > maxScaleWaitTimeoutSeconds {
+				return utils.ErrResponse(fmt.Errorf("invalid waitTimeoutSeconds: %v, expected non-negative number not greater than %d", waitTimeoutSeconds, maxScaleWaitTimeoutSeconds))
+			}
+
+			group := input.StringOr(groupProperty, "")
+			version := input.StringOr(versionProperty, "")
+
+			mapping, err := pool.GetRESTMapping(k8sCtx, kind, group, version)
+			if err != nil {
+				return utils.ErrResponse(err)
+			}
+
+			dynamicClient, err := pool.GetDynamicClient(k8sCtx)
+			if err != nil {
+				return utils.ErrResponse(fmt.Errorf("failed to retrieve dynamic client from the client pool: %w", err))
+			}
+
+			namespace := ""
+			var dr dynamic.ResourceInterface
+			if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+				namespace, err = defaults.ResolveNamespace(ctx, k8sCtx, input.StringOr(namespaceProperty, ""))
+				if err != nil {
+					return utils.ErrResponse(err)
+				}
+				dr = dynamicClient.Resource(mapping.Resource).Namespace(namespace)
+			} else {
+				dr = dynamicClient.Resource(mapping.Resource)
+			}
+
+			resource := formatResourceText(mapping.Resource.Resource, mapping.Resource.Group, name)
+
+			scale, err := dr.Get(ctx, name, metav1.GetOptions{}, "scale")
+			if err != nil {
+				if errors.IsNotFound(err) {
+					// resource itself might exist, but not support scale subresource
+					if _, getErr := dr.Get(ctx, name, metav1.GetOptions{}); getErr == nil {
+						return utils.ErrResponse(fmt.Errorf("%s does not support scale subresource", resource))
+					}
+				}
+				return utils.ErrResponse(err)
+			}
+
+			previousReplicas, _, err := unstructured.NestedInt64(scale.Object, "spec", "replicas")
+			if err != nil {
+				return utils.ErrResponse(fmt.Errorf("failed to read replicas of %s: %w", resource, err))
+			}
+
+			if checkCurrentReplicas && int64(currentReplicas) != previousReplicas {
+				return utils.ErrResponse(fmt.Errorf(
+					"expected current replicas of %s to be %d, but it is %d",
+					resource, int64(currentReplicas), previousReplicas,
+				))
+			}
+
+			desiredReplicas := int64(replicas)
+			if err := unstructured.SetNestedField(scale.Object, desiredReplicas, "spec", "replicas"); err != nil {
+				return utils.ErrResponse(err)
+			}
+
+			// resource version of the scale retrieved above makes update fail,
+			// if replicas were changed in the meantime and precondition is no longer valid
+			scale, err = dr.Update(ctx, scale, metav1.UpdateOptions{}, "scale")
+			if err != nil {
+				return utils.ErrResponse(fmt.Errorf("failed to scale %s: %w", resource, err))
+			}
+
+			result := ScaleResultContent{
+				Resource:         resource,
+				Namespace:        namespace,
+				PreviousReplicas: previousReplicas,
+				Replicas:         desiredReplicas,
+				Message:          fmt.Sprintf("%s scaled from %d to %d", resource, previousReplicas, desiredReplicas),
+			}
+
+			if waitTimeoutSeconds > 0 {
+				clientset, err := pool.GetClientset(k8sCtx)
+				if err != nil {
+					return utils.ErrResponse(err)
+				}
+
+				selector, _, _ := unstructured.NestedString(scale.Object, "status", "selector")
+				timeout := time.Duration(waitTimeoutSeconds * float64(time.Second))
+				ready, done, err := waitForScaledReplicas(ctx, dr, clientset, namespace, name, selector, desiredReplicas, timeout)
+				if err != nil {
+					return utils.ErrResponse(err)
+				}
+				result.ReadyReplicas = &ready
+				if done {
+					result.Message += fmt.Sprintf(", %d of %d replicas are ready", ready, desiredReplicas)
+				} else {
+					result.Message += fmt.Sprintf(", timed out waiting for replicas to be ready: %d of %d replicas are ready", ready, desiredReplicas)
+				}
+			}
+
+			c, err := content.NewJsonContent(result)
+			if err != nil {
+				return utils.ErrResponse(err)
+			}
+			return &mcp.CallToolResult{
+				Meta:    namespaceMeta(namespace),
+				Content: []any{c},
+				IsError: utils.Ptr(false),
+			}
+		},
+	)
+}
+
+// waitForScaledReplicas waits until scale subresource reports desired number of replicas
+// and the same number of pods matching selector of the scale are ready, while all others
+// are gone, returns number of ready pods and whether waiting finished before timeout
+func waitForScaledReplicas(
+	ctx context.Context,
+	dr dynamic.ResourceInterface,
+	clientset kubernetes.Interface,
+	namespace string,
+	name string,
+	selector string,
+	desiredReplicas int64,
+	timeout time.Duration,
+) (int64, bool, error) {
+	var ready int64
+	err := wait.PollUntilContextTimeout(ctx, scalePollInterval, timeout, true, func(ctx context.Context) (bool, error) {
+		scale, err := dr.Get(ctx, name, metav1.GetOptions{}, "scale")
+		if err != nil {
+			return false, err
+		}
+		replicas, _, _ := unstructured.NestedInt64(scale.Object, "status", "replicas")
+
+		if selector == "" {
+			// without selector pods can not be found, so rely only on replicas reported by the scale
+			ready = replicas
+			return replicas == desiredReplicas, nil
+		}
+
+		pods, err := clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector})
+		if err != nil {
+			return false, err
+		}
+		var total int64
+		ready = 0
+		for _, p := range pods.Items {
+			total++
+			if p.DeletionTimestamp == nil && pod.IsPodReady(&p) {
+				ready++
+			}
+		}
+		return replicas == desiredReplicas && total == desiredReplicas && ready == desiredReplicas, nil
+	})
+	if err != nil {
+		if wait.Interrupted(err) {
+			return ready, false, nil
+		}
+		return ready, false, err
+	}
+	return ready, true, nil
+}
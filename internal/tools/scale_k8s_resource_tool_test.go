@@ -0,0 +1,173 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/strowk/foxy-contexts/pkg/mcp"
+	"github.com/strowk/foxy-contexts/pkg/session"
+	"github.com/strowk/mcp-k8s-go/internal/k8s"
+	mock_k8s "github.com/strowk/mcp-k8s-go/internal/k8s/mock"
+	"github.com/strowk/mcp-k8s-go/internal/utils"
+	"go.uber.org/mock/gomock"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestScaleK8sResource(t *testing.T) {
+	previousPollInterval := scalePollInterval
+	scalePollInterval = 10 * time.Millisecond
+	defer func() {
+		scalePollInterval = previousPollInterval
+	}()
+
+	mapping := func(kind, resource string) *meta.RESTMapping {
+		return &meta.RESTMapping{
+			Resource:         schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: resource},
+			GroupVersionKind: schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: kind},
+			Scope:            meta.RESTScopeNamespace,
+		}
+	}
+
+	// newScaleClient serves scale subresource of the resource, which fake dynamic client
+	// does not support, status of the scale follows its spec as soon as it is updated
+	newScaleClient := func(resource string, replicas int64) *dynamicfake.FakeDynamicClient {
+		scale := &unstructured.Unstructured{Object: map[string]any{
+			"apiVersion": "autoscaling/v1",
+			"kind":       "Scale",
+			"metadata":   map[string]any{"name": "web", "namespace": "namespace"},
+			"spec":       map[string]any{"replicas": replicas},
+			"status":     map[string]any{"replicas": replicas, "selector": "app=web"},
+		}}
+		client := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme())
+		client.PrependReactor("get", resource, func(action k8stesting.Action) (bool, runtime.Object, error) {
+			return action.GetSubresource() == "scale", scale.DeepCopy(), nil
+		})
+		client.PrependReactor("update", resource, func(action k8stesting.Action) (bool, runtime.Object, error) {
+			if action.GetSubresource() != "scale" {
+				return false, nil, nil
+			}
+			updated := action.(k8stesting.UpdateAction).GetObject().(*unstructured.Unstructured)
+			replicas, _, _ := unstructured.NestedInt64(updated.Object, "spec", "replicas")
+			_ = unstructured.SetNestedField(scale.Object, replicas, "spec", "replicas")
+			_ = unstructured.SetNestedField(scale.Object, replicas, "status", "replicas")
+			return true, scale.DeepCopy(), nil
+		})
+		return client
+	}
+
+	newPod := func(name string, ready bool) *corev1.Pod {
+		status := corev1.ConditionFalse
+		if ready {
+			status = corev1.ConditionTrue
+		}
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "namespace", Labels: map[string]string{"app": "web"}},
+			Status:     corev1.PodStatus{Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: status}}},
+		}
+	}
+
+	call := func(t *testing.T, poolMock *mock_k8s.MockClientPool, args map[string]any) ScaleResultContent {
+		tool := NewScaleK8sResourceTool(poolMock, k8s.NewDefaults(session.NewSessionManager()))
+		resp := tool.Callback(context.Background(), args)
+		require.NotNil(t, resp.IsError)
+		require.False(t, *resp.IsError, resp.Content)
+
+		var result ScaleResultContent
+		require.NoError(t, json.Unmarshal([]byte(resp.Content[0].(mcp.TextContent).Text), &result))
+		return result
+	}
+
+	t.Run("scale StatefulSet and wait for ready replicas", func(t *testing.T) {
+		poolMock := mock_k8s.NewMockClientPool(gomock.NewController(t))
+		poolMock.EXPECT().GetRESTMapping("context", "StatefulSet", "", "").Return(mapping("StatefulSet", "statefulsets"), nil)
+		poolMock.EXPECT().GetDynamicClient("context").Return(newScaleClient("statefulsets", 1), nil)
+		poolMock.EXPECT().GetClientset("context").Return(fake.NewClientset(newPod("web-0", true), newPod("web-1", true)), nil)
+
+		result := call(t, poolMock, map[string]any{
+			"context":            "context",
+			"namespace":          "namespace",
+			"kind":               "StatefulSet",
+			"name":               "web",
+			"replicas":           float64(2),
+			"waitTimeoutSeconds": float64(1),
+		})
+		assert.Equal(t, ScaleResultContent{
+			Resource:         "statefulsets.apps/web",
+			Namespace:        "namespace",
+			PreviousReplicas: 1,
+			Replicas:         2,
+			ReadyReplicas:    utils.Ptr(int64(2)),
+			Message:          "statefulsets.apps/web scaled from 1 to 2, 2 of 2 replicas are ready",
+		}, result)
+	})
+
+	t.Run("scale ReplicaSet and time out waiting for pods to be gone", func(t *testing.T) {
+		poolMock := mock_k8s.NewMockClientPool(gomock.NewController(t))
+		poolMock.EXPECT().GetRESTMapping("context", "ReplicaSet", "", "").Return(mapping("ReplicaSet", "replicasets"), nil)
+		poolMock.EXPECT().GetDynamicClient("context").Return(newScaleClient("replicasets", 3), nil)
+		poolMock.EXPECT().GetClientset("context").Return(fake.NewClientset(newPod("web-a", true), newPod("web-b", false)), nil)
+
+		result := call(t, poolMock, map[string]any{
+			"context":            "context",
+			"namespace":          "namespace",
+			"kind":               "ReplicaSet",
+			"name":               "web",
+			"replicas":           float64(1),
+			"waitTimeoutSeconds": float64(0.05),
+		})
+		assert.Equal(t, int64(3), result.PreviousReplicas)
+		assert.Equal(t, utils.Ptr(int64(1)), result.ReadyReplicas)
+		assert.Equal(t, "replicasets.apps/web scaled from 3 to 1, timed out waiting for replicas to be ready: 1 of 1 replicas are ready", result.Message)
+	})
+
+	t.Run("reject too long waitTimeoutSeconds", func(t *testing.T) {
+		poolMock := mock_k8s.NewMockClientPool(gomock.NewController(t))
+		tool := NewScaleK8sResourceTool(poolMock, k8s.NewDefaults(session.NewSessionManager()))
+		resp := tool.Callback(context.Background(), map[string]any{
+			"context":            "context",
+			"kind":               "Deployment",
+			"name":               "web",
+			"replicas":           float64(1),
+			"waitTimeoutSeconds": float64(301),
+		})
+		require.NotNil(t, resp.IsError)
+		assert.True(t, *resp.IsError)
+		assert.Equal(t, "invalid waitTimeoutSeconds: 301, expected non-negative number not greater than 300", resp.Content[0].(mcp.TextContent).Text)
+	})
+
+	t.Run("reject fractional and too large replicas", func(t *testing.T) {
+		cases := []struct {
+			args    map[string]any
+			message string
+		}{
+			{args: map[string]any{"replicas": float64(2.7)}, message: "invalid replicas: 2.7, expected non-negative integer"},
+			{args: map[string]any{"replicas": float64(math.MaxInt32) + 1}, message: "invalid replicas: 2.147483648e+09, expected non-negative integer"},
+			{args: map[string]any{"replicas": float64(2), "currentReplicas": float64(1.5)}, message: "invalid currentReplicas: 1.5, expected non-negative integer"},
+		}
+		for _, c := range cases {
+			poolMock := mock_k8s.NewMockClientPool(gomock.NewController(t))
+			tool := NewScaleK8sResourceTool(poolMock, k8s.NewDefaults(session.NewSessionManager()))
+			args := map[string]any{"context": "context", "kind": "Deployment", "name": "web"}
+			for key, value := range c.args {
+				args[key] = value
+			}
+			resp := tool.Callback(context.Background(), args)
+			require.NotNil(t, resp.IsError)
+			assert.True(t, *resp.IsError)
+			assert.Equal(t, c.message, resp.Content[0].(mcp.TextContent).Text)
+		}
+	})
+}
@@ -0,0 +1,123 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/strowk/mcp-k8s-go/internal/k8s"
+	"github.com/strowk/mcp-k8s-go/internal/utils"
+
+	"github.com/strowk/foxy-contexts/pkg/fxctx"
+	"github.com/strowk/foxy-contexts/pkg/mcp"
+	"github.com/strowk/foxy-contexts/pkg/toolinput"
+)
+
+func NewSetSessionDefaultsTool(defaults k8s.Defaults) fxctx.Tool {
+	contextProperty := "context"
+	namespaceProperty := "namespace"
+
+	schema := toolinput.NewToolInputSchema(
+		toolinput.WithString(contextProperty, "Name of the Kubernetes context to use by default in this session, empty string resets it to current context from kubeconfig"),
+		toolinput.WithString(namespaceProperty, "Namespace to use by default in this session, empty string resets it to namespace of the context. Reset when context changes, unless given in the same call"),
+	)
+	return fxctx.NewTool(
+		&mcp.Tool{
+			Name:        "set-k8s-session-defaults",
+			Description: utils.Ptr("Set Kubernetes context and namespace used by default for the rest of this session, kubeconfig file is not modified"),
+			InputSchema: schema.GetMcpToolInputSchema(),
+		},
+		func(ctx context.Context, args map[string]interface{}) *mcp.CallToolResult {
+			input, err := schema.Validate(args)
+			if err != nil {
+				return errResponse(err)
+			}
+
+			sessionDefaults := defaults.GetSessionDefaults(ctx)
+
+			if k8sCtx, err := input.String(contextProperty); err == nil && k8sCtx != sessionDefaults.Context {
+				if k8sCtx != "" {
+					if err := validateContext(k8sCtx); err != nil {
+						return errResponse(err)
+					}
+				}
+				sessionDefaults.Context = k8sCtx
+				sessionDefaults.Namespace = ""
+			}
+
+			if namespace, err := input.String(namespaceProperty); err == nil {
+				sessionDefaults.Namespace = namespace
+			}
+
+			defaults.SetSessionDefaults(ctx, sessionDefaults)
+
+			return sessionDefaultsResponse(ctx, defaults)
+		},
+	)
+}
+
+func NewGetSessionDefaultsTool(defaults k8s.Defaults) fxctx.Tool {
+	return fxctx.NewTool(
+		&mcp.Tool{
+			Name:        "get-k8s-session-defaults",
+			Description: utils.Ptr("Get Kubernetes context and namespace used by default in this session"),
+			InputSchema: toolinput.NewToolInputSchema().GetMcpToolInputSchema(),
+		},
+		func(ctx context.Context, args map[string]interface{}) *mcp.CallToolResult {
+			return sessionDefaultsResponse(ctx, defaults)
+		},
+	)
+}
+
+func validateContext(k8sCtx string) error {
+	cfg, err := k8s.GetKubeConfig().RawConfig()
+	if err != nil {
+		return fmt.Errorf("failed to get kubeconfig: %w", err)
+	}
+	if _, ok := cfg.Contexts[k8sCtx]; !ok {
+		return fmt.Errorf("context %s is not found in kubeconfig", k8sCtx)
+	}
+	if !k8s.IsContextAllowed(k8sCtx) {
+		return fmt.Errorf("context %s is not allowed", k8sCtx)
+	}
+	return nil
+}
+
+func sessionDefaultsResponse(ctx context.Context, defaults k8s.Defaults) *mcp.CallToolResult {
+	sessionDefaults := defaults.GetSessionDefaults(ctx)
+
+	k8sCtx := defaults.ResolveContext(ctx, "")
+	namespace, err := defaults.ResolveNamespace(ctx, k8sCtx, "")
+	if err != nil {
+		return errResponse(err)
+	}
+
+	if k8sCtx == "" {
+		k8sCtx, err = k8s.GetCurrentContext()
+		if err != nil {
+			return errResponse(err)
+		}
+	}
+
+	content, err := NewJsonContent(SessionDefaultsContent{
+		Context:   k8sCtx,
+		Namespace: namespace,
+		Session:   sessionDefaults,
+	})
+	if err != nil {
+		return errResponse(err)
+	}
+
+	return &mcp.CallToolResult{
+		Meta:    map[string]interface{}{},
+		Content: []interface{}{content},
+		IsError: utils.Ptr(false),
+	}
+}
+
+// SessionDefaultsContent shows context and namespace which would be used
+// when not given explicitly, together with what was set for the session
+type SessionDefaultsContent struct {
+	Context   string              `json:"context"`
+	Namespace string              `json:"namespace"`
+	Session   k8s.SessionDefaults `json:"session"`
+}
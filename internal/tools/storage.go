@@ -0,0 +1,95 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/strowk/foxy-contexts/pkg/fxctx"
+	"github.com/strowk/foxy-contexts/pkg/mcp"
+	"github.com/strowk/foxy-contexts/pkg/toolinput"
+	"github.com/strowk/mcp-k8s-go/internal/content"
+	"github.com/strowk/mcp-k8s-go/internal/k8s"
+	"github.com/strowk/mcp-k8s-go/internal/k8s/storage"
+	"github.com/strowk/mcp-k8s-go/internal/utils"
+)
+
+func NewDiagnoseStorageTool(pool k8s.ClientPool, defaults k8s.Defaults) fxctx.Tool {
+	inputSchema := toolinput.NewToolInputSchema(
+		toolinput.WithString("context", "Name of the Kubernetes context to use, defaults to current context"),
+		toolinput.WithString("namespace", "Namespace of the claim or the pod, defaults to namespace of the context"),
+		toolinput.WithString("claim", "Name of PersistentVolumeClaim to diagnose"),
+		toolinput.WithString("pod", "Name of pod to diagnose all claims it mounts, used when claim is not set"),
+	)
+
+	return fxctx.NewTool(
+		&mcp.Tool{
+			Name:        "diagnose-k8s-storage",
+			Description: utils.Ptr("Explain PersistentVolumeClaim to PersistentVolume to StorageClass to VolumeAttachment to node chain for a claim or all claims of a pod: binding mode, access modes, capacity and expansion status, reasons of pending claims, zone mismatches and which pods mount the claim. Without claim and pod lists PersistentVolumes, which are not bound, such as Available and Released ones, across the cluster"),
+			InputSchema: inputSchema.GetMcpToolInputSchema(),
+		},
+		func(ctx context.Context, args map[string]any) *mcp.CallToolResult {
+			input, err := inputSchema.Validate(args)
+			if err != nil {
+				return utils.ErrResponse(err)
+			}
+
+			k8sCtx := defaults.ResolveContext(ctx, input.StringOr("context", ""))
+			clientset, err := pool.GetClientset(k8sCtx)
+			if err != nil {
+				return utils.ErrResponse(err)
+			}
+
+			claimName := input.StringOr("claim", "")
+			podName := input.StringOr("pod", "")
+			if claimName == "" && podName == "" {
+				volumes, err := storage.ListUnboundVolumes(ctx, clientset)
+				if err != nil {
+					return utils.ErrResponse(err)
+				}
+				contents := make([]any, 0, len(volumes))
+				for _, volume := range volumes {
+					c, err := content.NewJsonContent(volume)
+					if err != nil {
+						return utils.ErrResponse(err)
+					}
+					contents = append(contents, c)
+				}
+				return &mcp.CallToolResult{
+					Meta:    map[string]any{},
+					Content: contents,
+					IsError: utils.Ptr(false),
+				}
+			}
+
+			namespace, err := defaults.ResolveNamespace(ctx, k8sCtx, input.StringOr("namespace", ""))
+			if err != nil {
+				return utils.ErrResponse(err)
+			}
+
+			var result any
+			if claimName != "" {
+				result, err = storage.DiagnoseClaim(ctx, clientset, namespace, claimName)
+			} else {
+				var diagnoses []storage.ClaimDiagnosis
+				diagnoses, err = storage.DiagnosePodVolumes(ctx, clientset, namespace, podName)
+				if err == nil && len(diagnoses) == 0 {
+					err = fmt.Errorf("pod %s does not mount any PersistentVolumeClaim", podName)
+				}
+				result = diagnoses
+			}
+			if err != nil {
+				return utils.ErrResponse(err)
+			}
+
+			c, err := content.NewJsonContent(result)
+			if err != nil {
+				return utils.ErrResponse(err)
+			}
+			return &mcp.CallToolResult{
+				Meta:    namespaceMeta(namespace),
+				Content: []any{c},
+				IsError: utils.Ptr(false),
+			}
+		},
+	)
+}
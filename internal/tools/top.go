@@ -0,0 +1,151 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/strowk/foxy-contexts/pkg/fxctx"
+	"github.com/strowk/foxy-contexts/pkg/mcp"
+	"github.com/strowk/foxy-contexts/pkg/toolinput"
+	"github.com/strowk/mcp-k8s-go/internal/content"
+	"github.com/strowk/mcp-k8s-go/internal/k8s"
+	"github.com/strowk/mcp-k8s-go/internal/k8s/metrics"
+	"github.com/strowk/mcp-k8s-go/internal/utils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func NewTopPodsTool(pool k8s.ClientPool, defaults k8s.Defaults) fxctx.Tool {
+	inputSchema := toolinput.NewToolInputSchema(
+		toolinput.WithString("context", "Name of the Kubernetes context to use, defaults to current context"),
+		toolinput.WithString("namespace", "Namespace to get usage of pods from, defaults to namespace of the context"),
+		toolinput.WithBoolean("allNamespaces", "Get usage of pods from all namespaces, defaults to false"),
+		toolinput.WithString("labelSelector", "Only get usage of pods matching this label selector"),
+		toolinput.WithString("sortBy", "Sort pods by cpu or memory usage, from the biggest, defaults to sorting by namespace and name"),
+		toolinput.WithBoolean("containers", "Include usage of every container, defaults to false"),
+		toolinput.WithNumber("limit", "Maximum number of pods to return after sorting, defaults to all"),
+		toolinput.WithNumber("memoryLimitThreshold", fmt.Sprintf("Percent of memory limit, above which containers are flagged as near their limit, defaults to %d", int(metrics.DefaultMemoryLimitThreshold*100))),
+	)
+
+	return fxctx.NewTool(
+		&mcp.Tool{
+			Name:        "top-k8s-pods",
+			Description: utils.Ptr("Get CPU and memory usage of Kubernetes pods from metrics API compared with requests and limits, flagging containers near their memory limit, requires metrics-server"),
+			InputSchema: inputSchema.GetMcpToolInputSchema(),
+		},
+		func(ctx context.Context, args map[string]any) *mcp.CallToolResult {
+			input, err := inputSchema.Validate(args)
+			if err != nil {
+				return utils.ErrResponse(err)
+			}
+
+			k8sCtx := defaults.ResolveContext(ctx, input.StringOr("context", ""))
+
+			namespace := metav1.NamespaceAll
+			if !input.BooleanOr("allNamespaces", false) {
+				namespace, err = defaults.ResolveNamespace(ctx, k8sCtx, input.StringOr("namespace", ""))
+				if err != nil {
+					return utils.ErrResponse(err)
+				}
+			}
+
+			limit := int(input.NumberOr("limit", 0))
+			if limit < 0 {
+				return utils.ErrResponse(fmt.Errorf("invalid limit: %d, expected non-negative number", limit))
+			}
+			threshold := input.NumberOr("memoryLimitThreshold", metrics.DefaultMemoryLimitThreshold*100)
+			if threshold <= 0 {
+				return utils.ErrResponse(fmt.Errorf("invalid memoryLimitThreshold: %v, expected positive number", threshold))
+			}
+
+			metricsClient, err := pool.GetMetricsClientset(k8sCtx)
+			if err != nil {
+				return utils.ErrResponse(err)
+			}
+			clientset, err := pool.GetClientset(k8sCtx)
+			if err != nil {
+				return utils.ErrResponse(err)
+			}
+
+			pods, err := metrics.TopPods(ctx, metricsClient, clientset, metrics.TopPodsOptions{
+				Namespace:            namespace,
+				LabelSelector:        input.StringOr("labelSelector", ""),
+				SortBy:               input.StringOr("sortBy", ""),
+				Containers:           input.BooleanOr("containers", false),
+				Limit:                limit,
+				MemoryLimitThreshold: threshold / 100,
+			})
+			if err != nil {
+				return utils.ErrResponse(err)
+			}
+
+			contents := make([]any, 0, len(pods))
+			for _, pod := range pods {
+				c, err := content.NewJsonContent(pod)
+				if err != nil {
+					return utils.ErrResponse(err)
+				}
+				contents = append(contents, c)
+			}
+			return &mcp.CallToolResult{
+				Meta:    namespaceMeta(namespace),
+				Content: contents,
+				IsError: utils.Ptr(false),
+			}
+		},
+	)
+}
+
+func NewTopNodesTool(pool k8s.ClientPool, defaults k8s.Defaults) fxctx.Tool {
+	inputSchema := toolinput.NewToolInputSchema(
+		toolinput.WithString("context", "Name of the Kubernetes context to use, defaults to current context"),
+		toolinput.WithString("labelSelector", "Only get usage of nodes matching this label selector"),
+		toolinput.WithString("sortBy", "Sort nodes by cpu or memory usage, from the biggest, defaults to sorting by name"),
+	)
+
+	return fxctx.NewTool(
+		&mcp.Tool{
+			Name:        "top-k8s-nodes",
+			Description: utils.Ptr("Get CPU and memory usage of Kubernetes nodes from metrics API compared with allocatable resources, requires metrics-server"),
+			InputSchema: inputSchema.GetMcpToolInputSchema(),
+		},
+		func(ctx context.Context, args map[string]any) *mcp.CallToolResult {
+			input, err := inputSchema.Validate(args)
+			if err != nil {
+				return utils.ErrResponse(err)
+			}
+
+			k8sCtx := defaults.ResolveContext(ctx, input.StringOr("context", ""))
+
+			metricsClient, err := pool.GetMetricsClientset(k8sCtx)
+			if err != nil {
+				return utils.ErrResponse(err)
+			}
+			clientset, err := pool.GetClientset(k8sCtx)
+			if err != nil {
+				return utils.ErrResponse(err)
+			}
+
+			nodes, err := metrics.TopNodes(ctx, metricsClient, clientset, metrics.TopNodesOptions{
+				LabelSelector: input.StringOr("labelSelector", ""),
+				SortBy:        input.StringOr("sortBy", ""),
+			})
+			if err != nil {
+				return utils.ErrResponse(err)
+			}
+
+			contents := make([]any, 0, len(nodes))
+			for _, node := range nodes {
+				c, err := content.NewJsonContent(node)
+				if err != nil {
+					return utils.ErrResponse(err)
+				}
+				contents = append(contents, c)
+			}
+			return &mcp.CallToolResult{
+				Meta:    map[string]any{},
+				Content: contents,
+				IsError: utils.Ptr(false),
+			}
+		},
+	)
+}
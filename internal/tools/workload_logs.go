@@ -0,0 +1,290 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/strowk/foxy-contexts/pkg/fxctx"
+	"github.com/strowk/foxy-contexts/pkg/mcp"
+	"github.com/strowk/foxy-contexts/pkg/toolinput"
+	"github.com/strowk/mcp-k8s-go/internal/k8s"
+	"github.com/strowk/mcp-k8s-go/internal/utils"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	defaultLogsConcurrency = 5
+	maxLogsConcurrency     = 20
+)
+
+// maxWorkloadLogsBytes limits total size of logs returned from all containers,
+// as merged logs of many pods can easily be more than client can handle
+var maxWorkloadLogsBytes = 4 * 1024 * 1024
+
+func NewWorkloadLogsTool(pool k8s.ClientPool, defaults k8s.Defaults) fxctx.Tool {
+	schema := toolinput.NewToolInputSchema(
+		toolinput.WithString("context", "Name of the Kubernetes context to use, defaults to current context"),
+		toolinput.WithString("namespace", "Name of the namespace where the pods are located, defaults to namespace of the context"),
+		toolinput.WithString("kind", "Kind of the workload to get logs of pods from: Deployment, StatefulSet, DaemonSet, ReplicaSet or Job, must be set together with name"),
+		toolinput.WithString("name", "Name of the workload, either name or labelSelector must be set"),
+		toolinput.WithString("labelSelector", "Label selector of pods to get logs from, for example app=nginx, either name or labelSelector must be set"),
+		toolinput.WithString("containerName", "Name of the container to get logs from, defaults to all containers of each pod"),
+		toolinput.WithString("grep", "Regular expression to only return matching lines"),
+		toolinput.WithString("sinceDuration", "Only return logs newer than a relative duration like 5s, 2m, or 3h. Only one of sinceTime or sinceDuration may be set."),
+		toolinput.WithString("sinceTime", "Only return logs after a specific date (RFC3339). Only one of sinceTime or sinceDuration may be set."),
+		toolinput.WithNumber("limitBytes", "Maximum bytes of logs to return from each container. Defaults to no limit."),
+		toolinput.WithNumber("tailLines", "Number of lines from the end of the logs of each container to return. Defaults to all lines."),
+		toolinput.WithBoolean("timestamps", "Include RFC3339 timestamp in every line, defaults to false."),
+		toolinput.WithBoolean("previousContainer", "Return previous terminated container logs, defaults to false."),
+		toolinput.WithNumber("maxConcurrency", fmt.Sprintf("Maximum number of containers to fetch logs from at the same time, defaults to %d, at most %d", defaultLogsConcurrency, maxLogsConcurrency)),
+	)
+	return fxctx.NewTool(
+		&mcp.Tool{
+			Name:        "get-k8s-workload-logs",
+			Description: utils.Ptr(fmt.Sprintf("Get logs of all pods of a workload or matching label selector, merged chronologically and prefixed with pod and container names, at most %dMiB of the newest lines are returned", maxWorkloadLogsBytes/1024/1024)),
+			InputSchema: schema.GetMcpToolInputSchema(),
+		},
+		func(ctx context.Context, args map[string]interface{}) *mcp.CallToolResult {
+			input, err := schema.Validate(args)
+			if err != nil {
+				return errResponse(fmt.Errorf("invalid input: %w", err))
+			}
+
+			k8sCtx := defaults.ResolveContext(ctx, input.StringOr("context", ""))
+
+			k8sNamespace, err := defaults.ResolveNamespace(ctx, k8sCtx, input.StringOr("namespace", ""))
+			if err != nil {
+				return errResponse(err)
+			}
+
+			kind := input.StringOr("kind", "")
+			name := input.StringOr("name", "")
+			labelSelector := input.StringOr("labelSelector", "")
+			if (name == "") == (labelSelector == "") {
+				return errResponse(fmt.Errorf("exactly one of name or labelSelector must be set"))
+			}
+			if name != "" && kind == "" {
+				return errResponse(fmt.Errorf("kind must be set together with name"))
+			}
+
+			var grep *regexp.Regexp
+			if grepStr := input.StringOr("grep", ""); grepStr != "" {
+				grep, err = regexp.Compile(grepStr)
+				if err != nil {
+					return errResponse(fmt.Errorf("invalid grep: %w", err))
+				}
+			}
+
+			maxConcurrency := int(input.NumberOr("maxConcurrency", defaultLogsConcurrency))
+			if maxConcurrency < 1 || maxConcurrency > maxLogsConcurrency {
+				return errResponse(fmt.Errorf("invalid maxConcurrency: %d, expected number from 1 to %d", maxConcurrency, maxLogsConcurrency))
+			}
+
+			options, err := podLogOptionsFromInput(input)
+			if err != nil {
+				return errResponse(err)
+			}
+			timestamps := options.Timestamps
+			// timestamps are always requested to merge logs chronologically
+			options.Timestamps = true
+
+			clientset, err := pool.GetClientset(k8sCtx)
+			if err != nil {
+				return errResponse(err)
+			}
+
+			if name != "" {
+				labelSelector, err = getWorkloadPodSelector(ctx, clientset, kind, k8sNamespace, name)
+				if err != nil {
+					return errResponse(err)
+				}
+			}
+
+			pods, err := clientset.CoreV1().Pods(k8sNamespace).List(ctx, metav1.ListOptions{LabelSelector: labelSelector})
+			if err != nil {
+				return errResponse(err)
+			}
+			if len(pods.Items) == 0 {
+				return errResponse(fmt.Errorf("no pods found matching selector %s", labelSelector))
+			}
+
+			containerName := input.StringOr("containerName", "")
+			var sources []podLogSource
+			for i := range pods.Items {
+				pod := &pods.Items[i]
+				for _, container := range pod.Spec.Containers {
+					if containerName == "" || container.Name == containerName {
+						sources = append(sources, podLogSource{pod: pod, container: container.Name})
+					}
+				}
+			}
+			if len(sources) == 0 {
+				return errResponse(fmt.Errorf("container %s is not found in pods matching selector %s", containerName, labelSelector))
+			}
+
+			results := fetchPodLogs(ctx, clientset, sources, options, maxConcurrency)
+
+			var lines []logLine
+			var errors []string
+			for _, result := range results {
+				if result.err != nil {
+					errors = append(errors, fmt.Sprintf("[%s] error: %s", result.source, result.err))
+					continue
+				}
+				for _, line := range result.lines {
+					if grep == nil || grep.MatchString(line.text) {
+						lines = append(lines, line)
+					}
+				}
+			}
+
+			// stable sort keeps order of lines with the same timestamp from the same container
+			sort.SliceStable(lines, func(i, j int) bool {
+				return lines[i].timestamp.Before(lines[j].timestamp)
+			})
+
+			text, omitted := formatLogLines(lines, timestamps, maxWorkloadLogsBytes)
+			contents := []interface{}{
+				mcp.TextContent{
+					Type: "text",
+					Text: text,
+				},
+			}
+			if omitted > 0 {
+				contents = append(contents, mcp.TextContent{
+					Type: "text",
+					Text: fmt.Sprintf(
+						"logs are truncated to %d bytes, %d older lines of %d are omitted, use sinceTime, sinceDuration, tailLines or limitBytes to get less logs",
+						maxWorkloadLogsBytes, omitted, len(lines),
+					),
+				})
+			}
+			if len(errors) > 0 {
+				contents = append(contents, mcp.TextContent{
+					Type: "text",
+					Text: strings.Join(errors, "\n"),
+				})
+			}
+
+			return &mcp.CallToolResult{
+				Meta:    namespaceMeta(k8sNamespace),
+				Content: withNamespaceContent(contents, k8sNamespace),
+				IsError: utils.Ptr(false),
+			}
+		},
+	)
+}
+
+// podLogSource is container of the pod to get logs from
+type podLogSource struct {
+	pod       *v1.Pod
+	container string
+}
+
+func (s podLogSource) String() string {
+	return fmt.Sprintf("%s/%s", s.pod.Name, s.container)
+}
+
+type podLogResult struct {
+	source string
+	lines  []logLine
+	err    error
+}
+
+// fetchPodLogs gets logs of all sources using limited number of workers
+// and returns results in the same order as sources
+func fetchPodLogs(
+	ctx context.Context,
+	clientset kubernetes.Interface,
+	sources []podLogSource,
+	options *v1.PodLogOptions,
+	workers int,
+) []podLogResult {
+	results := make([]podLogResult, len(sources))
+	indexes := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers && w < len(sources); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				source := sources[i]
+				sourceOptions := *options
+				sourceOptions.Container = source.container
+				data, err := getPodLogs(ctx, clientset, source.pod, &sourceOptions)
+				var lines []logLine
+				if err == nil {
+					lines, err = parseLogLines(source.String(), data)
+				}
+				results[i] = podLogResult{
+					source: source.String(),
+					lines:  lines,
+					err:    err,
+				}
+			}
+		}()
+	}
+
+	for i := range sources {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	return results
+}
+
+// getWorkloadPodSelector returns label selector of pods managed by the workload
+func getWorkloadPodSelector(ctx context.Context, clientset kubernetes.Interface, kind string, namespace string, name string) (string, error) {
+	var selector *metav1.LabelSelector
+	switch strings.ToLower(kind) {
+	case "deployment", "deployments", "deploy":
+		deployment, err := clientset.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return "", err
+		}
+		selector = deployment.Spec.Selector
+	case "statefulset", "statefulsets", "sts":
+		statefulSet, err := clientset.AppsV1().StatefulSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return "", err
+		}
+		selector = statefulSet.Spec.Selector
+	case "daemonset", "daemonsets", "ds":
+		daemonSet, err := clientset.AppsV1().DaemonSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return "", err
+		}
+		selector = daemonSet.Spec.Selector
+	case "replicaset", "replicasets", "rs":
+		replicaSet, err := clientset.AppsV1().ReplicaSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return "", err
+		}
+		selector = replicaSet.Spec.Selector
+	case "job", "jobs":
+		job, err := clientset.BatchV1().Jobs(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return "", err
+		}
+		selector = job.Spec.Selector
+	default:
+		return "", fmt.Errorf("unsupported kind %s, expected one of Deployment, StatefulSet, DaemonSet, ReplicaSet or Job", kind)
+	}
+
+	if selector == nil {
+		return "", fmt.Errorf("%s %s does not have selector", kind, name)
+	}
+	podSelector, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		return "", fmt.Errorf("invalid selector of %s %s: %w", kind, name, err)
+	}
+	return podSelector.String(), nil
+}
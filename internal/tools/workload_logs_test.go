@@ -0,0 +1,142 @@
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/strowk/foxy-contexts/pkg/mcp"
+	"github.com/strowk/foxy-contexts/pkg/session"
+	"github.com/strowk/mcp-k8s-go/internal/k8s"
+	mock_k8s "github.com/strowk/mcp-k8s-go/internal/k8s/mock"
+	"go.uber.org/mock/gomock"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestParseLogLines(t *testing.T) {
+	lines, err := parseLogLines("pod/app", []byte(
+		"2024-12-01T19:00:08.000000001Z first\n"+
+			"continuation without timestamp\n"+
+			"2024-12-01T19:00:09Z\n",
+	))
+	require.NoError(t, err)
+
+	first := time.Date(2024, 12, 1, 19, 0, 8, 1, time.UTC)
+	second := time.Date(2024, 12, 1, 19, 0, 9, 0, time.UTC)
+	assert.Equal(t, []logLine{
+		{timestamp: first, source: "pod/app", text: "first"},
+		{timestamp: first, source: "pod/app", text: "continuation without timestamp"},
+		{timestamp: second, source: "pod/app", text: ""},
+	}, lines)
+
+	assert.Equal(t, "[pod/app] first", formatLogLine(lines[0], false))
+	assert.Equal(t, "[pod/app] 2024-12-01T19:00:08.000000001Z first", formatLogLine(lines[0], true))
+
+	text, omitted := formatLogLines(lines, false, 60)
+	assert.Equal(t, "[pod/app] continuation without timestamp\n[pod/app] \n", text)
+	assert.Equal(t, 1, omitted)
+
+	t.Run("line longer than maximum", func(t *testing.T) {
+		lines, err := parseLogLines("pod/app", []byte("short\n"+strings.Repeat("x", maxLogLineBytes+1)+"\n"))
+		assert.EqualError(t, err, "failed to split logs of pod/app into lines: bufio.Scanner: token too long")
+		assert.Equal(t, []logLine{{source: "pod/app", text: "short"}}, lines)
+	})
+}
+
+func TestWorkloadLogs(t *testing.T) {
+	newPod := func(name string, labels map[string]string) *v1.Pod {
+		return &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "namespace", Labels: labels},
+			Spec:       v1.PodSpec{Containers: []v1.Container{{Name: "app"}, {Name: "sidecar"}}},
+		}
+	}
+
+	setup := func(t *testing.T) *mock_k8s.MockClientPool {
+		cntr := gomock.NewController(t)
+		poolMock := mock_k8s.NewMockClientPool(cntr)
+		poolMock.EXPECT().GetClientset("context").Return(fake.NewClientset(
+			&appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "namespace"},
+				Spec: appsv1.DeploymentSpec{
+					Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+				},
+			},
+			newPod("web-1", map[string]string{"app": "web"}),
+			newPod("web-2", map[string]string{"app": "web"}),
+			newPod("other", map[string]string{"app": "other"}),
+		), nil)
+		return poolMock
+	}
+
+	t.Run("Get logs of deployment pods", func(t *testing.T) {
+		tool := NewWorkloadLogsTool(setup(t), k8s.NewDefaults(session.NewSessionManager()))
+		resp := tool.Callback(context.Background(), map[string]any{
+			"context":       "context",
+			"namespace":     "namespace",
+			"kind":          "Deployment",
+			"name":          "web",
+			"containerName": "app",
+		})
+		require.NotNil(t, resp.IsError)
+		assert.False(t, *resp.IsError)
+		assert.Equal(t, "[web-1/app] fake logs\n[web-2/app] fake logs\n", resp.Content[0].(mcp.TextContent).Text)
+	})
+
+	t.Run("Filter logs of pods matching label selector", func(t *testing.T) {
+		tool := NewWorkloadLogsTool(setup(t), k8s.NewDefaults(session.NewSessionManager()))
+		resp := tool.Callback(context.Background(), map[string]any{
+			"context":        "context",
+			"namespace":      "namespace",
+			"labelSelector":  "app=other",
+			"grep":           "^fake",
+			"maxConcurrency": 1.0,
+		})
+		require.NotNil(t, resp.IsError)
+		assert.False(t, *resp.IsError)
+		assert.Equal(t, "[other/app] fake logs\n[other/sidecar] fake logs\n", resp.Content[0].(mcp.TextContent).Text)
+	})
+
+	t.Run("Filter out all logs", func(t *testing.T) {
+		tool := NewWorkloadLogsTool(setup(t), k8s.NewDefaults(session.NewSessionManager()))
+		resp := tool.Callback(context.Background(), map[string]any{
+			"context":       "context",
+			"namespace":     "namespace",
+			"labelSelector": "app=web",
+			"grep":          "error",
+		})
+		require.NotNil(t, resp.IsError)
+		assert.False(t, *resp.IsError)
+		assert.Equal(t, "", resp.Content[0].(mcp.TextContent).Text)
+	})
+
+	t.Run("Truncate logs exceeding maximum size", func(t *testing.T) {
+		previousMaxBytes := maxWorkloadLogsBytes
+		maxWorkloadLogsBytes = 40
+		defer func() {
+			maxWorkloadLogsBytes = previousMaxBytes
+		}()
+
+		tool := NewWorkloadLogsTool(setup(t), k8s.NewDefaults(session.NewSessionManager()))
+		resp := tool.Callback(context.Background(), map[string]any{
+			"context":       "context",
+			"namespace":     "namespace",
+			"labelSelector": "app=web",
+			"containerName": "app",
+		})
+		require.NotNil(t, resp.IsError)
+		assert.False(t, *resp.IsError)
+		require.Len(t, resp.Content, 3)
+		assert.Equal(t, "[web-2/app] fake logs\n", resp.Content[0].(mcp.TextContent).Text)
+		assert.Equal(t,
+			"logs are truncated to 40 bytes, 1 older lines of 2 are omitted, use sinceTime, sinceDuration, tailLines or limitBytes to get less logs",
+			resp.Content[1].(mcp.TextContent).Text,
+		)
+		assert.Equal(t, "served from namespace namespace", resp.Content[2].(mcp.TextContent).Text)
+	})
+}
@@ -0,0 +1,53 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/strowk/foxy-contexts/pkg/mcp"
+)
+
+type progressReporterKey struct{}
+
+// progressReporter sends progress notifications for the request,
+// which asked for them by setting progress token
+type progressReporter struct {
+	token  json.RawMessage
+	notify func(method string, params any) error
+
+	mu       sync.Mutex
+	progress int
+}
+
+// progressParams mirror params of progress notification, while keeping
+// token as it was given, since it could be either string or number
+type progressParams struct {
+	ProgressToken json.RawMessage `json:"progressToken"`
+	Progress      int             `json:"progress"`
+	Message       string          `json:"message,omitempty"`
+}
+
+func withProgressReporter(ctx context.Context, reporter *progressReporter) context.Context {
+	return context.WithValue(ctx, progressReporterKey{}, reporter)
+}
+
+// ReportProgress sends progress notification with the message to the
+// client, if the request served within ctx has progress token, every
+// notification increases progress by one, returns false if nothing was sent
+func ReportProgress(ctx context.Context, message string) bool {
+	reporter, ok := ctx.Value(progressReporterKey{}).(*progressReporter)
+	if !ok || ctx.Err() != nil {
+		return false
+	}
+
+	reporter.mu.Lock()
+	defer reporter.mu.Unlock()
+	reporter.progress++
+	err := reporter.notify((mcp.ProgressNotification{}).GetMethod(), progressParams{
+		ProgressToken: reporter.token,
+		Progress:      reporter.progress,
+		Message:       message,
+	})
+	return err == nil
+}
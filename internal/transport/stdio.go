@@ -0,0 +1,305 @@
+package transport
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	foxyevent "github.com/strowk/foxy-contexts/pkg/foxy_event"
+	"github.com/strowk/foxy-contexts/pkg/jsonrpc2"
+	"github.com/strowk/foxy-contexts/pkg/mcp"
+	"github.com/strowk/foxy-contexts/pkg/server"
+	"github.com/strowk/foxy-contexts/pkg/session"
+)
+
+// maxQueuedRequests is how many requests can wait for the one being
+// handled, before reading of input stops until queue is drained
+const maxQueuedRequests = 256
+
+// NewStdioTransport creates transport, which works like stdio transport
+// of foxy-contexts, but keeps reading input while request is handled,
+// so that the client could cancel it, and lets tools send notifications
+// to report progress of the request, see ReportProgress
+func NewStdioTransport() server.Transport {
+	return newStdioTransport(os.Stdin, os.Stdout)
+}
+
+func newStdioTransport(in io.Reader, out io.Writer) *stdioTransport {
+	return &stdioTransport{
+		shuttingDown:   make(chan struct{}),
+		stopped:        make(chan struct{}),
+		in:             in,
+		out:            out,
+		sessionManager: session.NewSessionManager(),
+		inFlight:       map[string]context.CancelFunc{},
+		cancelled:      map[string]bool{},
+	}
+}
+
+type stdioTransport struct {
+	shuttingDown chan struct{}
+	stopped      chan struct{}
+
+	in  io.Reader
+	out io.Writer
+	// writing guards out, as responses and notifications
+	// are written from different goroutines
+	writing sync.Mutex
+
+	sessionManager *session.SessionManager
+
+	// requests guards inFlight and cancelled, which are keyed
+	// by JSON representation of id of the request
+	requests  sync.Mutex
+	inFlight  map[string]context.CancelFunc
+	cancelled map[string]bool
+}
+
+// message is the part of JSON-RPC message needed to route it
+type message struct {
+	Id     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+	Params struct {
+		Meta struct {
+			ProgressToken json.RawMessage `json:"progressToken"`
+		} `json:"_meta"`
+		RequestId json.RawMessage `json:"requestId"`
+	} `json:"params"`
+}
+
+type queuedRequest struct {
+	ctx   context.Context
+	key   string
+	input []byte
+}
+
+func (s *stdioTransport) Run(
+	capabilities *mcp.ServerCapabilities,
+	serverInfo *mcp.Implementation,
+	options ...server.ServerOption,
+) error {
+	srv := server.NewServer(capabilities, serverInfo, options...)
+
+	// local stdio transport is using only one session per whole execution
+	ctx, _, err := s.sessionManager.CreateNewSession(context.Background(), nil)
+	if err != nil {
+		srv.GetLogger().LogEvent(foxyevent.FailedCreatingSession{Err: err})
+		return fmt.Errorf("failed to create session: %w", err)
+	}
+
+	stoppedWriting := make(chan struct{})
+	go func() {
+		defer close(stoppedWriting)
+		for {
+			select {
+			case <-s.shuttingDown:
+				return
+			case res := <-srv.GetResponses():
+				if s.wasCancelled(res.Id) {
+					// cancelled requests are not supposed to be answered
+					continue
+				}
+				data, err := jsonrpc2.Marshal(res.Id, res.Result, res.Error)
+				if err != nil {
+					srv.GetLogger().LogEvent(foxyevent.StdioFailedMarhalResponse{Err: err})
+					continue
+				}
+				srv.GetLogger().LogEvent(foxyevent.StdioSendingResponse{Data: data})
+				if err := s.write(data); err != nil {
+					srv.GetLogger().LogEvent(foxyevent.StdioFailedWriting{Err: err})
+					return
+				}
+			}
+		}
+	}()
+
+	// requests are handled one at a time in the order they come,
+	// while input is read further to receive cancellations
+	queue := make(chan queuedRequest, maxQueuedRequests)
+	stoppedHandling := make(chan struct{})
+	go func() {
+		defer close(stoppedHandling)
+		for request := range queue {
+			srv.Handle(request.ctx, request.input)
+			s.finish(request.key)
+		}
+	}()
+
+	stoppedReading := make(chan struct{})
+	go func() {
+		defer close(stoppedReading)
+		defer close(queue)
+		reader := bufio.NewReader(s.in)
+		for {
+			input, err := reader.ReadBytes('\n')
+			if err != nil {
+				if !errors.Is(err, io.EOF) {
+					srv.GetLogger().LogEvent(foxyevent.StdioFailedReadingInput{Err: err})
+				}
+				return
+			}
+			if request, ok := s.route(ctx, input); ok {
+				queue <- request
+			}
+		}
+	}()
+
+	select {
+	case <-s.shuttingDown:
+	case <-stoppedReading:
+		// wait for queued requests to be answered
+		// and then initiate transport shutdown
+		<-stoppedHandling
+		safeClose(s.shuttingDown)
+	}
+
+	<-stoppedWriting
+	close(s.stopped)
+	return nil
+}
+
+// route handles cancellation notifications and prepares context
+// for other messages, which should be passed to the server
+func (s *stdioTransport) route(ctx context.Context, input []byte) (queuedRequest, bool) {
+	var msg message
+	if err := json.Unmarshal(input, &msg); err != nil {
+		// let the server report the error, it could also be a batch
+		return queuedRequest{ctx: ctx, input: input}, true
+	}
+
+	if msg.Method == (mcp.CancelledNotification{}).GetMethod() {
+		s.cancel(idKey(msg.Params.RequestId))
+		return queuedRequest{}, false
+	}
+
+	key := idKey(msg.Id)
+	if key == "" {
+		// notifications cannot be cancelled or report progress
+		return queuedRequest{ctx: ctx, input: input}, true
+	}
+
+	requestCtx, cancel := context.WithCancel(ctx)
+	if len(msg.Params.Meta.ProgressToken) > 0 {
+		requestCtx = withProgressReporter(requestCtx, &progressReporter{
+			token:  msg.Params.Meta.ProgressToken,
+			notify: s.notify,
+		})
+	}
+
+	s.requests.Lock()
+	s.inFlight[key] = cancel
+	s.requests.Unlock()
+
+	return queuedRequest{ctx: requestCtx, key: key, input: input}, true
+}
+
+func (s *stdioTransport) cancel(key string) {
+	s.requests.Lock()
+	defer s.requests.Unlock()
+	if cancel, ok := s.inFlight[key]; ok {
+		s.cancelled[key] = true
+		cancel()
+	}
+}
+
+func (s *stdioTransport) finish(key string) {
+	if key == "" {
+		return
+	}
+	s.requests.Lock()
+	defer s.requests.Unlock()
+	if cancel, ok := s.inFlight[key]; ok {
+		cancel()
+		delete(s.inFlight, key)
+	}
+}
+
+// wasCancelled tells whether response has to be dropped, because
+// request was cancelled, this is only asked once for every response
+func (s *stdioTransport) wasCancelled(id jsonrpc2.RequestId) bool {
+	if id.IdIsMissing || id.IdIsNull {
+		return false
+	}
+	data, err := json.Marshal(id)
+	if err != nil {
+		return false
+	}
+	key := idKey(data)
+
+	s.requests.Lock()
+	defer s.requests.Unlock()
+	cancelled := s.cancelled[key]
+	delete(s.cancelled, key)
+	return cancelled
+}
+
+func (s *stdioTransport) notify(method string, params any) error {
+	data, err := json.Marshal(struct {
+		JsonRpc string `json:"jsonrpc"`
+		Method  string `json:"method"`
+		Params  any    `json:"params"`
+	}{
+		JsonRpc: "2.0",
+		Method:  method,
+		Params:  params,
+	})
+	if err != nil {
+		return err
+	}
+	return s.write(data)
+}
+
+func (s *stdioTransport) write(data []byte) error {
+	s.writing.Lock()
+	defer s.writing.Unlock()
+	if _, err := s.out.Write(append(data, '\n')); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (s *stdioTransport) Shutdown(ctx context.Context) error {
+	safeClose(s.shuttingDown)
+
+	// this waits either till we are stopped or we cannot wait anymore
+	select {
+	case <-s.stopped:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *stdioTransport) GetSessionManager() *session.SessionManager {
+	return s.sessionManager
+}
+
+// idKey normalizes JSON-RPC id, so that the same id would have the
+// same key whether it comes from request, cancellation or response,
+// empty key is returned if there is no id
+func idKey(id json.RawMessage) string {
+	var value any
+	if err := json.Unmarshal(id, &value); err != nil || value == nil {
+		return ""
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+func safeClose(ch chan struct{}) {
+	defer func() {
+		//nolint:errcheck // it is ok to ignore if there was no panic
+		recover()
+		// channel could be already closed if Shutdown
+		// is called soon after transport is stopped
+	}()
+	close(ch)
+}
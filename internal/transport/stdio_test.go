@@ -0,0 +1,74 @@
+package transport
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/strowk/foxy-contexts/pkg/jsonrpc2"
+	"github.com/strowk/foxy-contexts/pkg/mcp"
+	"github.com/strowk/foxy-contexts/pkg/server"
+)
+
+func TestStdioTransport(t *testing.T) {
+	inReader, inWriter := io.Pipe()
+	outReader, outWriter := io.Pipe()
+	output := bufio.NewReader(outReader)
+
+	reported := make(chan bool, 1)
+	cancelled := make(chan struct{})
+	callTool := func(ctx context.Context, req jsonrpc2.Request) (jsonrpc2.Result, *jsonrpc2.Error) {
+		reported <- ReportProgress(ctx, "line 1")
+		if req.(*mcp.CallToolRequest).Params.Name == "wait" {
+			<-ctx.Done()
+			close(cancelled)
+		}
+		return &mcp.CallToolResult{Content: []any{}}, nil
+	}
+
+	tp := newStdioTransport(inReader, outWriter)
+	stopped := make(chan error)
+	go func() {
+		stopped <- tp.Run(
+			&mcp.ServerCapabilities{},
+			&mcp.Implementation{Name: "test", Version: "test"},
+			server.ServerStartCallbackOption{Callback: func(s server.Server) {
+				s.SetRequestHandler(&mcp.CallToolRequest{}, callTool)
+			}},
+		)
+	}()
+
+	send := func(message string) {
+		_, err := inWriter.Write([]byte(message + "\n"))
+		require.NoError(t, err)
+	}
+	receive := func() string {
+		line, err := output.ReadString('\n')
+		require.NoError(t, err)
+		return line
+	}
+
+	t.Run("answer request without progress token", func(t *testing.T) {
+		send(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"quick"}}`)
+		assert.JSONEq(t, `{"jsonrpc":"2.0","id":1,"result":{"content":[]}}`, receive())
+		assert.False(t, <-reported)
+	})
+
+	t.Run("report progress and drop response of cancelled request", func(t *testing.T) {
+		send(`{"jsonrpc":"2.0","id":"two","method":"tools/call","params":{"name":"wait","_meta":{"progressToken":"token"}}}`)
+		assert.JSONEq(t, `{"jsonrpc":"2.0","method":"notifications/progress","params":{"progressToken":"token","progress":1,"message":"line 1"}}`, receive())
+		assert.True(t, <-reported)
+
+		send(`{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":"two"}}`)
+		<-cancelled
+
+		send(`{"jsonrpc":"2.0","id":3,"method":"ping"}`)
+		assert.JSONEq(t, `{"jsonrpc":"2.0","id":3,"result":{}}`, receive())
+	})
+
+	require.NoError(t, inWriter.Close())
+	require.NoError(t, <-stopped)
+}
@@ -0,0 +1,73 @@
+package utils
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// Change describes difference between two objects at particular path
+type Change struct {
+	Path   string `json:"path"`
+	Before any    `json:"before,omitempty"`
+	After  any    `json:"after,omitempty"`
+}
+
+// Diff compares two objects decoded from JSON, such as unstructured
+// content of Kubernetes resources, and returns changes sorted by path,
+// where path is written like "spec.template.spec.containers[0].image"
+func Diff(before, after any) []Change {
+	changes := []Change{}
+	collectChanges("", before, after, &changes)
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].Path < changes[j].Path
+	})
+	return changes
+}
+
+func collectChanges(path string, before, after any, changes *[]Change) {
+	beforeMap, beforeIsMap := before.(map[string]any)
+	afterMap, afterIsMap := after.(map[string]any)
+	if beforeIsMap && afterIsMap {
+		for key, beforeValue := range beforeMap {
+			collectChanges(joinPath(path, key), beforeValue, afterMap[key], changes)
+		}
+		for key, afterValue := range afterMap {
+			if _, ok := beforeMap[key]; !ok {
+				collectChanges(joinPath(path, key), nil, afterValue, changes)
+			}
+		}
+		return
+	}
+
+	beforeSlice, beforeIsSlice := before.([]any)
+	afterSlice, afterIsSlice := after.([]any)
+	if beforeIsSlice && afterIsSlice {
+		for i := 0; i < len(beforeSlice) || i < len(afterSlice); i++ {
+			var beforeValue, afterValue any
+			if i < len(beforeSlice) {
+				beforeValue = beforeSlice[i]
+			}
+			if i < len(afterSlice) {
+				afterValue = afterSlice[i]
+			}
+			collectChanges(fmt.Sprintf("%s[%d]", path, i), beforeValue, afterValue, changes)
+		}
+		return
+	}
+
+	if !reflect.DeepEqual(before, after) {
+		*changes = append(*changes, Change{
+			Path:   path,
+			Before: before,
+			After:  after,
+		})
+	}
+}
+
+func joinPath(path string, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,47 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiff(t *testing.T) {
+	before := map[string]any{
+		"spec": map[string]any{
+			"replicas": int64(1),
+			"paused":   true,
+			"template": map[string]any{
+				"spec": map[string]any{
+					"containers": []any{
+						map[string]any{"name": "app", "image": "nginx:1.27.2"},
+						map[string]any{"name": "sidecar", "image": "busybox"},
+					},
+				},
+			},
+		},
+	}
+	after := map[string]any{
+		"spec": map[string]any{
+			"replicas": int64(3),
+			"template": map[string]any{
+				"spec": map[string]any{
+					"containers": []any{
+						map[string]any{"name": "app", "image": "nginx:1.27.3"},
+					},
+				},
+			},
+			"minReadySeconds": int64(10),
+		},
+	}
+
+	assert.Equal(t, []Change{
+		{Path: "spec.minReadySeconds", After: int64(10)},
+		{Path: "spec.paused", Before: true},
+		{Path: "spec.replicas", Before: int64(1), After: int64(3)},
+		{Path: "spec.template.spec.containers[0].image", Before: "nginx:1.27.2", After: "nginx:1.27.3"},
+		{Path: "spec.template.spec.containers[1]", Before: map[string]any{"name": "sidecar", "image": "busybox"}},
+	}, Diff(before, after))
+
+	assert.Empty(t, Diff(before, before))
+}
@@ -0,0 +1,11 @@
+package utils
+
+// FirstNonEmpty returns the first of values, which is not empty
+func FirstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
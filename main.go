@@ -8,16 +8,19 @@ import (
 	"github.com/strowk/mcp-k8s-go/internal/config"
 	"github.com/strowk/mcp-k8s-go/internal/k8s"
 	"github.com/strowk/mcp-k8s-go/internal/k8s/apps/v1/deployment"
+	"github.com/strowk/mcp-k8s-go/internal/k8s/core/v1/persistentvolume"
+	"github.com/strowk/mcp-k8s-go/internal/k8s/core/v1/persistentvolumeclaim"
 	"github.com/strowk/mcp-k8s-go/internal/k8s/core/v1/service"
 	"github.com/strowk/mcp-k8s-go/internal/k8s/list_mapping"
+	"github.com/strowk/mcp-k8s-go/internal/k8s/metrics"
 	"github.com/strowk/mcp-k8s-go/internal/prompts"
 	"github.com/strowk/mcp-k8s-go/internal/resources"
 	"github.com/strowk/mcp-k8s-go/internal/tools"
+	"github.com/strowk/mcp-k8s-go/internal/transport"
 	"github.com/strowk/mcp-k8s-go/internal/utils"
 
 	"github.com/strowk/foxy-contexts/pkg/app"
 	"github.com/strowk/foxy-contexts/pkg/mcp"
-	"github.com/strowk/foxy-contexts/pkg/stdio"
 
 	"go.uber.org/fx"
 	"go.uber.org/fx/fxevent"
@@ -96,6 +99,10 @@ func printHelp() {
 	println("      If not specified, all tools are available")
 	println("  --mask-secrets: Mask secrets in the output")
 	println("      If not specified, secrets are masked by default. Use --mask-secrets=false to disable masking")
+	println("  --protection-label=<key|key=value>: Label marking objects which cannot be deleted unless forced")
+	println("      If not specified, mcp-k8s-go/protected label is used")
+	println("  --sample-metrics: Sample usage of pods in background for resource recommendations")
+	println("      If not specified, usage is sampled by default. Use --sample-metrics=false to disable sampling")
 }
 
 func getApp() *app.Builder {
@@ -108,6 +115,12 @@ func getApp() *app.Builder {
 			fx.Provide(func() (*kubernetes.Clientset, error) {
 				return k8s.GetKubeClientset()
 			}),
+			fx.Provide(k8s.NewDefaults),
+			fx.Provide(func(lc fx.Lifecycle, pool k8s.ClientPool) *metrics.Sampler {
+				sampler := metrics.NewSampler(pool)
+				lc.Append(fx.StopHook(sampler.Stop))
+				return sampler
+			}),
 			fx.Provide(fx.Annotate(
 				func(listMappingResolvers []list_mapping.ListMappingResolver) k8s.ClientPool {
 					return k8s.NewClientPool(listMappingResolvers)
@@ -124,14 +137,42 @@ func getApp() *app.Builder {
 					return service.NewListMappingResolver()
 				}),
 			),
+			fx.Provide(
+				list_mapping.AsMappingResolver(func() list_mapping.ListMappingResolver {
+					return persistentvolumeclaim.NewListMappingResolver()
+				}),
+			),
+			fx.Provide(
+				list_mapping.AsMappingResolver(func() list_mapping.ListMappingResolver {
+					return persistentvolume.NewListMappingResolver()
+				}),
+			),
 		).
 		WithTool(tools.NewPodLogsTool).
+		WithTool(tools.NewWorkloadLogsTool).
 		WithTool(tools.NewListContextsTool).
 		WithTool(tools.NewListNamespacesTool).
+		WithTool(tools.NewNamespaceInventoryTool).
 		WithTool(tools.NewListResourcesTool).
 		WithTool(tools.NewGetResourceTool).
 		WithTool(tools.NewListNodesTool).
+		WithTool(tools.NewClusterHealthTool).
+		WithTool(tools.NewNodeAllocationTool).
+		WithTool(tools.NewAnalyzeQuotaTool).
+		WithTool(tools.NewDiagnoseStorageTool).
+		WithTool(tools.NewTopPodsTool).
+		WithTool(tools.NewTopNodesTool).
+		WithTool(tools.NewRecommendResourcesTool).
 		WithTool(tools.NewListEventsTool).
+		WithTool(tools.NewEventTimelineTool).
+		WithTool(tools.NewDiagnosePodTool).
+		WithTool(tools.NewExplainPodSchedulingTool).
+		WithTool(tools.NewDiagnoseServiceTool).
+		WithTool(tools.NewGetRolloutStatusTool).
+		WithTool(tools.NewGetRolloutHistoryTool).
+		WithTool(tools.NewDiagnoseDeploymentRolloutTool).
+		WithTool(tools.NewGetSessionDefaultsTool).
+		WithTool(tools.NewSetSessionDefaultsTool).
 		WithPrompt(prompts.NewListPodsPrompt).
 		WithPrompt(prompts.NewListNamespacesPrompt).
 		WithResourceProvider(resources.NewContextsResourceProvider).
@@ -139,7 +180,7 @@ func getApp() *app.Builder {
 		// setting up server
 		WithName("mcp-k8s-go").
 		WithVersion(version).
-		WithTransport(stdio.NewTransport()).
+		WithTransport(transport.NewStdioTransport()).
 		// Configuring fx logging to only show errors
 		WithFxOptions(
 			fx.Provide(func() *zap.Logger {
@@ -165,6 +206,15 @@ func getApp() *app.Builder {
 		return app
 	}
 
-	app = app.WithTool(tools.NewApplyK8sResourceTool).WithTool(tools.NewPodExecCommandTool)
+	app = app.
+		WithTool(tools.NewApplyK8sResourceTool).
+		WithTool(tools.NewDeleteK8sResourceTool).
+		WithTool(tools.NewPatchK8sResourceTool).
+		WithTool(tools.NewRolloutK8sResourceTool).
+		WithTool(tools.NewScaleK8sResourceTool).
+		WithTool(tools.NewCordonNodeTool).
+		WithTool(tools.NewUncordonNodeTool).
+		WithTool(tools.NewDrainNodeTool).
+		WithTool(tools.NewPodExecCommandTool)
 	return app
 }
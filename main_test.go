@@ -92,8 +92,10 @@ func TestInK3dCluster(t *testing.T) {
 		{name: "internal/k8s/core/v1/pod"},
 		{name: "internal/k8s/core/v1/node"},
 		{name: "internal/k8s/core/v1/service"},
+		{name: "internal/k8s/core/v1/persistentvolumeclaim"},
 		{name: "internal/k8s/core/v1/secret"},
 		{name: "internal/k8s/core/v1/secret-not-masked", args: []string{"--mask-secrets=false"}},
+		{name: "internal/k8s/storage"},
 	}
 
 	withK3dCluster(t, k3dClusterName, func() {